@@ -7,7 +7,6 @@ package blacklist
 
 import (
 	"dtools2/extras"
-	"dtools2/rest"
 	"fmt"
 	"strings"
 
@@ -40,7 +39,7 @@ func (rb *ResourceBlacklist) Add(resourceType, name string) (bool, *ce.CustomErr
 	slice = append(slice, name)
 	*slicePtr = slice
 
-	if !rest.QuietOutput {
+	if !extras.QuietOutput {
 		fmt.Println(hftx.NoteSign("Resource " + name + " now blacklisted from " + resourceType))
 	}
 	return true, nil
@@ -107,7 +106,7 @@ func (rb *ResourceBlacklist) Remove(resourceType, name string) (bool, *ce.Custom
 		*slicePtr = out
 	}
 
-	if !rest.QuietOutput {
+	if !extras.QuietOutput {
 		fmt.Println(hftx.NoteSign("Resource " + name + " removed from the " + resourceType + " list"))
 	}
 	return removed, nil
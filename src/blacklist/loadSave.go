@@ -50,6 +50,9 @@ func (rb *ResourceBlacklist) Save() *ce.CustomError {
 		return &ce.CustomError{Title: "cannot marshal blacklist struct", Message: err.Error()}
 	}
 
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return &ce.CustomError{Title: "cannot create the directory of " + path, Message: err.Error()}
+	}
 	// 0600 so only the user can read/write
 	if err := os.WriteFile(path, data, 0o600); err != nil {
 		return &ce.CustomError{Title: "cannot write blacklist file " + path, Message: err.Error()}
@@ -10,6 +10,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"os"
@@ -25,21 +26,21 @@ import (
 
 // BuildImage emulates `docker build` using the daemon API: POST /build.
 // This works for both Docker Engine and Podman service when using the compat API.
-func BuildImage(client *rest.Client, contextDir string) error {
-	ctx := rest.Context
-	if ctx == nil {
-		ctx = context.Background()
+func BuildImage(ctx context.Context, client *rest.Client, contextDir string, opts Options) error {
+	out := opts.Out
+	if out == nil {
+		out = io.Discard
 	}
 
-	progressMode := strings.ToLower(strings.TrimSpace(Progress))
+	progressMode := strings.ToLower(strings.TrimSpace(opts.Progress))
 	if progressMode == "" {
 		progressMode = "auto"
 	}
 	if progressMode != "auto" && progressMode != "plain" && progressMode != "tty" {
-		return fmt.Errorf("invalid --progress %q (supported: auto|plain|tty)", Progress)
+		return fmt.Errorf("invalid --progress %q (supported: auto|plain|tty)", opts.Progress)
 	}
 
-	dfRel, err := dockerfileRelative(contextDir)
+	dfRel, err := dockerfileRelative(contextDir, opts.Dockerfile)
 	if err != nil {
 		return err
 	}
@@ -47,41 +48,41 @@ func BuildImage(client *rest.Client, contextDir string) error {
 	q := url.Values{}
 	q.Set("dockerfile", dfRel)
 
-	for _, t := range Tags {
+	for _, t := range opts.Tags {
 		if strings.TrimSpace(t) == "" {
 			continue
 		}
 		q.Add("t", t)
 	}
 
-	if Pull {
+	if opts.Pull {
 		q.Set("pull", "true")
 	}
-	if NoCache {
+	if opts.NoCache {
 		q.Set("nocache", "true")
 	}
 
 	// docker default is rm=true
-	if RemoveIntermediate {
+	if opts.RemoveIntermediate {
 		q.Set("rm", "true")
 	} else {
 		q.Set("rm", "false")
 	}
 
-	if ForceRemoveIntermediate {
+	if opts.ForceRemoveIntermediate {
 		q.Set("forcerm", "true")
 	}
 
-	if Target != "" {
-		q.Set("target", Target)
+	if opts.Target != "" {
+		q.Set("target", opts.Target)
 	}
 
-	if Platform != "" {
-		q.Set("platform", Platform)
+	if opts.Platform != "" {
+		q.Set("platform", opts.Platform)
 	}
 
-	if len(BuildArgs) > 0 {
-		m, err := parseBuildArgs(BuildArgs)
+	if len(opts.BuildArgs) > 0 {
+		m, err := parseBuildArgs(opts.BuildArgs)
 		if err != nil {
 			return err
 		}
@@ -106,14 +107,19 @@ func BuildImage(client *rest.Client, contextDir string) error {
 		headers.Set("X-Registry-Config", h)
 	}
 
-	resp, err := client.Do(ctx, http.MethodPost, "/build", q, body, headers)
+	// Build steps may stay silent for a long time (compiling, downloading...): no idle timeout here.
+	resp, err := client.Do(rest.FollowContext(ctx), http.MethodPost, "/build", q, body, headers)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
+	if aerr := rest.CheckResponse(resp); aerr != nil {
+		return aerr
+	}
+
 	// Stream build output (Docker JSON message stream).
-	termFd, autoIsTerm := term.GetFdInfo(os.Stdout)
+	termFd, autoIsTerm := term.GetFdInfo(out)
 	isTerm := autoIsTerm
 
 	// `--progress=tty` is meaningful for BuildKit-style output.
@@ -140,21 +146,15 @@ func BuildImage(client *rest.Client, contextDir string) error {
 		isTerm = true
 	}
 
-	if derr := jsonmessage.DisplayJSONMessagesStream(resp.Body, os.Stdout, termFd, isTerm, nil); derr != nil {
+	if derr := jsonmessage.DisplayJSONMessagesStream(resp.Body, out, termFd, isTerm, nil); derr != nil {
 		// Fallback: if daemon doesn't speak exact docker JSONMessage stream.
 		// Still expose status code below.
-		_, _ = ioCopyAll(os.Stdout, resp.Body)
-	}
-
-	if resp.StatusCode >= 400 {
-		return fmt.Errorf("build failed: %s", resp.Status)
+		_, _ = io.Copy(out, resp.Body)
 	}
-
 	return nil
 }
 
-func dockerfileRelative(contextDir string) (string, error) {
-	df := Dockerfile
+func dockerfileRelative(contextDir, df string) (string, error) {
 	if strings.TrimSpace(df) == "" {
 		df = "Dockerfile"
 	}
@@ -266,22 +266,3 @@ func buildRegistryConfigHeader() (string, error) {
 	// Docker expects URL-safe base64 for these auth headers.
 	return base64.URLEncoding.EncodeToString(b), nil
 }
-
-// local helper to avoid importing io in multiple files
-func ioCopyAll(dst *os.File, src interface{ Read([]byte) (int, error) }) (int64, error) {
-	buf := make([]byte, 32*1024)
-	var n int64
-	for {
-		r, err := src.Read(buf)
-		if r > 0 {
-			w, werr := dst.Write(buf[:r])
-			n += int64(w)
-			if werr != nil {
-				return n, werr
-			}
-		}
-		if err != nil {
-			return n, err
-		}
-	}
-}
@@ -82,8 +82,8 @@ func pingBuilderVersion(ctx context.Context, client *rest.Client, method string)
 	}
 	defer resp2.Body.Close()
 
-	if resp2.StatusCode >= 400 {
-		return "", false, fmt.Errorf("%s /_ping returned %s", method, resp2.Status)
+	if aerr := rest.CheckResponse(resp2); aerr != nil {
+		return "", false, aerr
 	}
 
 	bv := strings.TrimSpace(resp2.Header.Get("Builder-Version"))
@@ -5,40 +5,27 @@
 
 package build
 
-import "regexp"
-
-// CLI-bound flags (wired in cmd/root.go).
-//
-
-// Dockerfile is the path (in the context dir) to the Dockerfile. Wired to -f / --file
-var Dockerfile string = "Dockerfile"
-
-// Tags is a repeatable list of image tags. Wired to -t / --tag
-var Tags []string
-
-// BuildArgs is a repeatable list of KEY=VALUE (or KEY) build arguments. Wired to --build-arg
-var BuildArgs []string
-
-// NoCache disables build cache. Wired to: --no-cache
-var NoCache bool
-
-// Pull attempts to pull newer base images.  Wired to: --pull
-var Pull bool
-
-// RemoveIntermediate removes intermediate containers after successful build. Wired to: --rm
-var RemoveIntermediate bool = true
-
-// ForceRemoveIntermediate always removes intermediate containers, even on failure. Wired to: --force-rm
-var ForceRemoveIntermediate bool
-
-// Target is an optional multi-stage target. Wired to: --target
-var Target string
-
-// Platform is an optional platform (BuildKit / compatible daemons). Wired to: --platform
-var Platform string
-
-// Progress controls how build output is rendered. Values: auto | plain | tty. Wired to: --progress
-var Progress string = "auto"
+import (
+	"io"
+	"regexp"
+)
+
+// Options controls BuildImage(), our `docker build`.
+type Options struct {
+	Dockerfile              string   // -f: path (in the context dir) to the Dockerfile; empty means "Dockerfile"
+	Tags                    []string // -t: repeatable list of image tags
+	BuildArgs               []string // --build-arg: repeatable list of KEY=VALUE (or KEY) build arguments
+	NoCache                 bool     // --no-cache: disables build cache
+	Pull                    bool     // --pull: attempts to pull newer base images
+	RemoveIntermediate      bool     // --rm: removes intermediate containers after successful build (docker defaults to true)
+	ForceRemoveIntermediate bool     // --force-rm: always removes intermediate containers, even on failure
+	Target                  string   // --target: optional multi-stage target
+	Platform                string   // --platform: optional platform (BuildKit / compatible daemons)
+	Progress                string   // --progress: how build output is rendered; auto | plain | tty (empty means auto)
+
+	// Where the build output goes; nil discards it.
+	Out io.Writer
+}
 
 // Other vars and structs
 //
@@ -0,0 +1,248 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 02:50
+// Original filename: src/cmd/composeCommands.go
+
+package cmd
+
+import (
+	"dtools2/compose"
+	"dtools2/extras"
+	"fmt"
+	"os"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+	hftx "github.com/jeanfrancoisgratton/helperFunctions/v4/terminalfx"
+	"github.com/spf13/cobra"
+)
+
+var composeCmd = &cobra.Command{
+	Use:   "compose",
+	Short: "Manage multi-container stacks from a compose file",
+	Long: `Read a compose file (compose.yaml, docker-compose.yml...) and manage its stack: services, networks and volumes.
+Everything is labelled with the project name, the compose file directory name unless -p or the name: key says otherwise.
+A practical subset of the Compose spec is supported; the keys we do not support are reported, then ignored.`,
+}
+
+var composeUpCmd = &cobra.Command{
+	Use:     "up [flags] [SERVICE...]",
+	Short:   "Create and start the stack",
+	Example: "dtools compose up -d\ndtools compose -f stack.yml up --build web",
+	Long: `Create the networks and volumes, build or pull the images, then create and start the containers in dependency order.
+Containers whose configuration did not change are left alone; the others are recreated.
+Without -d, the logs are followed afterwards; Ctrl-C stops following, not the containers.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if restClient == nil {
+			fmt.Println("REST client not initialized")
+			return
+		}
+
+		p, cerr := loadComposeProject()
+		if cerr != nil {
+			fmt.Println(cerr)
+			return
+		}
+		opts := composeUpOpts
+		opts.Services = args
+		opts.OnEvent = printEvent
+		opts.Progress = progressOutput()
+		if cerr = compose.Up(cmd.Context(), restClient, p, opts); cerr != nil {
+			fmt.Println(cerr)
+			return
+		}
+		if composeUpDetach || opts.NoStart {
+			return
+		}
+
+		logOpts := compose.LogsOptions{Services: args}
+		logOpts.Follow, logOpts.Tail = true, -1
+		logOpts.Stdout, logOpts.Stderr = os.Stdout, os.Stderr
+		if cerr = compose.Logs(cmd.Context(), restClient, p.Name, logOpts); cerr != nil {
+			fmt.Println(cerr)
+		}
+	},
+}
+
+var composeDownCmd = &cobra.Command{
+	Use:     "down",
+	Short:   "Stop and remove the stack",
+	Example: "dtools compose down\ndtools compose -p myproject down -v",
+	Long: `Stop and remove the containers of the project, then its networks; with -v, its volumes too.
+The project resources are found by their labels: with -p, no compose file is needed.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if restClient == nil {
+			fmt.Println("REST client not initialized")
+			return
+		}
+
+		project, cerr := composeProjectName()
+		if cerr != nil {
+			fmt.Println(cerr)
+			return
+		}
+		opts := composeDownOpts
+		opts.OnEvent = printEvent
+		if cerr = compose.Down(cmd.Context(), restClient, project, opts); cerr != nil {
+			fmt.Println(cerr)
+		}
+	},
+}
+
+var composePsCmd = &cobra.Command{
+	Use:     "ps [SERVICE...]",
+	Short:   "List the containers of the stack",
+	Example: "dtools compose ps\ndtools compose ps web --json",
+	Run: func(cmd *cobra.Command, args []string) {
+		if restClient == nil {
+			fmt.Println("REST client not initialized")
+			return
+		}
+
+		project, cerr := composeProjectName()
+		if cerr != nil {
+			fmt.Println(cerr)
+			return
+		}
+		cs, cerr := compose.Ps(cmd.Context(), restClient, project, args)
+		if cerr == nil {
+			cerr = renderComposePs(cs)
+		}
+		if cerr != nil {
+			fmt.Println(cerr)
+		}
+	},
+}
+
+var composeLogsCmd = &cobra.Command{
+	Use:     "logs [flags] [SERVICE...]",
+	Short:   "Show the logs of the stack containers",
+	Example: "dtools compose logs --follow\ndtools compose logs -n 50 db",
+	Run: func(cmd *cobra.Command, args []string) {
+		if restClient == nil {
+			fmt.Println("REST client not initialized")
+			return
+		}
+
+		project, cerr := composeProjectName()
+		if cerr != nil {
+			fmt.Println(cerr)
+			return
+		}
+		opts := composeLogsOpts
+		opts.Services = args
+		opts.Stdout, opts.Stderr = os.Stdout, os.Stderr
+		if cerr = compose.Logs(cmd.Context(), restClient, project, opts); cerr != nil {
+			fmt.Println(cerr)
+		}
+	},
+}
+
+var composeExportCmd = &cobra.Command{
+	Use:     "export [flags] [CONTAINER...]",
+	Short:   "Write a compose file that recreates existing containers",
+	Example: "dtools compose export web db -o compose.yaml\ndtools compose -p legacy export --all",
+	Long: `Inspect containers, and the networks and named volumes they use, then write the compose file that recreates them.
+Shared networks and volumes are declared once, under their current names; what the containers inherit from their image is left out.
+With -p, the project name is written in the file. What has no compose equivalent is reported, then left out.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if restClient == nil {
+			fmt.Println("REST client not initialized")
+			return
+		}
+		if len(args) == 0 && !composeExportOpts.All {
+			fmt.Println(&ce.CustomError{Title: "Missing containers", Message: "name the containers to export, or use --all"})
+			return
+		}
+
+		opts := composeExportOpts
+		opts.Containers = args
+		opts.ProjectName = composeLoadOpts.ProjectName
+		data, warnings, cerr := compose.Export(cmd.Context(), restClient, opts)
+		if cerr != nil {
+			fmt.Println(cerr)
+			return
+		}
+		if !extras.QuietOutput {
+			for _, w := range warnings {
+				fmt.Fprintln(os.Stderr, hftx.WarningSign(" "+w))
+			}
+		}
+
+		if composeExportOutput == "" {
+			os.Stdout.Write(data)
+			return
+		}
+		if err := os.WriteFile(composeExportOutput, data, 0600); err != nil {
+			fmt.Println(&ce.CustomError{Title: "Error writing the compose file", Message: err.Error()})
+			return
+		}
+		if !extras.QuietOutput {
+			fmt.Println(hftx.EnabledSign("Output sent to " + composeExportOutput))
+		}
+	},
+}
+
+// loadComposeProject loads -f, or the compose file of the current directory, and reports the ignored keys
+func loadComposeProject() (*compose.Project, *ce.CustomError) {
+	path := composeFile
+	if path == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, &ce.CustomError{Title: "Unable to get the current directory", Message: err.Error()}
+		}
+		var cerr *ce.CustomError
+		if path, cerr = compose.FindFile(wd); cerr != nil {
+			return nil, cerr
+		}
+	}
+
+	p, cerr := compose.Load(path, composeLoadOpts)
+	if cerr != nil {
+		return nil, cerr
+	}
+	if !extras.QuietOutput {
+		for _, w := range p.Warnings {
+			fmt.Println(hftx.WarningSign(" " + w))
+		}
+	}
+	return p, nil
+}
+
+// composeProjectName is -p when given: down, ps and logs then work without a compose file
+func composeProjectName() (string, *ce.CustomError) {
+	if composeLoadOpts.ProjectName != "" && composeFile == "" {
+		return composeLoadOpts.ProjectName, nil
+	}
+	p, cerr := loadComposeProject()
+	if cerr != nil {
+		return "", cerr
+	}
+	return p.Name, nil
+}
+
+func init() {
+	rootCmd.AddCommand(composeCmd)
+	composeCmd.AddCommand(composeUpCmd, composeDownCmd, composePsCmd, composeLogsCmd, composeExportCmd)
+
+	composeCmd.PersistentFlags().StringVarP(&composeFile, "file", "f", "", "Compose file (default: compose.yaml, compose.yml, docker-compose.yaml or docker-compose.yml)")
+	composeCmd.PersistentFlags().StringVarP(&composeLoadOpts.ProjectName, "project-name", "p", "", "Project name (default: the name: key, or the compose file directory name)")
+	composeCmd.PersistentFlags().StringVar(&composeLoadOpts.EnvFile, "env-file", "", "File of variables for the interpolation (default: .env next to the compose file)")
+
+	composeUpCmd.Flags().BoolVarP(&composeUpDetach, "detach", "d", false, "Do not follow the logs once the stack is up")
+	composeUpCmd.Flags().BoolVar(&composeUpOpts.Build, "build", false, "Build the images, even if present")
+	composeUpCmd.Flags().BoolVar(&composeUpOpts.ForceRecreate, "force-recreate", false, "Recreate the containers, even if their configuration did not change")
+	composeUpCmd.Flags().BoolVar(&composeUpOpts.NoStart, "no-start", false, "Create the containers without starting them")
+	composeUpCmd.Flags().BoolVar(&composeUpOpts.RemoveOrphans, "remove-orphans", false, "Remove the containers of services no longer in the compose file")
+
+	composeDownCmd.Flags().BoolVarP(&composeDownOpts.Volumes, "volumes", "v", false, "Also remove the project volumes, named and anonymous")
+	composeDownCmd.Flags().IntVarP(&composeDownOpts.Timeout, "timeout", "t", 10, "timeout (seconds) when stopping containers; 0 to stop all concurrently")
+
+	composeLogsCmd.Flags().BoolVar(&composeLogsOpts.Follow, "follow", false, "Follow log output (no -f shorthand: -f is the compose file)")
+	composeLogsCmd.Flags().BoolVarP(&composeLogsOpts.Timestamps, "timestamps", "t", false, "Show timestamps")
+	composeLogsCmd.Flags().IntVarP(&composeLogsOpts.Tail, "tail", "n", -1, "Number of lines to show from the end of the logs (-1 means all)")
+	composeLogsCmd.Flags().BoolVar(&composeLogsOpts.NoPrefix, "no-log-prefix", false, "Do not prefix the lines with the container name")
+
+	composeExportCmd.Flags().BoolVarP(&composeExportOpts.All, "all", "a", false, "Export every container, stopped ones included")
+	composeExportCmd.Flags().StringVarP(&composeExportOutput, "output", "o", "", "Write the compose file there instead of the standard output")
+}
@@ -0,0 +1,60 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 03:00
+// Original filename: src/cmd/composeOutput.go
+
+package cmd
+
+import (
+	"dtools2/compose"
+	"dtools2/containers"
+	"os"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// renderComposePs renders the output of `dtools compose ps`
+func renderComposePs(cs []containers.ContainerSummary) *ce.CustomError {
+	if done, cerr := renderPayload(cs); done || cerr != nil {
+		return cerr
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Name", "Service", "Image", "State", "Health", "Status", "Ports"})
+
+	if len(cs) == 0 {
+		t.AppendRow(table.Row{"", "", "", "", "", "", ""})
+	}
+	for _, c := range cs {
+		t.AppendRow(table.Row{
+			c.Names[0][1:],
+			c.Labels[compose.LabelService],
+			getImageTag(c.Image),
+			c.State,
+			c.HealthStatus(),
+			c.Status,
+			prettifyPortsList(c.Ports, "\n"),
+		})
+	}
+
+	t.SetStyle(table.StyleBold)
+	t.Style().Format.Header = text.FormatDefault
+	t.SetRowPainter(func(row table.Row) text.Colors {
+		if row[4] == "unhealthy" {
+			return text.Colors{text.FgHiRed}
+		}
+		switch row[3] {
+		case "running":
+			return text.Colors{text.FgHiGreen}
+		case "paused":
+			return text.Colors{text.FgHiYellow}
+		}
+		return nil
+	})
+
+	t.Render()
+	return nil
+}
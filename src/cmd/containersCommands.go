@@ -7,12 +7,19 @@
 package cmd
 
 import (
+	"dtools2/blacklist"
 	"dtools2/containers"
 	"dtools2/extras"
-	"dtools2/rest"
 	"dtools2/run"
+	"dtools2/system"
 	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
 
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+	hftx "github.com/jeanfrancoisgratton/helperFunctions/v4/terminalfx"
 	"github.com/spf13/cobra"
 )
 
@@ -32,8 +39,16 @@ var containerListCmd = &cobra.Command{
 			fmt.Println("REST client not initialized")
 			return
 		}
-		rest.Context = cmd.Context()
-		_, errCode := containers.ListContainers(restClient, true)
+		opts := containerListOpts
+		var errCode *ce.CustomError
+		if opts.Filters, errCode = extras.ParseFilters(listFilters); errCode != nil {
+			fmt.Println(errCode)
+			return
+		}
+		cs, errCode := containers.ListContainers(cmd.Context(), restClient, opts)
+		if errCode == nil {
+			errCode = renderContainerList(cs, containerListExtended)
+		}
 		if errCode != nil {
 			fmt.Println(errCode)
 		}
@@ -51,27 +66,33 @@ var containerInfoCmd = &cobra.Command{
 			fmt.Println("REST client not initialized")
 			return
 		}
-		rest.Context = cmd.Context()
-
-		if errCode := containers.InfoContainers(restClient, args[0]); errCode != nil {
+		ci, errCode := containers.InfoContainer(cmd.Context(), restClient, args[0])
+		if errCode != nil {
 			fmt.Println(errCode)
+			return
 		}
+		showExtendedInfo(*ci)
 		return
 	},
 }
 
 var containerRemoveCmd = &cobra.Command{
 	Use:     "rmc [flags]",
-	Example: "dtools container rmc [-f] [-k] [-r]  container1 [container2..containerN]",
+	Example: "dtools container rmc [-f] [-k] [-r]  container1 [container2..containerN]\ndtools rmc --state exited --name-glob 'test-*' --dry-run",
 	Short:   "Remove one or many containers",
-	Args:    cobra.MinimumNArgs(1),
+	Args:    containerArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		if restClient == nil {
 			fmt.Println("REST client not initialized")
 			return
 		}
-		rest.Context = cmd.Context()
-		if errCode := containers.RemoveContainer(restClient, args); errCode != nil {
+		names, ok := selectedContainers(cmd, args, !containerRemoveOpts.IgnoreBlacklist)
+		if !ok {
+			return
+		}
+		opts := containerRemoveOpts
+		opts.OnEvent = printEvent
+		if _, errCode := containers.RemoveContainer(cmd.Context(), restClient, names, opts); errCode != nil {
 			fmt.Println(errCode)
 		}
 		return
@@ -82,14 +103,17 @@ var containerPauseCmd = &cobra.Command{
 	Use:     "pause",
 	Example: "dtools pause container1 [container2..containerN]",
 	Short:   "Pause one or many containers",
-	Args:    cobra.MinimumNArgs(1),
+	Args:    containerArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		if restClient == nil {
 			fmt.Println("REST client not initialized")
 			return
 		}
-		rest.Context = cmd.Context()
-		if errCode := containers.PauseContainer(restClient, args); errCode != nil {
+		names, ok := selectedContainers(cmd, args, false)
+		if !ok {
+			return
+		}
+		if errCode := containers.PauseContainer(cmd.Context(), restClient, names, printEvent); errCode != nil {
 			fmt.Println(errCode)
 		}
 		return
@@ -100,14 +124,17 @@ var containerUnpauseCmd = &cobra.Command{
 	Use:     "unpause",
 	Example: "dtools unpause container1 [container2..containerN]",
 	Short:   "Unpause one or many containers",
-	Args:    cobra.MinimumNArgs(1),
+	Args:    containerArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		if restClient == nil {
 			fmt.Println("REST client not initialized")
 			return
 		}
-		rest.Context = cmd.Context()
-		if errCode := containers.UnpauseContainer(restClient, args); errCode != nil {
+		names, ok := selectedContainers(cmd, args, false)
+		if !ok {
+			return
+		}
+		if errCode := containers.UnpauseContainer(cmd.Context(), restClient, names, printEvent); errCode != nil {
 			fmt.Println(errCode)
 		}
 		return
@@ -119,14 +146,18 @@ var containerStartCmd = &cobra.Command{
 	Aliases: []string{"up"},
 	Example: "dtools start  container1 [container2..containerN]",
 	Short:   "Start one or many containers",
-	Args:    cobra.MinimumNArgs(1),
+	Long:    "The containers are started in dependency order: links, shared network namespaces, compose depends_on and the dtools.depends-on label",
+	Args:    containerArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		if restClient == nil {
 			fmt.Println("REST client not initialized")
 			return
 		}
-		rest.Context = cmd.Context()
-		if errCode := containers.StartContainers(restClient, args); errCode != nil {
+		names, ok := selectedContainers(cmd, args, false)
+		if !ok {
+			return
+		}
+		if errCode := containers.StartContainers(cmd.Context(), restClient, names, startOptions()); errCode != nil {
 			fmt.Println(errCode)
 		}
 		return
@@ -137,13 +168,13 @@ var containerStartAllCmd = &cobra.Command{
 	Use:     "startall",
 	Example: "dtools startall",
 	Short:   "Start all non-running containers",
+	Long:    "The containers are started in dependency order: links, shared network namespaces, compose depends_on and the dtools.depends-on label",
 	Run: func(cmd *cobra.Command, args []string) {
 		if restClient == nil {
 			fmt.Println("REST client not initialized")
 			return
 		}
-		rest.Context = cmd.Context()
-		if errCode := containers.StartAllContainers(restClient); errCode != nil {
+		if errCode := containers.StartAllContainers(cmd.Context(), restClient, startOptions()); errCode != nil {
 			fmt.Println(errCode)
 		}
 		return
@@ -153,17 +184,20 @@ var containerStartAllCmd = &cobra.Command{
 var containerStopCmd = &cobra.Command{
 	Use:     "stop",
 	Aliases: []string{"down"},
-	Example: "dtools stop  container1 [container2..containerN]",
+	Example: "dtools stop  container1 [container2..containerN]\ndtools stop --selector label=env=staging --image nginx",
 	Short:   "Stop one or many containers",
 	Long:    "Using a timeout of 0 (-t 0) will stop them concurrently, but conclusion is still dependent on the containers gracefully shut down",
-	Args:    cobra.MinimumNArgs(1),
+	Args:    containerArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		if restClient == nil {
 			fmt.Println("REST client not initialized")
 			return
 		}
-		rest.Context = cmd.Context()
-		if errCode := containers.StopContainers(restClient, args); errCode != nil {
+		names, ok := selectedContainers(cmd, args, false)
+		if !ok {
+			return
+		}
+		if errCode := containers.StopContainers(cmd.Context(), restClient, names, stopOptions()); errCode != nil {
 			fmt.Println(errCode)
 		}
 		return
@@ -180,8 +214,7 @@ var containerStopAllCmd = &cobra.Command{
 			fmt.Println("REST client not initialized")
 			return
 		}
-		rest.Context = cmd.Context()
-		if errCode := containers.StopAllContainers(restClient); errCode != nil {
+		if errCode := containers.StopAllContainers(cmd.Context(), restClient, stopOptions()); errCode != nil {
 			fmt.Println(errCode)
 		}
 		return
@@ -198,9 +231,12 @@ var containerRenameCmd = &cobra.Command{
 			fmt.Println("REST client not initialized")
 			return
 		}
-		rest.Context = cmd.Context()
-		if errCode := containers.RenameContainer(restClient, args[0], args[1]); errCode != nil {
+		if errCode := containers.RenameContainer(cmd.Context(), restClient, args[0], args[1]); errCode != nil {
 			fmt.Println(errCode)
+			return
+		}
+		if !extras.QuietOutput {
+			fmt.Println("Container " + hftx.Green(args[0]) + " renamed to " + hftx.Green(args[1]))
 		}
 		return
 	},
@@ -210,14 +246,17 @@ var containerKillCmd = &cobra.Command{
 	Use:     "kill",
 	Example: "dtools kill  container1 [container2..containerN]",
 	Short:   "Kill one or many containers",
-	Args:    cobra.MinimumNArgs(1),
+	Args:    containerArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		if restClient == nil {
 			fmt.Println("REST client not initialized")
 			return
 		}
-		rest.Context = cmd.Context()
-		if errCode := containers.KillContainers(restClient, args); errCode != nil {
+		names, ok := selectedContainers(cmd, args, false)
+		if !ok {
+			return
+		}
+		if errCode := containers.KillContainers(cmd.Context(), restClient, names, printEvent); errCode != nil {
 			fmt.Println(errCode)
 		}
 		return
@@ -233,8 +272,7 @@ var containerKillAllCmd = &cobra.Command{
 			fmt.Println("REST client not initialized")
 			return
 		}
-		rest.Context = cmd.Context()
-		if errCode := containers.KillAllContainers(restClient); errCode != nil {
+		if errCode := containers.KillAllContainers(cmd.Context(), restClient, printEvent); errCode != nil {
 			fmt.Println(errCode)
 		}
 		return
@@ -245,14 +283,17 @@ var containerRestartCmd = &cobra.Command{
 	Use:     "restart",
 	Example: "dtools restart container1 [container2..containerN]",
 	Short:   "Restart one or many containers",
-	Args:    cobra.MinimumNArgs(1),
+	Args:    containerArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		if restClient == nil {
 			fmt.Println("REST client not initialized")
 			return
 		}
-		rest.Context = cmd.Context()
-		if errCode := containers.RestartContainers(restClient, args); errCode != nil {
+		names, ok := selectedContainers(cmd, args, false)
+		if !ok {
+			return
+		}
+		if errCode := containers.RestartContainers(cmd.Context(), restClient, names, stopOptions()); errCode != nil {
 			fmt.Println(errCode)
 		}
 		return
@@ -268,8 +309,7 @@ var containerRestartAllCmd = &cobra.Command{
 			fmt.Println("REST client not initialized")
 			return
 		}
-		rest.Context = cmd.Context()
-		if errCode := containers.RestartAllContainers(restClient); errCode != nil {
+		if errCode := containers.RestartAllContainers(cmd.Context(), restClient, stopOptions()); errCode != nil {
 			fmt.Println(errCode)
 		}
 		return
@@ -286,34 +326,344 @@ var containerAttachCmd = &cobra.Command{
 			fmt.Println("REST client not initialized")
 			return
 		}
-		rest.Context = cmd.Context()
-
-		if _, errCode := run.AttachContainer(restClient, args[0]); errCode != nil {
+		hj, errCode := run.AttachContainer(cmd.Context(), restClient, args[0], false)
+		if errCode != nil {
 			fmt.Println(errCode)
+			return
+		}
+		hj.Conn.Close()
+		return
+	},
+}
+
+var containerStatsCmd = &cobra.Command{
+	Use:     "stats [flags] [CONTAINER...]",
+	Example: "dtools stats\ndtools stats --no-stream web db\ndtools stats --json web",
+	Short:   "Show the live resource usage of containers",
+	Long: `Show the CPU, memory, network and block I/O usage, and the number of processes, of the running containers
+(or of the given ones). The table refreshes every second until interrupted, unless --no-stream is passed.
+With --json, every sample is printed as a JSON object, one per line.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if restClient == nil {
+			fmt.Println("REST client not initialized")
+			return
+		}
+
+		if err := renderStats(cmd.Context(), args, !statsNoStream); err != nil {
+			fmt.Println(err)
+		}
+		return
+	},
+}
+
+var containerTopCmd = &cobra.Command{
+	Use:     "top CONTAINER [ps OPTIONS]",
+	Example: "dtools top web\ndtools container top web -- -o pid,user,args\ndtools top web --format PID,CMD",
+	Short:   "List the processes running in a container",
+	Long: `List the processes running in a container, as seen by ps (-ef by default).
+The ps options follow the container name; put them after -- when they start with a dash.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if restClient == nil {
+			fmt.Println("REST client not initialized")
+			return
+		}
+
+		top, err := containers.Top(cmd.Context(), restClient, args[0], strings.Join(args[1:], " "))
+		if err == nil {
+			err = renderTop(top)
+		}
+		if err != nil {
+			fmt.Println(err)
+		}
+		return
+	},
+}
+
+var containerDiffCmd = &cobra.Command{
+	Use:     "diff CONTAINER",
+	Example: "dtools diff web\ndtools container diff web --format Type,Path",
+	Short:   "Show the files changed in a container",
+	Long: `Show the files added (A), changed (C) or deleted (D) in a container's filesystem since it was created from its image.
+Useful to audit a container before committing it into a new image.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if restClient == nil {
+			fmt.Println("REST client not initialized")
+			return
+		}
+
+		changes, err := containers.Changes(cmd.Context(), restClient, args[0])
+		if err == nil {
+			err = renderChanges(changes)
+		}
+		if err != nil {
+			fmt.Println(err)
 		}
 		return
 	},
 }
 
+var containerInspectCmd = &cobra.Command{
+	Use:     "inspect CONTAINER [CONTAINER...]",
+	Example: "dtools container inspect web --format State.Health.Status",
+	Short:   "Show the full inspect document of containers",
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if restClient == nil {
+			fmt.Println("REST client not initialized")
+			return
+		}
+
+		if err := renderInspect(cmd.Context(), system.InspectContainer, args); err != nil {
+			fmt.Println(err)
+		}
+		return
+	},
+}
+
+var containerRunLikeCmd = &cobra.Command{
+	Use:     "runlike CONTAINER",
+	Example: "dtools container runlike web\ndtools runlike web --json",
+	Short:   "Print the dtools run command line that would recreate a container",
+	Long: `Inspect a container and print an equivalent dtools run command line: image, name, env, ports, mounts, networks,
+restart policy, entrypoint/command, user, workdir, hostname, labels, limits and security options.
+Values inherited from the image are left out. With --json, the arguments are printed as a JSON array.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if restClient == nil {
+			fmt.Println("REST client not initialized")
+			return
+		}
+
+		runArgs, err := run.RunLike(cmd.Context(), restClient, args[0])
+		if err == nil {
+			err = renderRunLike(runArgs)
+		}
+		if err != nil {
+			fmt.Println(err)
+		}
+		return
+	},
+}
+
+var containerUpdateCmd = &cobra.Command{
+	Use:     "update [flags] CONTAINER [CONTAINER...]",
+	Example: "dtools update -m 512m --memory-swap 1g --cpus 1.5 web\ndtools update --restart unless-stopped web db",
+	Short:   "Change the resource limits and restart policy of containers",
+	Long: `Change the resource limits and/or the restart policy of containers, running or not.
+Limits that are not given are left unchanged.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if restClient == nil {
+			fmt.Println("REST client not initialized")
+			return
+		}
+
+		opts := containerUpdateOpts
+		opts.OnEvent = printEvent
+		if err := containers.UpdateContainers(cmd.Context(), restClient, args, opts); err != nil {
+			fmt.Println(err)
+		}
+		return
+	},
+}
+
+var containerWaitCmd = &cobra.Command{
+	Use:     "wait CONTAINER [CONTAINER...]",
+	Example: "dtools wait job\ndtools wait --condition removed job1 job2",
+	Short:   "Block until containers stop, then print their exit codes",
+	Long: `Block until every container meets the condition, then print their exit codes, one per line.
+The command exits with 1 when a wait failed, else with the last non-zero exit code (0 when all succeeded).
+Conditions: not-running (default), next-exit, removed.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if restClient == nil {
+			fmt.Println("REST client not initialized")
+			os.Exit(1)
+		}
+
+		type result struct {
+			code int
+			err  *ce.CustomError
+		}
+		results := make([]result, len(args))
+		var wg sync.WaitGroup
+		for i, name := range args {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i].code, results[i].err = run.WaitContainer(cmd.Context(), restClient, name, waitCondition)
+			}()
+		}
+		wg.Wait()
+
+		exitCode, waitFailed := 0, false
+		for _, r := range results {
+			if r.err != nil {
+				fmt.Println(r.err)
+				waitFailed = true
+				continue
+			}
+			fmt.Println(r.code)
+			if r.code != 0 {
+				exitCode = r.code
+			}
+		}
+		// A failed wait wins over the exit codes of the other containers
+		if waitFailed {
+			exitCode = 1
+		}
+		os.Exit(exitCode)
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(containerCmd, containerListCmd, containerInfoCmd, containerRemoveCmd, containerPauseCmd,
 		containerUnpauseCmd, containerStartCmd, containerStartAllCmd, containerStopCmd, containerStopAllCmd,
 		containerRenameCmd, containerKillCmd, containerKillAllCmd, containerRestartCmd,
-		containerRestartAllCmd, containerAttachCmd)
+		containerRestartAllCmd, containerAttachCmd, containerStatsCmd, containerTopCmd, containerDiffCmd, containerWaitCmd,
+		containerUpdateCmd, containerRunLikeCmd)
 	containerCmd.AddCommand(containerListCmd, containerInfoCmd, containerRemoveCmd, containerPauseCmd,
 		containerUnpauseCmd, containerStartCmd, containerStartAllCmd, containerStopCmd, containerStopAllCmd,
 		containerRenameCmd, containerKillCmd, containerKillAllCmd, containerRestartCmd,
-		containerRestartAllCmd, containerAttachCmd)
-
-	containerRestartCmd.Flags().BoolVarP(&containers.KillSwitch, "kill", "k", false, "force kill of container")
-	containerRestartAllCmd.Flags().BoolVarP(&containers.KillSwitch, "kill", "k", false, "force kill of container")
-	containerStopCmd.Flags().IntVarP(&containers.StopTimeout, "timeout", "t", 10, "timeout (seconds) when stopping containers; 0 to stop all concurrently")
-	containerStopAllCmd.Flags().IntVarP(&containers.StopTimeout, "timeout", "t", 10, "timeout (seconds) when stopping containers; 0 to stop all concurrently")
-	containerRemoveCmd.Flags().BoolVarP(&containers.ForceRemoveContainer, "force", "f", false, "force removal of container")
-	containerRemoveCmd.Flags().BoolVarP(&containers.RemoveUnamedVolumes, "remove-vols", "r", true, "remove non-named volume")
-	containerRemoveCmd.Flags().BoolVarP(&containers.RemoveBlacklisted, "blacklist", "B", false, "remove container even if blacklisted")
-	containerListCmd.Flags().BoolVarP(&containers.OnlyRunningContainers, "running", "r", false, "List only the running containers")
-	containerListCmd.Flags().BoolVarP(&containers.ExtendedContainerInfo, "extended", "x", false, "Show extended container info")
+		containerRestartAllCmd, containerAttachCmd, containerStatsCmd, containerTopCmd, containerDiffCmd, containerInspectCmd,
+		containerWaitCmd, containerUpdateCmd, containerRunLikeCmd)
+
+	containerRestartCmd.Flags().BoolVarP(&containerStopOpts.Kill, "kill", "k", false, "force kill of container")
+	containerRestartAllCmd.Flags().BoolVarP(&containerStopOpts.Kill, "kill", "k", false, "force kill of container")
+	containerStartCmd.Flags().BoolVar(&containerStartOpts.WaitHealthy, "wait-healthy", false, "wait for the containers with a healthcheck to be healthy before starting those depending on them")
+	containerStartAllCmd.Flags().BoolVar(&containerStartOpts.WaitHealthy, "wait-healthy", false, "wait for the containers with a healthcheck to be healthy before starting those depending on them")
+	containerRestartCmd.Flags().BoolVar(&containerStopOpts.WaitHealthy, "wait-healthy", false, "wait for the containers with a healthcheck to be healthy before starting those depending on them")
+	containerRestartAllCmd.Flags().BoolVar(&containerStopOpts.WaitHealthy, "wait-healthy", false, "wait for the containers with a healthcheck to be healthy before starting those depending on them")
+	containerStopCmd.Flags().IntVarP(&containerStopOpts.Timeout, "timeout", "t", 10, "timeout (seconds) when stopping containers; 0 to stop all concurrently")
+	containerStopAllCmd.Flags().IntVarP(&containerStopOpts.Timeout, "timeout", "t", 10, "timeout (seconds) when stopping containers; 0 to stop all concurrently")
+	for _, c := range []*cobra.Command{containerKillCmd, containerStartCmd, containerStopCmd, containerRestartCmd,
+		containerPauseCmd, containerUnpauseCmd, containerRemoveCmd} {
+		c.Flags().StringArrayVar(&containerSelectors, "selector", nil, "Select the containers instead of naming them (label=KEY[=VALUE], name=GLOB, image=IMAGE or state=STATE). Can be specified multiple times")
+		c.Flags().StringArrayVar(&containerSelector.NameGlobs, "name-glob", nil, "Select the containers whose name matches the shell pattern (e.g. 'web-*')")
+		c.Flags().StringArrayVar(&containerSelector.Images, "image", nil, "Select the containers created from this image, or from an image built on it")
+		c.Flags().StringArrayVar(&containerSelector.States, "state", nil, "Select the containers in this state (created, restarting, running, removing, paused, exited, dead)")
+		c.Flags().BoolVar(&containerDryRun, "dry-run", false, "List the containers that would be affected, without touching them")
+	}
+	containerRemoveCmd.Flags().BoolVarP(&containerRemoveOpts.Force, "force", "f", false, "force removal of container")
+	containerRemoveCmd.Flags().BoolVarP(&containerRemoveOpts.RemoveVolumes, "remove-vols", "r", true, "remove non-named volume")
+	containerRemoveCmd.Flags().BoolVarP(&containerRemoveOpts.IgnoreBlacklist, "blacklist", "B", false, "remove container even if blacklisted")
+	containerListCmd.Flags().BoolVarP(&containerListOpts.OnlyRunning, "running", "r", false, "List only the running containers")
+	containerListCmd.Flags().BoolVarP(&containerListExtended, "extended", "x", false, "Show extended container info")
 	containerListCmd.Flags().StringVarP(&extras.OutputFile, "file", "F", "", "Write JSON output to a file")
 	containerListCmd.Flags().StringVar(&extras.OutputFormat, "format", "", "Output only the values for the given field (or comma-separated fields) as plaintext")
+	containerListCmd.Flags().StringArrayVar(&listFilters, "filter", nil, "Filter the output (key=value, e.g. status=exited, label=env=prod, name=web, ancestor=nginx). Can be specified multiple times")
+	containerStatsCmd.Flags().BoolVar(&statsNoStream, "no-stream", false, "Show a single sample instead of refreshing the table")
+	containerTopCmd.Flags().StringVarP(&extras.OutputFile, "file", "F", "", "Write JSON output to a file")
+	containerTopCmd.Flags().StringVar(&extras.OutputFormat, "format", "", "Output only the values for the given field (or comma-separated fields) as plaintext")
+	containerDiffCmd.Flags().StringVarP(&extras.OutputFile, "file", "F", "", "Write JSON output to a file")
+	containerDiffCmd.Flags().StringVar(&extras.OutputFormat, "format", "", "Output only the values for the given field (or comma-separated fields) as plaintext")
+	containerInspectCmd.Flags().StringVarP(&extras.OutputFile, "file", "F", "", "Write JSON output to a file")
+	containerInspectCmd.Flags().StringVar(&extras.OutputFormat, "format", "", "Output only the values at the given path (or comma-separated paths); \"*\" walks every element of a list or map")
+	containerRunLikeCmd.Flags().StringVarP(&extras.OutputFile, "file", "F", "", "Write JSON output to a file")
+	containerUpdateCmd.Flags().StringVar(&containerUpdateOpts.Restart, "restart", "", "Restart policy: no, always, unless-stopped, on-failure[:max-retries]")
+	resourceFlags(containerUpdateCmd, &containerUpdateOpts.Resources)
+	containerWaitCmd.Flags().StringVar(&waitCondition, "condition", run.WaitNotRunning, "Wait condition: not-running, next-exit or removed")
+}
+
+// startOptions returns the start flags, wired to our progress output
+func startOptions() containers.StartOptions {
+	opts := containerStartOpts
+	opts.OnEvent = printEvent
+	return opts
+}
+
+// stopOptions returns the stop/kill/restart flags, wired to our progress output
+func stopOptions() containers.StopOptions {
+	opts := containerStopOpts
+	opts.OnEvent = printEvent
+	return opts
+}
+
+// containerArgs requires container names or selector flags, but not both
+func containerArgs(cmd *cobra.Command, args []string) error {
+	selecting := len(containerSelectors) > 0 || !containerSelector.IsEmpty()
+	if selecting && len(args) > 0 {
+		return fmt.Errorf("container names cannot be combined with --selector, --name-glob, --image or --state")
+	}
+	if !selecting && len(args) == 0 {
+		return fmt.Errorf("requires at least 1 container name, or a selector")
+	}
+	return nil
+}
+
+// selectedContainers returns the containers to act on: those named, or those picked by the selector flags.
+// With --dry-run, they are listed instead, along with the blacklisted ones when checkBlacklist is set, and ok is false.
+func selectedContainers(cmd *cobra.Command, args []string, checkBlacklist bool) ([]string, bool) {
+	if len(args) > 0 && !containerDryRun {
+		return args, true
+	}
+
+	var cs []containers.ContainerSummary
+	var errCode *ce.CustomError
+	if len(args) > 0 {
+		cs, errCode = namedContainers(cmd, args)
+	} else {
+		sel := containerSelector
+		var parsed containers.Selector
+		if parsed, errCode = containers.ParseSelectors(containerSelectors); errCode == nil {
+			sel.Labels = append(sel.Labels, parsed.Labels...)
+			sel.NameGlobs = append(sel.NameGlobs, parsed.NameGlobs...)
+			sel.Images = append(sel.Images, parsed.Images...)
+			sel.States = append(sel.States, parsed.States...)
+			cs, errCode = containers.SelectContainers(cmd.Context(), restClient, sel)
+		}
+	}
+	if errCode != nil {
+		fmt.Println(errCode)
+		return nil, false
+	}
+
+	if !containerDryRun {
+		if len(cs) == 0 {
+			printEvent(extras.Event{Resource: "container", Action: extras.EventSkipped, Message: "No containers selected"})
+		}
+		return containerNamesOf(cs), len(cs) > 0
+	}
+
+	if errCode = renderContainerList(cs, false); errCode != nil {
+		fmt.Println(errCode)
+		return nil, false
+	}
+	if checkBlacklist && !extras.QuietOutput {
+		for _, name := range containerNamesOf(cs) {
+			if isBL, errCode := blacklist.IsResourceBlackListed("containers", name); errCode != nil {
+				fmt.Println(errCode)
+			} else if isBL {
+				fmt.Println(hftx.WarningSign(" " + name + " is blacklisted, and would be skipped"))
+			}
+		}
+	}
+	return nil, false
+}
+
+// namedContainers returns the containers given by name, in the given order; an unknown name is an error
+func namedContainers(cmd *cobra.Command, names []string) ([]containers.ContainerSummary, *ce.CustomError) {
+	all, errCode := containers.ListContainers(cmd.Context(), restClient, containers.ListOptions{})
+	if errCode != nil {
+		return nil, errCode
+	}
+	var cs []containers.ContainerSummary
+	for _, name := range names {
+		i := slices.IndexFunc(all, func(c containers.ContainerSummary) bool { return c.Names[0][1:] == name })
+		if i < 0 {
+			return nil, &ce.CustomError{Title: "No such container", Message: name}
+		}
+		cs = append(cs, all[i])
+	}
+	return cs, nil
+}
+
+func containerNamesOf(cs []containers.ContainerSummary) []string {
+	names := make([]string, 0, len(cs))
+	for _, c := range cs {
+		names = append(names, c.Names[0][1:])
+	}
+	return names
 }
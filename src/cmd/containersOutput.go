@@ -0,0 +1,457 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 16:50
+// Original filename: src/cmd/containersOutput.go
+
+package cmd
+
+import (
+	"context"
+	"dtools2/containers"
+	"dtools2/extras"
+	"dtools2/systemd"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+	hftx "github.com/jeanfrancoisgratton/helperFunctions/v4/terminalfx"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// renderContainerList renders the output of `dtools lsc`
+func renderContainerList(cs []containers.ContainerSummary, extended bool) *ce.CustomError {
+	if done, cerr := renderPayload(cs); done || cerr != nil {
+		return cerr
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+
+	stateRow := 0
+	if !extended {
+		stateRow = 3
+		t.AppendHeader(table.Row{"Image", "Name", "Created", "State", "Status", "Ports"})
+	} else {
+		stateRow = 4
+		t.AppendHeader(table.Row{"Container ID", "Image", "Name", "Created", "State", "Health", "Status", "Ports", "Command"})
+	}
+
+	// Option B: when there are no containers, append a single empty row to keep
+	// the table borders and layout intact.
+	if len(cs) == 0 {
+		if !extended {
+			// 6 columns: Image, Name, Created, State, Status, Ports
+			t.AppendRow(table.Row{"", "", "", "", "", ""})
+		} else {
+			// 9 columns: Container ID, Image, Name, Created, State, Health, Status, Ports, Command
+			t.AppendRow(table.Row{"", "", "", "", "", "", "", "", ""})
+		}
+	} else {
+		for _, container := range cs {
+			containerImage := getImageTag(container.Image)
+			prettyPorts := prettifyPortsList(container.Ports, "\n")
+
+			if !extended {
+				t.AppendRow([]interface{}{
+					containerImage,
+					container.Names[0][1:],
+					time.Unix(container.Created, 0).Format("2006.01.02 15:04:05"),
+					container.State,
+					container.Status,
+					prettyPorts,
+				})
+			} else {
+				t.AppendRow([]interface{}{
+					container.ID[:10],
+					containerImage,
+					container.Names[0][1:],
+					time.Unix(container.Created, 0).Format("2006.01.02 15:04:05"),
+					container.State,
+					container.HealthStatus(),
+					container.Status,
+					prettyPorts,
+					container.Command,
+				})
+			}
+		}
+	}
+
+	t.SortBy([]table.SortBy{
+		{Name: "Name", Mode: table.Asc},
+	})
+	t.SetStyle(table.StyleBold)
+
+	t.Style().Format.Header = text.FormatDefault
+	t.SetRowPainter(func(row table.Row) text.Colors {
+		if extended && row[stateRow+1] == "unhealthy" {
+			return text.Colors{text.FgHiRed}
+		}
+		switch row[stateRow] {
+		case "running":
+			return text.Colors{text.FgHiGreen}
+		case "crashed":
+			return text.Colors{text.BgBlack, text.FgHiRed}
+		case "blocked", "suspended", "paused":
+			return text.Colors{text.FgHiYellow}
+		}
+		return nil
+	})
+
+	t.Render()
+	return nil
+}
+
+// showExtendedInfo renders the output of `dtools info`
+func showExtendedInfo(cInfo containers.ContainerSummary) {
+	state := ""
+	switch cInfo.State {
+	case "running":
+		state = hftx.Green("running")
+	case "paused", "suspended", "blocked":
+		state = hftx.Yellow(cInfo.State)
+	case "crashed":
+		state = hftx.Red("crashed")
+	default:
+		state = hftx.White(strings.ToLower(cInfo.State))
+	}
+	w := tabwriter.NewWriter(os.Stdout, 1, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\t%s\n", hftx.Blue("Container name"), cInfo.Names[0][1:])
+	fmt.Fprintf(w, "%s\t%s\n", hftx.Blue("Image"), getImageTag(cInfo.Image))
+	fmt.Fprintf(w, "%s\t%s\n", hftx.Blue("Created"), time.Unix(cInfo.Created, 0).Format("2006.01.02 15:04:05"))
+	fmt.Fprintf(w, "%s\t%s\n", hftx.Blue("State"), state)
+	switch health := cInfo.HealthStatus(); health {
+	case "healthy":
+		fmt.Fprintf(w, "%s\t%s\n", hftx.Blue("Health"), hftx.Green(health))
+	case "unhealthy":
+		fmt.Fprintf(w, "%s\t%s\n", hftx.Blue("Health"), hftx.Red(health))
+	case "starting":
+		fmt.Fprintf(w, "%s\t%s\n", hftx.Blue("Health"), hftx.Yellow(health))
+	}
+	fmt.Fprintf(w, "%s\t%s\n", hftx.Blue("Status"), strings.ToLower(cInfo.Status))
+	fmt.Fprintf(w, "%s\t%s\n", hftx.Blue("RW filesystem size"), formatSize(cInfo.SizeRw))
+	fmt.Fprintf(w, "%s\t%s\n", hftx.Blue("RootFS size"), formatSize(cInfo.SizeRootFs))
+	fmt.Fprintf(w, "%s\t%s\n", hftx.Blue("Exposed ports"), prettifyPortsList(cInfo.Ports, ", "))
+	fmt.Fprintf(w, "%s\t%s\n", hftx.Blue("Mount points"), prettifyMounts(cInfo.Mounts, ", "))
+	fmt.Fprintf(w, "%s\t%s\n", hftx.Blue("Command"), cInfo.Command)
+	w.Flush()
+}
+
+// Standardizes the image:tag format, adding :latest when the tag is missing.
+// Handles registry prefixes with or without ports.
+func getImageTag(name string) string {
+	slashIndex := strings.LastIndex(name, "/")
+	colonIndex := strings.LastIndex(name, ":")
+
+	// If the last colon comes after the last slash, we already have a tag.
+	if colonIndex > slashIndex {
+		return name
+	}
+	return name + ":latest"
+}
+
+// Formats the ports list to make it more human-readable
+func prettifyPortsList(ports []containers.PortsStruct, delimiter string) string {
+	seen := make(map[string]struct{})
+	var portsString, sourcePort string
+
+	for ndx, val := range ports {
+		key := fmt.Sprintf("%s-%d-%d", val.Type, val.PublicPort, val.PrivatePort)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		if val.PublicPort == 0 {
+			sourcePort = ""
+		} else {
+			sourcePort = fmt.Sprintf("%d->", val.PublicPort)
+		}
+		if ndx < len(ports)-1 {
+			portsString += fmt.Sprintf("%s/%s%d%s", val.Type, sourcePort, val.PrivatePort, delimiter)
+		} else {
+			portsString += fmt.Sprintf("%s/%s%d", val.Type, sourcePort, val.PrivatePort)
+		}
+	}
+	return portsString
+}
+
+// Same principle here as for prettifyPortsList
+func prettifyMounts(mounts []containers.MountsStruct, delimiter string) string {
+	var mountspecs string
+
+	for ndx, mount := range mounts {
+		// Last item: no trailing delimiter
+		if ndx == len(mounts)-1 {
+			delimiter = ""
+		}
+
+		var src string
+
+		switch mount.Type {
+		case "bind":
+			// Bind mounts: show the host path
+			src = mount.Source
+
+		case "volume":
+			// Named volumes: show the volume name, not the host path
+			if mount.Name != "" {
+				src = "[" + mount.Name + "]"
+			} else if mount.Source != "" {
+				// Anonymous volume: fall back to source path
+				src = "[" + mount.Source + "]"
+			} else {
+				src = "[<anonymous>]"
+			}
+
+		default:
+			// Anything else (tmpfs, npipe, etc): fall back to Source if present
+			if mount.Source != "" {
+				src = "[" + mount.Source + "]"
+			} else {
+				src = "[?]"
+			}
+		}
+
+		if mount.RW {
+			mountspecs += fmt.Sprintf("%s %s:%s%s",
+				hftx.EnabledSign(""), src, mount.Destination, delimiter)
+		} else {
+			mountspecs += fmt.Sprintf("%s %s:%s%s",
+				hftx.ErrorSign(""), src, mount.Destination, delimiter)
+		}
+	}
+
+	return mountspecs
+}
+
+// formatSize is shared by the containers and images renderers
+func formatSize(sz int64) string {
+	numSize := (float64)(sz) / 1000.0 / 1000.0 // this will give us the size in MB
+	if (int)(math.Log10(float64(numSize))) > 2 {
+		return fmt.Sprintf("%.3f GB", numSize/1000.0)
+	} else {
+		return fmt.Sprintf("%.3f MB", numSize)
+	}
+}
+
+// renderStats runs `dtools stats`: a table refreshed every second while streaming, or one JSON object per sample with --json
+func renderStats(ctx context.Context, targets []string, stream bool) *ce.CustomError {
+	var mu sync.Mutex
+	latest := make(map[string]containers.StatsSample)
+	enc := json.NewEncoder(os.Stdout)
+
+	opts := containers.StatsOptions{Stream: stream}
+	opts.OnSample = func(s containers.StatsSample) {
+		if extras.OutputJSON {
+			_ = enc.Encode(s) // Stats() serializes the calls
+			return
+		}
+		mu.Lock()
+		latest[s.ID] = s
+		mu.Unlock()
+	}
+
+	if !stream || extras.OutputJSON {
+		samples, err := containers.Stats(ctx, restClient, targets, opts)
+		if err == nil && !extras.OutputJSON {
+			printStatsTable(samples)
+		}
+		return err
+	}
+
+	snapshot := func() []containers.StatsSample {
+		mu.Lock()
+		defer mu.Unlock()
+		samples := make([]containers.StatsSample, 0, len(latest))
+		for _, s := range latest {
+			samples = append(samples, s)
+		}
+		return samples
+	}
+
+	done := make(chan *ce.CustomError, 1)
+	go func() {
+		_, err := containers.Stats(ctx, restClient, targets, opts)
+		done <- err
+	}()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-done:
+			// Every stream ended (the containers stopped, or we were interrupted): leave the last figures on screen
+			if err == nil {
+				fmt.Print("\033[H\033[2J")
+				printStatsTable(snapshot())
+			}
+			return err
+		case <-ticker.C:
+			fmt.Print("\033[H\033[2J")
+			printStatsTable(snapshot())
+		}
+	}
+}
+
+func printStatsTable(samples []containers.StatsSample) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Container ID", "Name", "CPU %", "Mem usage / limit", "Mem %", "Net I/O", "Block I/O", "PIDs"})
+
+	rows := 0
+	for _, s := range samples {
+		if s.ID == "" {
+			continue // no sample was read for that container
+		}
+		t.AppendRow(table.Row{
+			shortContainerID(s.ID),
+			s.Name,
+			fmt.Sprintf("%.2f%%", s.CPUPercent),
+			formatBytesBinary(int64(s.MemUsage)) + " / " + formatBytesBinary(int64(s.MemLimit)),
+			fmt.Sprintf("%.2f%%", s.MemPercent),
+			formatBytesBinary(int64(s.NetRx)) + " / " + formatBytesBinary(int64(s.NetTx)),
+			formatBytesBinary(int64(s.BlockRead)) + " / " + formatBytesBinary(int64(s.BlockWrite)),
+			s.PIDs,
+		})
+		rows++
+	}
+	// Keep the table borders and layout intact when there is nothing to show
+	if rows == 0 {
+		t.AppendRow(table.Row{"", "", "", "", "", "", "", ""})
+	}
+
+	t.SortBy([]table.SortBy{
+		{Name: "Name", Mode: table.Asc},
+	})
+	t.SetStyle(table.StyleBold)
+	t.Style().Format.Header = text.FormatDefault
+	t.Render()
+}
+
+func shortContainerID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+// renderTop renders the output of `dtools top`, with the ps columns in the order ps printed them
+func renderTop(top *containers.TopResponse) *ce.CustomError {
+	if done, cerr := renderPayload(top.ProcessList()); done || cerr != nil {
+		return cerr
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+
+	header := table.Row{}
+	for _, title := range top.Titles {
+		header = append(header, title)
+	}
+	t.AppendHeader(header)
+	for _, p := range top.Processes {
+		row := table.Row{}
+		for _, field := range p {
+			row = append(row, field)
+		}
+		t.AppendRow(row)
+	}
+	if len(top.Processes) == 0 {
+		t.AppendRow(make(table.Row, len(top.Titles)))
+	}
+
+	t.SetStyle(table.StyleBold)
+	t.Style().Format.Header = text.FormatDefault
+	t.Render()
+	return nil
+}
+
+// renderChanges renders the output of `dtools diff`
+func renderChanges(changes []containers.FilesystemChange) *ce.CustomError {
+	if done, cerr := renderPayload(changes); done || cerr != nil {
+		return cerr
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Type", "Path"})
+	for _, c := range changes {
+		t.AppendRow(table.Row{c.Type, c.Path})
+	}
+	if len(changes) == 0 {
+		t.AppendRow(table.Row{"", ""})
+	}
+
+	t.SortBy([]table.SortBy{
+		{Name: "Path", Mode: table.Asc},
+	})
+	t.SetStyle(table.StyleBold)
+	t.Style().Format.Header = text.FormatDefault
+	t.SetRowPainter(func(row table.Row) text.Colors {
+		switch row[0] {
+		case "A":
+			return text.Colors{text.FgHiGreen}
+		case "D":
+			return text.Colors{text.FgHiRed}
+		case "C":
+			return text.Colors{text.FgHiYellow}
+		}
+		return nil
+	})
+	t.Render()
+	return nil
+}
+
+// renderRunLike renders the output of `dtools runlike`: a command line that can be pasted into a shell
+func renderRunLike(args []string) *ce.CustomError {
+	if done, cerr := renderPayload(args); done || cerr != nil {
+		return cerr
+	}
+
+	quoted := make([]string, 0, len(args))
+	for _, a := range args {
+		quoted = append(quoted, shellQuote(a))
+	}
+	fmt.Println("dtools run " + strings.Join(quoted, " "))
+	return nil
+}
+
+// renderUnitFiles renders the output of `dtools container generate systemd`: the files one after the other
+func renderUnitFiles(files []systemd.UnitFile) *ce.CustomError {
+	if done, cerr := renderPayload(files); done || cerr != nil {
+		return cerr
+	}
+
+	for i, f := range files {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Print(f.Content)
+	}
+	return nil
+}
+
+// shellQuote single-quotes an argument when the shell would otherwise split or expand it
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	safe := true
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=,@%+", r)) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
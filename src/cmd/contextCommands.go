@@ -0,0 +1,95 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 12:03
+// Original filename: src/cmd/contextCommands.go
+
+package cmd
+
+import (
+	"dtools2/contexts"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var contextCmd = &cobra.Command{
+	Use:     "context",
+	Aliases: []string{"ctx"},
+	Example: "dtools context {create | ls | use | rm | inspect}",
+	Short:   "Manage named connection contexts",
+	Long: `A context is a named set of connection parameters (host, TLS settings, API version).
+The active context is picked from --context, then DTOOLS_CONTEXT, then the one set with 'dtools context use'.
+-H always wins, and DOCKER_HOST wins over a context that was not explicitly requested.`,
+	// Context management never talks to the daemon.
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+}
+
+var contextCreateCmd = &cobra.Command{
+	Use:     "create NAME",
+	Example: "dtools context create prod -H tcp://prod:2376 -T [-d description]",
+	Short:   "Create (or overwrite) a context from the connection flags",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if errCode := contexts.Create(args[0], configFromFlags(cmd)); errCode != nil {
+			fmt.Println(errCode)
+		}
+	},
+}
+
+var contextListCmd = &cobra.Command{
+	Use:     "ls",
+	Aliases: []string{"list"},
+	Example: "dtools context ls",
+	Short:   "List contexts",
+	Run: func(cmd *cobra.Command, args []string) {
+		active, _ := contexts.ActiveName(ContextName)
+		if errCode := contexts.ListContexts(active); errCode != nil {
+			fmt.Println(errCode)
+		}
+	},
+}
+
+var contextUseCmd = &cobra.Command{
+	Use:     "use NAME",
+	Example: "dtools context use prod",
+	Short:   "Set the current context ('default' to go back to flags and environment)",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if errCode := contexts.Use(args[0]); errCode != nil {
+			fmt.Println(errCode)
+		}
+	},
+}
+
+var contextRemoveCmd = &cobra.Command{
+	Use:     "rm NAME [NAME...]",
+	Aliases: []string{"remove"},
+	Example: "dtools context rm prod [-f]",
+	Short:   "Remove one or more contexts",
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if errCode := contexts.Remove(args); errCode != nil {
+			fmt.Println(errCode)
+		}
+	},
+}
+
+var contextInspectCmd = &cobra.Command{
+	Use:     "inspect NAME [NAME...]",
+	Example: "dtools context inspect prod",
+	Short:   "Display the contexts' details",
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if errCode := contexts.InspectContexts(args); errCode != nil {
+			fmt.Println(errCode)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(contextCmd)
+	contextCmd.AddCommand(contextCreateCmd, contextListCmd, contextUseCmd, contextRemoveCmd, contextInspectCmd)
+
+	contextCreateCmd.Flags().StringVarP(&contexts.ContextDescription, "description", "d", "", "Context description")
+	contextRemoveCmd.Flags().BoolVarP(&contexts.ForceContextRemoval, "force", "f", false, "Remove the context even if it is the current one")
+}
@@ -7,7 +7,7 @@ package cmd
 
 import (
 	"dtools2/env"
-	"dtools2/rest"
+	"dtools2/extras"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -40,7 +40,7 @@ var envRemoveCmd = &cobra.Command{
 			fmt.Println(err)
 			return
 		}
-		if !rest.QuietOutput {
+		if !extras.QuietOutput {
 			fmt.Println(hftx.EnabledSign("Default registry removed"))
 		}
 	},
@@ -67,7 +67,7 @@ var envAddCmd = &cobra.Command{
 			fmt.Println(err)
 			return
 		}
-		if !rest.QuietOutput {
+		if !extras.QuietOutput {
 			fmt.Println(hftx.EnabledSign("Default registry set to " + args[0] + " in " + env.RegConfigFile))
 		}
 	},
@@ -7,7 +7,6 @@ package cmd
 
 import (
 	"dtools2/extras"
-	"dtools2/rest"
 	"fmt"
 	"os"
 
@@ -24,12 +23,11 @@ var execCmd = &cobra.Command{
 			fmt.Println("REST client not initialized")
 			os.Exit(1)
 		}
-		rest.Context = cmd.Context()
 
 		container := args[0]
 		command := args[1:]
 
-		exitCode, cerr := extras.Run(restClient, container, command)
+		exitCode, cerr := extras.Run(cmd.Context(), restClient, container, command, execOpts)
 		if cerr != nil {
 			fmt.Println(cerr)
 			os.Exit(1)
@@ -49,9 +47,10 @@ var logsCmd = &cobra.Command{
 			fmt.Println("REST client not initialized")
 			return
 		}
-		rest.Context = cmd.Context()
 
-		if cerr := extras.Logs(restClient, args[0]); cerr != nil {
+		opts := logOpts
+		opts.Stdout, opts.Stderr = os.Stdout, os.Stderr
+		if cerr := extras.Logs(cmd.Context(), restClient, args[0], opts); cerr != nil {
 			fmt.Println(cerr)
 			return
 		}
@@ -61,11 +60,11 @@ var logsCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(execCmd, logsCmd)
 
-	execCmd.Flags().BoolVarP(&extras.Interactive, "interactive", "i", false, "Keep STDIN open even if not attached")
-	execCmd.Flags().BoolVarP(&extras.AllocateTTY, "tty", "t", false, "Allocate a pseudo-TTY")
-	execCmd.Flags().StringVarP(&extras.User, "user", "u", "", "Username or UID (format: <name|uid>[:<group|gid>])")
-	logsCmd.Flags().BoolVarP(&extras.LogTimestamps, "timestamps", "t", false, "Show timestamps")
-	logsCmd.Flags().IntVarP(&extras.LogTail, "tail", "n", -1, "Number of lines to show from the end of the logs (-1 means all)")
-	logsCmd.Flags().BoolVarP(&extras.LogFollow, "follow", "f", false, "Follow log output")
+	execCmd.Flags().BoolVarP(&execOpts.Interactive, "interactive", "i", false, "Keep STDIN open even if not attached")
+	execCmd.Flags().BoolVarP(&execOpts.TTY, "tty", "t", false, "Allocate a pseudo-TTY")
+	execCmd.Flags().StringVarP(&execOpts.User, "user", "u", "", "Username or UID (format: <name|uid>[:<group|gid>])")
+	logsCmd.Flags().BoolVarP(&logOpts.Timestamps, "timestamps", "t", false, "Show timestamps")
+	logsCmd.Flags().IntVarP(&logOpts.Tail, "tail", "n", -1, "Number of lines to show from the end of the logs (-1 means all)")
+	logsCmd.Flags().BoolVarP(&logOpts.Follow, "follow", "f", false, "Follow log output")
 
 }
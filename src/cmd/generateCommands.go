@@ -0,0 +1,78 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 04:55
+// Original filename: src/cmd/generateCommands.go
+
+package cmd
+
+import (
+	"dtools2/extras"
+	"dtools2/systemd"
+	"fmt"
+	"os"
+	"strings"
+
+	hftx "github.com/jeanfrancoisgratton/helperFunctions/v4/terminalfx"
+	"github.com/spf13/cobra"
+)
+
+var containerGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate files from a container",
+}
+
+var containerGenerateSystemdCmd = &cobra.Command{
+	Use:     "systemd [flags] CONTAINER",
+	Example: "dtools container generate systemd web\ndtools container generate systemd --quadlet --install web",
+	Short:   "Generate the systemd unit (or Quadlet files) that runs a container",
+	Long: `Inspect a container and print the systemd unit that runs it with podman: the container is created when the unit
+starts and removed when it stops, and Restart= follows the restart policy of the container.
+With --quadlet, print the .container file instead, plus a .network file per network (Podman 4.4+); Quadlet names those
+networks systemd-NAME. The named volumes are used as they are, so the data stays. The flags without a Quadlet key of
+Podman 4.4 go to PodmanArgs=, which needs Podman 4.5: they are reported, like the volumes podman cannot create alone.
+With --install, write the files instead: units in ~/.config/systemd/user, Quadlet files in ~/.config/containers/systemd.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if restClient == nil {
+			fmt.Println("REST client not initialized")
+			return
+		}
+
+		files, warnings, cerr := systemd.Generate(cmd.Context(), restClient, args[0], generateSystemdOpts)
+		if cerr != nil {
+			fmt.Println(cerr)
+			return
+		}
+		if !extras.QuietOutput {
+			for _, w := range warnings {
+				fmt.Fprintln(os.Stderr, hftx.WarningSign(" "+w))
+			}
+		}
+		if !generateSystemdInstall {
+			if cerr = renderUnitFiles(files); cerr != nil {
+				fmt.Println(cerr)
+			}
+			return
+		}
+
+		if _, cerr = systemd.Install(files, systemd.InstallOptions{OnEvent: printEvent}); cerr != nil {
+			fmt.Println(cerr)
+			return
+		}
+		if !extras.QuietOutput {
+			unit := files[0].Name
+			if generateSystemdOpts.Quadlet {
+				unit = strings.TrimSuffix(unit, ".container") + ".service"
+			}
+			fmt.Println(hftx.InfoSign("Run: systemctl --user daemon-reload && systemctl --user start " + unit))
+		}
+	},
+}
+
+func init() {
+	containerCmd.AddCommand(containerGenerateCmd)
+	containerGenerateCmd.AddCommand(containerGenerateSystemdCmd)
+
+	containerGenerateSystemdCmd.Flags().BoolVar(&generateSystemdOpts.Quadlet, "quadlet", false, "Generate Quadlet files (.container, .network) instead of a service unit")
+	containerGenerateSystemdCmd.Flags().BoolVar(&generateSystemdInstall, "install", false, "Write the files in the user directories of systemd or Quadlet instead of printing them")
+}
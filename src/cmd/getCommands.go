@@ -7,11 +7,16 @@ package cmd
 
 import (
 	"dtools2/env"
+	"dtools2/extras"
 	"dtools2/system"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+	hfjson "github.com/jeanfrancoisgratton/helperFunctions/v4/prettyjson"
+	hftx "github.com/jeanfrancoisgratton/helperFunctions/v4/terminalfx"
 	"github.com/spf13/cobra"
 )
 
@@ -28,7 +33,11 @@ var getCatalogCmd = &cobra.Command{
 			env.RegConfigFile = filepath.Join(os.Getenv("HOME"), ".config", "JFG", "dtools", "defaultRegistry.json")
 		}
 
-		if err := system.GetCatalog(); err != nil {
+		payload, raw, err := system.GetCatalog(cmd.Context(), env.RegConfigFile)
+		if err == nil {
+			err = showRegistryPayload(payload, raw)
+		}
+		if err != nil {
 			fmt.Println(err)
 		}
 		return
@@ -43,7 +52,11 @@ var getTagsCmd = &cobra.Command{
 		if env.RegConfigFile == "" {
 			env.RegConfigFile = filepath.Join(os.Getenv("HOME"), ".config", "JFG", "dtools", "defaultRegistry.json")
 		}
-		if err := system.GetTags(args[0]); err != nil {
+		payload, raw, err := system.GetTags(cmd.Context(), env.RegConfigFile, args[0])
+		if err == nil {
+			err = showRegistryPayload(payload, raw)
+		}
+		if err != nil {
 			fmt.Println(err)
 		}
 		return
@@ -54,8 +67,27 @@ func init() {
 	rootCmd.AddCommand(getCmd)
 	getCmd.AddCommand(getCatalogCmd, getTagsCmd)
 	getCatalogCmd.Flags().StringVarP(&env.RegConfigFile, "registryfile", "r", "", "registry config file")
-	getCatalogCmd.Flags().StringVarP(&system.JSONoutputfile, "output", "o", "", "send output to file")
+	getCatalogCmd.Flags().StringVarP(&catalogOutputFile, "output", "o", "", "send output to file")
 	getTagsCmd.Flags().StringVarP(&env.RegConfigFile, "registryfile", "r", "", "registry config file")
-	getTagsCmd.Flags().StringVarP(&system.JSONoutputfile, "file", "f", "", "send output to file")
+	getTagsCmd.Flags().StringVarP(&catalogOutputFile, "file", "f", "", "send output to file")
+}
+
+// showRegistryPayload writes the registry's answer to the --output/--file file if one was given, or prints it
+func showRegistryPayload(payload any, raw []byte) *ce.CustomError {
+	if catalogOutputFile == "" {
+		hfjson.Print(raw)
+		return nil
+	}
 
+	if !extras.QuietOutput {
+		fmt.Println(hftx.EnabledSign("Output sent to " + catalogOutputFile))
+	}
+	jStream, jerr := json.MarshalIndent(payload, "", "  ")
+	if jerr != nil {
+		return &ce.CustomError{Title: "Error marshaling the JSON payload", Message: jerr.Error()}
+	}
+	if werr := os.WriteFile(catalogOutputFile, jStream, 0600); werr != nil {
+		return &ce.CustomError{Title: "Error writing the JSON output file", Message: werr.Error()}
+	}
+	return nil
 }
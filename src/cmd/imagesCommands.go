@@ -9,9 +9,11 @@ package cmd
 import (
 	"dtools2/extras"
 	"dtools2/images"
-	"dtools2/rest"
+	"dtools2/system"
 	"fmt"
 
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+	hftx "github.com/jeanfrancoisgratton/helperFunctions/v4/terminalfx"
 	"github.com/spf13/cobra"
 )
 
@@ -36,8 +38,7 @@ var imagePullCmd = &cobra.Command{
 		}
 
 		imageRef := args[0]
-		rest.Context = cmd.Context()
-		if err := images.ImagePull(restClient, imageRef); err != nil {
+		if err := images.ImagePull(cmd.Context(), restClient, imageRef, progressOutput()); err != nil {
 			fmt.Println(err)
 		}
 		return
@@ -58,8 +59,7 @@ var imagePushCmd = &cobra.Command{
 		}
 
 		imageRef := args[0]
-		rest.Context = cmd.Context()
-		if err := images.ImagePush(restClient, imageRef); err != nil {
+		if err := images.ImagePush(cmd.Context(), restClient, imageRef, progressOutput()); err != nil {
 			fmt.Println(err)
 		}
 		return
@@ -75,8 +75,17 @@ var imageListCmd = &cobra.Command{
 			return
 		}
 
-		rest.Context = cmd.Context()
-		if _, err := images.ImagesList(restClient, true); err != nil {
+		opts := imageListOpts
+		var err *ce.CustomError
+		if opts.Filters, err = extras.ParseFilters(listFilters); err != nil {
+			fmt.Println(err)
+			return
+		}
+		is, err := images.ImagesList(cmd.Context(), restClient, opts)
+		if err == nil {
+			err = renderImageList(is)
+		}
+		if err != nil {
 			fmt.Println(err)
 		}
 		return
@@ -93,9 +102,12 @@ var imageTagCmd = &cobra.Command{
 			return
 		}
 
-		rest.Context = cmd.Context()
-		if err := images.TagImage(restClient, args[0], args[1]); err != nil {
+		if err := images.TagImage(cmd.Context(), restClient, args[0], args[1]); err != nil {
 			fmt.Println(err)
+			return
+		}
+		if !extras.QuietOutput {
+			fmt.Println("Image " + hftx.Green(args[0]) + " tagged as " + hftx.Green(args[1]))
 		}
 		return
 	},
@@ -112,8 +124,9 @@ var imageRemoveCmd = &cobra.Command{
 			return
 		}
 
-		rest.Context = cmd.Context()
-		if err := images.RemoveImage(restClient, args); err != nil {
+		opts := imageRemoveOpts
+		opts.OnEvent = printEvent
+		if _, err := images.RemoveImage(cmd.Context(), restClient, args, opts); err != nil {
 			fmt.Println(err)
 		}
 		return
@@ -131,8 +144,7 @@ var imageLoadCmd = &cobra.Command{
 			return
 		}
 
-		rest.Context = cmd.Context()
-		if err := images.ImageLoad(restClient, args[0]); err != nil {
+		if err := images.ImageLoad(cmd.Context(), restClient, args[0], progressOutput()); err != nil {
 			fmt.Println(err)
 		}
 		return
@@ -154,8 +166,7 @@ var imageSaveCmd = &cobra.Command{
 		outFile := args[0]
 		imgs := args[1:]
 
-		rest.Context = cmd.Context()
-		if err := images.ImageSave(restClient, imgs, outFile); err != nil {
+		if err := images.ImageSave(cmd.Context(), restClient, imgs, outFile); err != nil {
 			fmt.Println(err)
 		}
 		return
@@ -173,11 +184,35 @@ var imageCommitCmd = &cobra.Command{
 			return
 		}
 
-		containerRef := args[0]
-		repoTag := args[1]
+		opts := images.CommitOptions{
+			Container: args[0],
+			RepoTag:   args[1],
+			Author:    commitAuthor,
+			Message:   commitMessage,
+			Changes:   commitChanges,
+		}
+		if _, err := images.ImageCommit(cmd.Context(), restClient, opts); err != nil {
+			fmt.Println(err)
+			return
+		}
+		if !extras.QuietOutput {
+			fmt.Println(hftx.GreenGoSign("Commited container " + opts.Container + " to image " + opts.RepoTag))
+		}
+	},
+}
+
+var imageInspectCmd = &cobra.Command{
+	Use:     "inspect IMAGE [IMAGE...]",
+	Example: "dtools image inspect nginx:latest --format Config.Env",
+	Short:   "Show the full inspect document of images",
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if restClient == nil {
+			fmt.Println("REST client not initialized")
+			return
+		}
 
-		rest.Context = cmd.Context()
-		if err := images.ImageCommit(restClient, containerRef, repoTag, commitAuthor, commitMessage, commitChanges); err != nil {
+		if err := renderInspect(cmd.Context(), system.InspectImage, args); err != nil {
 			fmt.Println(err)
 		}
 		return
@@ -186,15 +221,18 @@ var imageCommitCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(imgCmd, imagePullCmd, imagePushCmd, imageListCmd, imageTagCmd, imageRemoveCmd, imageLoadCmd, imageSaveCmd, imageCommitCmd)
-	imgCmd.AddCommand(imagePullCmd, imagePushCmd, imageListCmd, imageTagCmd, imageRemoveCmd, imageLoadCmd, imageSaveCmd, imageCommitCmd)
+	imgCmd.AddCommand(imagePullCmd, imagePushCmd, imageListCmd, imageTagCmd, imageRemoveCmd, imageLoadCmd, imageSaveCmd, imageCommitCmd, imageInspectCmd)
 
 	imagePullCmd.Flags().StringVarP(&imagePullRegistry, "registry", "r", "", "registry hostname to use for auth (e.g. registry.example.com:5000); empty for anonymous")
-	imageRemoveCmd.Flags().BoolVarP(&images.ForceRemove, "force", "f", false, "Force remove image")
-	imageRemoveCmd.Flags().BoolVarP(&images.RemoveBlacklisted, "blacklist", "B", false, "remove image even if blacklisted")
+	imageRemoveCmd.Flags().BoolVarP(&imageRemoveOpts.Force, "force", "f", false, "Force remove image")
+	imageRemoveCmd.Flags().BoolVarP(&imageRemoveOpts.IgnoreBlacklist, "blacklist", "B", false, "remove image even if blacklisted")
 	imageListCmd.Flags().StringVarP(&extras.OutputFile, "file", "F", "", "Write JSON output to a file")
 	imageListCmd.Flags().StringVar(&extras.OutputFormat, "format", "", "Output only the values for the given field (or comma-separated fields) as plaintext")
+	imageListCmd.Flags().StringArrayVar(&listFilters, "filter", nil, "Filter the output (key=value, e.g. dangling=true, reference=nginx:*, before=alpine:3.20, label=maintainer). Can be specified multiple times")
 
 	imageCommitCmd.Flags().StringVarP(&commitAuthor, "author", "a", "", "Author (equivalent to docker commit -a)")
 	imageCommitCmd.Flags().StringVarP(&commitMessage, "message", "m", "", "Commit message (equivalent to docker commit -m)")
 	imageCommitCmd.Flags().StringArrayVarP(&commitChanges, "change", "c", nil, "Apply Dockerfile instruction to the created image (equivalent to docker commit -c). Can be specified multiple times")
+	imageInspectCmd.Flags().StringVarP(&extras.OutputFile, "file", "F", "", "Write JSON output to a file")
+	imageInspectCmd.Flags().StringVar(&extras.OutputFormat, "format", "", "Output only the values at the given path (or comma-separated paths); \"*\" walks every element of a list or map")
 }
@@ -0,0 +1,74 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 17:05
+// Original filename: src/cmd/imagesOutput.go
+
+package cmd
+
+import (
+	"dtools2/images"
+	"os"
+	"time"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// renderImageList renders the output of `dtools lsi`
+func renderImageList(iInfoSlice []images.ImageSummary) *ce.CustomError {
+	if done, cerr := renderPayload(iInfoSlice); done || cerr != nil {
+		return cerr
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{
+		"Repository/image name",
+		"Image tag",
+		"Image ID",
+		"Creation time",
+		"Size",
+		"# containers",
+	})
+
+	// When there are no images, append a single empty row to keep the
+	// table borders and layout intact.
+	if len(iInfoSlice) == 0 {
+		t.AppendRow(table.Row{"", "", "", "", "", ""})
+	} else {
+		for _, imgspec := range iInfoSlice {
+			// imgspec.ID is already stripped of "sha256:"; trim to 12 chars for display
+			displayID := imgspec.ID
+			if len(displayID) >= 12 {
+				displayID = displayID[:12]
+			}
+
+			t.AppendRow(table.Row{
+				imgspec.RepoImgName,
+				imgspec.ImgTag,
+				displayID,
+				time.Unix(imgspec.Created, 0).Format("2006.01.02 15:04:05"),
+				formatSize(imgspec.Size),
+				imgspec.Containers,
+			})
+		}
+	}
+
+	t.SortBy([]table.SortBy{
+		{Name: "Repository/image name", Mode: table.Asc},
+	})
+	t.SetStyle(table.StyleColoredBlackOnBlueWhite)
+	t.Style().Format.Header = text.FormatDefault
+	t.SetRowPainter(func(row table.Row) text.Colors {
+		switch row[5] {
+		case 0:
+			return text.Colors{text.FgHiWhite}
+		default:
+			return text.Colors{text.FgHiGreen}
+		}
+	})
+
+	t.Render()
+	return nil
+}
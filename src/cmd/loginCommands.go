@@ -8,7 +8,6 @@ package cmd
 import (
 	"dtools2/auth"
 	"dtools2/extras"
-	"dtools2/rest"
 	"fmt"
 	"os"
 
@@ -53,8 +52,7 @@ registry's /v2/ endpoint and, on success, stored in the Docker config file.`,
 			CACertPath: loginCACertPath,
 			// Timeout left as zero => default inside auth.Login
 		}
-		rest.Context = cmd.Context()
-		if err := auth.Login(opts); err != nil {
+		if err := auth.Login(cmd.Context(), opts); err != nil {
 			fmt.Println("Login failed: ", err.Error())
 			os.Exit(1)
 		}
@@ -9,9 +9,11 @@ package cmd
 import (
 	"dtools2/extras"
 	"dtools2/networks"
-	"dtools2/rest"
+	"dtools2/system"
 	"fmt"
 
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+	hftx "github.com/jeanfrancoisgratton/helperFunctions/v4/terminalfx"
 	"github.com/spf13/cobra"
 )
 
@@ -31,8 +33,17 @@ var networkListCmd = &cobra.Command{
 			return
 		}
 
-		rest.Context = cmd.Context()
-		if _, err := networks.NetworkList(restClient, true); err != nil {
+		opts := networkListOpts
+		var err *ce.CustomError
+		if opts.Filters, err = extras.ParseFilters(listFilters); err != nil {
+			fmt.Println(err)
+			return
+		}
+		ns, err := networks.NetworkList(cmd.Context(), restClient, opts)
+		if err == nil {
+			err = renderNetworkList(ns)
+		}
+		if err != nil {
 			fmt.Println(err)
 		}
 		return
@@ -53,9 +64,14 @@ var networkCreateCmd = &cobra.Command{
 			return
 		}
 
-		rest.Context = cmd.Context()
-		if err := networks.AddNetwork(restClient, args[0]); err != nil {
+		ncr := networkCreateReq
+		ncr.Name = args[0]
+		if _, err := networks.AddNetwork(cmd.Context(), restClient, ncr); err != nil {
 			fmt.Println(err)
+			return
+		}
+		if !extras.QuietOutput {
+			fmt.Println(hftx.GreenGoSign("Network " + ncr.Name + " has been created"))
 		}
 		return
 	},
@@ -72,8 +88,9 @@ var networkRmCmd = &cobra.Command{
 			return
 		}
 
-		rest.Context = cmd.Context()
-		if err := networks.RemoveNetwork(restClient, args); err != nil {
+		opts := networkRemoveOpts
+		opts.OnEvent = printEvent
+		if _, err := networks.RemoveNetwork(cmd.Context(), restClient, args, opts); err != nil {
 			fmt.Println(err)
 		}
 		return
@@ -92,11 +109,12 @@ var networkAttachCmd = &cobra.Command{
 			return
 		}
 
-		rest.Context = cmd.Context()
-		//containerName := args[0]
-		//networkName := args[1]
-		if err := networks.AttachNetwork(restClient, args[0], args[1]); err != nil {
+		if err := networks.AttachNetwork(cmd.Context(), restClient, args[0], args[1]); err != nil {
 			fmt.Println(err)
+			return
+		}
+		if !extras.QuietOutput {
+			fmt.Println(hftx.GreenGoSign("Network " + hftx.Green(args[0]) + " connected"))
 		}
 		return
 	},
@@ -114,10 +132,29 @@ var networkDetachCmd = &cobra.Command{
 			return
 		}
 
-		rest.Context = cmd.Context()
-		//containerName := args[0]
-		//networkName := args[1]
-		if err := networks.DetachNetwork(restClient, args[0], args[1]); err != nil {
+		if err := networks.DetachNetwork(cmd.Context(), restClient, args[0], args[1], networkDetachForce); err != nil {
+			fmt.Println(err)
+			return
+		}
+		if !extras.QuietOutput {
+			fmt.Println(hftx.GreenGoSign("Network " + hftx.Green(args[0]) + " disconnected"))
+		}
+		return
+	},
+}
+
+var networkInspectCmd = &cobra.Command{
+	Use:     "inspect NETWORK [NETWORK...]",
+	Example: "dtools network inspect bridge --format IPAM.Config.*.Subnet",
+	Short:   "Show the full inspect document of networks",
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if restClient == nil {
+			fmt.Println("REST client not initialized")
+			return
+		}
+
+		if err := renderInspect(cmd.Context(), system.InspectNetwork, args); err != nil {
 			fmt.Println(err)
 		}
 		return
@@ -126,14 +163,17 @@ var networkDetachCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(networkCmd, networkListCmd, networkRmCmd)
-	networkCmd.AddCommand(networkListCmd, networkCreateCmd, networkRmCmd, networkAttachCmd, networkDetachCmd)
-
-	networkDetachCmd.Flags().BoolVarP(&networks.ForceNetworkDetach, "force", "f", false, "force-detach the network from the container")
-	networkCreateCmd.Flags().StringVarP(&networks.NetworkDriverName, "driver", "d", "bridge", "network driver network")
-	networkCreateCmd.Flags().BoolVarP(&networks.NetworkEnableIPv6, "ipv6", "6", false, "enable IPv6 on the network")
-	networkCreateCmd.Flags().BoolVarP(&networks.NetworkInternalUse, "internal", "i", false, "internal network only")
-	networkCreateCmd.Flags().BoolVarP(&networks.NetworkAttachable, "attachable", "a", false, "network is attachable (no effect on bridged networks)")
-	networkRmCmd.Flags().BoolVarP(&networks.RemoveBlacklisted, "blacklist", "B", false, "remove network even if blacklisted")
+	networkCmd.AddCommand(networkListCmd, networkCreateCmd, networkRmCmd, networkAttachCmd, networkDetachCmd, networkInspectCmd)
+
+	networkDetachCmd.Flags().BoolVarP(&networkDetachForce, "force", "f", false, "force-detach the network from the container")
+	networkCreateCmd.Flags().StringVarP(&networkCreateReq.Driver, "driver", "d", "bridge", "network driver network")
+	networkCreateCmd.Flags().BoolVarP(&networkCreateReq.EnableIPv6, "ipv6", "6", false, "enable IPv6 on the network")
+	networkCreateCmd.Flags().BoolVarP(&networkCreateReq.Internal, "internal", "i", false, "internal network only")
+	networkCreateCmd.Flags().BoolVarP(&networkCreateReq.Attachable, "attachable", "a", false, "network is attachable (no effect on bridged networks)")
+	networkRmCmd.Flags().BoolVarP(&networkRemoveOpts.IgnoreBlacklist, "blacklist", "B", false, "remove network even if blacklisted")
 	networkListCmd.Flags().StringVarP(&extras.OutputFile, "file", "F", "", "Write JSON output to a file")
 	networkListCmd.Flags().StringVar(&extras.OutputFormat, "format", "", "Output only the values for the given field (or comma-separated fields) as plaintext")
+	networkListCmd.Flags().StringArrayVar(&listFilters, "filter", nil, "Filter the output (key=value, e.g. driver=bridge, name=front, label=env=prod). Can be specified multiple times")
+	networkInspectCmd.Flags().StringVarP(&extras.OutputFile, "file", "F", "", "Write JSON output to a file")
+	networkInspectCmd.Flags().StringVar(&extras.OutputFormat, "format", "", "Output only the values at the given path (or comma-separated paths); \"*\" walks every element of a list or map")
 }
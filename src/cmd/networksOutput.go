@@ -0,0 +1,65 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 17:25
+// Original filename: src/cmd/networksOutput.go
+
+package cmd
+
+import (
+	"dtools2/networks"
+	"os"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+	hftx "github.com/jeanfrancoisgratton/helperFunctions/v4/terminalfx"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// renderNetworkList renders the output of `dtools lsn`
+func renderNetworkList(ns []networks.NetworkSummary) *ce.CustomError {
+	if done, cerr := renderPayload(ns); done || cerr != nil {
+		return cerr
+	}
+
+	tw := table.NewWriter()
+	tw.SetOutputMirror(os.Stdout)
+	tw.AppendHeader(table.Row{"Name", "Driver", "Scope", "Used", "Network ID"})
+
+	if len(ns) == 0 {
+		tw.AppendRow(table.Row{"", "", "", "", ""})
+	} else {
+		for _, n := range ns {
+			displayID := n.ID
+			if len(displayID) > 12 {
+				displayID = displayID[:12]
+			}
+
+			inUse := hftx.ErrorSign("")
+			if n.InUse {
+				inUse = hftx.EnabledSign("")
+			}
+
+			tw.AppendRow(table.Row{
+				n.Name,
+				n.Driver,
+				n.Scope,
+				inUse,
+				displayID,
+			})
+		}
+	}
+
+	tw.SortBy([]table.SortBy{{Name: "Name", Mode: table.Asc}})
+	tw.SetStyle(table.StyleBold)
+	tw.Style().Format.Header = text.FormatDefault
+	tw.SetRowPainter(func(row table.Row) text.Colors {
+		// "In use" column
+		if row[3] == "yes" {
+			return text.Colors{text.FgHiGreen}
+		}
+		return text.Colors{text.FgHiWhite}
+	})
+
+	tw.Render()
+	return nil
+}
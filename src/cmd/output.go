@@ -0,0 +1,105 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 16:40
+// Original filename: src/cmd/output.go
+
+package cmd
+
+import (
+	"dtools2/extras"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+	hfjson "github.com/jeanfrancoisgratton/helperFunctions/v4/prettyjson"
+	hftx "github.com/jeanfrancoisgratton/helperFunctions/v4/terminalfx"
+)
+
+// renderPayload handles the output flags shared by the list commands: --file, --format and --json.
+// It returns true when nothing is left to do, i.e. the payload was already rendered or -q was given;
+// otherwise the caller renders its own table.
+func renderPayload(payload any) (bool, *ce.CustomError) {
+	var payloadBytes []byte
+	if extras.OutputFile != "" {
+		b, cerr := extras.Send2File(payload, extras.OutputFile)
+		if cerr != nil {
+			return true, cerr
+		}
+		payloadBytes = b
+	}
+
+	// --format takes precedence over --json, tables and ignores --quiet.
+	if extras.OutputFormat != "" {
+		rows, cerr := extras.ExtractFormatRows(payload, extras.OutputFormat)
+		if cerr != nil {
+			return true, cerr
+		}
+		return true, extras.PrintFormatRows(rows)
+	}
+
+	if extras.OutputJSON {
+		// Marshal once if we didn't already (for --file).
+		if payloadBytes == nil {
+			b, cerr := extras.MarshalJSON(payload)
+			if cerr != nil {
+				return true, cerr
+			}
+			payloadBytes = b
+		}
+		hfjson.Print(payloadBytes)
+		return true, nil
+	}
+
+	// JSON output not requested; if quiet, we're done.
+	return extras.QuietOutput, nil
+}
+
+// progressOutput is where the pull/push/load/build progress goes: stdout, or nowhere with -q
+func progressOutput() io.Writer {
+	if extras.QuietOutput {
+		return nil
+	}
+	return os.Stdout
+}
+
+// printEvent reports the progress of the library functions (start, stop, remove, prune...)
+func printEvent(e extras.Event) {
+	if extras.QuietOutput {
+		return
+	}
+
+	resource := e.Resource
+	if resource != "" {
+		resource = strings.ToUpper(resource[:1]) + resource[1:]
+	}
+	name := resource + " " + e.Name
+
+	switch e.Action {
+	case extras.EventStarted, extras.EventUnpaused:
+		fmt.Println(hftx.InProgressSign(name + hftx.Green(" "+strings.ToUpper(string(e.Action)))))
+	case extras.EventStopped, extras.EventKilled, extras.EventRemoved:
+		fmt.Println(hftx.InProgressSign(name + hftx.Red(" "+strings.ToUpper(string(e.Action)))))
+	case extras.EventPaused:
+		fmt.Println(hftx.InProgressSign(name + hftx.Yellow(" PAUSED")))
+	case extras.EventBlacklisted:
+		fmt.Println(hftx.WarningSign(" " + name + " is blacklisted"))
+		fmt.Println(hftx.InfoSign("Force removal flag is present, continuing"))
+	case extras.EventSkipped:
+		if e.Name == "" {
+			fmt.Println(hftx.WarningSign(e.Message))
+		} else if e.Message == "blacklisted" {
+			fmt.Println(hftx.WarningSign(" " + name + " is blacklisted"))
+			fmt.Println(hftx.InfoSign("Removal flag is absent, skipping " + e.Resource))
+		} else {
+			fmt.Println(hftx.WarningSign(name + " skipped: " + e.Message))
+		}
+	default:
+		msg := name + " " + string(e.Action)
+		if e.Message != "" {
+			msg += ": " + e.Message
+		}
+		fmt.Println(hftx.GreenGoSign(msg))
+	}
+}
@@ -4,6 +4,7 @@
 package cmd
 
 import (
+	"dtools2/contexts"
 	"dtools2/extras"
 	"dtools2/rest"
 	"dtools2/system"
@@ -12,6 +13,7 @@ import (
 	"runtime"
 	"strings"
 
+	ce "github.com/jeanfrancoisgratton/customError/v3"
 	hftx "github.com/jeanfrancoisgratton/helperFunctions/v4/terminalfx"
 	"github.com/spf13/cobra"
 )
@@ -28,14 +30,10 @@ to the daemon's REST API (local Unix socket or remote TCP, with optional TLS).`,
 			return
 		}
 
-		cfg := rest.Config{
-			Host:               rest.ConnectURI,
-			APIVersion:         APIVersion,
-			UseTLS:             UseTLS,
-			CACertPath:         TLSCACert,
-			CertPath:           TLSCert,
-			KeyPath:            TLSKey,
-			InsecureSkipVerify: TLSSkipVerify,
+		cfg, errCode := resolveConfig(cmd)
+		if errCode != nil {
+			fmt.Println(errCode)
+			return
 		}
 
 		client, err := rest.NewClient(cfg)
@@ -43,9 +41,12 @@ to the daemon's REST API (local Unix socket or remote TCP, with optional TLS).`,
 			fmt.Println("Failed to initialize the REST client: ", err.Error())
 			return
 		}
+		if !extras.QuietOutput && DaemonHost != "" {
+			fmt.Println(fmt.Sprintf("%s: %s\n", hftx.InfoSign("Connected to"), hftx.Blue(DaemonHost)))
+		}
 
 		// If user did not force an API version, negotiate it with /version.
-		if APIVersion == "" {
+		if cfg.APIVersion == "" {
 			v, err := rest.NegotiateAPIVersion(cmd.Context(), client)
 			if err != nil {
 				fmt.Println("Failed to negotiate API version: ", err.Error())
@@ -55,6 +56,12 @@ to the daemon's REST API (local Unix socket or remote TCP, with optional TLS).`,
 			if extras.Debug {
 				fmt.Printf("Negotiated API version: v%s\n", v)
 			}
+		} else {
+			// Still find out which engine we talk to; a failure here is not fatal.
+			_, _ = rest.ServerVersion(cmd.Context(), client)
+		}
+		if extras.Debug {
+			fmt.Printf("Engine: %s\n", client.Engine())
 		}
 		restClient = client
 		return
@@ -85,14 +92,88 @@ var copyCmd = &cobra.Command{
 			return
 		}
 
-		rest.Context = cmd.Context()
-		if err := system.CopyFile(restClient, args[0], args[1]); err != nil {
+		if err := system.CopyFile(cmd.Context(), restClient, args[0], args[1]); err != nil {
 			fmt.Println(err)
+			return
+		}
+		if !extras.QuietOutput {
+			fmt.Println(hftx.EnabledSign("Copied : " + args[0] + " ==> " + args[1]))
 		}
 		return
 	},
 }
 
+// configFromFlags returns the connection parameters given on the command line.
+// --tlsverify and the certificate flags imply --tls; --tlsverify=false disables server verification.
+func configFromFlags(cmd *cobra.Command) rest.Config {
+	verifyChanged := cmd.Flags().Changed("tlsverify")
+
+	return rest.Config{
+		Host:               DaemonHost,
+		APIVersion:         APIVersion,
+		UseTLS:             UseTLS || verifyChanged || TLSCACert != "" || TLSCert != "" || TLSKey != "",
+		CACertPath:         TLSCACert,
+		CertPath:           TLSCert,
+		KeyPath:            TLSKey,
+		InsecureSkipVerify: verifyChanged && !TLSVerify,
+
+		ConnectTimeout:        RequestTimeout,
+		ResponseHeaderTimeout: RequestTimeout,
+		IdleTimeout:           RequestTimeout,
+	}
+}
+
+// resolveConfig builds the connection parameters, by increasing priority:
+// the current context or DOCKER_* envs (an explicitly requested context beats the envs), then the flags.
+// When -H is given, contexts are ignored altogether.
+// Missing TLS files are looked up in DOCKER_CERT_PATH (or ~/.docker) by rest.NewClient.
+func resolveConfig(cmd *cobra.Command) (rest.Config, *ce.CustomError) {
+	cfg := rest.ConfigFromEnv()
+
+	name, explicit := contexts.ActiveName(ContextName)
+	if name != contexts.DefaultContext && DaemonHost == "" && (explicit || cfg.Host == "") {
+		c, err := contexts.Get(name)
+		if err != nil {
+			return cfg, err
+		}
+		cfg = c.Config
+		if extras.Debug {
+			fmt.Printf("Using context %s (%s)\n", c.Name, c.Config.Host)
+		}
+	}
+
+	flags := configFromFlags(cmd)
+	if flags.Host != "" {
+		cfg.Host = flags.Host
+	}
+	if flags.APIVersion != "" {
+		cfg.APIVersion = flags.APIVersion
+	}
+	if flags.UseTLS {
+		cfg.UseTLS = true
+	}
+	if flags.CACertPath != "" {
+		cfg.CACertPath = flags.CACertPath
+	}
+	if flags.CertPath != "" {
+		cfg.CertPath = flags.CertPath
+	}
+	if flags.KeyPath != "" {
+		cfg.KeyPath = flags.KeyPath
+	}
+	if RequestTimeout > 0 {
+		cfg.ConnectTimeout = RequestTimeout
+		cfg.ResponseHeaderTimeout = RequestTimeout
+		cfg.IdleTimeout = RequestTimeout
+	}
+	if cmd.Flags().Changed("tlsverify") {
+		cfg.InsecureSkipVerify = flags.InsecureSkipVerify
+	}
+	cfg.RecordFile = RecordFile
+	cfg.ReplayFile = ReplayFile
+	return cfg, nil
+}
+
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
@@ -113,9 +194,17 @@ func init() {
 	// Global flags.
 	rootCmd.PersistentFlags().BoolVarP(&extras.Debug, "debug", "D", false, "Enable debug output on stderr")
 	rootCmd.PersistentFlags().BoolVar(&extras.OutputJSON, "json", false, "Output JSON instead of formatted tables")
-	rootCmd.PersistentFlags().BoolVarP(&rest.QuietOutput, "quiet", "q", false, "Quiet output")
-	rootCmd.PersistentFlags().StringVarP(&rest.ConnectURI, "host", "H", "", "Docker daemon host (e.g. unix:///var/run/docker.sock, tcp://host:2376)")
+	rootCmd.PersistentFlags().BoolVarP(&extras.QuietOutput, "quiet", "q", false, "Quiet output")
+	rootCmd.PersistentFlags().StringVarP(&DaemonHost, "host", "H", "", "Docker daemon host (e.g. unix:///var/run/docker.sock, tcp://host:2376, ssh://user@host)")
+	rootCmd.PersistentFlags().StringVar(&ContextName, "context", "", "Named context to use (overrides DTOOLS_CONTEXT and the current context)")
 	rootCmd.PersistentFlags().StringVarP(&APIVersion, "api-version", "A", "", "Docker API version (e.g. 1.43); if empty, auto-negotiate with the daemon")
+	rootCmd.PersistentFlags().DurationVar(&RequestTimeout, "timeout", 0, "How long to wait on a silent daemon (e.g. 90s); streams such as pull/push/logs -f are only cut after a long idle period")
 	rootCmd.PersistentFlags().BoolVarP(&UseTLS, "tls", "T", false, "Use TLS when connecting to the daemon (for tcp:// hosts)")
+	rootCmd.PersistentFlags().BoolVar(&TLSVerify, "tlsverify", true, "Verify the daemon certificate; implies --tls (--tlsverify=false to skip verification)")
+	rootCmd.PersistentFlags().StringVar(&TLSCACert, "tlscacert", "", "Trust certs signed only by this CA (default: $DOCKER_CERT_PATH/ca.pem)")
+	rootCmd.PersistentFlags().StringVar(&TLSCert, "tlscert", "", "Path to the TLS client certificate (default: $DOCKER_CERT_PATH/cert.pem)")
+	rootCmd.PersistentFlags().StringVar(&TLSKey, "tlskey", "", "Path to the TLS client key (default: $DOCKER_CERT_PATH/key.pem)")
+	rootCmd.PersistentFlags().StringVar(&RecordFile, "record", "", "Record every exchange with the daemon to this file (NDJSON, credentials redacted)")
+	rootCmd.PersistentFlags().StringVar(&ReplayFile, "replay", "", "Answer from a file made with --record instead of contacting a daemon")
 
 }
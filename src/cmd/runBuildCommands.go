@@ -7,9 +7,10 @@ package cmd
 
 import (
 	"dtools2/build"
-	"dtools2/rest"
+	"dtools2/extras"
 	"dtools2/run"
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 )
@@ -24,7 +25,6 @@ var runCmd = &cobra.Command{
 			fmt.Println("REST client not initialized")
 			return
 		}
-		rest.Context = cmd.Context()
 
 		image := args[0]
 		command := []string{}
@@ -32,13 +32,15 @@ var runCmd = &cobra.Command{
 			command = args[1:]
 		}
 
-		_, id, cerr := run.RunContainer(restClient, image, command)
+		opts := runOpts
+		opts.PullOutput = progressOutput()
+		_, id, cerr := run.RunContainer(cmd.Context(), restClient, image, command, opts)
 		if cerr != nil {
 			fmt.Println(cerr)
 			return
 		}
 
-		if run.RunDetach {
+		if opts.Detach {
 			if id != "" {
 				fmt.Println(id)
 			}
@@ -48,6 +50,29 @@ var runCmd = &cobra.Command{
 	},
 }
 
+var createCmd = &cobra.Command{
+	Use:     "create [flags] IMAGE [COMMAND] [ARG...]",
+	Short:   "Create a new container without starting it",
+	Long:    `Create a new container, with the same flags as run, and print its ID; start it later with dtools start.`,
+	Example: "dtools create --name web -p 8080:80 nginx:latest",
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if restClient == nil {
+			fmt.Println("REST client not initialized")
+			os.Exit(1)
+		}
+
+		opts := runOpts
+		opts.PullOutput = progressOutput()
+		id, cerr := run.CreateContainer(cmd.Context(), restClient, args[0], args[1:], opts)
+		if cerr != nil {
+			fmt.Println(cerr)
+			os.Exit(1)
+		}
+		fmt.Println(id)
+	},
+}
+
 var buildCmd = &cobra.Command{
 	Use:     "build [flags] PATH",
 	Short:   "Build an image from a Dockerfile",
@@ -58,9 +83,10 @@ var buildCmd = &cobra.Command{
 			fmt.Println("REST client not initialized")
 			return
 		}
-		rest.Context = cmd.Context()
 
-		if err := build.BuildImage(restClient, args[0]); err != nil {
+		opts := buildOpts
+		opts.Out = os.Stdout
+		if err := build.BuildImage(cmd.Context(), restClient, args[0], opts); err != nil {
 			fmt.Println(err)
 			return
 		}
@@ -68,30 +94,80 @@ var buildCmd = &cobra.Command{
 }
 
 func init() {
-	rootCmd.AddCommand(runCmd, buildCmd)
-
-	runCmd.Flags().BoolVarP(&run.RunDetach, "detach", "d", false, "Run container in background and print container ID")
-	runCmd.Flags().BoolVarP(&run.RunInteractive, "interactive", "i", false, "Keep STDIN open even if not attached")
-	runCmd.Flags().BoolVarP(&run.RunTTY, "tty", "t", false, "Allocate a pseudo-TTY")
-	runCmd.Flags().BoolVar(&run.RunRemove, "rm", false, "Automatically remove the container when it exits")
-	runCmd.Flags().StringVar(&run.RunName, "name", "", "Assign a name to the container")
-	runCmd.Flags().StringVarP(&run.RunUser, "user", "u", "", "Username or UID (format: <name|uid>[:<group|gid>])")
-	runCmd.Flags().StringVarP(&run.RunWorkdir, "workdir", "w", "", "Working directory inside the container")
-	runCmd.Flags().StringArrayVarP(&run.RunEnv, "env", "e", nil, "Set environment variables")
-	runCmd.Flags().StringArrayVarP(&run.RunPublish, "publish", "p", nil, "Publish a container's port(s) to the host")
-	runCmd.Flags().StringArrayVarP(&run.RunVolume, "volume", "v", nil, "Bind mount a volume")
-	runCmd.Flags().StringArrayVar(&run.RunMount, "mount", nil, "Attach a filesystem mount to the container (e.g. type=bind,src=/host,dst=/ctr,ro)")
-	runCmd.Flags().StringVar(&run.RunNetwork, "network", "", "Connect a container to a network")
-	runCmd.Flags().StringVar(&run.RunEntrypoint, "entrypoint", "", "Overwrite the default ENTRYPOINT of the image")
-	runCmd.Flags().StringVarP(&run.RunHostname, "hostname", "", "", "Container host name")
-	buildCmd.Flags().StringVarP(&build.Dockerfile, "file", "f", "Dockerfile", "Name of the Dockerfile (relative to PATH)")
-	buildCmd.Flags().StringArrayVarP(&build.Tags, "tag", "t", nil, "Name and optional tag in the 'name:tag' format")
-	buildCmd.Flags().StringArrayVar(&build.BuildArgs, "build-arg", nil, "Set build-time variables")
-	buildCmd.Flags().BoolVar(&build.NoCache, "no-cache", false, "Do not use cache when building the image")
-	buildCmd.Flags().BoolVar(&build.Pull, "pull", false, "Always attempt to pull a newer version of the base images")
-	buildCmd.Flags().BoolVar(&build.RemoveIntermediate, "rm", true, "Remove intermediate containers after a successful build")
-	buildCmd.Flags().BoolVar(&build.ForceRemoveIntermediate, "force-rm", false, "Always remove intermediate containers, even upon failure")
-	buildCmd.Flags().StringVar(&build.Target, "target", "", "Set the target build stage to build")
-	buildCmd.Flags().StringVar(&build.Platform, "platform", "", "Set platform if supported by the daemon")
-	buildCmd.Flags().StringVar(&build.Progress, "progress", "auto", "Set type of progress output (auto|plain|tty)")
+	rootCmd.AddCommand(runCmd, createCmd, buildCmd)
+
+	runFlags(runCmd)
+	runFlags(createCmd)
+
+	buildCmd.Flags().StringVarP(&buildOpts.Dockerfile, "file", "f", "Dockerfile", "Name of the Dockerfile (relative to PATH)")
+	buildCmd.Flags().StringArrayVarP(&buildOpts.Tags, "tag", "t", nil, "Name and optional tag in the 'name:tag' format")
+	buildCmd.Flags().StringArrayVar(&buildOpts.BuildArgs, "build-arg", nil, "Set build-time variables")
+	buildCmd.Flags().BoolVar(&buildOpts.NoCache, "no-cache", false, "Do not use cache when building the image")
+	buildCmd.Flags().BoolVar(&buildOpts.Pull, "pull", false, "Always attempt to pull a newer version of the base images")
+	buildCmd.Flags().BoolVar(&buildOpts.RemoveIntermediate, "rm", true, "Remove intermediate containers after a successful build")
+	buildCmd.Flags().BoolVar(&buildOpts.ForceRemoveIntermediate, "force-rm", false, "Always remove intermediate containers, even upon failure")
+	buildCmd.Flags().StringVar(&buildOpts.Target, "target", "", "Set the target build stage to build")
+	buildCmd.Flags().StringVar(&buildOpts.Platform, "platform", "", "Set platform if supported by the daemon")
+	buildCmd.Flags().StringVar(&buildOpts.Progress, "progress", "auto", "Set type of progress output (auto|plain|tty)")
+}
+
+// runFlags declares the container settings shared by run and create
+func runFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&runOpts.Detach, "detach", "d", false, "Run container in background and print container ID")
+	cmd.Flags().BoolVarP(&runOpts.Interactive, "interactive", "i", false, "Keep STDIN open even if not attached")
+	cmd.Flags().BoolVarP(&runOpts.TTY, "tty", "t", false, "Allocate a pseudo-TTY")
+	cmd.Flags().BoolVar(&runOpts.Remove, "rm", false, "Automatically remove the container when it exits")
+	cmd.Flags().StringVar(&runOpts.Name, "name", "", "Assign a name to the container")
+	cmd.Flags().StringVarP(&runOpts.User, "user", "u", "", "Username or UID (format: <name|uid>[:<group|gid>])")
+	cmd.Flags().StringVarP(&runOpts.Workdir, "workdir", "w", "", "Working directory inside the container")
+	cmd.Flags().StringArrayVarP(&runOpts.Env, "env", "e", nil, "Set environment variables (a bare VAR is taken from the current environment)")
+	cmd.Flags().StringArrayVar(&runOpts.EnvFile, "env-file", nil, "Read in a file of environment variables")
+	cmd.Flags().StringArrayVarP(&runOpts.Labels, "label", "l", nil, "Set meta data on a container (key=value)")
+	cmd.Flags().StringArrayVar(&runOpts.LabelFile, "label-file", nil, "Read in a line delimited file of labels")
+	cmd.Flags().StringVar(&runOpts.Pull, "pull", run.PullMissing, "Pull image before running: always, missing or never")
+	cmd.Flags().StringArrayVarP(&runOpts.Publish, "publish", "p", nil, "Publish a container's port(s) to the host")
+	cmd.Flags().StringArrayVarP(&runOpts.Volume, "volume", "v", nil, "Bind mount a volume")
+	cmd.Flags().StringArrayVar(&runOpts.Mount, "mount", nil, "Attach a filesystem mount to the container (e.g. type=bind,src=/host,dst=/ctr,ro)")
+	cmd.Flags().StringArrayVar(&runOpts.Network, "network", nil, "Connect a container to a network. Can be specified multiple times")
+	cmd.Flags().StringArrayVar(&runOpts.NetworkAlias, "network-alias", nil, "Add network-scoped aliases for the container")
+	cmd.Flags().StringVar(&runOpts.IP, "ip", "", "IPv4 address on the first network (e.g. 172.30.100.104)")
+	cmd.Flags().StringVar(&runOpts.IP6, "ip6", "", "IPv6 address on the first network (e.g. 2001:db8::33)")
+	cmd.Flags().StringVar(&runOpts.MacAddress, "mac-address", "", "Container MAC address on the first network (e.g. 92:d0:c6:0a:29:33)")
+	cmd.Flags().StringArrayVar(&runOpts.DNS, "dns", nil, "Set custom DNS servers")
+	cmd.Flags().StringArrayVar(&runOpts.DNSSearch, "dns-search", nil, "Set custom DNS search domains")
+	cmd.Flags().StringArrayVar(&runOpts.DNSOption, "dns-option", nil, "Set DNS options")
+	cmd.Flags().StringArrayVar(&runOpts.AddHost, "add-host", nil, "Add a custom host-to-IP mapping (host:ip)")
+	cmd.Flags().BoolVarP(&runOpts.PublishAll, "publish-all", "P", false, "Publish all exposed ports to random ports")
+	cmd.Flags().StringVar(&runOpts.Entrypoint, "entrypoint", "", "Overwrite the default ENTRYPOINT of the image")
+	cmd.Flags().StringVarP(&runOpts.Hostname, "hostname", "", "", "Container host name")
+	cmd.Flags().StringVar(&runOpts.Restart, "restart", "", "Restart policy: no, always, unless-stopped, on-failure[:max-retries]")
+	cmd.Flags().StringVar(&runOpts.HealthCmd, "health-cmd", "", "Command to run to check health; a JSON array ([\"pg_isready\",\"-U\",\"app\"]) runs it without a shell")
+	cmd.Flags().DurationVar(&runOpts.HealthInterval, "health-interval", 0, "Time between running the check (e.g. 30s)")
+	cmd.Flags().DurationVar(&runOpts.HealthTimeout, "health-timeout", 0, "Maximum time to allow one check to run")
+	cmd.Flags().IntVar(&runOpts.HealthRetries, "health-retries", 0, "Consecutive failures needed to report unhealthy")
+	cmd.Flags().DurationVar(&runOpts.HealthStartPeriod, "health-start-period", 0, "Start period for the container to initialize before the failures count")
+	cmd.Flags().BoolVar(&runOpts.NoHealthcheck, "no-healthcheck", false, "Disable any container-specified HEALTHCHECK")
+	cmd.Flags().StringArrayVar(&runOpts.CapAdd, "cap-add", nil, "Add Linux capabilities (e.g. NET_ADMIN)")
+	cmd.Flags().StringArrayVar(&runOpts.CapDrop, "cap-drop", nil, "Drop Linux capabilities (e.g. ALL)")
+	cmd.Flags().BoolVar(&runOpts.Privileged, "privileged", false, "Give extended privileges to this container")
+	cmd.Flags().BoolVar(&runOpts.ReadOnly, "read-only", false, "Mount the container's root filesystem as read only")
+	cmd.Flags().StringArrayVar(&runOpts.SecurityOpt, "security-opt", nil, "Security options (no-new-privileges, seccomp=PROFILE|unconfined, apparmor=PROFILE, label=...)")
+	cmd.Flags().StringArrayVar(&runOpts.Device, "device", nil, "Add a host device to the container (/dev/host[:/dev/ctr][:rwm])")
+	cmd.Flags().StringArrayVar(&runOpts.GroupAdd, "group-add", nil, "Add additional groups to join")
+	cmd.Flags().StringVar(&runOpts.Userns, "userns", "", "User namespace to use (host; podman: auto, keep-id, nomap, private, ns:PATH)")
+	cmd.Flags().StringArrayVar(&runOpts.Tmpfs, "tmpfs", nil, "Mount a tmpfs directory (/path[:options])")
+	cmd.Flags().StringArrayVar(&runOpts.Resources.Ulimits, "ulimit", nil, "Ulimit options (name=soft[:hard], e.g. nofile=1024:2048)")
+	resourceFlags(cmd, &runOpts.Resources)
+}
+
+// resourceFlags declares the resource limits shared by run, create and update
+func resourceFlags(cmd *cobra.Command, o *extras.ResourceOptions) {
+	cmd.Flags().StringVarP(&o.Memory, "memory", "m", "", "Memory limit (e.g. 512m, 2g)")
+	cmd.Flags().StringVar(&o.MemorySwap, "memory-swap", "", "Memory plus swap limit; -1 for unlimited swap")
+	cmd.Flags().StringVar(&o.MemoryReservation, "memory-reservation", "", "Memory soft limit")
+	cmd.Flags().Float64Var(&o.CPUs, "cpus", 0, "Number of CPUs (e.g. 1.5)")
+	cmd.Flags().Int64VarP(&o.CPUShares, "cpu-shares", "c", 0, "CPU shares (relative weight)")
+	cmd.Flags().StringVar(&o.CpusetCpus, "cpuset-cpus", "", "CPUs in which to allow execution (e.g. 0-3, 0,1)")
+	cmd.Flags().Int64Var(&o.PidsLimit, "pids-limit", 0, "Tune container pids limit; -1 for unlimited")
+	cmd.Flags().Uint16Var(&o.BlkioWeight, "blkio-weight", 0, "Block IO relative weight, between 10 and 1000")
 }
@@ -0,0 +1,90 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 08:40
+// Original filename: src/cmd/runBuildCommands_test.go
+
+package cmd
+
+import (
+	"context"
+	"dtools2/extras"
+	"dtools2/rest/fakedaemon"
+	"dtools2/run"
+	"slices"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+// runArgs runs `dtools run ARGS...` against restClient, from fresh flags
+func runArgs(t *testing.T, args []string) {
+	t.Helper()
+	runCmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			_ = sv.Replace(nil)
+		} else {
+			_ = f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	})
+	rootCmd.SetArgs(append([]string{"run"}, args...))
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestRunLikeRoundTrip parses what runlike writes with the flags of `dtools run`, and checks that the copy
+// gives the same runlike back
+func TestRunLikeRoundTrip(t *testing.T) {
+	d, client := fakedaemon.StartT(t)
+	d.AddImage(fakedaemon.Image{RepoTags: []string{"alpine:3.20"}, Cmd: []string{"/bin/sh"}})
+	restClient, extras.QuietOutput = client, true
+	t.Cleanup(func() { restClient, extras.QuietOutput = nil, false })
+
+	runArgs(t, []string{"-d", "--name", "orig", "--entrypoint", "/bin/sh", "-e", "A=1 2", "-l", "tier=web",
+		"-p", "[::1]:8443:443", "-p", "127.0.0.1:8080:80/udp", "-p", "9000",
+		"-m", "512m", "--memory-reservation", "1536k", "--restart", "on-failure:3",
+		"--health-cmd", `["pg_isready","-U","x y"]`, "--health-interval", "10s",
+		"alpine:3.20", "--", "-c", "echo hi"})
+
+	spec, _, cerr := run.RunLikeSpec(context.Background(), client, "orig")
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	args := spec.Args()
+	for _, want := range [][]string{
+		{"--health-cmd", `["pg_isready","-U","x y"]`},
+		{"--publish", "[::1]:8443:443"},
+		{"--publish", "127.0.0.1:8080:80/udp"},
+		{"--memory", "512m"},
+		{"--memory-reservation", "1536k"},
+		{"--", "alpine:3.20", "-c", "echo hi"},
+	} {
+		if !containsRun(args, want) {
+			t.Errorf("runlike %q lacks %q", args, want)
+		}
+	}
+
+	// The same arguments, under another name
+	i := slices.Index(args, "orig")
+	copyArgs := slices.Concat(args[:i], []string{"copy"}, args[i+1:])
+	runArgs(t, copyArgs)
+
+	again, _, cerr := run.RunLikeSpec(context.Background(), client, "copy")
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	if got := again.Args(); !slices.Equal(got, copyArgs) {
+		t.Errorf("round trip:\n got %q\nwant %q", got, copyArgs)
+	}
+}
+
+// containsRun tells whether want appears in args, in a row
+func containsRun(args, want []string) bool {
+	for i := range args {
+		if len(args)-i >= len(want) && slices.Equal(args[i:i+len(want)], want) {
+			return true
+		}
+	}
+	return false
+}
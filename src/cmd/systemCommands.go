@@ -6,10 +6,12 @@
 package cmd
 
 import (
-	"dtools2/rest"
+	"dtools2/extras"
 	"dtools2/system"
 	"fmt"
 
+	hfjson "github.com/jeanfrancoisgratton/helperFunctions/v4/prettyjson"
+	hftx "github.com/jeanfrancoisgratton/helperFunctions/v4/terminalfx"
 	"github.com/spf13/cobra"
 )
 
@@ -30,8 +32,9 @@ var systemRmCmd = &cobra.Command{
 			fmt.Println("REST client not initialized")
 			return
 		}
-		rest.Context = cmd.Context()
-		if errCode := system.RmContainers(restClient); errCode != nil {
+		opts := systemRmOpts
+		opts.OnEvent = printEvent
+		if _, errCode := system.RmContainers(cmd.Context(), restClient, opts); errCode != nil {
 			fmt.Println(errCode)
 		}
 		return
@@ -47,9 +50,15 @@ var systemCleanCmd = &cobra.Command{
 			fmt.Println("REST client not initialized")
 			return
 		}
-		rest.Context = cmd.Context()
-		if errCode := system.Clean(restClient); errCode != nil {
+		report, errCode := system.Clean(cmd.Context(), restClient, cleanOpts)
+		if errCode != nil {
 			fmt.Println(errCode)
+			return
+		}
+		if !extras.QuietOutput {
+			fmt.Println(hftx.EnabledSign(fmt.Sprintf("Removed %d image(s)", len(report.Images))))
+			fmt.Println(hftx.EnabledSign("Volumes pruned"))
+			fmt.Println(hftx.EnabledSign(fmt.Sprintf("Removed %d network(s)", len(report.Networks))))
 		}
 		return
 	},
@@ -63,22 +72,52 @@ var sysInfoCmd = &cobra.Command{
 			fmt.Println("REST client not initialized")
 			return
 		}
-		rest.Context = cmd.Context()
-		if errCode := system.Info(restClient); errCode != nil {
+		info, raw, errCode := system.Info(cmd.Context(), restClient)
+		if errCode != nil {
 			fmt.Println(errCode)
+			return
+		}
+		if info == nil {
+			// The payload did not decode into InfoResponse: show it as is
+			hfjson.Print(raw)
+			return
+		}
+		printInfo(*info, restClient.Engine())
+		return
+	},
+}
+
+var inspectCmd = &cobra.Command{
+	Use:     "inspect [--type TYPE] NAME [NAME...]",
+	Example: "dtools inspect web\ndtools inspect --type image nginx:latest\ndtools inspect web --format State.Status,NetworkSettings.Networks.*.IPAddress",
+	Short:   "Show the full inspect document of containers, images, volumes or networks",
+	Long: `Show the full inspect document of containers, images, volumes or networks, as JSON.
+Without --type, each name is looked up as a container, then an image, a volume and a network.
+--format takes dotted paths into the document; "*" walks every element of a list or map.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if restClient == nil {
+			fmt.Println("REST client not initialized")
+			return
+		}
+
+		if err := renderInspect(cmd.Context(), inspectType, args); err != nil {
+			fmt.Println(err)
 		}
 		return
 	},
 }
 
 func init() {
-	rootCmd.AddCommand(sysCmd, systemRmCmd, systemCleanCmd)
+	rootCmd.AddCommand(sysCmd, systemRmCmd, systemCleanCmd, inspectCmd)
 	sysCmd.AddCommand(systemRmCmd, systemCleanCmd, sysInfoCmd)
 
-	systemRmCmd.Flags().BoolVarP(&system.ForceRemove, "force", "f", false, "force removal of container")
-	systemRmCmd.Flags().BoolVarP(&system.RemoveUnamedVolumes, "remove-vols", "r", true, "remove non-named volume")
-	systemRmCmd.Flags().BoolVarP(&system.RemoveBlacklisted, "blacklist", "B", false, "remove container even if blacklisted")
-	systemCleanCmd.Flags().BoolVarP(&system.RemoveBlacklisted, "blacklist", "B", false, "remove container even if blacklisted")
-	systemCleanCmd.Flags().BoolVarP(&system.ForceRemove, "force", "f", false, "force removal of container")
-
+	systemRmCmd.Flags().BoolVarP(&systemRmOpts.Force, "force", "f", false, "force removal of container")
+	systemRmCmd.Flags().BoolVarP(&systemRmOpts.RemoveVolumes, "remove-vols", "r", true, "remove non-named volume")
+	systemRmCmd.Flags().BoolVarP(&systemRmOpts.IgnoreBlacklist, "blacklist", "B", false, "remove container even if blacklisted")
+	systemCleanCmd.Flags().BoolVarP(&cleanOpts.IgnoreBlacklist, "blacklist", "B", false, "remove container even if blacklisted")
+	systemCleanCmd.Flags().BoolVarP(&cleanOpts.Force, "force", "f", false, "force removal of container")
+	inspectCmd.Flags().StringVar(&inspectType, "type", "", "Only look for a container, image, volume or network")
+	inspectCmd.Flags().StringVarP(&extras.OutputFile, "file", "F", "", "Write JSON output to a file")
+	inspectCmd.Flags().StringVar(&extras.OutputFormat, "format", "", "Output only the values at the given path (or comma-separated paths); \"*\" walks every element of a list or map")
 }
@@ -5,7 +5,20 @@
 
 package cmd
 
-import "dtools2/rest"
+import (
+	"dtools2/build"
+	"dtools2/compose"
+	"dtools2/containers"
+	"dtools2/extras"
+	"dtools2/images"
+	"dtools2/networks"
+	"dtools2/rest"
+	"dtools2/run"
+	"dtools2/system"
+	"dtools2/systemd"
+	"dtools2/volumes"
+	"time"
+)
 
 // Global flags used for option parsing by COBRA
 var OutputJSON bool
@@ -14,7 +27,12 @@ var UseTLS bool
 var TLSCACert string
 var TLSCert string
 var TLSKey string
-var TLSSkipVerify bool
+var TLSVerify bool
+var ContextName string
+var RequestTimeout time.Duration
+var DaemonHost string
+var RecordFile string
+var ReplayFile string
 
 // Resolved REST client, shared by subcommands.
 var restClient *rest.Client
@@ -26,9 +44,72 @@ var loginPassword string
 var loginInsecure bool
 var loginCACertPath string
 
+// --filter key=value, shared by lsc, lsi, lsv and lsn; parsed into the ListOptions of each command.
+var listFilters []string
+
+// Container-related flags.
+
+var containerListOpts containers.ListOptions
+var containerListExtended bool
+var containerStartOpts containers.StartOptions
+var containerStopOpts containers.StopOptions
+var containerRemoveOpts containers.RemoveOptions
+var containerUpdateOpts containers.UpdateOptions
+var statsNoStream bool
+var containerSelector containers.Selector // --name-glob, --image and --state
+var containerSelectors []string           // --selector key=value
+var containerDryRun bool
+var waitCondition string
+
 // Image-related flags.
 
 var imagePullRegistry string
+var imageListOpts images.ListOptions
+var imageRemoveOpts images.RemoveOptions
+
+// Volume-related flags.
+
+var volumeListOpts volumes.ListOptions
+var volumeRemoveOpts volumes.RemoveOptions
+var volumePruneOpts volumes.PruneOptions
+var volumeCreateDriver string
+
+// Network-related flags.
+
+var networkListOpts networks.ListOptions
+var networkCreateReq networks.NetworkCreateRequest
+var networkRemoveOpts networks.RemoveOptions
+var networkDetachForce bool
+
+// run, build, exec and logs flags.
+
+var runOpts run.Options
+var buildOpts build.Options
+var execOpts extras.ExecOptions
+var logOpts extras.LogOptions
+
+// compose flags.
+
+var composeFile string
+var composeLoadOpts compose.LoadOptions
+var composeUpOpts compose.UpOptions
+var composeUpDetach bool
+var composeDownOpts compose.DownOptions
+var composeLogsOpts compose.LogsOptions
+var composeExportOpts compose.ExportOptions
+var composeExportOutput string
+
+// container generate flags.
+
+var generateSystemdOpts systemd.GenerateOptions
+var generateSystemdInstall bool
+
+// System-related flags.
+
+var systemRmOpts containers.RemoveOptions
+var cleanOpts system.CleanOptions
+var catalogOutputFile string
+var inspectType string
 
 // docker commit-like flags.
 
@@ -8,10 +8,12 @@ package cmd
 
 import (
 	"dtools2/extras"
-	"dtools2/rest"
+	"dtools2/system"
 	"dtools2/volumes"
 	"fmt"
 
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+	hftx "github.com/jeanfrancoisgratton/helperFunctions/v4/terminalfx"
 	"github.com/spf13/cobra"
 )
 
@@ -31,8 +33,17 @@ var volumeListCmd = &cobra.Command{
 			return
 		}
 
-		rest.Context = cmd.Context()
-		if _, err := volumes.ListVolumes(restClient, true); err != nil {
+		opts := volumeListOpts
+		var err *ce.CustomError
+		if opts.Filters, err = extras.ParseFilters(listFilters); err != nil {
+			fmt.Println(err)
+			return
+		}
+		vols, err := volumes.ListVolumes(cmd.Context(), restClient, opts)
+		if err == nil {
+			err = renderVolumeList(vols)
+		}
+		if err != nil {
 			fmt.Println(err)
 		}
 		return
@@ -52,8 +63,9 @@ var volumeRmCmd = &cobra.Command{
 			return
 		}
 
-		rest.Context = cmd.Context()
-		if err := volumes.RemoveVolumes(restClient, args); err != nil {
+		opts := volumeRemoveOpts
+		opts.OnEvent = printEvent
+		if _, err := volumes.RemoveVolumes(cmd.Context(), restClient, args, opts); err != nil {
 			fmt.Println(err)
 		}
 		return
@@ -72,9 +84,12 @@ var volumePruneCmd = &cobra.Command{
 			return
 		}
 
-		rest.Context = cmd.Context()
-		if err := volumes.PruneVolumes(restClient); err != nil {
+		if _, err := volumes.PruneVolumes(cmd.Context(), restClient, volumePruneOpts); err != nil {
 			fmt.Println(err)
+			return
+		}
+		if !extras.QuietOutput {
+			fmt.Println(hftx.EnabledSign("Volumes pruned"))
 		}
 		return
 	},
@@ -91,8 +106,30 @@ var volumeCreateCmd = &cobra.Command{
 			return
 		}
 
-		rest.Context = cmd.Context()
-		if err := volumes.CreateVolume(restClient, args[0]); err != nil {
+		vco := volumes.VolumeCreateOptions{Name: args[0], Driver: volumeCreateDriver}
+		if err := volumes.CreateVolume(cmd.Context(), restClient, vco); err != nil {
+			fmt.Println(err)
+			return
+		}
+		if !extras.QuietOutput {
+			fmt.Println(hftx.GreenGoSign("Volume " + hftx.Green(vco.Name) + " created"))
+		}
+		return
+	},
+}
+
+var volumeInspectCmd = &cobra.Command{
+	Use:     "inspect VOLUME [VOLUME...]",
+	Example: "dtools volume inspect data --format Mountpoint",
+	Short:   "Show the full inspect document of volumes",
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if restClient == nil {
+			fmt.Println("REST client not initialized")
+			return
+		}
+
+		if err := renderInspect(cmd.Context(), system.InspectVolume, args); err != nil {
 			fmt.Println(err)
 		}
 		return
@@ -101,13 +138,17 @@ var volumeCreateCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(volumeCmd, volumeListCmd, volumeRmCmd)
-	volumeCmd.AddCommand(volumeListCmd, volumeRmCmd, volumePruneCmd, volumeCreateCmd)
+	volumeCmd.AddCommand(volumeListCmd, volumeRmCmd, volumePruneCmd, volumeCreateCmd, volumeInspectCmd)
 
-	volumePruneCmd.Flags().BoolVarP(&volumes.RemoveBlackListed, "blacklist", "B", false, "remove volume even if blacklisted")
-	volumePruneCmd.Flags().BoolVarP(&volumes.RemoveNamedVolumes, "all", "a", true, "remove anonymous AND non-anonymous volumes")
-	volumeRmCmd.Flags().BoolVarP(&volumes.RemoveBlackListed, "blacklist", "B", false, "remove volume even if blacklisted")
-	volumeRmCmd.Flags().BoolVarP(&volumes.ForceRemove, "force", "f", false, "force-remove volume")
-	volumeCreateCmd.Flags().StringVarP(&volumes.CreateVolDriver, "driver", "d", "local", "volume driver")
+	volumePruneCmd.Flags().BoolVarP(&volumePruneOpts.IgnoreBlacklist, "blacklist", "B", false, "remove volume even if blacklisted")
+	volumePruneCmd.Flags().BoolVarP(&volumePruneOpts.All, "all", "a", true, "remove anonymous AND non-anonymous volumes")
+	volumePruneCmd.Flags().BoolVarP(&volumePruneOpts.Force, "force", "f", false, "force-remove volumes")
+	volumeRmCmd.Flags().BoolVarP(&volumeRemoveOpts.IgnoreBlacklist, "blacklist", "B", false, "remove volume even if blacklisted")
+	volumeRmCmd.Flags().BoolVarP(&volumeRemoveOpts.Force, "force", "f", false, "force-remove volume")
+	volumeCreateCmd.Flags().StringVarP(&volumeCreateDriver, "driver", "d", "local", "volume driver")
 	volumeListCmd.Flags().StringVarP(&extras.OutputFile, "file", "F", "", "Write JSON output to a file")
 	volumeListCmd.Flags().StringVar(&extras.OutputFormat, "format", "", "Output only the values for the given field (or comma-separated fields) as plaintext")
+	volumeListCmd.Flags().StringArrayVar(&listFilters, "filter", nil, "Filter the output (key=value, e.g. dangling=true, driver=local, label=backup). Can be specified multiple times")
+	volumeInspectCmd.Flags().StringVarP(&extras.OutputFile, "file", "F", "", "Write JSON output to a file")
+	volumeInspectCmd.Flags().StringVar(&extras.OutputFormat, "format", "", "Output only the values at the given path (or comma-separated paths); \"*\" walks every element of a list or map")
 }
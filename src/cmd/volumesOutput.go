@@ -0,0 +1,73 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 17:15
+// Original filename: src/cmd/volumesOutput.go
+
+package cmd
+
+import (
+	"dtools2/volumes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// renderVolumeList renders the output of `dtools lsv`
+func renderVolumeList(vols []volumes.Volume) *ce.CustomError {
+	if done, cerr := renderPayload(vols); done || cerr != nil {
+		return cerr
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Name", "Driver", "Scope", "Created", "RefCount", "Used by"})
+
+	if len(vols) == 0 {
+		t.AppendRow(table.Row{"", "", "", "", "", ""})
+	} else {
+		for _, v := range vols {
+			t.AppendRow(table.Row{v.Name, v.Driver, v.Scope, formatCreated(v.CreatedAt), v.RefCount, v.UsedByStr})
+		}
+	}
+
+	t.SortBy([]table.SortBy{{Name: "Name", Mode: table.Asc}})
+	t.SetStyle(table.StyleBold)
+	t.Style().Format.Header = text.FormatDefault
+
+	// Paint rows where the volume is used by at least one container.
+	t.SetRowPainter(func(row table.Row) text.Colors {
+		// Used by column is index 5
+		if s, ok := row[5].(string); ok && s != "" {
+			return text.Colors{text.FgHiGreen}
+		}
+		return nil
+	})
+
+	t.Render()
+	fmt.Println()
+	return nil
+}
+
+func formatCreated(created string) string {
+	created = strings.TrimSpace(created)
+	if created == "" {
+		return ""
+	}
+
+	// Docker/Podman commonly return RFC3339 timestamps; sometimes with fractional seconds.
+	tm, err := time.Parse(time.RFC3339Nano, created)
+	if err != nil {
+		tm, err = time.Parse(time.RFC3339, created)
+		if err != nil {
+			// If parsing fails, keep original to avoid losing information.
+			return created
+		}
+	}
+
+	return tm.Format("2006.01.02 15:04:05")
+}
@@ -0,0 +1,94 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 02:30
+// Original filename: src/compose/down.go
+
+package compose
+
+import (
+	"context"
+	"dtools2/containers"
+	"dtools2/extras"
+	"dtools2/networks"
+	"dtools2/rest"
+	"dtools2/volumes"
+	"slices"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+)
+
+// Down stops and removes the containers of the project, then its networks; with opts.Volumes, its volumes too.
+// Only the project labels are used, so a project whose compose file is gone can still be taken down.
+// External networks and volumes are never removed.
+func Down(ctx context.Context, client *rest.Client, project string, opts DownOptions) *ce.CustomError {
+	cs, cerr := projectContainers(ctx, client, project, nil)
+	if cerr != nil {
+		return cerr
+	}
+
+	var running, all []string
+	for _, c := range cs {
+		name := c.Names[0][1:]
+		all = append(all, name)
+		if c.State == "running" || c.State == "paused" {
+			running = append(running, name)
+		}
+	}
+	if len(running) > 0 {
+		if cerr := containers.StopContainers(ctx, client, running, containers.StopOptions{Timeout: opts.Timeout, OnEvent: opts.OnEvent}); cerr != nil {
+			return cerr
+		}
+	}
+	if len(all) > 0 {
+		rmOpts := containers.RemoveOptions{Force: true, RemoveVolumes: opts.Volumes, OnEvent: opts.OnEvent}
+		if _, cerr := containers.RemoveContainer(ctx, client, all, rmOpts); cerr != nil {
+			return cerr
+		}
+	}
+
+	byProject := extras.Filters{"label": {LabelProject + "=" + project}}
+	nets, cerr := networks.NetworkList(ctx, client, networks.ListOptions{Filters: byProject})
+	if cerr != nil {
+		return cerr
+	}
+	var netNames []string
+	for _, n := range nets {
+		if n.Labels[LabelProject] == project && !slices.Contains(netNames, n.Name) {
+			netNames = append(netNames, n.Name)
+		}
+	}
+	if len(netNames) > 0 {
+		if _, cerr := networks.RemoveNetwork(ctx, client, netNames, networks.RemoveOptions{OnEvent: opts.OnEvent}); cerr != nil {
+			return cerr
+		}
+	}
+
+	var volNames []string
+	if opts.Volumes {
+		if volNames, cerr = removeProjectVolumes(ctx, client, project, byProject, opts.OnEvent); cerr != nil {
+			return cerr
+		}
+	}
+	if len(all) == 0 && len(netNames) == 0 && len(volNames) == 0 {
+		opts.OnEvent.Emit(extras.Event{Action: extras.EventSkipped, Message: "Nothing to remove for project " + project})
+	}
+	return nil
+}
+
+// removeProjectVolumes removes the volumes labelled with the project, and returns their names
+func removeProjectVolumes(ctx context.Context, client *rest.Client, project string, byProject extras.Filters, onEvent extras.EventFunc) ([]string, *ce.CustomError) {
+	vols, cerr := volumes.ListVolumes(ctx, client, volumes.ListOptions{Filters: byProject})
+	if cerr != nil {
+		return nil, cerr
+	}
+	var names []string
+	for _, v := range vols {
+		if v.Labels[LabelProject] == project {
+			names = append(names, v.Name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+	return volumes.RemoveVolumes(ctx, client, names, volumes.RemoveOptions{OnEvent: onEvent})
+}
@@ -0,0 +1,445 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 03:40
+// Original filename: src/compose/export.go
+
+package compose
+
+import (
+	"bytes"
+	"context"
+	"dtools2/containers"
+	"dtools2/networks"
+	"dtools2/rest"
+	"dtools2/run"
+	"dtools2/volumes"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// Export inspects containers and returns the compose file that recreates them, with the warnings about what
+// could not be carried over. The networks and named volumes they use are declared once, under their current
+// names, and what a container inherits from its image (env, labels, command, user...) is left out.
+func Export(ctx context.Context, client *rest.Client, opts ExportOptions) ([]byte, []string, *ce.CustomError) {
+	names := opts.Containers
+	if opts.All {
+		cs, cerr := containers.ListContainers(ctx, client, containers.ListOptions{})
+		if cerr != nil {
+			return nil, nil, cerr
+		}
+		names = nil
+		for _, c := range cs {
+			if len(c.Names) > 0 {
+				names = append(names, strings.TrimPrefix(c.Names[0], "/"))
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil, &ce.CustomError{Title: "No containers", Message: "nothing to export"}
+	}
+
+	e := &exporter{
+		ctx:      ctx,
+		client:   client,
+		file:     composeFile{Name: opts.ProjectName, Services: map[string]*Service{}, Networks: map[string]*Network{}, Volumes: map[string]*Volume{}},
+		netKeys:  map[string]string{},
+		volKeys:  map[string]string{},
+		services: map[string]string{},
+		composed: map[string]string{},
+		static:   map[string]bool{},
+	}
+	var inspected []run.ContainerInspect
+	for _, name := range names {
+		var ci run.ContainerInspect
+		if cerr := getJSON(ctx, client, "/containers/"+url.PathEscape(name)+"/json", &ci); cerr != nil {
+			return nil, nil, cerr
+		}
+		inspected = append(inspected, ci)
+		e.services[strings.TrimPrefix(ci.Name, "/")] = e.serviceKey(ci)
+		if project, service := ci.Config.Labels[LabelProject], ci.Config.Labels[LabelService]; project != "" && service != "" {
+			e.composed[project+"/"+service] = strings.TrimPrefix(ci.Name, "/")
+		}
+	}
+	for _, ci := range inspected {
+		if cerr := e.addService(ci); cerr != nil {
+			return nil, nil, cerr
+		}
+	}
+	for key, n := range e.file.Networks {
+		if !e.static[key] {
+			n.IPAM = nil
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(e.file); err != nil {
+		return nil, nil, &ce.CustomError{Title: "Unable to marshal the compose file", Message: err.Error()}
+	}
+	enc.Close()
+	return buf.Bytes(), e.warnings, nil
+}
+
+type exporter struct {
+	ctx      context.Context
+	client   *rest.Client
+	file     composeFile
+	warnings []string
+	netKeys  map[string]string // daemon network name => key in the file
+	volKeys  map[string]string // daemon volume name => key in the file
+	services map[string]string // container name => service key
+	composed map[string]string // "project/service" of the compose labels => container name
+	static   map[string]bool   // the networks where a container has a static address
+	taken    []string          // the service keys already given out
+}
+
+// serviceKey is the compose service of the container when it has one, else its name; clashes get a suffix
+func (e *exporter) serviceKey(ci run.ContainerInspect) string {
+	name := strings.TrimPrefix(ci.Name, "/")
+	key := ci.Config.Labels[LabelService]
+	if key == "" || slices.Contains(e.taken, key) {
+		key = name
+	}
+	for i := 2; slices.Contains(e.taken, key); i++ {
+		key = name + "-" + strconv.Itoa(i)
+	}
+	e.taken = append(e.taken, key)
+	return key
+}
+
+func (e *exporter) warn(ci run.ContainerInspect, msg string) {
+	e.warnings = append(e.warnings, "container "+strings.TrimPrefix(ci.Name, "/")+": "+msg)
+}
+
+func (e *exporter) addService(ci run.ContainerInspect) *ce.CustomError {
+	cfg, hc := ci.Config, ci.HostConfig
+	name := strings.TrimPrefix(ci.Name, "/")
+	ic := run.ImageDefaults(e.ctx, e.client, cfg.Image)
+	own := run.ContainerOverrides(ci, ic)
+
+	svc := &Service{
+		Image:         cfg.Image,
+		ContainerName: name,
+		Hostname:      own.Hostname,
+		User:          own.User,
+		WorkingDir:    own.WorkingDir,
+		Entrypoint:    own.Entrypoint,
+		Command:       own.Command,
+		Environment:   own.Env,
+		Restart:       own.Restart,
+		SecurityOpt:   own.SecurityOpt,
+		Tty:           cfg.Tty,
+		StdinOpen:     cfg.OpenStdin,
+		DNS:           hc.DNS,
+		DNSSearch:     hc.DNSSearch,
+		DNSOpt:        hc.DNSOptions,
+		ExtraHosts:    hc.ExtraHosts,
+		CapAdd:        hc.CapAdd,
+		CapDrop:       hc.CapDrop,
+		Privileged:    hc.Privileged,
+		ReadOnly:      hc.ReadonlyRootfs,
+		GroupAdd:      hc.GroupAdd,
+		UsernsMode:    hc.UsernsMode,
+	}
+	// The compose labels are set again by `compose up`
+	for _, label := range own.Labels {
+		if !strings.HasPrefix(label, "com.docker.compose.") {
+			svc.Labels = append(svc.Labels, label)
+		}
+	}
+	for _, port := range own.Ports {
+		svc.Ports = append(svc.Ports, portSpec(port))
+	}
+	if hc.PublishAllPorts {
+		e.warn(ci, "--publish-all has no compose equivalent; list the ports instead")
+	}
+	if hc.AutoRemove {
+		e.warn(ci, "--rm has no compose equivalent")
+	}
+
+	if cerr := e.exportMounts(ci, ic, svc); cerr != nil {
+		return cerr
+	}
+	if cerr := e.exportNetworks(ci, svc); cerr != nil {
+		return cerr
+	}
+
+	exportResources(ci, svc)
+	if own.Seccomp {
+		e.warn(ci, "an inline seccomp profile cannot be exported")
+	}
+	for _, d := range hc.Devices {
+		svc.Devices = append(svc.Devices, d.PathOnHost+":"+d.PathInContainer+":"+d.CgroupPermissions)
+	}
+	if hc.BlkioWeight > 0 {
+		e.warn(ci, "--blkio-weight is not exported")
+	}
+
+	if own.Healthcheck != nil {
+		svc.Healthcheck = exportHealthcheck(own.Healthcheck)
+	}
+	e.exportDependsOn(ci, svc)
+
+	e.file.Services[e.services[name]] = svc
+	return nil
+}
+
+// exportDependsOn rebuilds depends_on from the compose depends_on label and the dtools.depends-on one, which
+// `dtools start` and friends also read; only the dependencies on exported containers can be kept
+func (e *exporter) exportDependsOn(ci run.ContainerInspect, svc *Service) {
+	labels := ci.Config.Labels
+	deps := map[string]string{} // container name => condition
+	for _, entry := range strings.Split(labels[containers.LabelComposeDependsOn], ",") {
+		service, rest, _ := strings.Cut(strings.TrimSpace(entry), ":")
+		if service == "" {
+			continue
+		}
+		condition, _, _ := strings.Cut(rest, ":")
+		if condition != ConditionHealthy && condition != ConditionCompleted {
+			condition = ConditionStarted
+		}
+		name, ok := e.composed[labels[LabelProject]+"/"+service]
+		if !ok {
+			e.warn(ci, "depends on service "+service+", which is not exported")
+			continue
+		}
+		deps[name] = condition
+	}
+	for _, name := range strings.Split(labels[containers.LabelDependsOn], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			if _, ok := deps[name]; !ok {
+				deps[name] = ConditionStarted
+			}
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(deps)) {
+		key, ok := e.services[name]
+		if !ok {
+			e.warn(ci, "depends on container "+name+", which is not exported")
+			continue
+		}
+		if svc.DependsOn == nil {
+			svc.DependsOn = dependsOn{}
+		}
+		svc.DependsOn[key] = deps[name]
+	}
+}
+
+// exportMounts turns the mounts into volumes and tmpfs; a named volume is declared once at the top level
+func (e *exporter) exportMounts(ci run.ContainerInspect, ic run.ImageConfig, svc *Service) *ce.CustomError {
+	for _, dst := range slices.Sorted(maps.Keys(ci.HostConfig.Tmpfs)) {
+		if opts := ci.HostConfig.Tmpfs[dst]; opts != "" {
+			dst += ":" + opts
+		}
+		svc.Tmpfs = append(svc.Tmpfs, dst)
+	}
+
+	for _, m := range ci.Mounts {
+		_, anonymous := ci.Config.Volumes[m.Destination]
+		v := serviceVolume{Type: m.Type, Target: m.Destination, ReadOnly: !m.RW}
+		switch {
+		case m.Type == "bind":
+			v.Source = m.Source
+		case m.Type == "volume" && anonymous:
+			// Those of the image come back by themselves
+			if _, fromImage := ic.Volumes[m.Destination]; fromImage {
+				continue
+			}
+			v.ReadOnly = false
+		case m.Type == "volume" && m.Name != "":
+			key, cerr := e.volumeKey(m.Name)
+			if cerr != nil {
+				return cerr
+			}
+			v.Source = key
+		case m.Type == "tmpfs":
+			if _, ok := ci.HostConfig.Tmpfs[m.Destination]; ok {
+				continue
+			}
+			v.ReadOnly = false
+		default:
+			e.warn(ci, "mount "+m.Destination+" of type "+m.Type+" is not exported")
+			continue
+		}
+		svc.Volumes = append(svc.Volumes, v)
+	}
+	return nil
+}
+
+// exportNetworks attaches the service to its networks; network_mode is used for host, none, container: and
+// for the default bridge, as a service without networks lands on the project network instead
+func (e *exporter) exportNetworks(ci run.ContainerInspect, svc *Service) *ce.CustomError {
+	name := strings.TrimPrefix(ci.Name, "/")
+	shortID := ci.ID[:min(len(ci.ID), 12)]
+	primary := ci.HostConfig.NetworkMode
+	eps := ci.NetworkSettings.Networks
+
+	switch {
+	case primary == "host" || primary == "none":
+		svc.NetworkMode = primary
+		return nil
+	case strings.HasPrefix(primary, "container:"):
+		svc.NetworkMode = primary
+		if key, ok := e.services[strings.TrimPrefix(primary, "container:")]; ok {
+			svc.DependsOn = dependsOn{key: ConditionStarted}
+		}
+		return nil
+	case primary == "" || primary == "default":
+		primary = "bridge"
+	}
+
+	others := slices.DeleteFunc(slices.Sorted(maps.Keys(eps)), func(n string) bool { return n == "bridge" })
+	if _, onBridge := eps["bridge"]; (onBridge || primary == "bridge") && len(others) == 0 {
+		svc.NetworkMode = "bridge"
+		return nil
+	} else if onBridge {
+		e.warn(ci, "the default bridge cannot be combined with other networks; it is left out")
+	}
+
+	svc.Networks = serviceNetworks{}
+	for _, n := range others {
+		ep := eps[n]
+		sn := &ServiceNetwork{}
+		// The daemon adds the container name and short ID by itself; compose up adds the service name
+		for _, a := range ep.Aliases {
+			if a != name && a != shortID && a != e.services[name] && !slices.Contains(sn.Aliases, a) {
+				sn.Aliases = append(sn.Aliases, a)
+			}
+		}
+		static := ep.IPAMConfig != nil && (ep.IPAMConfig.IPv4Address != "" || ep.IPAMConfig.IPv6Address != "")
+		if static {
+			sn.IPv4Address, sn.IPv6Address = ep.IPAMConfig.IPv4Address, ep.IPAMConfig.IPv6Address
+		}
+		key, cerr := e.networkKey(n, static)
+		if cerr != nil {
+			return cerr
+		}
+		svc.Networks[key] = sn
+	}
+	if mac := ci.Config.MacAddress; mac != "" {
+		svc.MacAddress = mac
+	}
+	return nil
+}
+
+// networkKey declares the network at the top level, under its current name; its subnets are only kept when a
+// container has a static address on it
+func (e *exporter) networkKey(name string, static bool) (string, *ce.CustomError) {
+	key, ok := e.netKeys[name]
+	if !ok {
+		var ns networks.NetworkSummary
+		if cerr := getJSON(e.ctx, e.client, "/networks/"+url.PathEscape(name), &ns); cerr != nil {
+			return "", cerr
+		}
+		key = e.resourceKey(ns.Labels[LabelNetwork], name, func(k string) bool { return e.file.Networks[k] != nil })
+		n := &Network{Name: name, Internal: ns.Internal, Attachable: ns.Attachable, EnableIPv6: ns.EnableIPv6,
+			DriverOpts: ns.Options, Labels: nonComposeLabels(ns.Labels)}
+		if ns.Driver != "bridge" {
+			n.Driver = ns.Driver
+		}
+		if len(ns.IPAM.Config) > 0 {
+			n.IPAM = &IPAM{}
+			for _, c := range ns.IPAM.Config {
+				n.IPAM.Config = append(n.IPAM.Config, IPAMConfig{Subnet: c.Subnet, IPRange: c.IPRange, Gateway: c.Gateway})
+			}
+		}
+		e.file.Networks[key] = n
+		e.netKeys[name] = key
+	}
+	if static {
+		e.static[key] = true
+	}
+	return key, nil
+}
+
+// volumeKey declares the named volume at the top level, under its current name
+func (e *exporter) volumeKey(name string) (string, *ce.CustomError) {
+	if key, ok := e.volKeys[name]; ok {
+		return key, nil
+	}
+	var vol volumes.Volume
+	if cerr := getJSON(e.ctx, e.client, "/volumes/"+url.PathEscape(name), &vol); cerr != nil {
+		return "", cerr
+	}
+	key := e.resourceKey(vol.Labels[LabelVolume], name, func(k string) bool { return e.file.Volumes[k] != nil })
+	v := &Volume{Name: name, DriverOpts: vol.Options, Labels: nonComposeLabels(vol.Labels)}
+	if vol.Driver != "local" {
+		v.Driver = vol.Driver
+	}
+	e.file.Volumes[key] = v
+	e.volKeys[name] = key
+	return key, nil
+}
+
+// resourceKey is the compose key of a network or volume created by compose, else its name
+func (e *exporter) resourceKey(fromLabel, name string, taken func(string) bool) string {
+	if fromLabel != "" && !taken(fromLabel) {
+		return fromLabel
+	}
+	return name
+}
+
+func nonComposeLabels(labels map[string]string) listOrMap {
+	var out listOrMap
+	for _, k := range slices.Sorted(maps.Keys(labels)) {
+		if !strings.HasPrefix(k, "com.docker.compose.") {
+			out = append(out, k+"="+labels[k])
+		}
+	}
+	return out
+}
+
+func exportResources(ci run.ContainerInspect, svc *Service) {
+	hc := ci.HostConfig
+	if hc.Memory > 0 {
+		svc.MemLimit = run.FormatBytes(hc.Memory)
+	}
+	if hc.MemorySwap == -1 {
+		svc.MemswapLimit = "-1"
+	} else if hc.MemorySwap > 0 {
+		svc.MemswapLimit = run.FormatBytes(hc.MemorySwap)
+	}
+	if hc.MemoryReservation > 0 {
+		svc.MemReservation = run.FormatBytes(hc.MemoryReservation)
+	}
+	svc.Cpus = float64(hc.NanoCpus) / 1e9
+	svc.CPUShares = hc.CpuShares
+	svc.Cpuset = hc.CpusetCpus
+	if hc.PidsLimit != nil {
+		svc.PidsLimit = *hc.PidsLimit
+	}
+	for _, u := range hc.Ulimits {
+		if svc.Ulimits == nil {
+			svc.Ulimits = map[string]ulimit{}
+		}
+		svc.Ulimits[u.Name] = ulimit{Soft: u.Soft, Hard: u.Hard}
+	}
+}
+
+func exportHealthcheck(h *run.Healthcheck) *Healthcheck {
+	if len(h.Test) > 0 && h.Test[0] == "NONE" {
+		return &Healthcheck{Disable: true}
+	}
+	duration := func(d time.Duration) string {
+		if d <= 0 {
+			return ""
+		}
+		return d.String()
+	}
+	return &Healthcheck{
+		Test:        h.Test,
+		Interval:    duration(h.Interval),
+		Timeout:     duration(h.Timeout),
+		StartPeriod: duration(h.StartPeriod),
+		Retries:     h.Retries,
+	}
+}
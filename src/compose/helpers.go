@@ -0,0 +1,93 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 02:20
+// Original filename: src/compose/helpers.go
+
+package compose
+
+import (
+	"context"
+	"dtools2/containers"
+	"dtools2/extras"
+	"dtools2/rest"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+)
+
+// projectContainers lists the containers of a project, stopped ones included, sorted by service then name;
+// services restricts the list when not empty
+func projectContainers(ctx context.Context, client *rest.Client, project string, services []string) ([]containers.ContainerSummary, *ce.CustomError) {
+	cs, cerr := containers.ListContainers(ctx, client, containers.ListOptions{Filters: extras.Filters{"label": {LabelProject + "=" + project}}})
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	out := []containers.ContainerSummary{}
+	for _, c := range cs {
+		if c.Labels[LabelProject] != project || len(c.Names) == 0 {
+			continue
+		}
+		if len(services) > 0 && !slices.Contains(services, c.Labels[LabelService]) {
+			continue
+		}
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if si, sj := out[i].Labels[LabelService], out[j].Labels[LabelService]; si != sj {
+			return si < sj
+		}
+		return out[i].Names[0] < out[j].Names[0]
+	})
+	return out, nil
+}
+
+// imageExists tells whether the image is present on the daemon
+func imageExists(ctx context.Context, client *rest.Client, ref string) (bool, *ce.CustomError) {
+	id, cerr := imageIDOf(ctx, client, ref)
+	return id != "", cerr
+}
+
+// imageIDOf returns the ID of a local image; "" when it is not present
+func imageIDOf(ctx context.Context, client *rest.Client, ref string) (string, *ce.CustomError) {
+	resp, err := client.Do(ctx, http.MethodGet, "/images/"+ref+"/json", url.Values{}, nil, nil)
+	if err != nil {
+		return "", &ce.CustomError{Title: "Unable to inspect image " + ref, Message: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	if aerr := rest.CheckResponse(resp); aerr != nil {
+		if rest.IsNotFound(aerr) {
+			return "", nil
+		}
+		return "", &ce.CustomError{Title: "Unable to inspect image " + ref, Message: aerr.Error()}
+	}
+	var img struct {
+		ID string `json:"Id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&img); err != nil {
+		return "", &ce.CustomError{Title: "Unable to decode JSON", Message: err.Error()}
+	}
+	return img.ID, nil
+}
+
+// getJSON decodes the answer to a GET request
+func getJSON(ctx context.Context, client *rest.Client, path string, out any) *ce.CustomError {
+	resp, err := client.Do(ctx, http.MethodGet, path, url.Values{}, nil, nil)
+	if err != nil {
+		return &ce.CustomError{Title: "Unable to GET request", Message: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	if aerr := rest.CheckResponse(resp); aerr != nil {
+		return &ce.CustomError{Title: "Unable to inspect", Message: aerr.Error()}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return &ce.CustomError{Title: "Unable to decode JSON", Message: err.Error()}
+	}
+	return nil
+}
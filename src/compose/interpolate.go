@@ -0,0 +1,120 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 01:40
+// Original filename: src/compose/interpolate.go
+
+package compose
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// interpolateNode substitutes the variables in every string value of the document; keys are left alone
+func interpolateNode(n *yaml.Node, vars map[string]string) error {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		if !strings.Contains(n.Value, "$") {
+			return nil
+		}
+		v, err := interpolate(n.Value, vars)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", n.Line, err)
+		}
+		n.Value = v
+	case yaml.MappingNode:
+		for i := 1; i < len(n.Content); i += 2 {
+			if err := interpolateNode(n.Content[i], vars); err != nil {
+				return err
+			}
+		}
+	default:
+		for _, c := range n.Content {
+			if err := interpolateNode(c, vars); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// interpolate supports $VAR, ${VAR}, ${VAR:-default}, ${VAR-default}, ${VAR:?error}, ${VAR?error}
+// and $$ for a literal $. With the colon, an empty variable counts as unset.
+func interpolate(s string, vars map[string]string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		switch next := s[i+1]; {
+		case next == '$':
+			b.WriteByte('$')
+			i++
+
+		case next == '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated variable in %q", s)
+			}
+			v, err := expandBraced(s[i+2:i+end], vars)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(v)
+			i += end
+
+		case isNameChar(next, true):
+			j := i + 1
+			for j < len(s) && isNameChar(s[j], false) {
+				j++
+			}
+			b.WriteString(vars[s[i+1:j]])
+			i = j - 1
+
+		default:
+			b.WriteByte('$')
+		}
+	}
+	return b.String(), nil
+}
+
+func expandBraced(expr string, vars map[string]string) (string, error) {
+	n := 0
+	for n < len(expr) && isNameChar(expr[n], n == 0) {
+		n++
+	}
+	name, op := expr[:n], expr[n:]
+	if name == "" {
+		return "", fmt.Errorf("invalid variable ${%s}", expr)
+	}
+	value, set := vars[name]
+
+	colon := strings.HasPrefix(op, ":")
+	if colon {
+		op = op[1:]
+		set = set && value != ""
+	}
+	switch {
+	case op == "" && !colon:
+		return value, nil
+	case strings.HasPrefix(op, "-"):
+		if !set {
+			return op[1:], nil
+		}
+		return value, nil
+	case strings.HasPrefix(op, "?"):
+		if !set {
+			return "", fmt.Errorf("required variable %s is missing a value: %s", name, op[1:])
+		}
+		return value, nil
+	}
+	return "", fmt.Errorf("invalid variable ${%s}", expr)
+}
+
+func isNameChar(c byte, first bool) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (!first && c >= '0' && c <= '9')
+}
@@ -0,0 +1,92 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 08:20
+// Original filename: src/compose/interpolate_test.go
+
+package compose
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestInterpolate(t *testing.T) {
+	vars := map[string]string{"TAG": "1.27", "EMPTY": "", "HOST_2": "db"}
+
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"nginx:$TAG", "nginx:1.27"},
+		{"nginx:${TAG}-alpine", "nginx:1.27-alpine"},
+		{"$HOST_2:5432", "db:5432"},
+		{"${MISSING}|$MISSING|", "||"},
+		{"${MISSING:-8080}", "8080"},
+		{"${EMPTY:-8080}", "8080"},
+		{"${EMPTY-8080}", ""},
+		{"${MISSING-8080}", "8080"},
+		{"${TAG:-latest}", "1.27"},
+		{"${TAG:?no tag}", "1.27"},
+		{"${EMPTY?unset}", ""},
+		{"$$HOME and $$$TAG", "$HOME and $1.27"},
+		{"cost: 5$ or $1", "cost: 5$ or $1"},
+		{"trailing $", "trailing $"},
+	} {
+		got, err := interpolate(tc.in, vars)
+		if err != nil {
+			t.Errorf("%s: %v", tc.in, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.in, got, tc.want)
+		}
+	}
+
+	for _, in := range []string{"${TAG", "${}", "${1X}", "${TAG+x}", "${MISSING:?set it}", "${EMPTY:?set it}", "${MISSING?set it}"} {
+		if got, err := interpolate(in, vars); err == nil {
+			t.Errorf("%s: accepted as %q", in, got)
+		}
+	}
+}
+
+func TestInterpolateNode(t *testing.T) {
+	in := `
+services:
+  $NAME:
+    image: nginx:${TAG:-latest}
+    ports: ["${PORT}:80"]
+`
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(in), &root); err != nil {
+		t.Fatal(err)
+	}
+	if err := interpolateNode(&root, map[string]string{"NAME": "web", "PORT": "8080"}); err != nil {
+		t.Fatal(err)
+	}
+	var cf struct {
+		Services map[string]struct {
+			Image string   `yaml:"image"`
+			Ports []string `yaml:"ports"`
+		} `yaml:"services"`
+	}
+	if err := root.Decode(&cf); err != nil {
+		t.Fatal(err)
+	}
+
+	// Keys are not interpolated
+	svc, ok := cf.Services["$NAME"]
+	if !ok {
+		t.Fatalf("services %v, want the $NAME key untouched", cf.Services)
+	}
+	if svc.Image != "nginx:latest" || len(svc.Ports) != 1 || svc.Ports[0] != "8080:80" {
+		t.Errorf("got image %q and ports %q", svc.Image, svc.Ports)
+	}
+
+	var bad yaml.Node
+	if err := yaml.Unmarshal([]byte("image: ${TAG:?a tag is needed}\n"), &bad); err != nil {
+		t.Fatal(err)
+	}
+	if err := interpolateNode(&bad, nil); err == nil {
+		t.Error("a missing required variable was accepted")
+	}
+}
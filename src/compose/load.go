@@ -0,0 +1,294 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 01:25
+// Original filename: src/compose/load.go
+
+package compose
+
+import (
+	"dtools2/run"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFiles are looked up, in this order, when no compose file is given
+var DefaultFiles = []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}
+
+type composeFile struct {
+	Name     string              `yaml:"name,omitempty"`
+	Version  string              `yaml:"version,omitempty"` // obsolete, ignored
+	Services map[string]*Service `yaml:"services"`
+	Networks map[string]*Network `yaml:"networks,omitempty"`
+	Volumes  map[string]*Volume  `yaml:"volumes,omitempty"`
+	Extra    map[string]any      `yaml:",inline"`
+}
+
+// FindFile returns the first of DefaultFiles present in dir
+func FindFile(dir string) (string, *ce.CustomError) {
+	for _, f := range DefaultFiles {
+		p := filepath.Join(dir, f)
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	return "", &ce.CustomError{Title: "No compose file", Message: "none of " + strings.Join(DefaultFiles, ", ") + " found in " + dir}
+}
+
+// Load reads a compose file: variables are interpolated (from our environment, then the .env file),
+// relative paths are resolved against the file directory, and the resource names get the project prefix.
+// The services are checked and sorted in dependency order.
+func Load(path string, opts LoadOptions) (*Project, *ce.CustomError) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &ce.CustomError{Title: "Unable to read the compose file", Message: err.Error()}
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, &ce.CustomError{Title: "Unable to read the compose file", Message: err.Error()}
+	}
+	dir := filepath.Dir(absPath)
+
+	vars, cerr := loadDotEnv(dir, opts.EnvFile)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, &ce.CustomError{Title: "Invalid compose file", Message: err.Error()}
+	}
+	if err := interpolateNode(&root, vars); err != nil {
+		return nil, &ce.CustomError{Title: "Invalid compose file", Message: err.Error()}
+	}
+	var cf composeFile
+	if err := root.Decode(&cf); err != nil {
+		return nil, &ce.CustomError{Title: "Invalid compose file", Message: err.Error()}
+	}
+	if len(cf.Services) == 0 {
+		return nil, &ce.CustomError{Title: "Invalid compose file", Message: path + " defines no services"}
+	}
+
+	p := &Project{
+		Name:       projectName(opts.ProjectName, cf.Name, vars, dir),
+		WorkingDir: dir,
+		Services:   cf.Services,
+		Networks:   orEmpty(cf.Networks),
+		Volumes:    orEmpty(cf.Volumes),
+	}
+	if p.Name == "" {
+		return nil, &ce.CustomError{Title: "Invalid project name", Message: "the project name must contain letters or digits"}
+	}
+	for _, k := range slices.Sorted(maps.Keys(cf.Extra)) {
+		p.Warnings = append(p.Warnings, "unsupported top-level key "+k+" ignored")
+	}
+
+	for key, n := range p.Networks {
+		if n == nil {
+			n = &Network{}
+			p.Networks[key] = n
+		}
+		n.Name = resourceName(p.Name, key, n.Name, n.External)
+	}
+	for key, v := range p.Volumes {
+		if v == nil {
+			v = &Volume{}
+			p.Volumes[key] = v
+		}
+		v.Name = resourceName(p.Name, key, v.Name, v.External)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(p.Services)) {
+		if cerr := p.checkService(name); cerr != nil {
+			return nil, cerr
+		}
+	}
+	if p.Order, cerr = p.dependencyOrder(); cerr != nil {
+		return nil, cerr
+	}
+	return p, nil
+}
+
+// checkService completes and validates a service; the default network is added to the project when used
+func (p *Project) checkService(name string) *ce.CustomError {
+	svc := p.Services[name]
+	if svc == nil {
+		return &ce.CustomError{Title: "Invalid service", Message: "service " + name + " is empty"}
+	}
+	svc.Name = name
+	invalid := func(format string, a ...any) *ce.CustomError {
+		return &ce.CustomError{Title: "Invalid service", Message: "service " + name + ": " + fmt.Sprintf(format, a...)}
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(svc.Extra)) {
+		p.Warnings = append(p.Warnings, "service "+name+": unsupported key "+k+" ignored")
+	}
+	if svc.Image == "" && svc.Build == nil {
+		return invalid("neither image nor build is set")
+	}
+	if svc.Build != nil {
+		svc.Build.Context = p.resolvePath(svc.Build.Context)
+	}
+	for i, f := range svc.EnvFile {
+		svc.EnvFile[i] = p.resolvePath(f)
+	}
+
+	for i := range svc.Volumes {
+		v := &svc.Volumes[i]
+		switch v.Type {
+		case "bind":
+			v.Source = p.resolvePath(v.Source)
+		case "volume":
+			if v.Source != "" && p.Volumes[v.Source] == nil {
+				return invalid("refers to undefined volume %s", v.Source)
+			}
+		case "tmpfs":
+		default:
+			return invalid("unsupported volume type %q", v.Type)
+		}
+	}
+
+	if svc.NetworkMode != "" {
+		if len(svc.Networks) > 0 {
+			return invalid("network_mode and networks cannot be combined")
+		}
+		if strings.HasPrefix(svc.NetworkMode, "service:") {
+			return invalid("network_mode service: is not supported")
+		}
+	} else if len(svc.Networks) == 0 {
+		svc.Networks = serviceNetworks{"default": &ServiceNetwork{}}
+	}
+	for key := range svc.Networks {
+		if p.Networks[key] == nil {
+			if key != "default" {
+				return invalid("refers to undefined network %s", key)
+			}
+			p.Networks[key] = &Network{Name: resourceName(p.Name, key, "", false)}
+		}
+	}
+
+	for dep := range svc.DependsOn {
+		if p.Services[dep] == nil {
+			return invalid("depends on undefined service %s", dep)
+		}
+	}
+	return nil
+}
+
+// dependencyOrder sorts the services so that each one comes after what it depends on
+func (p *Project) dependencyOrder() ([]string, *ce.CustomError) {
+	var order []string
+	state := map[string]int{} // 1: being visited, 2: done
+
+	var visit func(name string, path []string) *ce.CustomError
+	visit = func(name string, path []string) *ce.CustomError {
+		switch state[name] {
+		case 1:
+			return &ce.CustomError{Title: "Dependency cycle", Message: strings.Join(append(path, name), " -> ")}
+		case 2:
+			return nil
+		}
+		state[name] = 1
+		for _, dep := range slices.Sorted(maps.Keys(p.Services[name].DependsOn)) {
+			if cerr := visit(dep, append(path, name)); cerr != nil {
+				return cerr
+			}
+		}
+		state[name] = 2
+		order = append(order, name)
+		return nil
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(p.Services)) {
+		if cerr := visit(name, nil); cerr != nil {
+			return nil, cerr
+		}
+	}
+	return order, nil
+}
+
+// resolvePath makes a relative path absolute from the project directory, and expands ~
+func (p *Project) resolvePath(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = home + path[1:]
+		}
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(p.WorkingDir, path)
+	}
+	return filepath.Clean(path)
+}
+
+// projectName picks -p, then COMPOSE_PROJECT_NAME, then the name: key, then the directory name
+func projectName(flag, fromFile string, vars map[string]string, dir string) string {
+	name := flag
+	for _, candidate := range []string{vars["COMPOSE_PROJECT_NAME"], fromFile, filepath.Base(dir)} {
+		if name == "" {
+			name = candidate
+		}
+	}
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || ((r == '-' || r == '_') && b.Len() > 0) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// resourceName is the daemon name of a network or volume: its name: key, else project_key; external ones are not prefixed
+func resourceName(project, key, name string, external bool) string {
+	switch {
+	case name != "":
+		return name
+	case external:
+		return key
+	}
+	return project + "_" + key
+}
+
+// loadDotEnv reads the interpolation variables: the env file, overridden by our own environment
+func loadDotEnv(dir, envFile string) (map[string]string, *ce.CustomError) {
+	vars := map[string]string{}
+	path := envFile
+	if path == "" {
+		path = filepath.Join(dir, ".env")
+		if _, err := os.Stat(path); err != nil {
+			path = ""
+		}
+	}
+	if path != "" {
+		lines, cerr := run.ParseEnvFile(path)
+		if cerr != nil {
+			return nil, cerr
+		}
+		for _, l := range lines {
+			k, v, _ := strings.Cut(l, "=")
+			// Unlike docker's env files, compose's .env removes the quotes around the values
+			if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+				v = v[1 : len(v)-1]
+			}
+			vars[k] = v
+		}
+	}
+	for _, e := range os.Environ() {
+		k, v, _ := strings.Cut(e, "=")
+		vars[k] = v
+	}
+	return vars, nil
+}
+
+func orEmpty[V any](m map[string]*V) map[string]*V {
+	if m == nil {
+		return map[string]*V{}
+	}
+	return m
+}
@@ -0,0 +1,196 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 08:30
+// Original filename: src/compose/load_test.go
+
+package compose
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeProject writes the compose file, and the .env file when not empty, to a new directory
+func writeProject(t *testing.T, compose, dotEnv string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "Demo App")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "compose.yaml"), []byte(compose), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if dotEnv != "" {
+		if err := os.WriteFile(filepath.Join(dir, ".env"), []byte(dotEnv), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	t.Setenv("COMPOSE_PROJECT_NAME", "")
+	dir := writeProject(t, `
+services:
+  app:
+    image: nginx:${COMPOSE_TEST_TAG}
+    depends_on: [db, cache]
+    volumes: [./html:/usr/share/nginx/html:ro, data:/data]
+    networks: [front]
+  db:
+    image: postgres
+    depends_on:
+      cache: {condition: service_healthy}
+  cache:
+    build: ./cache
+    deploy: {replicas: 2}
+networks:
+  front:
+volumes:
+  data:
+  shared: {external: true}
+configs: {}
+`, `COMPOSE_TEST_TAG="1.27"`+"\n")
+
+	p, cerr := Load(filepath.Join(dir, "compose.yaml"), LoadOptions{})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+
+	if p.Name != "demoapp" {
+		t.Errorf("project name %q, want the directory name, demoapp", p.Name)
+	}
+	if want := []string{"cache", "db", "app"}; !slices.Equal(p.Order, want) {
+		t.Errorf("order %v, want %v", p.Order, want)
+	}
+	app := p.Services["app"]
+	if app.Name != "app" || app.Image != "nginx:1.27" {
+		t.Errorf("app is named %q with image %q", app.Name, app.Image)
+	}
+	if v := app.Volumes[0]; v.Type != "bind" || v.Source != filepath.Join(dir, "html") {
+		t.Errorf("bind mount %+v, want its source under %s", v, dir)
+	}
+	if got := p.Services["cache"].Build.Context; got != filepath.Join(dir, "cache") {
+		t.Errorf("build context %q", got)
+	}
+
+	// The default network is only added when a service uses it
+	names := map[string]string{}
+	for key, n := range p.Networks {
+		names["network "+key] = n.Name
+	}
+	for key, v := range p.Volumes {
+		names["volume "+key] = v.Name
+	}
+	want := map[string]string{
+		"network front": "demoapp_front", "network default": "demoapp_default",
+		"volume data": "demoapp_data", "volume shared": "shared",
+	}
+	if !maps.Equal(names, want) {
+		t.Errorf("resource names %v, want %v", names, want)
+	}
+	if _, ok := p.Services["db"].Networks["default"]; !ok {
+		t.Errorf("db networks %v, want default", slices.Collect(maps.Keys(p.Services["db"].Networks)))
+	}
+
+	wantWarnings := []string{"unsupported top-level key configs ignored", "service cache: unsupported key deploy ignored"}
+	if !slices.Equal(p.Warnings, wantWarnings) {
+		t.Errorf("warnings %q, want %q", p.Warnings, wantWarnings)
+	}
+
+	// -p wins over COMPOSE_PROJECT_NAME, which wins over the name: key
+	for _, tc := range []struct {
+		flag, env, want string
+	}{
+		{"Other_Name", "fromenv", "other_name"},
+		{"", "fromenv", "fromenv"},
+		{"", "", "fromfile"},
+	} {
+		t.Setenv("COMPOSE_PROJECT_NAME", tc.env)
+		dir := writeProject(t, "name: FromFile\nservices: {web: {image: nginx}}\n", "")
+		p, cerr := Load(filepath.Join(dir, "compose.yaml"), LoadOptions{ProjectName: tc.flag})
+		if cerr != nil {
+			t.Fatal(cerr)
+		}
+		if p.Name != tc.want {
+			t.Errorf("-p %q, COMPOSE_PROJECT_NAME %q: project %q, want %q", tc.flag, tc.env, p.Name, tc.want)
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	for _, tc := range []struct {
+		compose, want string
+	}{
+		{"name: empty\n", "defines no services"},
+		{"services: {web: {restart: always}}\n", "service web: neither image nor build is set"},
+		{"services: {web: {image: nginx, volumes: [data:/data]}}\n", "service web: refers to undefined volume data"},
+		{"services: {web: {image: nginx, volumes: [{type: npipe, target: /x}]}}\n", `service web: unsupported volume type "npipe"`},
+		{"services: {web: {image: nginx, networks: [front]}}\n", "service web: refers to undefined network front"},
+		{"services: {web: {image: nginx, network_mode: host, networks: [default]}}\n", "network_mode and networks cannot be combined"},
+		{"services: {web: {image: nginx, network_mode: 'service:db'}}\n", "network_mode service: is not supported"},
+		{"services: {web: {image: nginx, depends_on: [db]}}\n", "service web: depends on undefined service db"},
+		{"services: {a: {image: x, depends_on: [b]}, b: {image: x, depends_on: [c]}, c: {image: x, depends_on: [a]}}\n", "a -> b -> c -> a"},
+		{"services: {web: {image: '${COMPOSE_TEST_MISSING:?set it}'}}\n", "required variable COMPOSE_TEST_MISSING"},
+	} {
+		_, cerr := Load(filepath.Join(writeProject(t, tc.compose, ""), "compose.yaml"), LoadOptions{})
+		if cerr == nil {
+			t.Errorf("%s: accepted", tc.compose)
+			continue
+		}
+		if !strings.Contains(cerr.Message, tc.want) {
+			t.Errorf("%s: got %q, want %q", tc.compose, cerr.Message, tc.want)
+		}
+	}
+}
+
+func TestLoadDotEnv(t *testing.T) {
+	dir := writeProject(t, "", `# comment
+COMPOSE_TEST_DQ="double quoted"
+COMPOSE_TEST_SQ='single # quoted'
+COMPOSE_TEST_MIXED="mixed'
+COMPOSE_TEST_INNER=a "b" c
+COMPOSE_TEST_EMPTY=""
+COMPOSE_TEST_LONE="
+COMPOSE_TEST_OVERRIDDEN=from the file
+`)
+	t.Setenv("COMPOSE_TEST_OVERRIDDEN", "from the environment")
+
+	vars, cerr := loadDotEnv(dir, "")
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	for key, want := range map[string]string{
+		"COMPOSE_TEST_DQ":         "double quoted",
+		"COMPOSE_TEST_SQ":         "single # quoted",
+		"COMPOSE_TEST_MIXED":      `"mixed'`,
+		"COMPOSE_TEST_INNER":      `a "b" c`,
+		"COMPOSE_TEST_EMPTY":      "",
+		"COMPOSE_TEST_LONE":       `"`,
+		"COMPOSE_TEST_OVERRIDDEN": "from the environment",
+	} {
+		if got, ok := vars[key]; !ok || got != want {
+			t.Errorf("%s: got %q, want %q", key, got, want)
+		}
+	}
+
+	// An explicit env file replaces .env, which is optional
+	other := filepath.Join(t.TempDir(), "other.env")
+	if err := os.WriteFile(other, []byte("COMPOSE_TEST_OTHER='yes'\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	vars, cerr = loadDotEnv(dir, other)
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	if _, ok := vars["COMPOSE_TEST_DQ"]; ok || vars["COMPOSE_TEST_OTHER"] != "yes" {
+		t.Errorf("with %s: got DQ %q and OTHER %q", other, vars["COMPOSE_TEST_DQ"], vars["COMPOSE_TEST_OTHER"])
+	}
+	if _, cerr := loadDotEnv(t.TempDir(), ""); cerr != nil {
+		t.Errorf("a missing .env failed: %v", cerr)
+	}
+}
@@ -0,0 +1,191 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 02:10
+// Original filename: src/compose/options.go
+
+package compose
+
+import (
+	"dtools2/containers"
+	"dtools2/extras"
+	"dtools2/run"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+)
+
+// runOptions translates a service into the options of `dtools run`, so that every value goes through the same
+// parsers (ports, volumes, env files, restart policy, healthcheck...). It also returns the command.
+func (p *Project) runOptions(svc *Service, hash string) (run.Options, []string, *ce.CustomError) {
+	opts := run.Options{
+		Detach:      true,
+		Name:        p.ContainerName(svc),
+		User:        svc.User,
+		Workdir:     svc.WorkingDir,
+		Hostname:    svc.Hostname,
+		TTY:         svc.Tty,
+		Interactive: svc.StdinOpen,
+		Env:         svc.Environment,
+		EnvFile:     svc.EnvFile,
+		Restart:     svc.Restart,
+		MacAddress:  svc.MacAddress,
+		DNS:         svc.DNS,
+		DNSSearch:   svc.DNSSearch,
+		DNSOption:   svc.DNSOpt,
+		AddHost:     svc.ExtraHosts,
+		Tmpfs:       append([]string{}, svc.Tmpfs...),
+		CapAdd:      svc.CapAdd,
+		CapDrop:     svc.CapDrop,
+		Privileged:  svc.Privileged,
+		ReadOnly:    svc.ReadOnly,
+		SecurityOpt: svc.SecurityOpt,
+		Device:      svc.Devices,
+		GroupAdd:    svc.GroupAdd,
+		Userns:      svc.UsernsMode,
+		Resources: extras.ResourceOptions{
+			Memory:            svc.MemLimit,
+			MemorySwap:        svc.MemswapLimit,
+			MemoryReservation: svc.MemReservation,
+			CPUs:              svc.Cpus,
+			CPUShares:         svc.CPUShares,
+			CpusetCpus:        svc.Cpuset,
+			PidsLimit:         svc.PidsLimit,
+		},
+		Labels: append(append([]string{}, svc.Labels...),
+			LabelProject+"="+p.Name,
+			LabelService+"="+svc.Name,
+			LabelNumber+"=1",
+			LabelOneoff+"=False",
+			LabelConfigHash+"="+hash),
+	}
+	if svc.Build != nil {
+		opts.Pull = run.PullNever
+	}
+	// Read back by `dtools start` and friends to order the containers, as docker compose does
+	if len(svc.DependsOn) > 0 {
+		var deps []string
+		for _, dep := range slices.Sorted(maps.Keys(svc.DependsOn)) {
+			deps = append(deps, dep+":"+svc.DependsOn[dep]+":false")
+		}
+		opts.Labels = append(opts.Labels, containers.LabelComposeDependsOn+"="+strings.Join(deps, ","))
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(svc.Ulimits)) {
+		u := svc.Ulimits[name]
+		opts.Resources.Ulimits = append(opts.Resources.Ulimits, name+"="+strconv.FormatInt(u.Soft, 10)+":"+strconv.FormatInt(u.Hard, 10))
+	}
+
+	for _, port := range svc.Ports {
+		opts.Publish = append(opts.Publish, string(port))
+	}
+
+	for _, v := range svc.Volumes {
+		mode := v.Mode
+		if v.ReadOnly && mode == "" {
+			mode = "ro"
+		}
+		spec := v.Target
+		switch v.Type {
+		case "tmpfs":
+			opts.Tmpfs = append(opts.Tmpfs, v.Target)
+			continue
+		case "bind":
+			spec = v.Source + ":" + v.Target
+		case "volume":
+			if v.Source != "" {
+				spec = p.Volumes[v.Source].Name + ":" + v.Target
+			}
+		}
+		if mode != "" {
+			spec += ":" + mode
+		}
+		opts.Volume = append(opts.Volume, spec)
+	}
+
+	// The service name is an alias on all of its networks, as with docker compose
+	if svc.NetworkMode != "" {
+		opts.Network = []string{svc.NetworkMode}
+	} else {
+		// Static addresses are set at creation: their network goes first
+		keys := slices.Sorted(maps.Keys(svc.Networks))
+		static := func(key string) bool {
+			return svc.Networks[key].IPv4Address != "" || svc.Networks[key].IPv6Address != ""
+		}
+		if i := slices.IndexFunc(keys, static); i > 0 {
+			keys = append(append([]string{keys[i]}, keys[:i]...), keys[i+1:]...)
+		}
+		opts.NetworkAlias = []string{svc.Name}
+		for i, key := range keys {
+			sn := svc.Networks[key]
+			opts.Network = append(opts.Network, p.Networks[key].Name)
+			for _, a := range sn.Aliases {
+				if !strings.EqualFold(a, svc.Name) && !slices.Contains(opts.NetworkAlias, a) {
+					opts.NetworkAlias = append(opts.NetworkAlias, a)
+				}
+			}
+			if i == 0 {
+				opts.IP, opts.IP6 = sn.IPv4Address, sn.IPv6Address
+			} else if static(key) {
+				return opts, nil, &ce.CustomError{Title: "Invalid service", Message: "service " + svc.Name + ": static addresses are only supported on one network (" + keys[0] + ")"}
+			}
+		}
+	}
+
+	if cerr := applyHealthcheck(&opts, svc); cerr != nil {
+		return opts, nil, cerr
+	}
+
+	// --entrypoint takes a single binary: its arguments go before the command
+	var cmd []string
+	if len(svc.Entrypoint) > 0 {
+		opts.Entrypoint = svc.Entrypoint[0]
+		cmd = append(cmd, svc.Entrypoint[1:]...)
+	}
+	cmd = append(cmd, svc.Command...)
+	return opts, cmd, nil
+}
+
+func applyHealthcheck(opts *run.Options, svc *Service) *ce.CustomError {
+	hc := svc.Healthcheck
+	if hc == nil {
+		return nil
+	}
+	if hc.Disable || (len(hc.Test) > 0 && hc.Test[0] == "NONE") {
+		opts.NoHealthcheck = true
+		return nil
+	}
+
+	switch {
+	case len(hc.Test) < 2:
+	case hc.Test[0] == "CMD-SHELL":
+		opts.HealthCmd = hc.Test[1]
+	default:
+		// The exec form runs without a shell, which distroless or scratch images do not have
+		opts.HealthTest = hc.Test[1:]
+	}
+	opts.HealthRetries = hc.Retries
+
+	for _, d := range []struct {
+		key   string
+		value string
+		dst   *time.Duration
+	}{
+		{"interval", hc.Interval, &opts.HealthInterval},
+		{"timeout", hc.Timeout, &opts.HealthTimeout},
+		{"start_period", hc.StartPeriod, &opts.HealthStartPeriod},
+	} {
+		if d.value == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.value)
+		if err != nil {
+			return &ce.CustomError{Title: "Invalid service", Message: "service " + svc.Name + ": invalid healthcheck " + d.key + " " + d.value}
+		}
+		*d.dst = v
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 08:45
+// Original filename: src/compose/options_test.go
+
+package compose
+
+import (
+	"dtools2/containers"
+	"dtools2/run"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunOptions(t *testing.T) {
+	dir := writeProject(t, `
+services:
+  web:
+    image: nginx
+    entrypoint: /bin/tini -- nginx
+    command: ["-g", "daemon off;"]
+    ports: ["8080:80", {target: 443, published: "8443", host_ip: "::1"}]
+    volumes: [data:/data, ./conf:/etc/nginx:ro, {type: tmpfs, target: /run}]
+    networks:
+      back: {aliases: [api, WEB]}
+      front: {ipv4_address: 172.30.0.10}
+    depends_on: {db: {condition: service_healthy}, cache: {}}
+    labels: {tier: web}
+    ulimits: {nofile: {soft: 1024, hard: 2048}, nproc: 512}
+  db:
+    build: ./db
+  cache:
+    image: redis
+    networks:
+      front: {ipv4_address: 172.30.0.11}
+      back: {ipv4_address: 172.31.0.11}
+networks: {front: {}, back: {}}
+volumes: {data: {}}
+`, "")
+	p, cerr := Load(filepath.Join(dir, "compose.yaml"), LoadOptions{ProjectName: "demo"})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+
+	opts, cmd, cerr := p.runOptions(p.Services["web"], "abc")
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	if opts.Name != "demo-web-1" || !opts.Detach || opts.Pull != "" {
+		t.Errorf("name %q, detach %v, pull %q", opts.Name, opts.Detach, opts.Pull)
+	}
+	if opts.IP != "172.30.0.10" {
+		t.Errorf("ip %q, want the static address of front", opts.IP)
+	}
+	for _, tc := range []struct {
+		name      string
+		got, want []string
+	}{
+		// --entrypoint takes the binary, its arguments go before the command
+		{"entrypoint", []string{opts.Entrypoint}, []string{"/bin/tini"}},
+		{"command", cmd, []string{"--", "nginx", "-g", "daemon off;"}},
+		{"publish", opts.Publish, []string{"8080:80", "[::1]:8443:443"}},
+		{"volume", opts.Volume, []string{"demo_data:/data", filepath.Join(dir, "conf") + ":/etc/nginx:ro"}},
+		{"tmpfs", opts.Tmpfs, []string{"/run"}},
+		// The network with a static address goes first
+		{"network", opts.Network, []string{"demo_front", "demo_back"}},
+		{"network-alias", opts.NetworkAlias, []string{"web", "api"}},
+		{"ulimit", opts.Resources.Ulimits, []string{"nofile=1024:2048", "nproc=512:512"}},
+		{"label", opts.Labels, []string{"tier=web",
+			LabelProject + "=demo", LabelService + "=web", LabelNumber + "=1", LabelOneoff + "=False", LabelConfigHash + "=abc",
+			containers.LabelComposeDependsOn + "=cache:service_started:false,db:service_healthy:false"}},
+	} {
+		if !slices.Equal(tc.got, tc.want) {
+			t.Errorf("%s: got %q, want %q", tc.name, tc.got, tc.want)
+		}
+	}
+
+	// A built image is never pulled
+	if opts, _, cerr := p.runOptions(p.Services["db"], "abc"); cerr != nil || opts.Pull != run.PullNever {
+		t.Errorf("db: pull %q (%v), want %q", opts.Pull, cerr, run.PullNever)
+	}
+
+	_, _, cerr = p.runOptions(p.Services["cache"], "abc")
+	if cerr == nil || !strings.Contains(cerr.Message, "static addresses are only supported on one network") {
+		t.Errorf("cache: got %v, want an error about the static addresses", cerr)
+	}
+}
+
+func TestApplyHealthcheck(t *testing.T) {
+	type health struct {
+		Cmd                          string
+		Test                         []string
+		Disabled                     bool
+		Interval, Timeout, StartTime time.Duration
+		Retries                      int
+	}
+	for _, tc := range []struct {
+		hc   *Healthcheck
+		want health
+	}{
+		{nil, health{}},
+		{&Healthcheck{Test: healthTest{"CMD", "pg_isready", "-U", "x y"}}, health{Test: []string{"pg_isready", "-U", "x y"}}},
+		{&Healthcheck{Test: healthTest{"CMD-SHELL", "curl -f http://localhost || exit 1"}}, health{Cmd: "curl -f http://localhost || exit 1"}},
+		{&Healthcheck{Test: healthTest{"NONE"}, Interval: "10s"}, health{Disabled: true}},
+		{&Healthcheck{Disable: true}, health{Disabled: true}},
+		// Only the timings: the image healthcheck is kept
+		{&Healthcheck{Interval: "10s", Timeout: "2s", StartPeriod: "1m", Retries: 3},
+			health{Interval: 10 * time.Second, Timeout: 2 * time.Second, StartTime: time.Minute, Retries: 3}},
+	} {
+		var opts run.Options
+		if cerr := applyHealthcheck(&opts, &Service{Name: "web", Healthcheck: tc.hc}); cerr != nil {
+			t.Errorf("%+v: %v", tc.hc, cerr)
+			continue
+		}
+		got := health{opts.HealthCmd, opts.HealthTest, opts.NoHealthcheck,
+			opts.HealthInterval, opts.HealthTimeout, opts.HealthStartPeriod, opts.HealthRetries}
+		if got.Cmd != tc.want.Cmd || !slices.Equal(got.Test, tc.want.Test) || got.Disabled != tc.want.Disabled ||
+			got.Interval != tc.want.Interval || got.Timeout != tc.want.Timeout || got.StartTime != tc.want.StartTime ||
+			got.Retries != tc.want.Retries {
+			t.Errorf("%+v: got %+v, want %+v", tc.hc, got, tc.want)
+		}
+	}
+
+	var opts run.Options
+	cerr := applyHealthcheck(&opts, &Service{Name: "web", Healthcheck: &Healthcheck{Interval: "10 parsecs"}})
+	if cerr == nil || !strings.Contains(cerr.Message, "invalid healthcheck interval 10 parsecs") {
+		t.Errorf("got %v, want an error about the interval", cerr)
+	}
+}
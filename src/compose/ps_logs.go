@@ -0,0 +1,123 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 02:40
+// Original filename: src/compose/ps_logs.go
+
+package compose
+
+import (
+	"bytes"
+	"context"
+	"dtools2/containers"
+	"dtools2/extras"
+	"dtools2/rest"
+	"io"
+	"sync"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+)
+
+// Ps lists the containers of the project, stopped ones included; services restricts the list when not empty
+func Ps(ctx context.Context, client *rest.Client, project string, services []string) ([]containers.ContainerSummary, *ce.CustomError) {
+	return projectContainers(ctx, client, project, services)
+}
+
+// Logs shows the logs of the project containers, each line prefixed with the container name.
+// When following, all containers are streamed at once; the call returns when all streams end.
+func Logs(ctx context.Context, client *rest.Client, project string, opts LogsOptions) *ce.CustomError {
+	cs, cerr := projectContainers(ctx, client, project, opts.Services)
+	if cerr != nil {
+		return cerr
+	}
+	if len(cs) == 0 {
+		return &ce.CustomError{Fatality: ce.Warning, Title: "No containers", Message: "project " + project + " has no containers"}
+	}
+
+	width := 0
+	for _, c := range cs {
+		width = max(width, len(c.Names[0])-1)
+	}
+
+	var mu sync.Mutex // a line is written at once, whatever the stream
+	var wg sync.WaitGroup
+	errs := make([]*ce.CustomError, len(cs))
+	for i, c := range cs {
+		name := c.Names[0][1:]
+		lo := opts.LogOptions
+		prefix := ""
+		if !opts.NoPrefix {
+			prefix = name + spaces(width-len(name)) + " | "
+		}
+		stdout := &prefixWriter{mu: &mu, out: opts.Stdout, prefix: prefix}
+		stderr := &prefixWriter{mu: &mu, out: opts.Stderr, prefix: prefix}
+		lo.Stdout, lo.Stderr = stdout, stderr
+
+		logs := func() {
+			errs[i] = extras.Logs(ctx, client, c.ID, lo)
+			stdout.flush()
+			stderr.flush()
+		}
+		if !opts.Follow {
+			// One container after the other, so that each one's logs stay together
+			if logs(); errs[i] != nil {
+				return errs[i]
+			}
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logs()
+		}()
+	}
+	wg.Wait()
+
+	for _, e := range errs {
+		if e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+func spaces(n int) string {
+	return string(bytes.Repeat([]byte{' '}, max(n, 0)))
+}
+
+// prefixWriter writes complete lines, each one starting with prefix; out may be nil
+type prefixWriter struct {
+	mu      *sync.Mutex
+	out     io.Writer
+	prefix  string
+	pending []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.pending = append(w.pending, p...)
+	for {
+		i := bytes.IndexByte(w.pending, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		w.writeLine(w.pending[:i+1])
+		w.pending = w.pending[i+1:]
+	}
+}
+
+// flush writes what is left of an unterminated last line
+func (w *prefixWriter) flush() {
+	if len(w.pending) > 0 {
+		w.writeLine(append(w.pending, '\n'))
+		w.pending = nil
+	}
+}
+
+func (w *prefixWriter) writeLine(line []byte) {
+	if w.out == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	io.WriteString(w.out, w.prefix)
+	w.out.Write(line)
+}
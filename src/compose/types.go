@@ -0,0 +1,193 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 01:00
+// Original filename: src/compose/types.go
+
+package compose
+
+import (
+	"dtools2/extras"
+	"io"
+)
+
+// Labels set on everything we create for a project; they are the same as docker compose's, so that both tools
+// recognize each other's stacks.
+const (
+	LabelProject    = "com.docker.compose.project"
+	LabelService    = "com.docker.compose.service"
+	LabelNumber     = "com.docker.compose.container-number"
+	LabelOneoff     = "com.docker.compose.oneoff"
+	LabelConfigHash = "com.docker.compose.config-hash"
+	LabelNetwork    = "com.docker.compose.network"
+	LabelVolume     = "com.docker.compose.volume"
+)
+
+// Conditions of depends_on
+const (
+	ConditionStarted   = "service_started"
+	ConditionHealthy   = "service_healthy"
+	ConditionCompleted = "service_completed_successfully"
+)
+
+// Project is a loaded compose file: names are resolved, paths are absolute and variables are interpolated.
+type Project struct {
+	Name       string
+	WorkingDir string // the directory of the compose file; relative paths are resolved against it
+	Services   map[string]*Service
+	Networks   map[string]*Network
+	Volumes    map[string]*Volume
+	Order      []string // the service names, dependencies first
+	Warnings   []string // the keys of the file we do not support, and ignored
+}
+
+// Service is the supported subset of a compose service
+type Service struct {
+	Name          string          `yaml:"-" json:"-"`
+	Image         string          `yaml:"image,omitempty" json:"image,omitempty"`
+	Build         *Build          `yaml:"build,omitempty" json:"build,omitempty"`
+	ContainerName string          `yaml:"container_name,omitempty" json:"container_name,omitempty"`
+	Command       shellCommand    `yaml:"command,omitempty" json:"command,omitempty"`
+	Entrypoint    shellCommand    `yaml:"entrypoint,omitempty" json:"entrypoint,omitempty"`
+	Environment   listOrMap       `yaml:"environment,omitempty" json:"environment,omitempty"`
+	EnvFile       stringOrList    `yaml:"env_file,omitempty" json:"env_file,omitempty"`
+	Ports         []portSpec      `yaml:"ports,omitempty" json:"ports,omitempty"`
+	Volumes       []serviceVolume `yaml:"volumes,omitempty" json:"volumes,omitempty"`
+	Networks      serviceNetworks `yaml:"networks,omitempty" json:"networks,omitempty"`
+	NetworkMode   string          `yaml:"network_mode,omitempty" json:"network_mode,omitempty"`
+	DependsOn     dependsOn       `yaml:"depends_on,omitempty" json:"depends_on,omitempty"`
+	Restart       string          `yaml:"restart,omitempty" json:"restart,omitempty"`
+	Labels        listOrMap       `yaml:"labels,omitempty" json:"labels,omitempty"`
+	Healthcheck   *Healthcheck    `yaml:"healthcheck,omitempty" json:"healthcheck,omitempty"`
+	User          string          `yaml:"user,omitempty" json:"user,omitempty"`
+	WorkingDir    string          `yaml:"working_dir,omitempty" json:"working_dir,omitempty"`
+	Hostname      string          `yaml:"hostname,omitempty" json:"hostname,omitempty"`
+	Tty           bool            `yaml:"tty,omitempty" json:"tty,omitempty"`
+	StdinOpen     bool            `yaml:"stdin_open,omitempty" json:"stdin_open,omitempty"`
+	DNS           stringOrList    `yaml:"dns,omitempty" json:"dns,omitempty"`
+	DNSSearch     stringOrList    `yaml:"dns_search,omitempty" json:"dns_search,omitempty"`
+	DNSOpt        []string        `yaml:"dns_opt,omitempty" json:"dns_opt,omitempty"`
+	ExtraHosts    []string        `yaml:"extra_hosts,omitempty" json:"extra_hosts,omitempty"` // host:ip
+	MacAddress    string          `yaml:"mac_address,omitempty" json:"mac_address,omitempty"`
+	Tmpfs         stringOrList    `yaml:"tmpfs,omitempty" json:"tmpfs,omitempty"`
+
+	// Security
+	CapAdd      []string `yaml:"cap_add,omitempty" json:"cap_add,omitempty"`
+	CapDrop     []string `yaml:"cap_drop,omitempty" json:"cap_drop,omitempty"`
+	Privileged  bool     `yaml:"privileged,omitempty" json:"privileged,omitempty"`
+	ReadOnly    bool     `yaml:"read_only,omitempty" json:"read_only,omitempty"`
+	SecurityOpt []string `yaml:"security_opt,omitempty" json:"security_opt,omitempty"`
+	Devices     []string `yaml:"devices,omitempty" json:"devices,omitempty"` // host:container[:permissions]
+	GroupAdd    []string `yaml:"group_add,omitempty" json:"group_add,omitempty"`
+	UsernsMode  string   `yaml:"userns_mode,omitempty" json:"userns_mode,omitempty"`
+
+	// Resources; the sizes take the docker units (512m, 1g...)
+	MemLimit       string            `yaml:"mem_limit,omitempty" json:"mem_limit,omitempty"`
+	MemswapLimit   string            `yaml:"memswap_limit,omitempty" json:"memswap_limit,omitempty"`
+	MemReservation string            `yaml:"mem_reservation,omitempty" json:"mem_reservation,omitempty"`
+	Cpus           float64           `yaml:"cpus,omitempty" json:"cpus,omitempty"`
+	CPUShares      int64             `yaml:"cpu_shares,omitempty" json:"cpu_shares,omitempty"`
+	Cpuset         string            `yaml:"cpuset,omitempty" json:"cpuset,omitempty"`
+	PidsLimit      int64             `yaml:"pids_limit,omitempty" json:"pids_limit,omitempty"`
+	Ulimits        map[string]ulimit `yaml:"ulimits,omitempty" json:"ulimits,omitempty"`
+
+	Extra map[string]any `yaml:",inline" json:"-"` // keys we do not support, reported by Load()
+}
+
+// Build is the build section of a service; the short syntax is only the context
+type Build struct {
+	Context    string    `yaml:"context,omitempty" json:"context,omitempty"`
+	Dockerfile string    `yaml:"dockerfile,omitempty" json:"dockerfile,omitempty"`
+	Args       listOrMap `yaml:"args,omitempty" json:"args,omitempty"`
+	Target     string    `yaml:"target,omitempty" json:"target,omitempty"`
+}
+
+// Healthcheck overrides the healthcheck of the image
+type Healthcheck struct {
+	Test        healthTest `yaml:"test,omitempty" json:"test,omitempty"`
+	Interval    string     `yaml:"interval,omitempty" json:"interval,omitempty"`
+	Timeout     string     `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	Retries     int        `yaml:"retries,omitempty" json:"retries,omitempty"`
+	StartPeriod string     `yaml:"start_period,omitempty" json:"start_period,omitempty"`
+	Disable     bool       `yaml:"disable,omitempty" json:"disable,omitempty"`
+}
+
+// ServiceNetwork is a network a service is attached to
+type ServiceNetwork struct {
+	Aliases     []string `yaml:"aliases,omitempty" json:"aliases,omitempty"`
+	IPv4Address string   `yaml:"ipv4_address,omitempty" json:"ipv4_address,omitempty"`
+	IPv6Address string   `yaml:"ipv6_address,omitempty" json:"ipv6_address,omitempty"`
+}
+
+// Network is a top-level network; Name is the one on the daemon (project_key unless set or external)
+type Network struct {
+	Name       string            `yaml:"name,omitempty"`
+	Driver     string            `yaml:"driver,omitempty"`
+	DriverOpts map[string]string `yaml:"driver_opts,omitempty"`
+	External   bool              `yaml:"external,omitempty"`
+	Internal   bool              `yaml:"internal,omitempty"`
+	Attachable bool              `yaml:"attachable,omitempty"`
+	EnableIPv6 bool              `yaml:"enable_ipv6,omitempty"`
+	IPAM       *IPAM             `yaml:"ipam,omitempty"`
+	Labels     listOrMap         `yaml:"labels,omitempty"`
+}
+
+// IPAM is the addressing of a network; static addresses need a subnet
+type IPAM struct {
+	Driver string       `yaml:"driver,omitempty"`
+	Config []IPAMConfig `yaml:"config,omitempty"`
+}
+
+type IPAMConfig struct {
+	Subnet  string `yaml:"subnet,omitempty"`
+	IPRange string `yaml:"ip_range,omitempty"`
+	Gateway string `yaml:"gateway,omitempty"`
+}
+
+// Volume is a top-level volume; Name is the one on the daemon (project_key unless set or external)
+type Volume struct {
+	Name       string            `yaml:"name,omitempty"`
+	Driver     string            `yaml:"driver,omitempty"`
+	DriverOpts map[string]string `yaml:"driver_opts,omitempty"`
+	External   bool              `yaml:"external,omitempty"`
+	Labels     listOrMap         `yaml:"labels,omitempty"`
+}
+
+// LoadOptions controls Load()
+type LoadOptions struct {
+	ProjectName string // -p: overrides the name: key and the directory name
+	EnvFile     string // the variables used for the interpolation; .env in the project directory when empty
+}
+
+// UpOptions controls Up()
+type UpOptions struct {
+	Services      []string // only these services (and their dependencies); all when empty
+	Build         bool     // --build: build the images even if present
+	ForceRecreate bool     // --force-recreate: recreate the containers even if their configuration did not change
+	NoStart       bool     // --no-start: create the containers, do not start them
+	RemoveOrphans bool     // --remove-orphans: remove the containers of services no longer in the file
+	OnEvent       extras.EventFunc
+
+	// Where the pull and build progress goes; nil discards it.
+	Progress io.Writer
+}
+
+// DownOptions controls Down()
+type DownOptions struct {
+	Volumes bool // -v: also remove the named volumes declared in the file, and the anonymous ones
+	Timeout int  // -t: seconds to wait for the containers to stop
+	OnEvent extras.EventFunc
+}
+
+// LogsOptions controls Logs(); each line is prefixed with the container name
+type LogsOptions struct {
+	Services []string // only these services; all when empty
+	extras.LogOptions
+	NoPrefix bool // --no-log-prefix
+}
+
+// ExportOptions controls Export()
+type ExportOptions struct {
+	Containers  []string // the containers to export, by name or ID
+	All         bool     // --all: every container, stopped ones included
+	ProjectName string   // -p: written as the name: key when set
+}
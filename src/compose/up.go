@@ -0,0 +1,335 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 01:55
+// Original filename: src/compose/up.go
+
+package compose
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"dtools2/build"
+	"dtools2/containers"
+	"dtools2/extras"
+	"dtools2/networks"
+	"dtools2/rest"
+	"dtools2/run"
+	"dtools2/volumes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+)
+
+// Up reconciles the project with the daemon: the networks and volumes are created if missing, then each
+// service, dependencies first, gets its image built (or pulled) and its container created and started.
+// A container whose configuration did not change is left alone; the others are recreated.
+func Up(ctx context.Context, client *rest.Client, p *Project, opts UpOptions) *ce.CustomError {
+	services, cerr := p.selectServices(opts.Services)
+	if cerr != nil {
+		return cerr
+	}
+
+	existing, cerr := projectContainers(ctx, client, p.Name, nil)
+	if cerr != nil {
+		return cerr
+	}
+	if cerr := p.handleOrphans(ctx, client, existing, opts); cerr != nil {
+		return cerr
+	}
+
+	if cerr := p.createNetworks(ctx, client, services, opts.OnEvent); cerr != nil {
+		return cerr
+	}
+	if cerr := p.createVolumes(ctx, client, services, opts.OnEvent); cerr != nil {
+		return cerr
+	}
+
+	for _, name := range services {
+		svc := p.Services[name]
+		if !opts.NoStart {
+			if cerr := p.waitDependencies(ctx, client, svc); cerr != nil {
+				return cerr
+			}
+		}
+		if cerr := p.upService(ctx, client, svc, existing, opts); cerr != nil {
+			return cerr
+		}
+	}
+	return nil
+}
+
+// selectServices returns the requested services and their dependencies, in dependency order
+func (p *Project) selectServices(requested []string) ([]string, *ce.CustomError) {
+	if len(requested) == 0 {
+		return p.Order, nil
+	}
+
+	wanted := map[string]bool{}
+	var add func(name string)
+	add = func(name string) {
+		if wanted[name] {
+			return
+		}
+		wanted[name] = true
+		for dep := range p.Services[name].DependsOn {
+			add(dep)
+		}
+	}
+	for _, name := range requested {
+		if p.Services[name] == nil {
+			return nil, &ce.CustomError{Title: "No such service", Message: name + " is not a service of project " + p.Name}
+		}
+		add(name)
+	}
+
+	var selected []string
+	for _, name := range p.Order {
+		if wanted[name] {
+			selected = append(selected, name)
+		}
+	}
+	return selected, nil
+}
+
+// handleOrphans removes (or reports) the containers of services that are no longer in the file
+func (p *Project) handleOrphans(ctx context.Context, client *rest.Client, existing []containers.ContainerSummary, opts UpOptions) *ce.CustomError {
+	for _, c := range existing {
+		if p.Services[c.Labels[LabelService]] != nil {
+			continue
+		}
+		name := c.Names[0][1:]
+		if !opts.RemoveOrphans {
+			opts.OnEvent.Emit(extras.Event{Resource: "container", Name: name, Action: extras.EventSkipped,
+				Message: "orphan container, its service is not in the file (use --remove-orphans)"})
+			continue
+		}
+		if _, cerr := containers.RemoveContainer(ctx, client, []string{name}, containers.RemoveOptions{Force: true, OnEvent: opts.OnEvent}); cerr != nil {
+			return cerr
+		}
+	}
+	return nil
+}
+
+// createNetworks creates the missing networks used by the services; an external network must already exist
+func (p *Project) createNetworks(ctx context.Context, client *rest.Client, services []string, onEvent extras.EventFunc) *ce.CustomError {
+	present, cerr := networks.NetworkList(ctx, client, networks.ListOptions{})
+	if cerr != nil {
+		return cerr
+	}
+
+	for _, key := range p.usedKeys(services, func(s *Service) []string { return slices.Sorted(maps.Keys(s.Networks)) }) {
+		n := p.Networks[key]
+		if slices.ContainsFunc(present, func(ns networks.NetworkSummary) bool { return ns.Name == n.Name }) {
+			continue
+		}
+		if n.External {
+			return &ce.CustomError{Title: "Missing network", Message: "external network " + n.Name + " not found"}
+		}
+
+		labels := keyValues(n.Labels)
+		labels[LabelProject] = p.Name
+		labels[LabelNetwork] = key
+		req := networks.NetworkCreateRequest{Name: n.Name, Driver: n.Driver, Internal: n.Internal, Attachable: n.Attachable,
+			EnableIPv6: n.EnableIPv6, Options: n.DriverOpts, Labels: labels}
+		if n.IPAM != nil {
+			req.IPAM = &networks.IPAMSummary{Driver: cmp.Or(n.IPAM.Driver, "default")}
+			for _, c := range n.IPAM.Config {
+				req.IPAM.Config = append(req.IPAM.Config, networks.IPAMConfig{Subnet: c.Subnet, IPRange: c.IPRange, Gateway: c.Gateway})
+			}
+		}
+		if _, cerr := networks.AddNetwork(ctx, client, req); cerr != nil {
+			return cerr
+		}
+		onEvent.Emit(extras.Event{Resource: "network", Name: n.Name, Action: extras.EventCreated})
+	}
+	return nil
+}
+
+// createVolumes creates the missing named volumes used by the services; an external volume must already exist
+func (p *Project) createVolumes(ctx context.Context, client *rest.Client, services []string, onEvent extras.EventFunc) *ce.CustomError {
+	present, cerr := volumes.ListVolumes(ctx, client, volumes.ListOptions{})
+	if cerr != nil {
+		return cerr
+	}
+
+	used := p.usedKeys(services, func(s *Service) []string {
+		var keys []string
+		for _, v := range s.Volumes {
+			if v.Type == "volume" && v.Source != "" {
+				keys = append(keys, v.Source)
+			}
+		}
+		return keys
+	})
+	for _, key := range used {
+		v := p.Volumes[key]
+		if slices.ContainsFunc(present, func(vs volumes.Volume) bool { return vs.Name == v.Name }) {
+			continue
+		}
+		if v.External {
+			return &ce.CustomError{Title: "Missing volume", Message: "external volume " + v.Name + " not found"}
+		}
+
+		labels := keyValues(v.Labels)
+		labels[LabelProject] = p.Name
+		labels[LabelVolume] = key
+		if cerr := volumes.CreateVolume(ctx, client, volumes.VolumeCreateOptions{Name: v.Name, Driver: v.Driver, DriverOpts: v.DriverOpts, Labels: labels}); cerr != nil {
+			return cerr
+		}
+		onEvent.Emit(extras.Event{Resource: "volume", Name: v.Name, Action: extras.EventCreated})
+	}
+	return nil
+}
+
+// usedKeys collects the sorted, unique keys returned by keys() for the services
+func (p *Project) usedKeys(services []string, keys func(*Service) []string) []string {
+	var used []string
+	for _, name := range services {
+		for _, k := range keys(p.Services[name]) {
+			if !slices.Contains(used, k) {
+				used = append(used, k)
+			}
+		}
+	}
+	slices.Sort(used)
+	return used
+}
+
+// waitDependencies waits for the conditions of depends_on: a healthy container, or one that exited with 0
+func (p *Project) waitDependencies(ctx context.Context, client *rest.Client, svc *Service) *ce.CustomError {
+	for _, dep := range slices.Sorted(maps.Keys(svc.DependsOn)) {
+		name := p.ContainerName(p.Services[dep])
+
+		switch svc.DependsOn[dep] {
+		case ConditionHealthy:
+			if cerr := containers.WaitHealthy(ctx, client, name); cerr != nil {
+				return cerr
+			}
+		case ConditionCompleted:
+			code, cerr := run.WaitContainer(ctx, client, name, run.WaitNotRunning)
+			if cerr != nil {
+				return cerr
+			}
+			if code != 0 {
+				return &ce.CustomError{Title: "Dependency failed", Message: fmt.Sprintf("%s (needed by %s) exited with code %d", name, svc.Name, code)}
+			}
+		}
+	}
+	return nil
+}
+
+// upService brings one service to its desired state
+func (p *Project) upService(ctx context.Context, client *rest.Client, svc *Service, existing []containers.ContainerSummary, opts UpOptions) *ce.CustomError {
+	name := p.ContainerName(svc)
+	image := p.ImageName(svc)
+
+	if svc.Build != nil {
+		present, cerr := imageExists(ctx, client, image)
+		if cerr != nil {
+			return cerr
+		}
+		if opts.Build || !present {
+			bo := build.Options{Dockerfile: svc.Build.Dockerfile, Tags: []string{image}, BuildArgs: svc.Build.Args,
+				Target: svc.Build.Target, RemoveIntermediate: true, Out: opts.Progress}
+			if err := build.BuildImage(ctx, client, svc.Build.Context, bo); err != nil {
+				return &ce.CustomError{Title: "Unable to build service " + svc.Name, Message: err.Error()}
+			}
+			opts.OnEvent.Emit(extras.Event{Resource: "image", Name: image, Action: extras.EventCreated, Message: "built"})
+		}
+	}
+
+	hash, cerr := configHash(svc)
+	if cerr != nil {
+		return cerr
+	}
+	imageID, _ := imageIDOf(ctx, client, image)
+
+	var current *containers.ContainerSummary
+	for i, c := range existing {
+		if c.Names[0][1:] == name {
+			current = &existing[i]
+		}
+	}
+	if current != nil {
+		upToDate := current.Labels[LabelConfigHash] == hash && (imageID == "" || current.ImageID == imageID)
+		if upToDate && !opts.ForceRecreate {
+			opts.OnEvent.Emit(extras.Event{Resource: "container", Name: name, Action: extras.EventSkipped, Message: "up to date"})
+			if opts.NoStart || current.State == "running" {
+				return nil
+			}
+			return containers.StartContainers(ctx, client, []string{name}, containers.StartOptions{OnEvent: opts.OnEvent})
+		}
+		if cerr := recreateCleanup(ctx, client, current, opts.OnEvent); cerr != nil {
+			return cerr
+		}
+	}
+
+	ro, cmd, cerr := p.runOptions(svc, hash)
+	if cerr != nil {
+		return cerr
+	}
+	ro.PullOutput = opts.Progress
+	if _, cerr := run.CreateContainer(ctx, client, image, cmd, ro); cerr != nil {
+		return &ce.CustomError{Title: "Unable to create service " + svc.Name, Message: cerr.Error()}
+	}
+	opts.OnEvent.Emit(extras.Event{Resource: "container", Name: name, Action: extras.EventCreated})
+
+	if opts.NoStart {
+		return nil
+	}
+	return containers.StartContainers(ctx, client, []string{name}, containers.StartOptions{OnEvent: opts.OnEvent})
+}
+
+// recreateCleanup stops then removes the outdated container of a service
+func recreateCleanup(ctx context.Context, client *rest.Client, c *containers.ContainerSummary, onEvent extras.EventFunc) *ce.CustomError {
+	name := c.Names[0][1:]
+	if c.State == "running" || c.State == "paused" {
+		if cerr := containers.StopContainers(ctx, client, []string{name}, containers.StopOptions{OnEvent: onEvent}); cerr != nil {
+			return cerr
+		}
+	}
+	_, cerr := containers.RemoveContainer(ctx, client, []string{name}, containers.RemoveOptions{Force: true, OnEvent: onEvent})
+	return cerr
+}
+
+// ContainerName is container_name, or project-service-1
+func (p *Project) ContainerName(svc *Service) string {
+	if svc.ContainerName != "" {
+		return svc.ContainerName
+	}
+	return p.Name + "-" + svc.Name + "-1"
+}
+
+// ImageName is image; a service that is only built gets project-service
+func (p *Project) ImageName(svc *Service) string {
+	if svc.Image != "" {
+		return svc.Image
+	}
+	return p.Name + "-" + svc.Name
+}
+
+// configHash fingerprints the service definition; a change means the container must be recreated
+func configHash(svc *Service) (string, *ce.CustomError) {
+	data, err := json.Marshal(svc)
+	if err != nil {
+		return "", &ce.CustomError{Title: "Unable to marshal the service", Message: err.Error()}
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// keyValues turns KEY=value strings into a map; the map is never nil
+func keyValues(items []string) map[string]string {
+	m := make(map[string]string, len(items))
+	for _, item := range items {
+		k, v, _ := strings.Cut(item, "=")
+		m[k] = v
+	}
+	return m
+}
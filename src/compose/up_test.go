@@ -0,0 +1,112 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 09:00
+// Original filename: src/compose/up_test.go
+
+package compose
+
+import (
+	"context"
+	"dtools2/extras"
+	"dtools2/rest/fakedaemon"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestUp(t *testing.T) {
+	d, client := fakedaemon.StartT(t)
+	ctx := context.Background()
+	d.AddImage(fakedaemon.Image{RepoTags: []string{"postgres:16"}})
+	d.AddImage(fakedaemon.Image{RepoTags: []string{"nginx:1.27"}})
+	d.AddContainer(fakedaemon.Container{Name: "demo-old-1", Image: "nginx:1.27", State: fakedaemon.StateRunning,
+		Labels: map[string]string{LabelProject: "demo", LabelService: "old"}})
+
+	dir := writeProject(t, `
+services:
+  app:
+    image: nginx:1.27
+    depends_on: [db]
+    environment:
+      MODE: ${COMPOSE_TEST_MODE}
+  db:
+    image: postgres:16
+    volumes: [data:/var/lib/postgresql/data]
+volumes:
+  data:
+`, "")
+	load := func(mode string) *Project {
+		t.Helper()
+		t.Setenv("COMPOSE_TEST_MODE", mode)
+		p, cerr := Load(filepath.Join(dir, "compose.yaml"), LoadOptions{ProjectName: "demo"})
+		if cerr != nil {
+			t.Fatal(cerr)
+		}
+		return p
+	}
+	var events []extras.Event
+	up := func(p *Project) []string {
+		t.Helper()
+		before := len(d.Requests())
+		events = nil
+		opts := UpOptions{OnEvent: func(e extras.Event) { events = append(events, e) }}
+		if cerr := Up(ctx, client, p, opts); cerr != nil {
+			t.Fatal(cerr)
+		}
+		return d.Requests()[before:]
+	}
+
+	requests := up(load("one"))
+
+	// db is created and started before app, on the project network and volume
+	var order []string
+	for _, r := range requests {
+		switch r {
+		case "POST /networks/create", "POST /volumes/create", "POST /containers/create",
+			"POST /containers/demo-db-1/start", "POST /containers/demo-app-1/start":
+			order = append(order, r)
+		}
+	}
+	want := []string{"POST /networks/create", "POST /volumes/create",
+		"POST /containers/create", "POST /containers/demo-db-1/start",
+		"POST /containers/create", "POST /containers/demo-app-1/start"}
+	if !slices.Equal(order, want) {
+		t.Errorf("requests %q, want %q", order, want)
+	}
+	if !slices.ContainsFunc(d.Networks(), func(n fakedaemon.Network) bool { return n.Name == "demo_default" }) {
+		t.Error("network demo_default was not created")
+	}
+	if !slices.ContainsFunc(d.Volumes(), func(v fakedaemon.Volume) bool { return v.Name == "demo_data" }) {
+		t.Error("volume demo_data was not created")
+	}
+	for _, name := range []string{"demo-app-1", "demo-db-1", "demo-old-1"} {
+		c, ok := d.Container(name)
+		if !ok || c.State != fakedaemon.StateRunning {
+			t.Errorf("%s: found %v, state %q", name, ok, c.State)
+		}
+	}
+	if c, _ := d.Container("demo-app-1"); c.Labels[LabelService] != "app" || !slices.Contains(c.Env, "MODE=one") {
+		t.Errorf("app has labels %v and env %q", c.Labels, c.Env)
+	}
+	// Without --remove-orphans, the container of a service gone from the file is only reported
+	if !slices.Contains(events, extras.Event{Resource: "container", Name: "demo-old-1", Action: extras.EventSkipped,
+		Message: "orphan container, its service is not in the file (use --remove-orphans)"}) {
+		t.Errorf("events %+v, want the orphan reported", events)
+	}
+
+	// Nothing changed: nothing is created again
+	if requests := up(load("one")); slices.Contains(requests, "POST /containers/create") {
+		t.Errorf("an up to date project was recreated: %q", requests)
+	}
+
+	// Only the changed service is recreated
+	app, _ := d.Container("demo-app-1")
+	db, _ := d.Container("demo-db-1")
+	up(load("two"))
+	if c, ok := d.Container("demo-app-1"); !ok || c.ID == app.ID || !slices.Contains(c.Env, "MODE=two") {
+		t.Errorf("app was not recreated with the new environment: %+v", c)
+	}
+	if c, _ := d.Container("demo-db-1"); c.ID != db.ID {
+		t.Error("db was recreated though it did not change")
+	}
+}
@@ -0,0 +1,375 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 01:10
+// Original filename: src/compose/yamltypes.go
+
+package compose
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The compose spec accepts several syntaxes for most keys; these types read them all into a single form.
+
+// stringOrList: "a" or [a, b]
+type stringOrList []string
+
+func (s *stringOrList) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		*s = stringOrList{n.Value}
+		return nil
+	}
+	var l []string
+	if err := n.Decode(&l); err != nil {
+		return err
+	}
+	*s = l
+	return nil
+}
+
+// shellCommand: a string, split as a shell would, or a list used verbatim
+type shellCommand []string
+
+func (c *shellCommand) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		words, err := splitShellWords(n.Value)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", n.Line, err)
+		}
+		*c = words
+		return nil
+	}
+	var l []string
+	if err := n.Decode(&l); err != nil {
+		return err
+	}
+	*c = l
+	return nil
+}
+
+// healthTest: a string is run with the shell (CMD-SHELL); a list starts with CMD, CMD-SHELL or NONE
+type healthTest []string
+
+func (t *healthTest) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		*t = healthTest{"CMD-SHELL", n.Value}
+		return nil
+	}
+	var l []string
+	if err := n.Decode(&l); err != nil {
+		return err
+	}
+	if len(l) > 0 && l[0] != "CMD" && l[0] != "CMD-SHELL" && l[0] != "NONE" {
+		return fmt.Errorf("line %d: a healthcheck test list must start with CMD, CMD-SHELL or NONE", n.Line)
+	}
+	*t = l
+	return nil
+}
+
+// listOrMap: [KEY=value, KEY] or {KEY: value}; read as KEY=value strings, a null value giving a bare KEY
+type listOrMap []string
+
+func (l *listOrMap) UnmarshalYAML(n *yaml.Node) error {
+	switch n.Kind {
+	case yaml.SequenceNode:
+		var items []string
+		if err := n.Decode(&items); err != nil {
+			return err
+		}
+		*l = items
+	case yaml.MappingNode:
+		var items []string
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			if v.Tag == "!!null" {
+				items = append(items, k.Value)
+			} else {
+				items = append(items, k.Value+"="+v.Value)
+			}
+		}
+		*l = items
+	default:
+		return fmt.Errorf("line %d: expected a list or a mapping", n.Line)
+	}
+	return nil
+}
+
+// portSpec: "8080:80", 80, or the long syntax {target, published, host_ip, protocol}; read as a -p spec
+type portSpec string
+
+func (p *portSpec) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		*p = portSpec(n.Value)
+		return nil
+	}
+	var long struct {
+		Target    int    `yaml:"target"`
+		Published string `yaml:"published"`
+		HostIP    string `yaml:"host_ip"`
+		Protocol  string `yaml:"protocol"`
+	}
+	if err := n.Decode(&long); err != nil {
+		return err
+	}
+	if long.Target == 0 {
+		return fmt.Errorf("line %d: a port needs a target", n.Line)
+	}
+	spec := strconv.Itoa(long.Target)
+	if long.Published != "" || long.HostIP != "" {
+		spec = long.Published + ":" + spec
+	}
+	if long.HostIP != "" {
+		// -p wants IPv6 addresses bracketed
+		if strings.Contains(long.HostIP, ":") {
+			long.HostIP = "[" + strings.Trim(long.HostIP, "[]") + "]"
+		}
+		spec = long.HostIP + ":" + spec
+	}
+	if long.Protocol != "" {
+		spec += "/" + long.Protocol
+	}
+	*p = portSpec(spec)
+	return nil
+}
+
+// serviceVolume: "source:target[:mode]", "target", or the long syntax {type, source, target, read_only}
+type serviceVolume struct {
+	Type     string `yaml:"type" json:"type"` // bind, volume or tmpfs
+	Source   string `yaml:"source" json:"source,omitempty"`
+	Target   string `yaml:"target" json:"target"`
+	ReadOnly bool   `yaml:"read_only" json:"read_only,omitempty"`
+	Mode     string `yaml:"-" json:"mode,omitempty"` // what follows the target in the short syntax (ro, z...)
+}
+
+func (v *serviceVolume) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind != yaml.ScalarNode {
+		type plain serviceVolume
+		var long plain
+		if err := n.Decode(&long); err != nil {
+			return err
+		}
+		*v = serviceVolume(long)
+		if v.Type == "" {
+			v.Type = "volume"
+		}
+		if v.Target == "" {
+			return fmt.Errorf("line %d: a volume needs a target", n.Line)
+		}
+		return nil
+	}
+
+	parts := strings.Split(n.Value, ":")
+	switch len(parts) {
+	case 1:
+		*v = serviceVolume{Type: "volume", Target: parts[0]}
+	case 2, 3:
+		*v = serviceVolume{Type: "volume", Source: parts[0], Target: parts[1]}
+		if len(parts) == 3 {
+			v.Mode = parts[2]
+			v.ReadOnly = strings.Contains(","+parts[2]+",", ",ro,")
+		}
+		if isHostPath(parts[0]) {
+			v.Type = "bind"
+		}
+	default:
+		return fmt.Errorf("line %d: invalid volume %q", n.Line, n.Value)
+	}
+	return nil
+}
+
+// MarshalYAML writes the short syntax back, except for tmpfs which has none
+func (v serviceVolume) MarshalYAML() (any, error) {
+	if v.Type == "tmpfs" {
+		return map[string]string{"type": v.Type, "target": v.Target}, nil
+	}
+	spec := v.Target
+	if v.Source != "" {
+		spec = v.Source + ":" + spec
+	}
+	if mode := v.Mode; mode != "" || v.ReadOnly {
+		if mode == "" {
+			mode = "ro"
+		}
+		spec += ":" + mode
+	}
+	return spec, nil
+}
+
+func isHostPath(s string) bool {
+	return strings.HasPrefix(s, "/") || strings.HasPrefix(s, ".") || strings.HasPrefix(s, "~")
+}
+
+// serviceNetworks: [front, back] or {front: {aliases: [...]}, back: null}
+type serviceNetworks map[string]*ServiceNetwork
+
+func (s *serviceNetworks) UnmarshalYAML(n *yaml.Node) error {
+	nets := serviceNetworks{}
+	if n.Kind == yaml.SequenceNode {
+		var names []string
+		if err := n.Decode(&names); err != nil {
+			return err
+		}
+		for _, name := range names {
+			nets[name] = &ServiceNetwork{}
+		}
+	} else {
+		var m map[string]*ServiceNetwork
+		if err := n.Decode(&m); err != nil {
+			return err
+		}
+		for name, sn := range m {
+			if sn == nil {
+				sn = &ServiceNetwork{}
+			}
+			nets[name] = sn
+		}
+	}
+	*s = nets
+	return nil
+}
+
+// dependsOn: [db] or {db: {condition: service_healthy}}; read as service => condition
+type dependsOn map[string]string
+
+func (d *dependsOn) UnmarshalYAML(n *yaml.Node) error {
+	deps := dependsOn{}
+	if n.Kind == yaml.SequenceNode {
+		var names []string
+		if err := n.Decode(&names); err != nil {
+			return err
+		}
+		for _, name := range names {
+			deps[name] = ConditionStarted
+		}
+	} else {
+		var m map[string]struct {
+			Condition string `yaml:"condition"`
+		}
+		if err := n.Decode(&m); err != nil {
+			return err
+		}
+		for name, dep := range m {
+			switch dep.Condition {
+			case "":
+				dep.Condition = ConditionStarted
+			case ConditionStarted, ConditionHealthy, ConditionCompleted:
+			default:
+				return fmt.Errorf("line %d: unsupported depends_on condition %q", n.Line, dep.Condition)
+			}
+			deps[name] = dep.Condition
+		}
+	}
+	*d = deps
+	return nil
+}
+
+// MarshalYAML writes the short syntax when every condition is service_started
+func (d dependsOn) MarshalYAML() (any, error) {
+	names := slices.Sorted(maps.Keys(d))
+	if !slices.ContainsFunc(names, func(name string) bool { return d[name] != ConditionStarted }) {
+		return names, nil
+	}
+	long := map[string]map[string]string{}
+	for _, name := range names {
+		long[name] = map[string]string{"condition": d[name]}
+	}
+	return long, nil
+}
+
+// ulimit: 65535, or {soft: 1024, hard: 65535}
+type ulimit struct {
+	Soft int64 `yaml:"soft" json:"soft"`
+	Hard int64 `yaml:"hard" json:"hard"`
+}
+
+func (u *ulimit) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		v, err := strconv.ParseInt(n.Value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid ulimit %q", n.Line, n.Value)
+		}
+		*u = ulimit{Soft: v, Hard: v}
+		return nil
+	}
+	type plain ulimit
+	var long plain
+	if err := n.Decode(&long); err != nil {
+		return err
+	}
+	*u = ulimit(long)
+	return nil
+}
+
+// MarshalYAML writes a single number when both limits are the same
+func (u ulimit) MarshalYAML() (any, error) {
+	if u.Soft == u.Hard {
+		return u.Soft, nil
+	}
+	type plain ulimit
+	return plain(u), nil
+}
+
+// splitShellWords splits a command line on blanks, honouring single quotes, double quotes and backslashes
+func splitShellWords(s string) ([]string, error) {
+	var words []string
+	var cur strings.Builder
+	inWord, quote, escaped := false, rune(0), false
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			cur.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+		default:
+			cur.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape in %q", s)
+	}
+	if inWord {
+		words = append(words, cur.String())
+	}
+	return words, nil
+}
+
+// The short syntax of build is only the context directory
+func (b *Build) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		*b = Build{Context: n.Value}
+		return nil
+	}
+	type plain Build
+	var long plain
+	if err := n.Decode(&long); err != nil {
+		return err
+	}
+	*b = Build(long)
+	if b.Context == "" {
+		b.Context = "."
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 08:10
+// Original filename: src/compose/yamltypes_test.go
+
+package compose
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestPortSpec(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{`"8080:80"`, "8080:80"},
+		{`80`, "80"},
+		{`"127.0.0.1:53:53/udp"`, "127.0.0.1:53:53/udp"},
+		{`{target: 80}`, "80"},
+		{`{target: 80, published: "8080", protocol: udp}`, "8080:80/udp"},
+		{`{target: 80, published: "8080", host_ip: 127.0.0.1}`, "127.0.0.1:8080:80"},
+		{`{target: 443, published: "8443", host_ip: "::1"}`, "[::1]:8443:443"},
+		{`{target: 80, host_ip: 127.0.0.1}`, "127.0.0.1::80"},
+	} {
+		var p portSpec
+		if err := yaml.Unmarshal([]byte(tc.in), &p); err != nil {
+			t.Errorf("%s: %v", tc.in, err)
+			continue
+		}
+		if string(p) != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.in, p, tc.want)
+		}
+	}
+
+	var p portSpec
+	if err := yaml.Unmarshal([]byte(`{published: "8080"}`), &p); err == nil {
+		t.Errorf("a port without a target was accepted as %q", p)
+	}
+}
+
+func TestServiceVolume(t *testing.T) {
+	for _, tc := range []struct {
+		in    string
+		want  serviceVolume
+		short string // as written back by MarshalYAML
+	}{
+		{`/data`, serviceVolume{Type: "volume", Target: "/data"}, "/data"},
+		{`db:/var/lib/db`, serviceVolume{Type: "volume", Source: "db", Target: "/var/lib/db"}, "db:/var/lib/db"},
+		{`./conf:/etc/app:ro`, serviceVolume{Type: "bind", Source: "./conf", Target: "/etc/app", ReadOnly: true, Mode: "ro"}, "./conf:/etc/app:ro"},
+		{`/srv:/srv:z`, serviceVolume{Type: "bind", Source: "/srv", Target: "/srv", Mode: "z"}, "/srv:/srv:z"},
+		{`~/cache:/cache:ro,z`, serviceVolume{Type: "bind", Source: "~/cache", Target: "/cache", ReadOnly: true, Mode: "ro,z"}, "~/cache:/cache:ro,z"},
+		{`{source: db, target: /data, read_only: true}`, serviceVolume{Type: "volume", Source: "db", Target: "/data", ReadOnly: true}, "db:/data:ro"},
+		{`{type: bind, source: /etc, target: /host}`, serviceVolume{Type: "bind", Source: "/etc", Target: "/host"}, "/etc:/host"},
+	} {
+		var v serviceVolume
+		if err := yaml.Unmarshal([]byte(tc.in), &v); err != nil {
+			t.Errorf("%s: %v", tc.in, err)
+			continue
+		}
+		if v != tc.want {
+			t.Errorf("%s: got %+v, want %+v", tc.in, v, tc.want)
+		}
+		out, err := yaml.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.TrimSpace(string(out)); got != tc.short {
+			t.Errorf("%s: marshalled as %q, want %q", tc.in, got, tc.short)
+		}
+	}
+
+	for _, in := range []string{`a:b:c:d`, `{source: db}`} {
+		var v serviceVolume
+		if err := yaml.Unmarshal([]byte(in), &v); err == nil {
+			t.Errorf("%s: accepted as %+v", in, v)
+		}
+	}
+
+	// tmpfs has no short syntax
+	out, err := yaml.Marshal(serviceVolume{Type: "tmpfs", Target: "/run"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(out); !strings.Contains(got, "type: tmpfs") || !strings.Contains(got, "target: /run") {
+		t.Errorf("tmpfs marshalled as %q", got)
+	}
+}
+
+func TestDependsOn(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want dependsOn
+	}{
+		{`[db, cache]`, dependsOn{"db": ConditionStarted, "cache": ConditionStarted}},
+		{`{db: {condition: service_healthy}, cache: {}}`, dependsOn{"db": ConditionHealthy, "cache": ConditionStarted}},
+		{`{init: {condition: service_completed_successfully}}`, dependsOn{"init": ConditionCompleted}},
+	} {
+		var d dependsOn
+		if err := yaml.Unmarshal([]byte(tc.in), &d); err != nil {
+			t.Errorf("%s: %v", tc.in, err)
+			continue
+		}
+		if !maps.Equal(d, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.in, d, tc.want)
+		}
+
+		// Written back, it reads the same
+		out, err := yaml.Marshal(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var again dependsOn
+		if err := yaml.Unmarshal(out, &again); err != nil {
+			t.Fatalf("%s: reading back %q: %v", tc.in, out, err)
+		}
+		if !maps.Equal(again, tc.want) {
+			t.Errorf("%s: round trip through %q gave %v", tc.in, out, again)
+		}
+	}
+
+	out, err := yaml.Marshal(dependsOn{"db": ConditionStarted, "cache": ConditionStarted})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(out); got != "- cache\n- db\n" {
+		t.Errorf("started conditions marshalled as %q, want the short syntax", got)
+	}
+
+	var d dependsOn
+	if err := yaml.Unmarshal([]byte(`{db: {condition: service_ready}}`), &d); err == nil {
+		t.Errorf("an unknown condition was accepted")
+	}
+}
+
+func TestShellWords(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want []string
+	}{
+		{`echo hi`, []string{"echo", "hi"}},
+		{`  sh  -c	'echo "a b"'  `, []string{"sh", "-c", `echo "a b"`}},
+		{`echo "it's" 'a\b'`, []string{"echo", "it's", `a\b`}},
+		{`a\ b "c\"d" ''`, []string{"a b", `c"d`, ""}},
+		{``, nil},
+	} {
+		got, err := splitShellWords(tc.in)
+		if err != nil {
+			t.Errorf("%s: %v", tc.in, err)
+			continue
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("%s: got %q, want %q", tc.in, got, tc.want)
+		}
+	}
+
+	for _, in := range []string{`echo 'hi`, `echo "hi`, `echo hi\`} {
+		if got, err := splitShellWords(in); err == nil {
+			t.Errorf("%s: accepted as %q", in, got)
+		}
+	}
+}
+
+func TestCommandsAndLists(t *testing.T) {
+	var svc struct {
+		Command     shellCommand `yaml:"command"`
+		Entrypoint  shellCommand `yaml:"entrypoint"`
+		DNS         stringOrList `yaml:"dns"`
+		EnvFile     stringOrList `yaml:"env_file"`
+		Environment listOrMap    `yaml:"environment"`
+		Labels      listOrMap    `yaml:"labels"`
+		Test        healthTest   `yaml:"test"`
+	}
+	in := `
+command: sh -c "echo hi"
+entrypoint: ["/bin/tini", "--"]
+dns: 1.1.1.1
+env_file: [a.env, b.env]
+environment: {A: 1, B: null, C: "x y"}
+labels: [tier=web, bare]
+test: curl -f http://localhost
+`
+	if err := yaml.Unmarshal([]byte(in), &svc); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name      string
+		got, want []string
+	}{
+		{"command", svc.Command, []string{"sh", "-c", "echo hi"}},
+		{"entrypoint", svc.Entrypoint, []string{"/bin/tini", "--"}},
+		{"dns", svc.DNS, []string{"1.1.1.1"}},
+		{"env_file", svc.EnvFile, []string{"a.env", "b.env"}},
+		{"environment", svc.Environment, []string{"A=1", "B", "C=x y"}},
+		{"labels", svc.Labels, []string{"tier=web", "bare"}},
+		{"test", svc.Test, []string{"CMD-SHELL", "curl -f http://localhost"}},
+	} {
+		if !slices.Equal(tc.got, tc.want) {
+			t.Errorf("%s: got %q, want %q", tc.name, tc.got, tc.want)
+		}
+	}
+
+	var test healthTest
+	if err := yaml.Unmarshal([]byte(`[curl, -f, http://localhost]`), &test); err == nil {
+		t.Errorf("a healthcheck list without CMD was accepted as %q", test)
+	}
+}
@@ -0,0 +1,317 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 05:10
+// Original filename: src/containers/dependencies.go
+
+package containers
+
+import (
+	"context"
+	"dtools2/rest"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+)
+
+// Labels read to find what a container depends on
+const (
+	LabelDependsOn        = "dtools.depends-on"             // container names, comma-separated
+	LabelComposeDependsOn = "com.docker.compose.depends_on" // service:condition:restart, comma-separated
+	labelComposeProject   = "com.docker.compose.project"    // the services above are in this project
+	labelComposeService   = "com.docker.compose.service"
+)
+
+// depsInspect is the part of GET /containers/{id}/json that tells what a container depends on
+type depsInspect struct {
+	Name   string `json:"Name"`
+	Config struct {
+		Env    []string          `json:"Env"`
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+	HostConfig struct {
+		Links       []string `json:"Links"` // /db:/web/db
+		NetworkMode string   `json:"NetworkMode"`
+	} `json:"HostConfig"`
+	NetworkSettings struct {
+		Networks map[string]struct {
+			Aliases []string `json:"Aliases"`
+		} `json:"Networks"`
+	} `json:"NetworkSettings"`
+}
+
+// DependencyTiers sorts containers in tiers: those of a tier only depend on containers of the previous tiers.
+// A container depends on:
+//   - the containers named in its dtools.depends-on label;
+//   - the services of its compose depends_on label, in the same project;
+//   - its --link targets, and the container whose network it shares (--network container:NAME);
+//   - a container on a user network they share, when its environment refers to it by name or alias (DB_HOST=db).
+//
+// Only the dependencies between the given containers count. The environment references that would close a
+// cycle are ignored, as such a guess is less reliable than the others. The other cycles are an error, returned
+// along with the tiers of the containers outside of them, so that the caller can still process those.
+func DependencyTiers(ctx context.Context, client *rest.Client, names []string) ([][]string, *ce.CustomError) {
+	all, cerr := ListContainers(ctx, client, ListOptions{})
+	if cerr != nil {
+		return nil, cerr
+	}
+	names = slices.Compact(slices.Sorted(slices.Values(names)))
+
+	inspected := make(map[string]depsInspect, len(names))
+	for _, name := range names {
+		var di depsInspect
+		if cerr := inspectDeps(ctx, client, name, &di); cerr != nil {
+			return nil, cerr
+		}
+		inspected[name] = di
+	}
+
+	deps := map[string][]string{} // container => the containers it depends on
+	addDep := func(from, to string) {
+		if _, ok := inspected[to]; ok && from != to && !slices.Contains(deps[from], to) {
+			deps[from] = append(deps[from], to)
+		}
+	}
+	for _, name := range names {
+		di := inspected[name]
+		for _, dep := range strings.Split(di.Config.Labels[LabelDependsOn], ",") {
+			addDep(name, strings.TrimSpace(dep))
+		}
+		for _, dep := range composeDependencies(all, di.Config.Labels) {
+			addDep(name, dep)
+		}
+		for _, link := range di.HostConfig.Links {
+			target, _, _ := strings.Cut(link, ":")
+			addDep(name, strings.TrimPrefix(target, "/"))
+		}
+		if ref, ok := strings.CutPrefix(di.HostConfig.NetworkMode, "container:"); ok {
+			addDep(name, containerName(all, ref))
+		}
+	}
+	for _, name := range names {
+		slices.Sort(deps[name])
+	}
+	// The containers of a cycle are left out, and so are the dependencies on them
+	var cycles []string
+	inCycle := map[string]bool{}
+	for cycle := findCycle(names, deps); cycle != nil; cycle = findCycle(names, deps) {
+		cycles = append(cycles, strings.Join(cycle, " -> "))
+		for _, n := range cycle {
+			inCycle[n] = true
+		}
+		names = slices.DeleteFunc(names, func(n string) bool { return inCycle[n] })
+		for n := range deps {
+			deps[n] = slices.DeleteFunc(deps[n], func(d string) bool { return inCycle[d] })
+		}
+	}
+
+	// Environment references on shared networks, skipped when they would close a cycle
+	for _, name := range names {
+		for _, dep := range envReferences(name, inspected) {
+			if !inCycle[dep] && !dependsOn(deps, dep, name) {
+				addDep(name, dep)
+			}
+		}
+	}
+
+	if len(cycles) > 0 {
+		return tiers(names, deps), &ce.CustomError{Title: "Dependency cycle", Message: strings.Join(cycles, "; ")}
+	}
+	return tiers(names, deps), nil
+}
+
+func inspectDeps(ctx context.Context, client *rest.Client, name string, out *depsInspect) *ce.CustomError {
+	resp, err := client.Do(ctx, http.MethodGet, "/containers/"+url.PathEscape(name)+"/json", url.Values{}, nil, nil)
+	if err != nil {
+		return &ce.CustomError{Title: "Unable to inspect container " + name, Message: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	if aerr := rest.CheckResponse(resp); aerr != nil {
+		return &ce.CustomError{Title: "Unable to inspect container " + name, Message: aerr.Error()}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return &ce.CustomError{Title: "Unable to decode JSON", Message: err.Error()}
+	}
+	return nil
+}
+
+// composeDependencies resolves the services of the compose depends_on label into container names
+func composeDependencies(all []ContainerSummary, labels map[string]string) []string {
+	var names []string
+	project := labels[labelComposeProject]
+	for _, entry := range strings.Split(labels[LabelComposeDependsOn], ",") {
+		service, _, _ := strings.Cut(strings.TrimSpace(entry), ":")
+		if service == "" {
+			continue
+		}
+		for _, c := range all {
+			if c.Labels[labelComposeProject] == project && c.Labels[labelComposeService] == service {
+				names = append(names, c.Names[0][1:])
+			}
+		}
+	}
+	return names
+}
+
+// containerName returns the name of a container given by name or (short) ID
+func containerName(all []ContainerSummary, ref string) string {
+	for _, c := range all {
+		if c.Names[0][1:] == ref || strings.HasPrefix(c.ID, ref) {
+			return c.Names[0][1:]
+		}
+	}
+	return ref
+}
+
+// envReferences lists the containers sharing a user network with name that its environment refers to
+func envReferences(name string, inspected map[string]depsInspect) []string {
+	var words []string
+	for _, env := range inspected[name].Config.Env {
+		_, value, _ := strings.Cut(env, "=")
+		words = append(words, strings.FieldsFunc(value, func(r rune) bool {
+			return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_.", r))
+		})...)
+	}
+	if len(words) == 0 {
+		return nil
+	}
+
+	var refs []string
+	for _, other := range slices.Sorted(maps.Keys(inspected)) {
+		if other == name {
+			continue
+		}
+		for network, ep := range inspected[other].NetworkSettings.Networks {
+			if _, shared := inspected[name].NetworkSettings.Networks[network]; !shared || isDefaultNetwork(network) {
+				continue
+			}
+			if slices.Contains(words, other) || slices.ContainsFunc(ep.Aliases, func(a string) bool { return slices.Contains(words, a) }) {
+				refs = append(refs, other)
+				break
+			}
+		}
+	}
+	return refs
+}
+
+// isDefaultNetwork tells the networks without name resolution between containers
+func isDefaultNetwork(network string) bool {
+	return network == "bridge" || network == "host" || network == "none"
+}
+
+// findCycle returns the first dependency cycle found, as a path that ends where it starts
+func findCycle(names []string, deps map[string][]string) []string {
+	state := map[string]int{} // 0: not visited, 1: in the current path, 2: done
+	var path []string
+	var visit func(string) []string
+	visit = func(n string) []string {
+		switch state[n] {
+		case 1:
+			i := slices.Index(path, n)
+			return append(append([]string{}, path[i:]...), n)
+		case 2:
+			return nil
+		}
+		state[n] = 1
+		path = append(path, n)
+		for _, d := range deps[n] {
+			if cycle := visit(d); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[n] = 2
+		return nil
+	}
+	for _, n := range names {
+		if cycle := visit(n); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// dependsOn tells whether from depends on to, directly or not
+func dependsOn(deps map[string][]string, from, to string) bool {
+	seen := map[string]bool{}
+	stack := []string{from}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if n == to {
+			return true
+		}
+		if !seen[n] {
+			seen[n] = true
+			stack = append(stack, deps[n]...)
+		}
+	}
+	return false
+}
+
+// tiers groups the containers by depth: a container is one tier after the deepest of its dependencies;
+// names is sorted, and so is each tier
+func tiers(names []string, deps map[string][]string) [][]string {
+	depth := map[string]int{}
+	var depthOf func(string) int
+	depthOf = func(n string) int {
+		if d, ok := depth[n]; ok {
+			return d
+		}
+		d := 0
+		for _, dep := range deps[n] {
+			d = max(d, depthOf(dep)+1)
+		}
+		depth[n] = d
+		return d
+	}
+
+	var out [][]string
+	for _, n := range names {
+		d := depthOf(n)
+		for len(out) <= d {
+			out = append(out, nil)
+		}
+		out[d] = append(out[d], n)
+	}
+	return out
+}
+
+// WaitHealthy polls the container until its healthcheck passes; it fails as soon as it is unhealthy or stopped,
+// or when it has no healthcheck
+func WaitHealthy(ctx context.Context, client *rest.Client, name string) *ce.CustomError {
+	for {
+		cs, cerr := ListContainers(ctx, client, ListOptions{})
+		if cerr != nil {
+			return cerr
+		}
+		i := slices.IndexFunc(cs, func(c ContainerSummary) bool { return c.Names[0][1:] == name })
+		if i < 0 {
+			return &ce.CustomError{Title: "Container not healthy", Message: "container " + name + " not found"}
+		}
+		c := cs[i]
+		switch c.HealthStatus() {
+		case "healthy":
+			return nil
+		case "unhealthy":
+			return &ce.CustomError{Title: "Container not healthy", Message: "container " + name + " is unhealthy"}
+		case "":
+			if c.State != "running" {
+				return &ce.CustomError{Title: "Container not healthy", Message: "container " + name + " is not running"}
+			}
+			return &ce.CustomError{Title: "Container not healthy", Message: "container " + name + " has no healthcheck"}
+		}
+
+		select {
+		case <-ctx.Done():
+			return &ce.CustomError{Title: "Container not healthy", Message: ctx.Err().Error()}
+		case <-time.After(time.Second):
+		}
+	}
+}
@@ -0,0 +1,48 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 21:45
+// Original filename: src/containers/diff.go
+
+package containers
+
+import (
+	"context"
+	"dtools2/rest"
+	"encoding/json"
+	"net/http"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+)
+
+// Changes lists the files added, modified or deleted in a container, compared to its image
+func Changes(ctx context.Context, client *rest.Client, container string) ([]FilesystemChange, *ce.CustomError) {
+	resp, err := client.Do(ctx, http.MethodGet, "/containers/"+container+"/changes", nil, nil, nil)
+	if err != nil {
+		return nil, &ce.CustomError{Title: "Unable to list the changes of " + container, Message: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	if aerr := rest.CheckResponse(resp); aerr != nil {
+		return nil, &ce.CustomError{Title: "Unable to list the changes of " + container, Message: aerr.Error()}
+	}
+
+	// Docker answers null when nothing changed
+	var changes []FilesystemChange
+	if err := json.NewDecoder(resp.Body).Decode(&changes); err != nil {
+		return nil, &ce.CustomError{Title: "Unable to decode JSON", Message: err.Error()}
+	}
+	for i := range changes {
+		switch changes[i].Kind {
+		case ChangeModified:
+			changes[i].Type = "C"
+		case ChangeAdded:
+			changes[i].Type = "A"
+		case ChangeDeleted:
+			changes[i].Type = "D"
+		}
+	}
+	if changes == nil {
+		changes = []FilesystemChange{}
+	}
+	return changes, nil
+}
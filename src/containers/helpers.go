@@ -6,6 +6,7 @@
 package containers
 
 import (
+	"context"
 	"dtools2/rest"
 
 	ce "github.com/jeanfrancoisgratton/customError/v3"
@@ -13,13 +14,9 @@ import (
 
 // Name2ID takes the human-readable container name and returns its docker/podman ID
 
-func Name2ID(client *rest.Client, containerName string) (string, *ce.CustomError) {
-	OnlyRunningContainers = false
-	ExtendedContainerInfo = false
-	DisplaySizeValues = false
-
+func Name2ID(ctx context.Context, client *rest.Client, containerName string) (string, *ce.CustomError) {
 	// first off, let's fetch the list of containers
-	if cs, err := ListContainers(client, false); err != nil {
+	if cs, err := ListContainers(ctx, client, ListOptions{}); err != nil {
 		return "", err
 	} else {
 		if len(cs) == 0 {
@@ -36,13 +33,9 @@ func Name2ID(client *rest.Client, containerName string) (string, *ce.CustomError
 
 // ID2Name takes a container ID and returns its human-readable name
 
-func ID2Name(client *rest.Client, containerID string) (string, *ce.CustomError) {
-	OnlyRunningContainers = false
-	ExtendedContainerInfo = false
-	DisplaySizeValues = false
-
+func ID2Name(ctx context.Context, client *rest.Client, containerID string) (string, *ce.CustomError) {
 	// first off, let's fetch the list of containers
-	if cs, err := ListContainers(client, false); err != nil {
+	if cs, err := ListContainers(ctx, client, ListOptions{}); err != nil {
 		return "", err
 	} else {
 		if len(cs) == 0 {
@@ -56,3 +49,12 @@ func ID2Name(client *rest.Client, containerID string) (string, *ce.CustomError)
 	}
 	return "", &ce.CustomError{Fatality: ce.Warning, Message: "No containers found"}
 }
+
+// containerNames returns the names (without the leading /) of the containers in cs
+func containerNames(cs []ContainerSummary) []string {
+	var names []string
+	for _, c := range cs {
+		names = append(names, c.Names[0][1:])
+	}
+	return names
+}
@@ -6,73 +6,23 @@
 package containers
 
 import (
+	"context"
 	"dtools2/rest"
-	"fmt"
-	"os"
-	"strings"
-	"text/tabwriter"
-	"time"
 
-	hftx "github.com/jeanfrancoisgratton/helperFunctions/v4/terminalfx"
+	ce "github.com/jeanfrancoisgratton/customError/v3"
 )
 
-func InfoContainers(client *rest.Client, container string) error {
-	// Preserve the current flag. For now, it might not be useful to do so, but might be, eventually
-	currentRunningContainersFlag := OnlyRunningContainers
-	currentExtendedContainersFlag := ExtendedContainerInfo
-	currentDisplaySizeFlag := DisplaySizeValues
-	OnlyRunningContainers = false
-	ExtendedContainerInfo = true
-	DisplaySizeValues = true
-
-	// Fetch the container info from ListContainers()
-	cs, err := ListContainers(client, false)
+// InfoContainer returns the extended summary (sizes included) of a single container
+func InfoContainer(ctx context.Context, client *rest.Client, container string) (*ContainerSummary, *ce.CustomError) {
+	cs, err := ListContainers(ctx, client, ListOptions{Size: true})
 	if err != nil {
-		OnlyRunningContainers = currentRunningContainersFlag
-		ExtendedContainerInfo = currentExtendedContainersFlag
-		DisplaySizeValues = currentDisplaySizeFlag
-		return err
+		return nil, err
 	}
 
 	for _, ci := range cs {
 		if ci.Names[0][1:] == container {
-			// we got a match
-			showExtendedInfo(ci)
-			break
+			return &ci, nil
 		}
 	}
-
-	// restore flags as they were before this fx call
-	OnlyRunningContainers = currentRunningContainersFlag
-	ExtendedContainerInfo = currentExtendedContainersFlag
-	DisplaySizeValues = currentDisplaySizeFlag
-	return nil
-}
-
-func showExtendedInfo(cInfo ContainerSummary) {
-	state := ""
-	switch cInfo.State {
-	case "running":
-		state = hftx.Green("running")
-	case "paused":
-	case "suspended":
-	case "blocked":
-		state = hftx.Yellow(cInfo.State)
-	case "crashed":
-		state = hftx.Red("crashed")
-	default:
-		state = hftx.White(strings.ToLower(cInfo.State))
-	}
-	w := tabwriter.NewWriter(os.Stdout, 1, 4, 2, ' ', 0)
-	fmt.Fprintf(w, "%s\t%s\n", hftx.Blue("Container name"), cInfo.Names[0][1:])
-	fmt.Fprintf(w, "%s\t%s\n", hftx.Blue("Image"), getImageTag(cInfo.Image))
-	fmt.Fprintf(w, "%s\t%s\n", hftx.Blue("Created"), time.Unix(cInfo.Created, 0).Format("2006.01.02 15:04:05"))
-	fmt.Fprintf(w, "%s\t%s\n", hftx.Blue("State"), state)
-	fmt.Fprintf(w, "%s\t%s\n", hftx.Blue("Status"), strings.ToLower(cInfo.Status))
-	fmt.Fprintf(w, "%s\t%s\n", hftx.Blue("RW filesystem size"), formatSize(cInfo.SizeRw))
-	fmt.Fprintf(w, "%s\t%s\n", hftx.Blue("RootFS size"), formatSize(cInfo.SizeRootFs))
-	fmt.Fprintf(w, "%s\t%s\n", hftx.Blue("Exposed ports"), prettifyPortsList(cInfo.Ports, ", "))
-	fmt.Fprintf(w, "%s\t%s\n", hftx.Blue("Mount points"), prettifyMounts(cInfo.Mounts, ", "))
-	fmt.Fprintf(w, "%s\t%s\n", hftx.Blue("Command"), cInfo.Command)
-	w.Flush()
+	return nil, &ce.CustomError{Fatality: ce.Warning, Title: "Unable to fetch container info", Message: "Container " + container + " not found"}
 }
@@ -6,42 +6,64 @@
 package containers
 
 import (
+	"context"
+	"dtools2/extras"
 	"dtools2/rest"
+	"slices"
 
 	ce "github.com/jeanfrancoisgratton/customError/v3"
 )
 
 // ENDPOINT : POST /containers/{id}/kill
 
-func KillContainers(client *rest.Client, containers []string) *ce.CustomError {
-	KillSwitch = true
+// KillContainers kills the containers in the same order as StopContainers(), a container before those it depends
+// on, as far as it can tell: the containers of a dependency cycle, and the names it cannot inspect, go last.
+// Killing is the way out of a stuck stop, so a cycle is no reason to leave a container running.
+func KillContainers(ctx context.Context, client *rest.Client, containers []string, onEvent extras.EventFunc) *ce.CustomError {
+	return killContainers(ctx, client, killOrder(ctx, client, containers), onEvent)
+}
+
+// killOrder sorts the containers in the reverse order of DependencyTiers(), followed by those of the cycles, then
+// by the names that are not containers
+func killOrder(ctx context.Context, client *rest.Client, containers []string) []string {
+	cs, cerr := ListContainers(ctx, client, ListOptions{})
+	if cerr != nil {
+		return containers
+	}
+	all := containerNames(cs)
+	known := slices.DeleteFunc(slices.Clone(containers), func(name string) bool { return !slices.Contains(all, name) })
+
+	var order []string
+	tiers, _ := DependencyTiers(ctx, client, known)
+	for _, tier := range slices.Backward(tiers) {
+		order = append(order, tier...)
+	}
+	for _, name := range slices.Concat(known, containers) {
+		if !slices.Contains(order, name) {
+			order = append(order, name)
+		}
+	}
+	return order
+}
 
+func killContainers(ctx context.Context, client *rest.Client, containers []string, onEvent extras.EventFunc) *ce.CustomError {
 	for _, container := range containers {
-		if err := stop(client, "", container, 0); err != nil {
+		id, cerr := Name2ID(ctx, client, container)
+		if cerr != nil {
+			return cerr
+		}
+		if err := stop(ctx, client, id, container, 0, true, onEvent); err != nil {
 			return err
 		}
-
 	}
 	return nil
 }
 
-func KillAllContainers(client *rest.Client) *ce.CustomError {
-	var (
-		cerr *ce.CustomError
-		cs   []ContainerSummary
-	)
-
-	OnlyRunningContainers = true
-
-	// Fetch the list of containers currently present on the daemon, regardless of their state.
-	if cs, cerr = ListContainers(client, false); cerr != nil {
+func KillAllContainers(ctx context.Context, client *rest.Client, onEvent extras.EventFunc) *ce.CustomError {
+	cs, cerr := ListContainers(ctx, client, ListOptions{OnlyRunning: true})
+	if cerr != nil {
 		return cerr
 	}
 
-	var containerList []string
-	for _, c := range cs {
-		containerList = append(containerList, c.Names[0][1:])
-	}
-
-	return KillContainers(client, containerList)
+	return KillContainers(ctx, client, containerNames(cs), onEvent)
 }
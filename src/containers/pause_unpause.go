@@ -6,68 +6,46 @@
 package containers
 
 import (
+	"context"
+	"dtools2/extras"
 	"dtools2/rest"
-	"fmt"
 	"net/http"
 	"net/url"
 
 	ce "github.com/jeanfrancoisgratton/customError/v3"
-	hftx "github.com/jeanfrancoisgratton/helperFunctions/v4/terminalfx"
 )
 
 // Pauses one or many containers
 
-func PauseContainer(client *rest.Client, containers []string) *ce.CustomError {
-	var id string
-	var cerr *ce.CustomError
-	q := url.Values{}
-
-	for _, container := range containers {
-		if id, cerr = Name2ID(client, container); cerr != nil {
-			return cerr
-		}
-		path := "/containers/{" + id + "}/pause"
-
-		resp, err := client.Do(rest.Context, http.MethodPost, path, q, nil, nil)
-		if err != nil {
-			return &ce.CustomError{Title: "Unable to POST request", Message: err.Error()}
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
-			return &ce.CustomError{Title: "http request returned an error", Message: "POST" + path + " returned " + resp.Status}
-		}
-		if !rest.QuietOutput {
-			fmt.Println(hftx.InProgressSign("Container " + container + hftx.Yellow(" PAUSED")))
-		}
-	}
-	return nil
+func PauseContainer(ctx context.Context, client *rest.Client, containers []string, onEvent extras.EventFunc) *ce.CustomError {
+	return pauseUnpause(ctx, client, containers, "pause", extras.EventPaused, onEvent)
 }
 
 // The reverse: we unpause one or many containers
 
-func UnpauseContainer(client *rest.Client, containers []string) *ce.CustomError {
-	var id string
-	var cerr *ce.CustomError
+func UnpauseContainer(ctx context.Context, client *rest.Client, containers []string, onEvent extras.EventFunc) *ce.CustomError {
+	return pauseUnpause(ctx, client, containers, "unpause", extras.EventUnpaused, onEvent)
+}
 
+func pauseUnpause(ctx context.Context, client *rest.Client, containers []string, action string, event extras.EventAction, onEvent extras.EventFunc) *ce.CustomError {
 	for _, container := range containers {
-		if id, cerr = Name2ID(client, container); cerr != nil {
+		id, cerr := Name2ID(ctx, client, container)
+		if cerr != nil {
 			return cerr
 		}
-		path := "/containers/{" + id + "}/unpause"
+		path := "/containers/" + id + "/" + action
 
-		resp, err := client.Do(rest.Context, http.MethodPost, path, url.Values{}, nil, nil)
+		resp, err := client.Do(ctx, http.MethodPost, path, url.Values{}, nil, nil)
 		if err != nil {
 			return &ce.CustomError{Title: "Unable to POST request", Message: err.Error()}
 		}
-		defer resp.Body.Close()
+		aerr := rest.CheckResponse(resp)
+		resp.Body.Close()
 
-		if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
-			return &ce.CustomError{Title: "http request returned an error", Message: "POST" + path + " returned " + resp.Status}
-		}
-		if !rest.QuietOutput {
-			fmt.Println(hftx.InProgressSign("Container " + container + hftx.Green(" UNPAUSED")))
+		if aerr != nil {
+			return &ce.CustomError{Title: "Unable to " + action + " container " + container, Message: aerr.Error()}
 		}
+		onEvent.Emit(extras.Event{Resource: "container", Name: container, Action: event})
 	}
 	return nil
 }
@@ -6,79 +6,68 @@
 package containers
 
 import (
+	"context"
 	"dtools2/blacklist"
+	"dtools2/extras"
 	"dtools2/rest"
-	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
 
 	ce "github.com/jeanfrancoisgratton/customError/v3"
-	hftx "github.com/jeanfrancoisgratton/helperFunctions/v4/terminalfx"
 )
 
-// RemoveContainer Remove a single or multiple containers
-// This can be wrapped in RemoveAll
-func RemoveContainer(client *rest.Client, containerList []string) *ce.CustomError {
+// RemoveContainer Remove a single or multiple containers, and returns the names of those actually removed.
+// Blacklisted containers are skipped, unless opts.IgnoreBlacklist is set.
+func RemoveContainer(ctx context.Context, client *rest.Client, containerList []string, opts RemoveOptions) ([]string, *ce.CustomError) {
+	var removed []string
+
 	for _, container := range containerList {
-		id, err := Name2ID(client, container)
+		id, err := Name2ID(ctx, client, container)
 		if err != nil {
-			return err
+			return removed, err
 		}
 
 		isBL, err := blacklist.IsResourceBlackListed("containers", container)
 		if err != nil {
-			return err
+			return removed, err
 		}
 
-		// FIXME: this is tangled.. we need to clean this up
 		if isBL {
-			if !rest.QuietOutput {
-				fmt.Println(hftx.WarningSign(" Container " + container + " is blacklisted"))
-			}
-			if RemoveBlacklisted {
-				if !rest.QuietOutput {
-					fmt.Println(hftx.InfoSign("Force removal flag is present, continuing"))
-				}
-				if err := remove(client, container, id); err != nil {
-					return err
-				}
-			} else {
-				if !rest.QuietOutput {
-					fmt.Println(hftx.InfoSign("Removal flag is absent, skipping container"))
-				}
-			}
-		} else {
-			if err := remove(client, container, id); err != nil {
-				return err
+			if !opts.IgnoreBlacklist {
+				opts.OnEvent.Emit(extras.Event{Resource: "container", Name: container, Action: extras.EventSkipped, Message: "blacklisted"})
+				continue
 			}
+			opts.OnEvent.Emit(extras.Event{Resource: "container", Name: container, Action: extras.EventBlacklisted})
+		}
+		if err := remove(ctx, client, container, id, opts); err != nil {
+			return removed, err
 		}
+		removed = append(removed, container)
 	}
-	return nil
+	return removed, nil
 }
 
 // The actual removal call
-func remove(client *rest.Client, name, id string) *ce.CustomError {
+func remove(ctx context.Context, client *rest.Client, name, id string, opts RemoveOptions) *ce.CustomError {
 	q := url.Values{}
 
-	q.Set("force", strconv.FormatBool(ForceRemoveContainer))
-	q.Set("v", strconv.FormatBool(RemoveUnamedVolumes))
+	q.Set("force", strconv.FormatBool(opts.Force))
+	q.Set("v", strconv.FormatBool(opts.RemoveVolumes))
 
 	path := "/containers/" + id
-	resp, derr := client.Do(rest.Context, http.MethodDelete, path, q, nil, nil)
+	resp, derr := client.Do(ctx, http.MethodDelete, path, q, nil, nil)
 	if derr != nil {
 		return &ce.CustomError{Title: "Unable to post DELETE", Message: derr.Error()}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusConflict {
-		return &ce.CustomError{Title: "DELETE request returned http 409 (Conflict)", Message: "Container " + name + " might be running"}
-	}
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		return &ce.CustomError{Title: "DELETE request returned an error", Message: "http request returned " + resp.Status}
-	}
-	if !rest.QuietOutput {
-		fmt.Println(hftx.InProgressSign("Container " + name + hftx.Red(" REMOVED")))
+	if aerr := rest.CheckResponse(resp); aerr != nil {
+		if rest.IsConflict(aerr) {
+			return &ce.CustomError{Title: "Unable to remove container " + name, Message: "Container " + name + " might be running: " + aerr.Error()}
+		}
+		return &ce.CustomError{Title: "Unable to remove container " + name, Message: aerr.Error()}
 	}
+	opts.OnEvent.Emit(extras.Event{Resource: "container", Name: name, Action: extras.EventRemoved})
 	return nil
 }
@@ -6,42 +6,37 @@
 package containers
 
 import (
+	"context"
 	"dtools2/rest"
-	"fmt"
 	"net/http"
 	"net/url"
 
 	ce "github.com/jeanfrancoisgratton/customError/v3"
-	hftx "github.com/jeanfrancoisgratton/helperFunctions/v4/terminalfx"
 )
 
 // Rename a container
-func RenameContainer(client *rest.Client, oldname, newname string) *ce.CustomError {
+func RenameContainer(ctx context.Context, client *rest.Client, oldname, newname string) *ce.CustomError {
 	var cerr *ce.CustomError
 	var id string
 
-	if id, cerr = Name2ID(client, oldname); cerr != nil {
+	if id, cerr = Name2ID(ctx, client, oldname); cerr != nil {
 		return cerr
 	}
 	path := "/containers/" + id + "/rename"
 	q := url.Values{}
 	q.Set("name", newname)
 
-	resp, err := client.Do(rest.Context, http.MethodPost, path, q, nil, nil)
+	resp, err := client.Do(ctx, http.MethodPost, path, q, nil, nil)
 	if err != nil {
 		return &ce.CustomError{Title: "Unable to POST request", Message: err.Error()}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusConflict {
-		return &ce.CustomError{Title: "Unable to rename the container", Message: "Container already exists"}
-	}
-
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		return &ce.CustomError{Title: "POST request returned an error", Message: "http requested returned " + resp.Status}
-	}
-	if !rest.QuietOutput {
-		fmt.Println("Container " + hftx.Green(oldname) + " renamed to " + hftx.Green(newname))
+	if aerr := rest.CheckResponse(resp); aerr != nil {
+		if rest.IsConflict(aerr) {
+			return &ce.CustomError{Title: "Unable to rename the container", Message: "Container " + newname + " already exists"}
+		}
+		return &ce.CustomError{Title: "Unable to rename the container", Message: aerr.Error()}
 	}
 	return nil
 }
@@ -1,41 +1,63 @@
 package containers
 
 import (
+	"context"
 	"dtools2/rest"
+	"slices"
+	"strings"
 
 	ce "github.com/jeanfrancoisgratton/customError/v3"
 )
 
-func RestartContainers(client *rest.Client, containers []string) *ce.CustomError {
-	if KillSwitch {
-		if err := KillContainers(client, containers); err != nil {
-			return err
-		}
-	} else {
-		if err := StopContainers(client, containers); err != nil {
-			return err
-		}
+// RestartContainers stops (or kills) the containers, then starts them, both in dependency order. The containers of
+// a dependency cycle are left alone, and the cycle is reported once the others are restarted.
+func RestartContainers(ctx context.Context, client *rest.Client, containers []string, opts StopOptions) *ce.CustomError {
+	// The names that are not containers are skipped, as StopContainers() and StartContainers() do
+	cs, cerr := ListContainers(ctx, client, ListOptions{})
+	if cerr != nil {
+		return cerr
+	}
+	all := containerNames(cs)
+	containers = slices.DeleteFunc(slices.Clone(containers), func(name string) bool { return !slices.Contains(all, name) })
+	if len(containers) == 0 {
+		return nil
 	}
 
-	if err := StartContainers(client, containers); err != nil {
-		return err
+	// The tiers are computed once, for both halves
+	tiers, cycleErr := DependencyTiers(ctx, client, containers)
+	if len(tiers) == 0 {
+		return cycleErr
 	}
-	return nil
-}
 
-func RestartAllContainers(client *rest.Client) *ce.CustomError {
-	if KillSwitch {
-		if err := KillAllContainers(client); err != nil {
-			return err
+	// Only the running containers are stopped, or killed
+	running := slices.DeleteFunc(cs, func(c ContainerSummary) bool { return strings.ToLower(c.State) != "running" })
+	if opts.Kill {
+		var names []string
+		for _, tier := range slices.Backward(tiers) {
+			names = append(names, slices.DeleteFunc(slices.Clone(tier), func(name string) bool {
+				return !slices.Contains(containerNames(running), name)
+			})...)
 		}
+		cerr = killContainers(ctx, client, names, opts.OnEvent)
 	} else {
-		if err := StopAllContainers(client); err != nil {
-			return err
-		}
+		cerr = stopTiers(ctx, client, running, tiers, opts)
+	}
+	if cerr != nil {
+		return cerr
 	}
 
-	if err := StartAllContainers(client); err != nil {
-		return err
+	if cerr = startTiers(ctx, client, tiers, StartOptions{WaitHealthy: opts.WaitHealthy, OnEvent: opts.OnEvent}); cerr != nil {
+		return cerr
 	}
-	return nil
+	return cycleErr
+}
+
+func RestartAllContainers(ctx context.Context, client *rest.Client, opts StopOptions) *ce.CustomError {
+	// Only the running containers get restarted; the stopped ones stay down.
+	cs, cerr := ListContainers(ctx, client, ListOptions{OnlyRunning: true})
+	if cerr != nil {
+		return cerr
+	}
+
+	return RestartContainers(ctx, client, containerNames(cs), opts)
 }
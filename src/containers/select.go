@@ -0,0 +1,120 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 06:20
+// Original filename: src/containers/select.go
+
+package containers
+
+import (
+	"context"
+	"dtools2/extras"
+	"dtools2/rest"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+)
+
+// containerStates are the values of the State field, as accepted by the status filter
+var containerStates = []string{"created", "restarting", "running", "removing", "paused", "exited", "dead"}
+
+// ParseSelectors turns the repeated `--selector key=value` flags into a Selector; the keys are label, name
+// (a shell pattern), image and state.
+func ParseSelectors(flags []string) (Selector, *ce.CustomError) {
+	var sel Selector
+	for _, flag := range flags {
+		key, value, ok := strings.Cut(flag, "=")
+		if !ok || value == "" {
+			return sel, &ce.CustomError{Title: "Invalid selector", Message: "'" + flag + "' is not in the key=value format"}
+		}
+		switch strings.TrimSpace(key) {
+		case "label":
+			sel.Labels = append(sel.Labels, value)
+		case "name":
+			sel.NameGlobs = append(sel.NameGlobs, value)
+		case "image":
+			sel.Images = append(sel.Images, value)
+		case "state":
+			sel.States = append(sel.States, value)
+		default:
+			return sel, &ce.CustomError{Title: "Invalid selector", Message: "'" + key + "' is not one of label, name, image or state"}
+		}
+	}
+	return sel, nil
+}
+
+// IsEmpty tells whether the selector has no criteria, in which case it selects nothing
+func (s Selector) IsEmpty() bool {
+	return len(s.Labels) == 0 && len(s.NameGlobs) == 0 && len(s.Images) == 0 && len(s.States) == 0
+}
+
+// SelectContainers returns the containers matching the selector, sorted by name. The labels, images and states
+// are filtered by the daemon, the name patterns here: the name filter of the daemon takes a regular expression.
+// As with `docker ps --filter`, different label keys must all match; the values of a same key are alternatives.
+func SelectContainers(ctx context.Context, client *rest.Client, sel Selector) ([]ContainerSummary, *ce.CustomError) {
+	if sel.IsEmpty() {
+		return nil, &ce.CustomError{Title: "Invalid selector", Message: "no selection criteria given"}
+	}
+	for _, glob := range sel.NameGlobs {
+		if _, err := path.Match(glob, ""); err != nil {
+			return nil, &ce.CustomError{Title: "Invalid selector", Message: "'" + glob + "': " + err.Error()}
+		}
+	}
+	for _, state := range sel.States {
+		if !slices.Contains(containerStates, state) {
+			return nil, &ce.CustomError{Title: "Invalid selector", Message: "'" + state + "' is not one of " + strings.Join(containerStates, ", ")}
+		}
+	}
+
+	// The daemon wants all the labels of its filter: a key given with one value goes there, the values of a key
+	// given several times are alternatives, matched here
+	byKey := map[string][]string{}
+	for _, label := range sel.Labels {
+		key, _, _ := strings.Cut(label, "=")
+		byKey[key] = append(byKey[key], label)
+	}
+	filters := extras.Filters{}
+	var alternatives [][]string
+	for _, key := range slices.Sorted(maps.Keys(byKey)) {
+		if len(byKey[key]) == 1 {
+			filters["label"] = append(filters["label"], byKey[key][0])
+		} else {
+			alternatives = append(alternatives, byKey[key])
+		}
+	}
+	for key, values := range map[string][]string{"ancestor": sel.Images, "status": sel.States} {
+		if len(values) > 0 {
+			filters[key] = values
+		}
+	}
+	cs, cerr := ListContainers(ctx, client, ListOptions{Filters: filters})
+	if cerr != nil {
+		return nil, cerr
+	}
+	cs = slices.DeleteFunc(cs, func(c ContainerSummary) bool {
+		return slices.ContainsFunc(alternatives, func(labels []string) bool {
+			return !slices.ContainsFunc(labels, func(label string) bool { return hasLabel(c.Labels, label) })
+		})
+	})
+
+	selected := slices.DeleteFunc(cs, func(c ContainerSummary) bool {
+		if len(sel.NameGlobs) == 0 {
+			return false
+		}
+		return !slices.ContainsFunc(sel.NameGlobs, func(glob string) bool {
+			matched, _ := path.Match(glob, c.Names[0][1:])
+			return matched
+		})
+	})
+	slices.SortFunc(selected, func(a, b ContainerSummary) int { return strings.Compare(a.Names[0], b.Names[0]) })
+	return selected, nil
+}
+
+// hasLabel tells whether labels has the KEY or KEY=VALUE of a label selector
+func hasLabel(labels map[string]string, label string) bool {
+	key, value, withValue := strings.Cut(label, "=")
+	v, ok := labels[key]
+	return ok && (!withValue || v == value)
+}
@@ -0,0 +1,45 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 07:50
+// Original filename: src/containers/select_test.go
+
+package containers
+
+import (
+	"context"
+	"dtools2/rest/fakedaemon"
+	"slices"
+	"testing"
+)
+
+func TestSelectContainersLabels(t *testing.T) {
+	d, client := fakedaemon.StartT(t)
+
+	d.AddContainer(fakedaemon.Container{Name: "prod", Image: "nginx", State: fakedaemon.StateRunning, Labels: map[string]string{"env": "prod"}})
+	d.AddContainer(fakedaemon.Container{Name: "staging", Image: "nginx", State: fakedaemon.StateRunning, Labels: map[string]string{"env": "staging", "tier": "web"}})
+	d.AddContainer(fakedaemon.Container{Name: "dev", Image: "nginx", State: fakedaemon.StateExited, Labels: map[string]string{"env": "dev", "tier": "web"}})
+
+	for _, tc := range []struct {
+		sel  Selector
+		want []string
+	}{
+		{Selector{Labels: []string{"env=prod", "env=staging"}}, []string{"prod", "staging"}},
+		{Selector{Labels: []string{"env=prod", "tier=web"}}, nil},
+		{Selector{Labels: []string{"env=staging", "env=dev", "tier=web"}}, []string{"dev", "staging"}},
+		{Selector{Labels: []string{"env=prod", "env=dev", "tier"}}, []string{"dev"}},
+		{Selector{Labels: []string{"tier=web"}, States: []string{"running"}}, []string{"staging"}},
+		{Selector{Labels: []string{"env=prod", "env=staging"}, NameGlobs: []string{"s*"}}, []string{"staging"}},
+	} {
+		cs, cerr := SelectContainers(context.Background(), client, tc.sel)
+		if cerr != nil {
+			t.Fatal(cerr)
+		}
+		var got []string
+		for _, c := range cs {
+			got = append(got, c.Names[0][1:])
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("%+v: got %v, want %v", tc.sel, got, tc.want)
+		}
+	}
+}
@@ -6,36 +6,62 @@
 package containers
 
 import (
+	"context"
+	"dtools2/extras"
 	"dtools2/rest"
-	"fmt"
 	"net/http"
 	"net/url"
 	"slices"
 	"strings"
 
 	ce "github.com/jeanfrancoisgratton/customError/v3"
-	hftx "github.com/jeanfrancoisgratton/helperFunctions/v4/terminalfx"
 )
 
-// Starts one or many containers
+// Starts one or many containers, dependencies first: tier after tier, as sorted by DependencyTiers().
+// With opts.WaitHealthy, the containers of a tier that have a healthcheck must pass it before the next tier starts.
 
-func StartContainers(client *rest.Client, containers []string) *ce.CustomError {
+func StartContainers(ctx context.Context, client *rest.Client, containers []string, opts StartOptions) *ce.CustomError {
 	var cerr *ce.CustomError
 	var cs []ContainerSummary
-	OnlyRunningContainers = false
 
-	// TODO: this might not be optimal, listing all containers if len(containers) == 1..
-	// TODO: needs optimizing at some point
 	// Fetch the list of containers currently present on the daemon, regardless of their state
-	if cs, cerr = ListContainers(client, false); cerr != nil {
+	if cs, cerr = ListContainers(ctx, client, ListOptions{}); cerr != nil {
 		return cerr
 	}
+	var targets []string
 	for _, container := range cs {
 		if strings.ToLower(container.State) == "running" {
 			continue
 		}
 		if slices.Contains(containers, container.Names[0][1:]) {
-			if cerr = start(client, container.ID, ""); cerr != nil {
+			targets = append(targets, container.Names[0][1:])
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	// As with StopContainers(), the containers of a dependency cycle are reported once the others are started
+	tiers, cycleErr := DependencyTiers(ctx, client, targets)
+	if len(tiers) == 0 {
+		return cycleErr
+	}
+	if cerr = startTiers(ctx, client, tiers, opts); cerr != nil {
+		return cerr
+	}
+	return cycleErr
+}
+
+// startTiers starts the containers tier after tier, waiting for them between tiers with opts.WaitHealthy
+func startTiers(ctx context.Context, client *rest.Client, tiers [][]string, opts StartOptions) *ce.CustomError {
+	for i, tier := range tiers {
+		for _, name := range tier {
+			if cerr := start(ctx, client, name, name, opts.OnEvent); cerr != nil {
+				return cerr
+			}
+		}
+		if opts.WaitHealthy && i < len(tiers)-1 {
+			if cerr := waitTier(ctx, client, tier, opts.OnEvent); cerr != nil {
 				return cerr
 			}
 		}
@@ -43,55 +69,52 @@ func StartContainers(client *rest.Client, containers []string) *ce.CustomError {
 	return nil
 }
 
-// The actual mechanics of starting the container
-
-func start(client *rest.Client, id string, containerName string) *ce.CustomError {
-	var cerr *ce.CustomError
-	if id == "" {
-
-		if id, cerr = Name2ID(client, containerName); cerr != nil {
-			return cerr
-		}
+// waitTier waits for the containers of the tier that have a healthcheck to pass it
+func waitTier(ctx context.Context, client *rest.Client, tier []string, onEvent extras.EventFunc) *ce.CustomError {
+	cs, cerr := ListContainers(ctx, client, ListOptions{})
+	if cerr != nil {
+		return cerr
 	}
-	if containerName == "" {
-		if containerName, cerr = ID2Name(client, id); cerr != nil {
+	for _, c := range cs {
+		name := c.Names[0][1:]
+		if !slices.Contains(tier, name) || (c.HealthStatus() == "" && c.State == "running") {
+			continue
+		}
+		if cerr := WaitHealthy(ctx, client, name); cerr != nil {
 			return cerr
 		}
+		onEvent.Emit(extras.Event{Resource: "container", Name: name, Action: extras.EventHealthy})
 	}
+	return nil
+}
+
+// The actual mechanics of starting the container
 
+func start(ctx context.Context, client *rest.Client, id string, containerName string, onEvent extras.EventFunc) *ce.CustomError {
 	path := "/containers/" + id + "/start"
 
-	resp, err := client.Do(rest.Context, http.MethodPost, path, url.Values{}, nil, nil)
+	resp, err := client.Do(ctx, http.MethodPost, path, url.Values{}, nil, nil)
 	if err != nil {
 		return &ce.CustomError{Title: "Unable to start container " + containerName, Message: err.Error()}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
-		return &ce.CustomError{Title: "http request returned an error", Message: "POST" + path + " returned " + resp.Status}
-	}
-	if !rest.QuietOutput {
-		fmt.Println(hftx.InProgressSign("Container " + containerName + hftx.Green(" STARTED")))
+	// 304 means the container was already started: nothing to report as an error.
+	if aerr := rest.CheckResponse(resp); aerr != nil && !rest.IsNotModified(aerr) {
+		return &ce.CustomError{Title: "Unable to start container " + containerName, Message: aerr.Error()}
 	}
+	onEvent.Emit(extras.Event{Resource: "container", Name: containerName, Action: extras.EventStarted})
 	return nil
 }
 
 // Starts all non-running containers
 
-func StartAllContainers(client *rest.Client) *ce.CustomError {
-	var cerr *ce.CustomError
-	var cs []ContainerSummary
-	var containerList []string
-	OnlyRunningContainers = false
-
+func StartAllContainers(ctx context.Context, client *rest.Client, opts StartOptions) *ce.CustomError {
 	// Fetch the list of containers currently present on the daemon, regardless of their state
-	if cs, cerr = ListContainers(client, false); cerr != nil {
+	cs, cerr := ListContainers(ctx, client, ListOptions{})
+	if cerr != nil {
 		return cerr
 	}
 
-	for _, container := range cs {
-		containerList = append(containerList, container.Names[0][1:])
-	}
-
-	return StartContainers(client, containerList)
+	return StartContainers(ctx, client, containerNames(cs), opts)
 }
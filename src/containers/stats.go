@@ -0,0 +1,163 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 20:55
+// Original filename: src/containers/stats.go
+
+package containers
+
+import (
+	"context"
+	"dtools2/rest"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+)
+
+// Stats reads GET /containers/{id}/stats for the given containers, or for all the running ones when
+// the list is empty. Each container is read concurrently; opts.OnSample sees the samples as they come.
+// It returns the last sample of every container, in the order of the list.
+func Stats(ctx context.Context, client *rest.Client, containerList []string, opts StatsOptions) ([]StatsSample, *ce.CustomError) {
+	targets := containerList
+	if len(targets) == 0 {
+		cs, err := ListContainers(ctx, client, ListOptions{OnlyRunning: true})
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range cs {
+			targets = append(targets, c.ID)
+		}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr *ce.CustomError
+	last := make([]StatsSample, len(targets))
+
+	for i, target := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := readStats(ctx, client, target, opts.Stream, func(s StatsSample) {
+				mu.Lock()
+				defer mu.Unlock()
+				last[i] = s
+				if opts.OnSample != nil {
+					opts.OnSample(s)
+				}
+			})
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Cancelling a stream is how it is meant to end.
+	if firstErr != nil && ctx.Err() == nil {
+		return last, firstErr
+	}
+	return last, nil
+}
+
+// readStats decodes the stats payloads of one container until the stream ends
+func readStats(ctx context.Context, client *rest.Client, container string, stream bool, onSample func(StatsSample)) *ce.CustomError {
+	q := url.Values{}
+	q.Set("stream", strconv.FormatBool(stream))
+
+	resp, err := client.Do(rest.StreamingContext(ctx), http.MethodGet, "/containers/"+container+"/stats", q, nil, nil)
+	if err != nil {
+		return &ce.CustomError{Title: "Unable to fetch the stats of " + container, Message: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	if aerr := rest.CheckResponse(resp); aerr != nil {
+		return &ce.CustomError{Title: "Unable to fetch the stats of " + container, Message: aerr.Error()}
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	var prev *StatsResponse
+	for {
+		var s StatsResponse
+		if err := dec.Decode(&s); err != nil {
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+				return nil
+			}
+			return &ce.CustomError{Title: "Unable to decode JSON", Message: err.Error()}
+		}
+		// The first streamed payload has no precpu_stats; the previous payload stands in for them.
+		if s.PreCPUStats.SystemUsage == 0 && prev != nil {
+			s.PreCPUStats = prev.CPUStats
+		}
+		onSample(s.Sample())
+		prev = &s
+	}
+}
+
+// Sample computes the figures shown by `docker stats` out of a raw payload
+func (s StatsResponse) Sample() StatsSample {
+	sample := StatsSample{
+		ID:         s.ID,
+		Name:       strings.TrimPrefix(s.Name, "/"),
+		Read:       s.Read,
+		CPUPercent: cpuPercent(s.CPUStats, s.PreCPUStats),
+		MemUsage:   memUsageNoCache(s),
+		MemLimit:   s.MemoryStats.Limit,
+		PIDs:       s.PidsStats.Current,
+	}
+	if sample.MemLimit != 0 {
+		sample.MemPercent = float64(sample.MemUsage) / float64(sample.MemLimit) * 100.0
+	}
+	for _, n := range s.Networks {
+		sample.NetRx += n.RxBytes
+		sample.NetTx += n.TxBytes
+	}
+	for _, e := range s.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(e.Op) {
+		case "read":
+			sample.BlockRead += e.Value
+		case "write":
+			sample.BlockWrite += e.Value
+		}
+	}
+	return sample
+}
+
+// cpuPercent is the share of the host CPU time used since the previous reading, times the number of CPUs:
+// a container saturating two cores shows 200%. cgroup v2 payloads have no percpu_usage, only online_cpus.
+func cpuPercent(cur, pre CPUStats) float64 {
+	cpuDelta := float64(cur.CPUUsage.TotalUsage) - float64(pre.CPUUsage.TotalUsage)
+	systemDelta := float64(cur.SystemUsage) - float64(pre.SystemUsage)
+	onlineCPUs := float64(cur.OnlineCPUs)
+	if onlineCPUs == 0 {
+		onlineCPUs = float64(len(cur.CPUUsage.PercpuUsage))
+	}
+	if systemDelta <= 0 || cpuDelta <= 0 {
+		return 0
+	}
+	return cpuDelta / systemDelta * onlineCPUs * 100.0
+}
+
+// memUsageNoCache leaves out the inactive page cache, which the kernel reclaims at will
+func memUsageNoCache(s StatsResponse) uint64 {
+	usage := s.MemoryStats.Usage
+	// cgroup v1
+	if v, ok := s.MemoryStats.Stats["total_inactive_file"]; ok && v < usage {
+		return usage - v
+	}
+	// cgroup v2
+	if v := s.MemoryStats.Stats["inactive_file"]; v < usage {
+		return usage - v
+	}
+	return usage
+}
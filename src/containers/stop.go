@@ -6,8 +6,9 @@
 package containers
 
 import (
+	"context"
+	"dtools2/extras"
 	"dtools2/rest"
-	"fmt"
 	"net/http"
 	"net/url"
 	"slices"
@@ -16,27 +17,25 @@ import (
 	"sync"
 
 	ce "github.com/jeanfrancoisgratton/customError/v3"
-	hftx "github.com/jeanfrancoisgratton/helperFunctions/v4/terminalfx"
 )
 
 // StopContainers stops one or many containers whose names are provided in the
-// containers slice. Behaviour depends on the StopTimeout value:
+// containers slice, in the reverse order of DependencyTiers(): a container is
+// stopped before those it depends on. Behaviour depends on opts.Timeout:
 //
-//   - StopTimeout > 0: containers are stopped sequentially, and the timeout
+//   - Timeout > 0: containers are stopped sequentially, and the timeout
 //     value (in seconds) is passed to the Docker/Podman API as the `t` query
 //     parameter.
-//   - StopTimeout == 0: containers are stopped concurrently using goroutines,
-//     each with a sensible default timeout.
-func StopContainers(client *rest.Client, containers []string) *ce.CustomError {
+//   - Timeout == 0: the containers of a tier are stopped concurrently using
+//     goroutines, each with a sensible default timeout.
+func StopContainers(ctx context.Context, client *rest.Client, containers []string, opts StopOptions) *ce.CustomError {
 	var (
 		cerr *ce.CustomError
 		cs   []ContainerSummary
 	)
 
-	OnlyRunningContainers = false
-
 	// Fetch the list of containers currently present on the daemon, regardless of their state.
-	if cs, cerr = ListContainers(client, false); cerr != nil {
+	if cs, cerr = ListContainers(ctx, client, ListOptions{}); cerr != nil {
 		return cerr
 	}
 
@@ -53,25 +52,50 @@ func StopContainers(client *rest.Client, containers []string) *ce.CustomError {
 	}
 
 	if len(targets) == 0 {
-		if !rest.QuietOutput {
-			fmt.Println(hftx.WarningSign("No containers to stop"))
-		}
+		opts.OnEvent.Emit(extras.Event{Resource: "container", Action: extras.EventSkipped, Message: "No containers to stop"})
 		return nil
 	}
 
-	if StopTimeout == 0 {
-		return stopContainersConcurrent(client, targets)
+	// The containers of a dependency cycle are not stopped; the others are, before the cycle is reported
+	tiers, cycleErr := DependencyTiers(ctx, client, containerNames(targets))
+	if len(tiers) == 0 {
+		return cycleErr
+	}
+	if cerr = stopTiers(ctx, client, targets, tiers, opts); cerr != nil {
+		return cerr
 	}
+	return cycleErr
+}
 
-	return stopContainersSequential(client, targets, StopTimeout)
+// stopTiers stops the targets tier after tier, from the last one; the containers of the tiers that are not
+// among the targets are left alone
+func stopTiers(ctx context.Context, client *rest.Client, targets []ContainerSummary, tiers [][]string, opts StopOptions) *ce.CustomError {
+	for _, tier := range slices.Backward(tiers) {
+		tierTargets := slices.DeleteFunc(slices.Clone(targets), func(c ContainerSummary) bool {
+			return !slices.Contains(tier, c.Names[0][1:])
+		})
+		if len(tierTargets) == 0 {
+			continue
+		}
+		var cerr *ce.CustomError
+		if opts.Timeout == 0 {
+			cerr = stopContainersConcurrent(ctx, client, tierTargets, opts.OnEvent)
+		} else {
+			cerr = stopContainersSequential(ctx, client, tierTargets, opts.Timeout, opts.OnEvent)
+		}
+		if cerr != nil {
+			return cerr
+		}
+	}
+	return nil
 }
 
 // stopContainersSequential stops all containers one after another, using the
 // provided timeout (in seconds) for the Docker/Podman API.
-func stopContainersSequential(client *rest.Client, targets []ContainerSummary, timeout int) *ce.CustomError {
+func stopContainersSequential(ctx context.Context, client *rest.Client, targets []ContainerSummary, timeout int, onEvent extras.EventFunc) *ce.CustomError {
 	for _, c := range targets {
 		name := c.Names[0][1:]
-		if cerr := stop(client, c.ID, name, timeout); cerr != nil {
+		if cerr := stop(ctx, client, c.ID, name, timeout, false, onEvent); cerr != nil {
 			return cerr
 		}
 	}
@@ -81,7 +105,7 @@ func stopContainersSequential(client *rest.Client, targets []ContainerSummary, t
 // stopContainersConcurrent stops all containers concurrently. Each container
 // is stopped with a fixed, sensible timeout. Any errors are aggregated and
 // returned as a single CustomError.
-func stopContainersConcurrent(client *rest.Client, targets []ContainerSummary) *ce.CustomError {
+func stopContainersConcurrent(ctx context.Context, client *rest.Client, targets []ContainerSummary, onEvent extras.EventFunc) *ce.CustomError {
 	const concurrentTimeout = 10 // seconds
 
 	errCh := make(chan *ce.CustomError, len(targets))
@@ -93,7 +117,7 @@ func stopContainersConcurrent(client *rest.Client, targets []ContainerSummary) *
 		go func() {
 			defer wg.Done()
 			name := c.Names[0][1:]
-			if cerr := stop(client, c.ID, name, concurrentTimeout); cerr != nil {
+			if cerr := stop(ctx, client, c.ID, name, concurrentTimeout, false, onEvent); cerr != nil {
 				errCh <- cerr
 			}
 		}()
@@ -120,27 +144,12 @@ func stopContainersConcurrent(client *rest.Client, targets []ContainerSummary) *
 	return &ce.CustomError{Title: "Errors occurred while stopping containers concurrently", Message: strings.Join(errMsgs, "; ")}
 }
 
-// stop performs the actual HTTP POST /containers/{id}/stop call.
-// If id is empty, it is resolved from containerName. If containerName is empty,
-// it is resolved from id. The timeout (in seconds) is passed as the `t` query
-// parameter when greater than zero.
-func stop(client *rest.Client, id string, containerName string, timeout int) *ce.CustomError {
-	var cerr *ce.CustomError
-	action := "/stop"
-	if id == "" {
-		if id, cerr = Name2ID(client, containerName); cerr != nil {
-			return cerr
-		}
-	}
-	if containerName == "" {
-		if containerName, cerr = ID2Name(client, id); cerr != nil {
-			return cerr
-		}
-	}
-
-	// The KillSwitch is used mainly by the Kill / KillAll commands
-	if KillSwitch {
-		action = "/kill"
+// stop performs the actual HTTP POST /containers/{id}/stop (or /kill) call.
+// The timeout (in seconds) is passed as the `t` query parameter when greater than zero.
+func stop(ctx context.Context, client *rest.Client, id string, containerName string, timeout int, kill bool, onEvent extras.EventFunc) *ce.CustomError {
+	action, event := "/stop", extras.EventStopped
+	if kill {
+		action, event = "/kill", extras.EventKilled
 	}
 	path := "/containers/" + id + action
 	q := url.Values{}
@@ -149,46 +158,33 @@ func stop(client *rest.Client, id string, containerName string, timeout int) *ce
 		q.Set("t", strconv.Itoa(timeout))
 	}
 
-	resp, err := client.Do(rest.Context, http.MethodPost, path, q, nil, nil)
+	// The daemon waits up to `timeout` seconds before answering a stop; it bounds the call itself.
+	resp, err := client.Do(rest.FollowContext(ctx), http.MethodPost, path, q, nil, nil)
 	if err != nil {
 		return &ce.CustomError{Title: "Unable to stop/kill the container " + containerName, Message: err.Error()}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
-		return &ce.CustomError{Title: "http request returned an error", Message: "POST " + path + " returned " + resp.Status}
-	}
-
-	if !rest.QuietOutput {
-		fmt.Println(hftx.InProgressSign("Container " + containerName + hftx.Red(" STOPPED")))
+	// 304: the container was already stopped.
+	if aerr := rest.CheckResponse(resp); aerr != nil && !rest.IsNotModified(aerr) {
+		return &ce.CustomError{Title: "Unable to stop/kill the container " + containerName, Message: aerr.Error()}
 	}
 
+	onEvent.Emit(extras.Event{Resource: "container", Name: containerName, Action: event})
 	return nil
 }
 
-// StopAllContainers builds a list of all containers present on the daemon and delegates
+// StopAllContainers builds a list of all running containers and delegates
 // to StopContainers, so it benefits from the timeout and concurrency logic.
-func StopAllContainers(client *rest.Client) *ce.CustomError {
-	var (
-		cerr *ce.CustomError
-		cs   []ContainerSummary
-	)
-
-	OnlyRunningContainers = true
-
-	// Fetch the list of containers currently present on the daemon, regardless of their state.
-	if cs, cerr = ListContainers(client, false); cerr != nil {
+func StopAllContainers(ctx context.Context, client *rest.Client, opts StopOptions) *ce.CustomError {
+	cs, cerr := ListContainers(ctx, client, ListOptions{OnlyRunning: true})
+	if cerr != nil {
 		return cerr
 	}
 
-	var containerList []string
-	for _, c := range cs {
-		containerList = append(containerList, c.Names[0][1:])
-	}
-
-	if len(containerList) == 0 {
-		fmt.Println(hftx.NoteSign("Not a single container is running, STOPALL is thus un-needed"))
+	if len(cs) == 0 {
+		opts.OnEvent.Emit(extras.Event{Resource: "container", Action: extras.EventSkipped, Message: "Not a single container is running, STOPALL is thus un-needed"})
 		return nil
 	}
-	return StopContainers(client, containerList)
+	return StopContainers(ctx, client, containerNames(cs), opts)
 }
@@ -0,0 +1,153 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 07:30
+// Original filename: src/containers/stop_test.go
+
+package containers
+
+import (
+	"context"
+	"dtools2/rest/fakedaemon"
+	"slices"
+	"testing"
+)
+
+func TestStopContainers(t *testing.T) {
+	for _, timeout := range []int{1, 0} { // sequential, then concurrent
+		d, client := fakedaemon.StartT(t)
+
+		db := d.AddContainer(fakedaemon.Container{Name: "db", Image: "postgres", State: fakedaemon.StateRunning})
+		app := d.AddContainer(fakedaemon.Container{Name: "app", Image: "nginx", State: fakedaemon.StateRunning,
+			Labels: map[string]string{LabelDependsOn: "db"}})
+		d.AddContainer(fakedaemon.Container{Name: "other", Image: "nginx", State: fakedaemon.StateRunning})
+		done := d.AddContainer(fakedaemon.Container{Name: "done", Image: "nginx", State: fakedaemon.StateExited})
+
+		if cerr := StopContainers(context.Background(), client, []string{"db", "app", "done"}, StopOptions{Timeout: timeout}); cerr != nil {
+			t.Fatal(cerr)
+		}
+
+		for _, c := range d.Containers() {
+			want := fakedaemon.StateExited
+			if c.Name == "other" {
+				want = fakedaemon.StateRunning
+			}
+			if c.State != want {
+				t.Errorf("timeout %d: %s is %s, want %s", timeout, c.Name, c.State, want)
+			}
+		}
+
+		// app depends on db, so it goes first; done was not running, so it is left alone
+		var stops []string
+		for _, r := range d.Requests() {
+			switch r {
+			case "POST /containers/" + app.ID + "/stop":
+				stops = append(stops, "app")
+			case "POST /containers/" + db.ID + "/stop":
+				stops = append(stops, "db")
+			}
+		}
+		if !slices.Equal(stops, []string{"app", "db"}) {
+			t.Errorf("timeout %d: stop order %v, want [app db]", timeout, stops)
+		}
+		if slices.Contains(d.Requests(), "POST /containers/"+done.ID+"/stop") {
+			t.Errorf("timeout %d: the exited container was stopped", timeout)
+		}
+	}
+}
+
+func TestStopContainersCycle(t *testing.T) {
+	d, client := fakedaemon.StartT(t)
+
+	d.AddContainer(fakedaemon.Container{Name: "a", Image: "nginx", State: fakedaemon.StateRunning, Labels: map[string]string{LabelDependsOn: "b"}})
+	d.AddContainer(fakedaemon.Container{Name: "b", Image: "nginx", State: fakedaemon.StateRunning, Labels: map[string]string{LabelDependsOn: "a"}})
+	d.AddContainer(fakedaemon.Container{Name: "web", Image: "nginx", State: fakedaemon.StateRunning, Labels: map[string]string{LabelDependsOn: "a"}})
+
+	cerr := StopContainers(context.Background(), client, []string{"a", "b", "web"}, StopOptions{Timeout: 1})
+	if cerr == nil || cerr.Title != "Dependency cycle" {
+		t.Fatalf("got %v, want the dependency cycle", cerr)
+	}
+	for _, c := range d.Containers() {
+		want := fakedaemon.StateRunning
+		if c.Name == "web" {
+			want = fakedaemon.StateExited
+		}
+		if c.State != want {
+			t.Errorf("%s is %s, want %s", c.Name, c.State, want)
+		}
+	}
+}
+
+func TestRestartKillOrder(t *testing.T) {
+	d, client := fakedaemon.StartT(t)
+
+	db := d.AddContainer(fakedaemon.Container{Name: "db", Image: "postgres", State: fakedaemon.StateRunning})
+	app := d.AddContainer(fakedaemon.Container{Name: "app", Image: "nginx", State: fakedaemon.StateRunning,
+		Labels: map[string]string{LabelDependsOn: "db"}})
+
+	if cerr := RestartContainers(context.Background(), client, []string{"db", "app", "ghost"}, StopOptions{Kill: true}); cerr != nil {
+		t.Fatal(cerr)
+	}
+
+	// app is killed before db, and started after it
+	var calls []string
+	for _, r := range d.Requests() {
+		for _, c := range []fakedaemon.Container{db, app} {
+			switch r {
+			case "POST /containers/" + c.ID + "/kill":
+				calls = append(calls, "kill "+c.Name)
+			case "POST /containers/" + c.Name + "/start":
+				calls = append(calls, "start "+c.Name)
+			}
+		}
+	}
+	if want := []string{"kill app", "kill db", "start db", "start app"}; !slices.Equal(calls, want) {
+		t.Errorf("calls %v, want %v", calls, want)
+	}
+	// The dependencies are looked up once for the whole restart
+	for _, c := range []fakedaemon.Container{db, app} {
+		if n := countRequests(d, "GET /containers/"+c.Name+"/json"); n != 1 {
+			t.Errorf("%s inspected %d times, want 1", c.Name, n)
+		}
+	}
+	for _, c := range d.Containers() {
+		if c.State != fakedaemon.StateRunning {
+			t.Errorf("%s is %s, want running", c.Name, c.State)
+		}
+	}
+}
+
+func TestKillContainersCycle(t *testing.T) {
+	d, client := fakedaemon.StartT(t)
+
+	a := d.AddContainer(fakedaemon.Container{Name: "a", Image: "nginx", State: fakedaemon.StateRunning, Labels: map[string]string{LabelDependsOn: "b"}})
+	b := d.AddContainer(fakedaemon.Container{Name: "b", Image: "nginx", State: fakedaemon.StateRunning, Labels: map[string]string{LabelDependsOn: "a"}})
+	web := d.AddContainer(fakedaemon.Container{Name: "web", Image: "nginx", State: fakedaemon.StateRunning, Labels: map[string]string{LabelDependsOn: "db"}})
+	db := d.AddContainer(fakedaemon.Container{Name: "db", Image: "postgres", State: fakedaemon.StateRunning})
+
+	// Everything is killed, the cycle last, before the unknown name fails
+	cerr := KillContainers(context.Background(), client, []string{"a", "ghost", "b", "db", "web"}, nil)
+	if cerr == nil {
+		t.Fatal("killing an unknown container did not fail")
+	}
+	var kills []string
+	for _, r := range d.Requests() {
+		for _, c := range []fakedaemon.Container{a, b, web, db} {
+			if r == "POST /containers/"+c.ID+"/kill" {
+				kills = append(kills, c.Name)
+			}
+		}
+	}
+	if want := []string{"web", "db", "a", "b"}; !slices.Equal(kills, want) {
+		t.Errorf("kill order %v, want %v", kills, want)
+	}
+}
+
+func countRequests(d *fakedaemon.Daemon, request string) int {
+	n := 0
+	for _, r := range d.Requests() {
+		if r == request {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,55 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 21:40
+// Original filename: src/containers/top.go
+
+package containers
+
+import (
+	"context"
+	"dtools2/rest"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+)
+
+// Top lists the processes running in a container; psArgs are the ps options (-ef when empty)
+func Top(ctx context.Context, client *rest.Client, container, psArgs string) (*TopResponse, *ce.CustomError) {
+	q := url.Values{}
+	if psArgs != "" {
+		q.Set("ps_args", psArgs)
+	}
+
+	resp, err := client.Do(ctx, http.MethodGet, "/containers/"+container+"/top", q, nil, nil)
+	if err != nil {
+		return nil, &ce.CustomError{Title: "Unable to list the processes of " + container, Message: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	if aerr := rest.CheckResponse(resp); aerr != nil {
+		return nil, &ce.CustomError{Title: "Unable to list the processes of " + container, Message: aerr.Error()}
+	}
+
+	var top TopResponse
+	if err := json.NewDecoder(resp.Body).Decode(&top); err != nil {
+		return nil, &ce.CustomError{Title: "Unable to decode JSON", Message: err.Error()}
+	}
+	return &top, nil
+}
+
+// ProcessList returns one map per process, keyed by the ps column titles (PID, USER, CMD...)
+func (t TopResponse) ProcessList() []map[string]string {
+	out := make([]map[string]string, 0, len(t.Processes))
+	for _, p := range t.Processes {
+		row := make(map[string]string, len(t.Titles))
+		for i, title := range t.Titles {
+			if i < len(p) {
+				row[title] = p[i]
+			}
+		}
+		out = append(out, row)
+	}
+	return out
+}
@@ -5,19 +5,64 @@
 
 package containers
 
-var OnlyRunningContainers bool
-var ExtendedContainerInfo bool
-var DisplaySizeValues bool = false
-var RemoveBlacklisted bool = false
-var RemoveUnamedVolumes = true
-var KillSwitch = false
-var ForceRemoveContainer = false
-
-// StopTimeout controls stop behaviour:
-//
-//	>0 => sequential stop, value passed as Docker/Podman `t` parameter
-//	 0 => concurrent stop, internal default timeout used per container
-var StopTimeout int = 10
+import (
+	"dtools2/extras"
+	"time"
+)
+
+// ListOptions controls ListContainers().
+type ListOptions struct {
+	OnlyRunning bool // skip the stopped containers
+	Size        bool // have the daemon compute SizeRw and SizeRootFs (slow)
+	Filters     extras.Filters
+}
+
+// Selector picks containers for the bulk operations; the criteria are AND'ed, the values of a same criterion OR'ed.
+type Selector struct {
+	Labels    []string // key or key=value
+	NameGlobs []string // shell patterns matched against the whole name (web-*)
+	Images    []string // the image the container was created from, or one of its ancestors
+	States    []string // created, restarting, running, removing, paused, exited or dead
+}
+
+// StartOptions controls the start functions; the containers are started in dependency order (see DependencyTiers()).
+type StartOptions struct {
+	WaitHealthy bool // wait for the containers of a tier to be healthy before starting the next one
+	OnEvent     extras.EventFunc
+}
+
+// StopOptions controls the stop, kill and restart functions; the containers are stopped in reverse dependency order.
+type StopOptions struct {
+	// Timeout controls stop behaviour:
+	//
+	//	>0 => sequential stop, value passed as Docker/Podman `t` parameter
+	//	 0 => concurrent stop within a dependency tier, internal default timeout used per container
+	Timeout     int
+	Kill        bool // restart only: kill the containers instead of stopping them
+	WaitHealthy bool // restart only: see StartOptions
+	OnEvent     extras.EventFunc
+}
+
+// RemoveOptions controls RemoveContainer().
+type RemoveOptions struct {
+	Force           bool // remove running containers too
+	RemoveVolumes   bool // also remove the anonymous volumes
+	IgnoreBlacklist bool // remove blacklisted containers anyway
+	OnEvent         extras.EventFunc
+}
+
+// UpdateOptions controls UpdateContainers(): the new limits and restart policy; what is left empty is unchanged.
+type UpdateOptions struct {
+	Resources extras.ResourceOptions
+	Restart   string // no, always, unless-stopped or on-failure[:max-retries]
+	OnEvent   extras.EventFunc
+}
+
+// UpdateRequest is the JSON body for POST /containers/{id}/update.
+type UpdateRequest struct {
+	extras.Resources
+	RestartPolicy *extras.RestartPolicy `json:"RestartPolicy,omitempty"`
+}
 
 type PortsStruct struct {
 	PrivatePort uint16 `json:"PrivatePort"`
@@ -34,6 +79,12 @@ type MountsStruct struct {
 	RW          bool   `json:"RW"`
 }
 
+// HealthSummary is the healthcheck state of a container, as listed by the daemon
+type HealthSummary struct {
+	Status        string `json:"Status"` // starting, healthy, unhealthy or none
+	FailingStreak int    `json:"FailingStreak"`
+}
+
 type ContainerSummary struct {
 	ID              string                    `json:"Id"`
 	Names           []string                  `json:"Names"`
@@ -49,6 +100,7 @@ type ContainerSummary struct {
 	Status          string                    `json:"Status"`
 	Mounts          []MountsStruct            `json:"Mounts"`
 	NetworkSettings *ContainerNetworkSettings `json:"NetworkSettings,omitempty"`
+	Health          *HealthSummary            `json:"Health,omitempty"` // API 1.52+ only; see HealthStatus()
 	//Networks        map[string]EndpointSummary `json:"Networks,omitempty"`
 }
 
@@ -67,3 +119,90 @@ type EndpointSummary struct {
 	GlobalIPv6PrefixLen int    `json:"GlobalIPv6PrefixLen,omitempty"`
 	MacAddress          string `json:"MacAddress,omitempty"`
 }
+
+// StatsOptions controls Stats().
+type StatsOptions struct {
+	Stream bool // keep sampling until ctx is done; otherwise a single sample per container
+
+	// Called for every sample as it arrives; calls are serialized even though each container
+	// is read from its own goroutine.
+	OnSample func(StatsSample)
+}
+
+// StatsSample is the computed reading of one container, as shown by `dtools stats`
+type StatsSample struct {
+	ID         string    `json:"ID"`
+	Name       string    `json:"Name"`
+	Read       time.Time `json:"Read"`
+	CPUPercent float64   `json:"CPUPercent"`
+	MemUsage   uint64    `json:"MemUsage"` // without the page cache, as docker computes it
+	MemLimit   uint64    `json:"MemLimit"`
+	MemPercent float64   `json:"MemPercent"`
+	NetRx      uint64    `json:"NetRx"`
+	NetTx      uint64    `json:"NetTx"`
+	BlockRead  uint64    `json:"BlockRead"`
+	BlockWrite uint64    `json:"BlockWrite"`
+	PIDs       uint64    `json:"PIDs"`
+}
+
+// StatsResponse matches (partially) one payload of GET /containers/{id}/stats, cgroup v1 and v2 alike
+type StatsResponse struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Read        time.Time `json:"read"`
+	PidsStats   PidsStats `json:"pids_stats"`
+	CPUStats    CPUStats  `json:"cpu_stats"`
+	PreCPUStats CPUStats  `json:"precpu_stats"`
+	MemoryStats struct {
+		Usage uint64            `json:"usage"`
+		Limit uint64            `json:"limit"`
+		Stats map[string]uint64 `json:"stats"` // cgroup v1: total_inactive_file...; v2: inactive_file...
+	} `json:"memory_stats"`
+	BlkioStats struct {
+		IoServiceBytesRecursive []BlkioStatEntry `json:"io_service_bytes_recursive"`
+	} `json:"blkio_stats"`
+	Networks map[string]NetworkStats `json:"networks"`
+}
+
+type PidsStats struct {
+	Current uint64 `json:"current"`
+}
+
+type CPUStats struct {
+	CPUUsage struct {
+		TotalUsage  uint64   `json:"total_usage"`
+		PercpuUsage []uint64 `json:"percpu_usage,omitempty"` // cgroup v1 only
+	} `json:"cpu_usage"`
+	SystemUsage uint64 `json:"system_cpu_usage"`
+	OnlineCPUs  uint32 `json:"online_cpus"`
+}
+
+type BlkioStatEntry struct {
+	Op    string `json:"op"` // "Read"/"Write" with cgroup v1, "read"/"write" with v2
+	Value uint64 `json:"value"`
+}
+
+type NetworkStats struct {
+	RxBytes uint64 `json:"rx_bytes"`
+	TxBytes uint64 `json:"tx_bytes"`
+}
+
+// TopResponse matches the JSON returned by GET /containers/{id}/top: the columns printed by ps, and one row per process
+type TopResponse struct {
+	Titles    []string   `json:"Titles"`
+	Processes [][]string `json:"Processes"`
+}
+
+// FilesystemChange matches an entry of GET /containers/{id}/changes
+type FilesystemChange struct {
+	Path string `json:"Path"`
+	Kind int    `json:"Kind"`           // one of the Change* constants
+	Type string `json:"Type,omitempty"` // internal use, not part of the REST API: C, A or D, as shown by docker diff
+}
+
+// FilesystemChange.Kind values
+const (
+	ChangeModified = 0
+	ChangeAdded    = 1
+	ChangeDeleted  = 2
+)
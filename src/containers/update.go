@@ -0,0 +1,76 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 21:55
+// Original filename: src/containers/update.go
+
+package containers
+
+import (
+	"bytes"
+	"context"
+	"dtools2/extras"
+	"dtools2/rest"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+)
+
+// UpdateContainers changes the resource limits and/or the restart policy of live containers
+func UpdateContainers(ctx context.Context, client *rest.Client, containers []string, opts UpdateOptions) *ce.CustomError {
+	var req UpdateRequest
+	var cerr *ce.CustomError
+
+	if req.Resources, cerr = opts.Resources.Resources(); cerr != nil {
+		return cerr
+	}
+	if len(req.Ulimits) > 0 {
+		return &ce.CustomError{Title: "Unable to update the containers", Message: "ulimits cannot be changed on existing containers"}
+	}
+	if opts.Restart != "" {
+		policy, cerr := extras.ParseRestartPolicy(opts.Restart)
+		if cerr != nil {
+			return cerr
+		}
+		req.RestartPolicy = &policy
+	}
+	if req.Resources.IsZero() && req.RestartPolicy == nil {
+		return &ce.CustomError{Title: "Nothing to update", Message: "pass at least one resource limit or a restart policy"}
+	}
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return &ce.CustomError{Title: "Unable to marshal container update request", Message: err.Error()}
+	}
+	headers := http.Header{}
+	headers.Set("Content-Type", "application/json")
+
+	for _, container := range containers {
+		id, cerr := Name2ID(ctx, client, container)
+		if cerr != nil {
+			return cerr
+		}
+
+		resp, err := client.Do(ctx, http.MethodPost, "/containers/"+id+"/update", url.Values{}, bytes.NewReader(payload), headers)
+		if err != nil {
+			return &ce.CustomError{Title: "Unable to POST request", Message: err.Error()}
+		}
+		if aerr := rest.CheckResponse(resp); aerr != nil {
+			resp.Body.Close()
+			return &ce.CustomError{Title: "Unable to update container " + container, Message: aerr.Error()}
+		}
+
+		var out struct {
+			Warnings []string `json:"Warnings"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&out)
+		resp.Body.Close()
+		if err != nil {
+			return &ce.CustomError{Title: "Unable to decode container update response", Message: err.Error()}
+		}
+		opts.OnEvent.Emit(extras.Event{Resource: "container", Name: container, Action: extras.EventUpdated, Message: strings.Join(out.Warnings, "; ")})
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 11:21
+// Original filename: src/contexts/add_remove_use.go
+
+package contexts
+
+import (
+	"dtools2/extras"
+	"dtools2/rest"
+	"fmt"
+	"slices"
+	"strings"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+	hftx "github.com/jeanfrancoisgratton/helperFunctions/v4/terminalfx"
+)
+
+// Create adds (or replaces) a named context
+func Create(name string, cfg rest.Config) *ce.CustomError {
+	name = strings.TrimSpace(name)
+	if name == "" || name == DefaultContext {
+		return &ce.CustomError{Title: "Invalid context name", Message: fmt.Sprintf("%q is reserved or empty", name)}
+	}
+	if cfg.Host == "" {
+		return &ce.CustomError{Title: "Invalid context", Message: "a context needs a host (-H)"}
+	}
+
+	cs, err := Load()
+	if err != nil {
+		return err
+	}
+
+	ctx := Context{Name: name, Description: ContextDescription, Config: cfg}
+	if i := cs.find(name); i >= 0 {
+		cs.Contexts[i] = ctx
+	} else {
+		cs.Contexts = append(cs.Contexts, ctx)
+	}
+	if err := cs.Save(); err != nil {
+		return err
+	}
+
+	if !extras.QuietOutput {
+		fmt.Println(hftx.EnabledSign("Context " + name + " created"))
+	}
+	return nil
+}
+
+// Remove deletes the named contexts. Removing the current context requires ForceContextRemoval,
+// and resets the current context to the default one. Nothing is removed unless all the names are valid.
+func Remove(names []string) *ce.CustomError {
+	cs, err := Load()
+	if err != nil {
+		return err
+	}
+
+	var toRemove []string
+	for _, name := range names {
+		if cs.find(name) < 0 {
+			return &ce.CustomError{Title: "Unable to remove context", Message: "context " + name + " does not exist"}
+		}
+		if cs.Current == name && !ForceContextRemoval {
+			return &ce.CustomError{Title: "Unable to remove context", Message: name + " is the current context (use -f to force)"}
+		}
+		if !slices.Contains(toRemove, name) {
+			toRemove = append(toRemove, name)
+		}
+	}
+
+	for _, name := range toRemove {
+		if cs.Current == name {
+			cs.Current = ""
+		}
+		i := cs.find(name)
+		cs.Contexts = append(cs.Contexts[:i], cs.Contexts[i+1:]...)
+	}
+	if err := cs.Save(); err != nil {
+		return err
+	}
+
+	if !extras.QuietOutput {
+		for _, name := range toRemove {
+			fmt.Println(hftx.EnabledSign("Context " + name + " removed"))
+		}
+	}
+	return nil
+}
+
+// Use makes the named context the current one; "default" goes back to flags/envs only
+func Use(name string) *ce.CustomError {
+	cs, err := Load()
+	if err != nil {
+		return err
+	}
+
+	if name == DefaultContext {
+		cs.Current = ""
+	} else {
+		if cs.find(name) < 0 {
+			return &ce.CustomError{Title: "Unable to switch context", Message: "context " + name + " does not exist"}
+		}
+		cs.Current = name
+	}
+	if err := cs.Save(); err != nil {
+		return err
+	}
+
+	if !extras.QuietOutput {
+		fmt.Println(hftx.EnabledSign("Current context is now " + name))
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 11:52
+// Original filename: src/contexts/list_inspect.go
+
+package contexts
+
+import (
+	"dtools2/extras"
+	"encoding/json"
+	"os"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+	hfjson "github.com/jeanfrancoisgratton/helperFunctions/v4/prettyjson"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// ListContexts displays all contexts, the active one being flagged with a star
+func ListContexts(active string) *ce.CustomError {
+	cs, err := Load()
+	if err != nil {
+		return err
+	}
+
+	if extras.OutputJSON {
+		payload, jerr := json.MarshalIndent(cs, "", "  ")
+		if jerr != nil {
+			return &ce.CustomError{Title: "Unable to marshal JSON", Message: jerr.Error()}
+		}
+		hfjson.Print(payload)
+		return nil
+	}
+	if extras.QuietOutput {
+		return nil
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Name", "Description", "Host", "TLS"})
+
+	current := ""
+	if active == DefaultContext {
+		current = " *"
+	}
+	t.AppendRow(table.Row{DefaultContext + current, "flags and DOCKER_* environment", os.Getenv("DOCKER_HOST"), ""})
+	for _, c := range cs.Contexts {
+		name := c.Name
+		if c.Name == active {
+			name += " *"
+		}
+		tls := ""
+		if c.Config.UseTLS {
+			tls = "yes"
+		}
+		t.AppendRow(table.Row{name, c.Description, c.Config.Host, tls})
+	}
+	t.SetStyle(table.StyleBold)
+	t.Style().Format.Header = text.FormatDefault
+	t.Render()
+	return nil
+}
+
+// InspectContexts dumps the named contexts as JSON
+func InspectContexts(names []string) *ce.CustomError {
+	var found []Context
+	for _, name := range names {
+		c, err := Get(name)
+		if err != nil {
+			return err
+		}
+		found = append(found, *c)
+	}
+
+	payload, jerr := json.MarshalIndent(found, "", "  ")
+	if jerr != nil {
+		return &ce.CustomError{Title: "Unable to marshal JSON", Message: jerr.Error()}
+	}
+	hfjson.Print(payload)
+	return nil
+}
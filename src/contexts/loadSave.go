@@ -0,0 +1,68 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 11:09
+// Original filename: src/contexts/loadSave.go
+
+package contexts
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+)
+
+// Load loads the contexts file. If the file does not exist or is empty,
+// it returns an empty ContextStore without error.
+func Load() (*ContextStore, *ce.CustomError) {
+	path := filepath.Join(os.Getenv("HOME"), ".config", "JFG", "dtools", ContextsFile)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &ContextStore{}, nil
+		}
+		return nil, &ce.CustomError{Title: "cannot read contexts file " + path, Message: err.Error()}
+	}
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		return &ContextStore{}, nil
+	}
+
+	var cs ContextStore
+	if err := json.Unmarshal(data, &cs); err != nil {
+		return nil, &ce.CustomError{Title: "cannot parse contexts JSON " + path, Message: err.Error()}
+	}
+
+	return &cs, nil
+}
+
+// Save writes the contexts file to disk
+func (cs *ContextStore) Save() *ce.CustomError {
+	path := filepath.Join(os.Getenv("HOME"), ".config", "JFG", "dtools", ContextsFile)
+
+	data, err := json.MarshalIndent(cs, "", "  ")
+	if err != nil {
+		return &ce.CustomError{Title: "cannot marshal contexts struct", Message: err.Error()}
+	}
+
+	// 0600: contexts may reference client keys
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return &ce.CustomError{Title: "cannot write contexts file " + path, Message: err.Error()}
+	}
+
+	return nil
+}
+
+// find returns the index of the named context, -1 if absent
+func (cs *ContextStore) find(name string) int {
+	for i, c := range cs.Contexts {
+		if c.Name == name {
+			return i
+		}
+	}
+	return -1
+}
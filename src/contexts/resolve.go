@@ -0,0 +1,43 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 11:40
+// Original filename: src/contexts/resolve.go
+
+package contexts
+
+import (
+	"os"
+	"strings"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+)
+
+// ActiveName returns the context to use, and whether it was explicitly requested.
+// Order: the --context flag, then DTOOLS_CONTEXT, then the current context saved with `context use`.
+func ActiveName(flagValue string) (string, bool) {
+	if name := strings.TrimSpace(flagValue); name != "" {
+		return name, true
+	}
+	if name := strings.TrimSpace(os.Getenv("DTOOLS_CONTEXT")); name != "" {
+		return name, true
+	}
+
+	cs, err := Load()
+	if err != nil || cs.Current == "" {
+		return DefaultContext, false
+	}
+	return cs.Current, false
+}
+
+// Get returns the named context
+func Get(name string) (*Context, *ce.CustomError) {
+	cs, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	i := cs.find(name)
+	if i < 0 {
+		return nil, &ce.CustomError{Title: "Unknown context", Message: "context " + name + " does not exist"}
+	}
+	return &cs.Contexts[i], nil
+}
@@ -0,0 +1,28 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 11:05
+// Original filename: src/contexts/types.go
+
+package contexts
+
+import "dtools2/rest"
+
+// DefaultContext is the implicit context: connection parameters come from flags and DOCKER_* envs only.
+const DefaultContext = "default"
+
+var ContextsFile = "contexts.json"
+var ContextDescription string
+var ForceContextRemoval bool
+
+// Context is a named set of connection parameters
+type Context struct {
+	Name        string      `json:"Name"`
+	Description string      `json:"Description,omitempty"`
+	Config      rest.Config `json:"Config"`
+}
+
+// ContextStore is the on-disk layout of the contexts file
+type ContextStore struct {
+	Current  string    `json:"Current,omitempty"`
+	Contexts []Context `json:"Contexts,omitempty"`
+}
@@ -0,0 +1,44 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 16:05
+// Original filename: src/extras/events.go
+
+package extras
+
+// EventAction tells what happened to a resource during an operation.
+type EventAction string
+
+const (
+	EventCreated      EventAction = "created"
+	EventStarted      EventAction = "started"
+	EventStopped      EventAction = "stopped"
+	EventKilled       EventAction = "killed"
+	EventPaused       EventAction = "paused"
+	EventUnpaused     EventAction = "unpaused"
+	EventRemoved      EventAction = "removed"
+	EventUpdated      EventAction = "updated"
+	EventConnected    EventAction = "connected"
+	EventDisconnected EventAction = "disconnected"
+	EventHealthy      EventAction = "healthy"
+	EventBlacklisted  EventAction = "blacklisted" // blacklisted, but removed anyway as the caller asked for it
+	EventSkipped      EventAction = "skipped"     // Message says why; an empty Name means the whole operation was a no-op
+)
+
+// Event is emitted by the library functions working on several resources (start, stop, remove, prune...),
+// so that the caller can report progress as it happens. Nothing is ever printed by the library itself.
+type Event struct {
+	Resource string // container, image, volume, network
+	Name     string
+	Action   EventAction
+	Message  string
+}
+
+// EventFunc receives the events. It may be called from several goroutines at once (concurrent stop).
+type EventFunc func(Event)
+
+// Emit calls f when it is set; a nil EventFunc simply drops the events.
+func (f EventFunc) Emit(e Event) {
+	if f != nil {
+		f(e)
+	}
+}
@@ -7,6 +7,7 @@ package extras
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -26,55 +27,48 @@ import (
 	xterm "golang.org/x/term"
 )
 
-// Flags (wired from Cobra).
-var (
-	Interactive bool
-	AllocateTTY bool
-	User        string
-)
-
 // Run emulates `docker exec`.
 //
 // It returns the remote command's exit code (like docker exec does).
 // If a transport/protocol error occurs, a CustomError is returned and the caller
 // should exit with code 1.
-func Run(client *rest.Client, container string, cmd []string) (int, *ce.CustomError) {
+func Run(ctx context.Context, client *rest.Client, container string, cmd []string, opts ExecOptions) (int, *ce.CustomError) {
 	if len(cmd) == 0 {
 		return 1, &ce.CustomError{Title: "Missing command", Message: "no command specified"}
 	}
 
 	// Docker CLI errors when -it is used but stdin isn't a TTY.
-	if AllocateTTY && Interactive {
+	if opts.TTY && opts.Interactive {
 		if !xterm.IsTerminal(int(os.Stdin.Fd())) {
 			return 1, &ce.CustomError{Title: "The input device is not a TTY", Message: "cannot allocate a TTY with non-terminal stdin"}
 		}
 	}
 
-	execID, cerr := createExec(client, container, cmd)
+	execID, cerr := createExec(ctx, client, container, cmd, opts)
 	if cerr != nil {
 		return 1, cerr
 	}
 
 	// Start the exec session (hijacked connection).
-	if cerr := startAndStream(client, execID); cerr != nil {
+	if cerr := startAndStream(ctx, client, execID, opts); cerr != nil {
 		return 1, cerr
 	}
 
-	exitCode, cerr := inspectExitCode(client, execID)
+	exitCode, cerr := inspectExitCode(ctx, client, execID)
 	if cerr != nil {
 		return 1, cerr
 	}
 	return exitCode, nil
 }
 
-func createExec(client *rest.Client, container string, cmd []string) (string, *ce.CustomError) {
+func createExec(ctx context.Context, client *rest.Client, container string, cmd []string, opts ExecOptions) (string, *ce.CustomError) {
 	req := ExecCreateRequest{
-		AttachStdin:  Interactive,
+		AttachStdin:  opts.Interactive,
 		AttachStdout: true,
 		AttachStderr: true,
-		Tty:          AllocateTTY,
+		Tty:          opts.TTY,
 		Cmd:          cmd,
-		User:         User,
+		User:         opts.User,
 	}
 
 	payload, err := json.Marshal(req)
@@ -86,19 +80,14 @@ func createExec(client *rest.Client, container string, cmd []string) (string, *c
 	headers := http.Header{}
 	headers.Set("Content-Type", "application/json")
 
-	resp, err := client.Do(rest.Context, http.MethodPost, path, nil, bytes.NewReader(payload), headers)
+	resp, err := client.Do(ctx, http.MethodPost, path, nil, bytes.NewReader(payload), headers)
 	if err != nil {
 		return "", &ce.CustomError{Title: "Unable to create exec instance", Message: err.Error()}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusCreated {
-		b, _ := io.ReadAll(resp.Body)
-		msg := StringsTrim(string(b))
-		if msg == "" {
-			msg = resp.Status
-		}
-		return "", &ce.CustomError{Title: "Exec create failed", Message: fmt.Sprintf("POST %s returned %s", path, msg)}
+	if aerr := rest.CheckResponse(resp); aerr != nil {
+		return "", &ce.CustomError{Title: "Exec create failed", Message: aerr.Error()}
 	}
 
 	var out ExecCreateResponse
@@ -111,8 +100,8 @@ func createExec(client *rest.Client, container string, cmd []string) (string, *c
 	return out.ID, nil
 }
 
-func startAndStream(client *rest.Client, execID string) *ce.CustomError {
-	startReq := ExecStartRequest{Detach: false, Tty: AllocateTTY}
+func startAndStream(ctx context.Context, client *rest.Client, execID string, opts ExecOptions) *ce.CustomError {
+	startReq := ExecStartRequest{Detach: false, Tty: opts.TTY}
 	payload, err := json.Marshal(startReq)
 	if err != nil {
 		return &ce.CustomError{Title: "Unable to marshal exec start request", Message: err.Error()}
@@ -122,7 +111,7 @@ func startAndStream(client *rest.Client, execID string) *ce.CustomError {
 	headers := http.Header{}
 	headers.Set("Content-Type", "application/json")
 
-	hj, err := client.Hijack(rest.Context, http.MethodPost, path, nil, headers, payload, true)
+	hj, err := client.Hijack(ctx, http.MethodPost, path, nil, headers, payload, true)
 	if err != nil {
 		return &ce.CustomError{Title: "Unable to start exec session", Message: err.Error()}
 	}
@@ -133,7 +122,7 @@ func startAndStream(client *rest.Client, execID string) *ce.CustomError {
 	done := make(chan struct{})
 	go func() {
 		select {
-		case <-rest.Context.Done():
+		case <-ctx.Done():
 			_ = conn.Close()
 		case <-done:
 		}
@@ -145,7 +134,7 @@ func startAndStream(client *rest.Client, execID string) *ce.CustomError {
 
 	// Terminal raw mode + resize when allocating TTY.
 	var restoreTerm func()
-	if AllocateTTY && Interactive && xterm.IsTerminal(int(os.Stdin.Fd())) {
+	if opts.TTY && opts.Interactive && xterm.IsTerminal(int(os.Stdin.Fd())) {
 		oldState, err := xterm.MakeRaw(int(os.Stdin.Fd()))
 		if err == nil {
 			restoreTerm = func() { _ = xterm.Restore(int(os.Stdin.Fd()), oldState) }
@@ -155,8 +144,8 @@ func startAndStream(client *rest.Client, execID string) *ce.CustomError {
 		defer restoreTerm()
 	}
 
-	if AllocateTTY {
-		setupResizeHandler(client, execID)
+	if opts.TTY {
+		setupResizeHandler(ctx, client, execID)
 	}
 
 	// Stream.
@@ -165,11 +154,11 @@ func startAndStream(client *rest.Client, execID string) *ce.CustomError {
 	stdinStop := make(chan struct{})
 
 	// stdin
-	if Interactive {
+	if opts.Interactive {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			CopyStdin(conn, stdinStop)
+			CopyStdin(ctx, conn, stdinStop)
 			if cw, ok := conn.(interface{ CloseWrite() error }); ok {
 				_ = cw.CloseWrite()
 			}
@@ -180,7 +169,7 @@ func startAndStream(client *rest.Client, execID string) *ce.CustomError {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		if AllocateTTY {
+		if opts.TTY {
 			_, _ = io.Copy(os.Stdout, reader)
 		} else {
 			_, _ = stdcopy.StdCopy(os.Stdout, os.Stderr, reader)
@@ -197,22 +186,17 @@ func errorsIsWouldBlock(err error) bool {
 	return errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.EWOULDBLOCK)
 }
 
-func inspectExitCode(client *rest.Client, execID string) (int, *ce.CustomError) {
+func inspectExitCode(ctx context.Context, client *rest.Client, execID string) (int, *ce.CustomError) {
 	path := "/exec/" + execID + "/json"
 
-	resp, err := client.Do(rest.Context, http.MethodGet, path, nil, nil, nil)
+	resp, err := client.Do(ctx, http.MethodGet, path, nil, nil, nil)
 	if err != nil {
 		return 1, &ce.CustomError{Title: "Unable to inspect exec instance", Message: err.Error()}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		b, _ := io.ReadAll(resp.Body)
-		msg := StringsTrim(string(b))
-		if msg == "" {
-			msg = resp.Status
-		}
-		return 1, &ce.CustomError{Title: "Exec inspect failed", Message: fmt.Sprintf("GET %s returned %s", path, msg)}
+	if aerr := rest.CheckResponse(resp); aerr != nil {
+		return 1, &ce.CustomError{Title: "Exec inspect failed", Message: aerr.Error()}
 	}
 
 	var out ExecInspectResponse
@@ -223,7 +207,7 @@ func inspectExitCode(client *rest.Client, execID string) (int, *ce.CustomError)
 	return out.ExitCode, nil
 }
 
-func setupResizeHandler(client *rest.Client, execID string) {
+func setupResizeHandler(ctx context.Context, client *rest.Client, execID string) {
 	// Send an initial resize, then keep it updated on SIGWINCH.
 	resize := func() {
 		ws, err := mobyterm.GetWinsize(os.Stdout.Fd())
@@ -234,7 +218,7 @@ func setupResizeHandler(client *rest.Client, execID string) {
 		q.Set("h", fmt.Sprintf("%d", ws.Height))
 		q.Set("w", fmt.Sprintf("%d", ws.Width))
 		path := "/exec/" + execID + "/resize"
-		resp, err := client.Do(rest.Context, http.MethodPost, path, q, nil, nil)
+		resp, err := client.Do(ctx, http.MethodPost, path, q, nil, nil)
 		if err == nil && resp != nil {
 			_ = resp.Body.Close()
 		}
@@ -249,7 +233,7 @@ func setupResizeHandler(client *rest.Client, execID string) {
 		defer signal.Stop(ch)
 		for {
 			select {
-			case <-rest.Context.Done():
+			case <-ctx.Done():
 				return
 			case <-ch:
 				resize()
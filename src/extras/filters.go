@@ -0,0 +1,45 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 20:10
+// Original filename: src/extras/filters.go
+
+package extras
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+)
+
+// Filters is the Engine API `filters` query parameter: each key (status, label, name, dangling...)
+// maps to the values accepted for it. Values of a same key are OR'ed, keys are AND'ed, by the daemon.
+type Filters map[string][]string
+
+// ParseFilters turns the repeated `--filter key=value` flags into Filters.
+// The keys are checked by the daemon, which knows which ones apply to each resource.
+func ParseFilters(flags []string) (Filters, *ce.CustomError) {
+	if len(flags) == 0 {
+		return nil, nil
+	}
+	f := make(Filters)
+	for _, flag := range flags {
+		key, value, ok := strings.Cut(flag, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, &ce.CustomError{Title: "Invalid filter", Message: "'" + flag + "' is not in the key=value format"}
+		}
+		f[key] = append(f[key], value)
+	}
+	return f, nil
+}
+
+// AddTo sets the JSON-encoded `filters` parameter on q; nothing is set when there are no filters
+func (f Filters) AddTo(q url.Values) {
+	if len(f) == 0 {
+		return
+	}
+	b, _ := json.Marshal(map[string][]string(f))
+	q.Set("filters", string(b))
+}
@@ -6,8 +6,8 @@
 package extras
 
 import (
+	"context"
 	"dtools2/env"
-	"dtools2/rest"
 	"io"
 	"os"
 	"strings"
@@ -55,7 +55,8 @@ func GetDefaultRegistry(regfile string) (string, *ce.CustomError) {
 	return dre.RegistryName, nil
 }
 
-func CopyStdin(dst io.Writer, stop <-chan struct{}) {
+// CopyStdin forwards our stdin to dst until stop is closed, ctx is done or stdin reaches EOF.
+func CopyStdin(ctx context.Context, dst io.Writer, stop <-chan struct{}) {
 	fd := int(os.Stdin.Fd())
 
 	// If stdin isn't a terminal (pipe/file), a straight io.Copy is fine and won't hang.
@@ -74,7 +75,7 @@ func CopyStdin(dst io.Writer, stop <-chan struct{}) {
 		select {
 		case <-stop:
 			return
-		case <-rest.Context.Done():
+		case <-ctx.Done():
 			return
 		default:
 		}
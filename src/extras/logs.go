@@ -8,14 +8,12 @@ package extras
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/binary"
-	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"os"
 	"strconv"
-	"strings"
 
 	"dtools2/rest"
 
@@ -23,37 +21,46 @@ import (
 	ce "github.com/jeanfrancoisgratton/customError/v3"
 )
 
-func Logs(client *rest.Client, container string) *ce.CustomError {
+// Logs streams the logs of a container to opts.Stdout / opts.Stderr.
+func Logs(ctx context.Context, client *rest.Client, container string, opts LogOptions) *ce.CustomError {
 	q := url.Values{}
 	q.Set("stdout", "true")
 	q.Set("stderr", "true")
 
-	if LogFollow {
+	if opts.Follow {
 		q.Set("follow", "true")
 	}
-	if LogTimestamps {
+	if opts.Timestamps {
 		q.Set("timestamps", "true")
 	}
-	if LogTail >= 0 {
-		q.Set("tail", strconv.Itoa(LogTail))
+	if opts.Tail >= 0 {
+		q.Set("tail", strconv.Itoa(opts.Tail))
 	} else {
 		q.Set("tail", "all")
 	}
 
+	callCtx := rest.StreamingContext(ctx)
+	if opts.Follow {
+		// A followed container can stay quiet for hours.
+		callCtx = rest.FollowContext(ctx)
+	}
+	stdout, stderr := opts.Stdout, opts.Stderr
+	if stdout == nil {
+		stdout = io.Discard
+	}
+	if stderr == nil {
+		stderr = io.Discard
+	}
+
 	path := "/containers/" + container + "/logs"
-	resp, err := client.Do(rest.Context, http.MethodGet, path, q, nil, nil)
+	resp, err := client.Do(callCtx, http.MethodGet, path, q, nil, nil)
 	if err != nil {
 		return &ce.CustomError{Title: "Unable to fetch logs", Message: err.Error()}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		b, _ := io.ReadAll(resp.Body)
-		msg := strings.TrimSpace(string(b))
-		if msg == "" {
-			msg = resp.Status
-		}
-		return &ce.CustomError{Title: "Logs request failed", Message: fmt.Sprintf("GET %s returned %s", path, msg)}
+	if aerr := rest.CheckResponse(resp); aerr != nil {
+		return &ce.CustomError{Title: "Logs request failed", Message: aerr.Error()}
 	}
 
 	// The logs endpoint returns either:
@@ -67,9 +74,9 @@ func Logs(client *rest.Client, container string) *ce.CustomError {
 	}
 
 	if looksLikeStdCopyMux(peek) {
-		_, err = stdcopy.StdCopy(os.Stdout, os.Stderr, br)
+		_, err = stdcopy.StdCopy(stdout, stderr, br)
 	} else {
-		_, err = io.Copy(os.Stdout, br)
+		_, err = io.Copy(stdout, br)
 	}
 	if err != nil && err != io.EOF {
 		return &ce.CustomError{Title: "Error while streaming logs", Message: err.Error()}
@@ -12,6 +12,8 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 
 	ce "github.com/jeanfrancoisgratton/customError/v3"
@@ -103,13 +105,14 @@ func Send2File(payload any, outputFile string) ([]byte, *ce.CustomError) {
 // --format support (plaintext output)
 // -----------------------------------------------------------------------------
 
-// ExtractFormatRows returns plaintext rows for a slice/array of structs.
+// ExtractFormatRows returns plaintext rows for a slice/array of structs (or of string-keyed maps).
 //
 // format accepts:
 //   - "Name" (single field)
 //   - "Name,Id" (multiple fields; output is tab-separated per row)
 //   - docker-ish single token like "{{.Name}}" or ".Name" (normalized to "Name")
-//   - simple dotted paths like "IPAM.Driver" (best-effort)
+//   - simple dotted paths like "IPAM.Driver" (best-effort), with slice indexes ("Config.Env.0")
+//   - "*" wildcards over slices and maps ("NetworkSettings.Networks.*.IPAddress"); the values are comma-joined
 func ExtractFormatRows(list any, format string) ([][]string, *ce.CustomError) {
 	keys := parseFormatKeys(format)
 	if len(keys) == 0 {
@@ -139,19 +142,22 @@ func ExtractFormatRows(list any, format string) ([][]string, *ce.CustomError) {
 			}
 			e = e.Elem()
 		}
-		if e.Kind() != reflect.Struct {
-			return nil, &ce.CustomError{Title: "Invalid element", Message: "expected struct elements"}
+		if e.Kind() == reflect.Interface {
+			e = e.Elem()
+		}
+		if e.Kind() != reflect.Struct && (e.Kind() != reflect.Map || e.Type().Key().Kind() != reflect.String) {
+			return nil, &ce.CustomError{Title: "Invalid element", Message: "expected struct or map elements"}
 		}
 
 		row := make([]string, 0, len(keys))
 		for _, k := range keys {
-			val, ok := getPathValue(e, strings.Split(k, "."))
-			if !ok {
-				// Match docker behavior loosely: missing field => empty string
-				row = append(row, "")
-				continue
+			// Match docker behavior loosely: missing field => empty string
+			vals := getPathValues(e, strings.Split(k, "."))
+			ss := make([]string, 0, len(vals))
+			for _, val := range vals {
+				ss = append(ss, valueToString(e, k, val))
 			}
-			row = append(row, valueToString(e, k, val))
+			row = append(row, strings.Join(ss, ","))
 		}
 		rows = append(rows, row)
 	}
@@ -232,11 +238,9 @@ func getPathValue(v reflect.Value, path []string) (reflect.Value, bool) {
 			return reflect.Value{}, false
 		}
 
-		for cur.Kind() == reflect.Pointer {
-			if cur.IsNil() {
-				return reflect.Value{}, false
-			}
-			cur = cur.Elem()
+		cur = deref(cur)
+		if !cur.IsValid() {
+			return reflect.Value{}, false
 		}
 
 		switch cur.Kind() {
@@ -251,11 +255,26 @@ func getPathValue(v reflect.Value, path []string) (reflect.Value, bool) {
 			if cur.Type().Key().Kind() != reflect.String {
 				return reflect.Value{}, false
 			}
-			mv := cur.MapIndex(reflect.ValueOf(seg))
+			mv := cur.MapIndex(reflect.ValueOf(seg).Convert(cur.Type().Key()))
+			if !mv.IsValid() {
+				// Be as lenient as with struct fields: "state" finds "State"
+				for _, k := range cur.MapKeys() {
+					if strings.EqualFold(k.String(), seg) {
+						mv = cur.MapIndex(k)
+						break
+					}
+				}
+			}
 			if !mv.IsValid() {
 				return reflect.Value{}, false
 			}
 			cur = mv
+		case reflect.Slice, reflect.Array:
+			idx, err := strconv.Atoi(seg)
+			if err != nil || idx < 0 || idx >= cur.Len() {
+				return reflect.Value{}, false
+			}
+			cur = cur.Index(idx)
 		default:
 			return reflect.Value{}, false
 		}
@@ -264,6 +283,73 @@ func getPathValue(v reflect.Value, path []string) (reflect.Value, bool) {
 	return cur, true
 }
 
+// getPathValues is getPathValue with wildcards: a "*" segment fans out over the elements of a slice
+// or the values of a map (in key order). Paths that lead nowhere simply contribute no value.
+func getPathValues(v reflect.Value, path []string) []reflect.Value {
+	star := -1
+	for i, seg := range path {
+		if strings.TrimSpace(seg) == "*" {
+			star = i
+			break
+		}
+	}
+	if star == -1 {
+		if val, ok := getPathValue(v, path); ok {
+			return []reflect.Value{val}
+		}
+		return nil
+	}
+
+	cur := v
+	if star > 0 {
+		var ok bool
+		if cur, ok = getPathValue(v, path[:star]); !ok {
+			return nil
+		}
+	}
+	cur = deref(cur)
+	if !cur.IsValid() {
+		return nil
+	}
+
+	var elems []reflect.Value
+	switch cur.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < cur.Len(); i++ {
+			elems = append(elems, cur.Index(i))
+		}
+	case reflect.Map:
+		keys := cur.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		for _, k := range keys {
+			elems = append(elems, cur.MapIndex(k))
+		}
+	default:
+		return nil
+	}
+
+	var out []reflect.Value
+	for _, e := range elems {
+		if star == len(path)-1 {
+			out = append(out, e)
+			continue
+		}
+		out = append(out, getPathValues(e, path[star+1:])...)
+	}
+	return out
+}
+
+// deref follows pointers and interfaces (such as the values of a decoded JSON document); nil yields an invalid Value
+func deref(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
 func findStructFieldByNameOrJSON(v reflect.Value, key string) (reflect.Value, bool) {
 	t := v.Type()
 	for i := 0; i < t.NumField(); i++ {
@@ -291,11 +377,9 @@ func findStructFieldByNameOrJSON(v reflect.Value, key string) (reflect.Value, bo
 }
 
 func valueToString(parent reflect.Value, key string, v reflect.Value) string {
-	for v.Kind() == reflect.Pointer {
-		if v.IsNil() {
-			return ""
-		}
-		v = v.Elem()
+	v = deref(v)
+	if !v.IsValid() {
+		return ""
 	}
 
 	// Special-case container names to match docker output expectations.
@@ -328,12 +412,14 @@ func valueToString(parent reflect.Value, key string, v reflect.Value) string {
 	case reflect.Float32, reflect.Float64:
 		return fmt.Sprintf("%v", v.Float())
 	case reflect.Slice, reflect.Array:
-		// Best effort: if []string, join with comma. Otherwise JSON encode.
-		if v.Type().Elem().Kind() == reflect.String {
-			ss := make([]string, 0, v.Len())
-			for i := 0; i < v.Len(); i++ {
-				ss = append(ss, v.Index(i).String())
+		// Best effort: if []string (or a decoded JSON array of strings), join with comma. Otherwise JSON encode.
+		ss := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			if e := deref(v.Index(i)); e.IsValid() && e.Kind() == reflect.String {
+				ss = append(ss, e.String())
 			}
+		}
+		if len(ss) == v.Len() {
 			return strings.Join(ss, ",")
 		}
 		b, err := json.Marshal(v.Interface())
@@ -0,0 +1,164 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 21:40
+// Original filename: src/extras/resources.go
+
+package extras
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/docker/go-units"
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+)
+
+// ResourceOptions holds the resource limits as typed on the command line (run, create, update).
+// Sizes accept the docker units (512m, 1g...); the zero value of each field means "not set".
+type ResourceOptions struct {
+	Memory            string   // --memory
+	MemorySwap        string   // --memory-swap: memory + swap; -1 for unlimited swap
+	MemoryReservation string   // --memory-reservation: soft limit
+	CPUs              float64  // --cpus
+	CPUShares         int64    // --cpu-shares: relative weight
+	CpusetCpus        string   // --cpuset-cpus: 0-3, 0,1...
+	PidsLimit         int64    // --pids-limit: -1 for unlimited
+	BlkioWeight       uint16   // --blkio-weight: 10 to 1000
+	Ulimits           []string // --ulimit name=soft[:hard]; run and create only
+}
+
+// Resources is the API form of the limits, found inline in HostConfig and in the /containers/{id}/update body.
+type Resources struct {
+	Memory            int64    `json:"Memory,omitempty"`
+	MemorySwap        int64    `json:"MemorySwap,omitempty"`
+	MemoryReservation int64    `json:"MemoryReservation,omitempty"`
+	NanoCpus          int64    `json:"NanoCpus,omitempty"`
+	CpuShares         int64    `json:"CpuShares,omitempty"`
+	CpusetCpus        string   `json:"CpusetCpus,omitempty"`
+	PidsLimit         *int64   `json:"PidsLimit,omitempty"`
+	BlkioWeight       uint16   `json:"BlkioWeight,omitempty"`
+	Ulimits           []Ulimit `json:"Ulimits,omitempty"`
+}
+
+type Ulimit struct {
+	Name string `json:"Name"`
+	Soft int64  `json:"Soft"`
+	Hard int64  `json:"Hard"`
+}
+
+// RestartPolicy is the API form of --restart
+type RestartPolicy struct {
+	Name              string `json:"Name"`
+	MaximumRetryCount int    `json:"MaximumRetryCount,omitempty"`
+}
+
+// IsZero tells whether no limit at all was given
+func (r Resources) IsZero() bool {
+	return r.Memory == 0 && r.MemorySwap == 0 && r.MemoryReservation == 0 && r.NanoCpus == 0 && r.CpuShares == 0 &&
+		r.CpusetCpus == "" && r.PidsLimit == nil && r.BlkioWeight == 0 && len(r.Ulimits) == 0
+}
+
+// Resources validates the options and converts them to their API form
+func (o ResourceOptions) Resources() (Resources, *ce.CustomError) {
+	var r Resources
+	var cerr *ce.CustomError
+
+	if r.Memory, cerr = parseSize("--memory", o.Memory); cerr != nil {
+		return r, cerr
+	}
+	if o.MemorySwap == "-1" {
+		r.MemorySwap = -1
+	} else if r.MemorySwap, cerr = parseSize("--memory-swap", o.MemorySwap); cerr != nil {
+		return r, cerr
+	}
+	if r.MemoryReservation, cerr = parseSize("--memory-reservation", o.MemoryReservation); cerr != nil {
+		return r, cerr
+	}
+	if r.MemorySwap > 0 && r.Memory > 0 && r.MemorySwap < r.Memory {
+		return r, &ce.CustomError{Title: "Invalid resource limit", Message: "--memory-swap must be larger than --memory, as it includes it"}
+	}
+
+	if o.CPUs < 0 {
+		return r, &ce.CustomError{Title: "Invalid resource limit", Message: "--cpus cannot be negative"}
+	}
+	r.NanoCpus = int64(o.CPUs * 1e9)
+	if o.CPUShares < 0 {
+		return r, &ce.CustomError{Title: "Invalid resource limit", Message: "--cpu-shares cannot be negative"}
+	}
+	r.CpuShares = o.CPUShares
+	r.CpusetCpus = o.CpusetCpus
+
+	if o.PidsLimit != 0 {
+		limit := o.PidsLimit
+		r.PidsLimit = &limit
+	}
+	if o.BlkioWeight != 0 && (o.BlkioWeight < 10 || o.BlkioWeight > 1000) {
+		return r, &ce.CustomError{Title: "Invalid resource limit", Message: "--blkio-weight must be between 10 and 1000"}
+	}
+	r.BlkioWeight = o.BlkioWeight
+
+	for _, spec := range o.Ulimits {
+		u, cerr := parseUlimit(spec)
+		if cerr != nil {
+			return r, cerr
+		}
+		r.Ulimits = append(r.Ulimits, u)
+	}
+	return r, nil
+}
+
+// ParseRestartPolicy parses --restart: no, always, unless-stopped or on-failure[:max-retries]
+func ParseRestartPolicy(spec string) (RestartPolicy, *ce.CustomError) {
+	name, retries, hasRetries := strings.Cut(spec, ":")
+	p := RestartPolicy{Name: name}
+
+	switch name {
+	case "no", "always", "unless-stopped":
+		if hasRetries {
+			return p, &ce.CustomError{Title: "Invalid restart policy", Message: "maximum retry count can only be used with on-failure"}
+		}
+	case "on-failure":
+		if hasRetries {
+			n, err := strconv.Atoi(retries)
+			if err != nil || n < 0 {
+				return p, &ce.CustomError{Title: "Invalid restart policy", Message: "invalid maximum retry count: " + retries}
+			}
+			p.MaximumRetryCount = n
+		}
+	default:
+		return p, &ce.CustomError{Title: "Invalid restart policy", Message: spec + " is not one of no, always, unless-stopped, on-failure[:max-retries]"}
+	}
+	return p, nil
+}
+
+func parseSize(flag, value string) (int64, *ce.CustomError) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := units.RAMInBytes(value)
+	if err != nil || n < 0 {
+		return 0, &ce.CustomError{Title: "Invalid resource limit", Message: "invalid size for " + flag + ": " + value}
+	}
+	return n, nil
+}
+
+// parseUlimit parses name=soft[:hard]; the hard limit defaults to the soft one
+func parseUlimit(spec string) (Ulimit, *ce.CustomError) {
+	name, limits, ok := strings.Cut(spec, "=")
+	if !ok || name == "" || limits == "" {
+		return Ulimit{}, &ce.CustomError{Title: "Invalid ulimit", Message: spec + " is not in the name=soft[:hard] format"}
+	}
+	soft, hard, hasHard := strings.Cut(limits, ":")
+	if !hasHard {
+		hard = soft
+	}
+	s, err1 := strconv.ParseInt(soft, 10, 64)
+	h, err2 := strconv.ParseInt(hard, 10, 64)
+	if err1 != nil || err2 != nil {
+		return Ulimit{}, &ce.CustomError{Title: "Invalid ulimit", Message: "invalid limit values in " + spec}
+	}
+	if s > h && h != -1 {
+		return Ulimit{}, &ce.CustomError{Title: "Invalid ulimit", Message: "soft limit cannot exceed the hard limit in " + spec}
+	}
+	return Ulimit{Name: name, Soft: s, Hard: h}, nil
+}
@@ -6,14 +6,33 @@
 
 package extras
 
+import "io"
+
+// CLI output settings; the library functions never read them, only the cmd package does.
 var Debug bool
-var LogTimestamps bool // -t
-var LogFollow bool     // -f
-var LogTail int        // -n (lines); -1 means "all"
-var OutputJSON bool    // render output in JSON
+var QuietOutput bool // -q
+var OutputJSON bool  // render output in JSON
 var OutputFile = ""
 var OutputFormat = "" // when non-empty, output only this field (or comma-separated fields) as plaintext
 
+// LogOptions controls Logs().
+type LogOptions struct {
+	Follow     bool // -f
+	Timestamps bool // -t
+	Tail       int  // -n (lines); negative means "all"
+
+	// Where the container output goes; nil discards it.
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// ExecOptions controls Run(), our `docker exec`.
+type ExecOptions struct {
+	Interactive bool   // -i
+	TTY         bool   // -t
+	User        string // -u
+}
+
 // Structures for the Docker/Podman exec API.
 //
 // Endpoints:
@@ -6,7 +6,8 @@
 package images
 
 import (
-	"fmt"
+	"context"
+	"encoding/json"
 	"net/http"
 	"net/url"
 	"strings"
@@ -14,37 +15,36 @@ import (
 	"dtools2/rest"
 
 	ce "github.com/jeanfrancoisgratton/customError/v3"
-	hftx "github.com/jeanfrancoisgratton/helperFunctions/v4/terminalfx"
 )
 
 type commitResponse struct {
 	ID string `json:"Id"`
 }
 
-// ImageCommit emulates `docker commit` using the daemon's /commit endpoint.
+// ImageCommit emulates `docker commit` using the daemon's /commit endpoint, and returns the new image ID.
 // Unlike docker, repository:tag is mandatory.
 //
-//   - author (docker commit -a) -> query param "author"
-//   - message (docker commit -m) -> query param "comment"
-//   - changes (docker commit -c) -> query param "changes" (repeatable)
-func ImageCommit(client *rest.Client, containerRef, repoTag, author, message string, changes []string) *ce.CustomError {
-	repo, tag := splitRepoTag(repoTag)
+//   - Author (docker commit -a) -> query param "author"
+//   - Message (docker commit -m) -> query param "comment"
+//   - Changes (docker commit -c) -> query param "changes" (repeatable)
+func ImageCommit(ctx context.Context, client *rest.Client, opts CommitOptions) (string, *ce.CustomError) {
+	repo, tag := splitRepoTag(opts.RepoTag)
 	if repo == "" || tag == "" {
-		return &ce.CustomError{Title: "image commit error", Message: "repository:tag is required (got " + repoTag + ")"}
+		return "", &ce.CustomError{Title: "image commit error", Message: "repository:tag is required (got " + opts.RepoTag + ")"}
 	}
 
 	q := url.Values{}
-	q.Set("container", containerRef)
+	q.Set("container", opts.Container)
 	q.Set("repo", repo)
 	q.Set("tag", tag)
 
-	if author != "" {
-		q.Set("author", author)
+	if opts.Author != "" {
+		q.Set("author", opts.Author)
 	}
-	if message != "" {
-		q.Set("comment", message)
+	if opts.Message != "" {
+		q.Set("comment", opts.Message)
 	}
-	for _, c := range changes {
+	for _, c := range opts.Changes {
 		c = strings.TrimSpace(c)
 		if c == "" {
 			continue
@@ -52,23 +52,20 @@ func ImageCommit(client *rest.Client, containerRef, repoTag, author, message str
 		q.Add("changes", c)
 	}
 
-	resp, err := client.Do(rest.Context, http.MethodPost, "/commit", q, nil, nil)
+	// The daemon only answers once the container filesystem has been committed, which can take a while.
+	resp, err := client.Do(rest.WithTimeouts(ctx, 0, -1), http.MethodPost, "/commit", q, nil, nil)
 	if err != nil {
-		return &ce.CustomError{Title: "image commit error", Message: err.Error()}
+		return "", &ce.CustomError{Title: "image commit error", Message: err.Error()}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-		msg, _ := readSmallBody(resp.Body, 8192)
-		if msg != "" {
-			return &ce.CustomError{Title: "image commit failed", Message: "http response is " + resp.Status + " (" + msg + ")"}
-		}
-		return &ce.CustomError{Title: "image commit failed", Message: "http response is " + resp.Status}
+	if aerr := rest.CheckResponse(resp); aerr != nil {
+		return "", &ce.CustomError{Title: "image commit failed", Message: aerr.Error()}
 	}
 
-	if !rest.QuietOutput {
-		fmt.Println(hftx.GreenGoSign("Commited container " + containerRef + " to image " + repoTag))
+	var cr commitResponse
+	if err := json.NewDecoder(resp.Body).Decode(&cr); err != nil {
+		return "", &ce.CustomError{Title: "Unable to decode JSON", Message: err.Error()}
 	}
-
-	return nil
+	return cr.ID, nil
 }
@@ -6,8 +6,6 @@
 package images
 
 import (
-	"fmt"
-	"math"
 	"strings"
 )
 
@@ -56,12 +54,3 @@ func registryFromImageRef(ref string) string {
 
 	return ""
 }
-
-func formatSize(sz int64) string {
-	numSize := (float64)(sz) / 1000.0 / 1000.0 // this will give us the size in MB
-	if (int)(math.Log10(float64(numSize))) > 2 {
-		return fmt.Sprintf("%.3f GB", numSize/1000.0)
-	} else {
-		return fmt.Sprintf("%.3f MB", numSize)
-	}
-}
@@ -7,32 +7,31 @@
 package images
 
 import (
+	"context"
 	"dtools2/extras"
 	"dtools2/rest"
 	"encoding/json"
 	"net/http"
 	"net/url"
-	"os"
-	"time"
 
 	ce "github.com/jeanfrancoisgratton/customError/v3"
-	hfjson "github.com/jeanfrancoisgratton/helperFunctions/v4/prettyjson"
-	"github.com/jedib0t/go-pretty/v6/table"
-	"github.com/jedib0t/go-pretty/v6/text"
 )
 
-func ImagesList(client *rest.Client, displayOutput bool) ([]ImageSummary, *ce.CustomError) {
+// ImagesList returns one entry per image tag (see cmd/imagesOutput.go for the rendering)
+func ImagesList(ctx context.Context, client *rest.Client, opts ListOptions) ([]ImageSummary, *ce.CustomError) {
 	var iInfoSlice []ImageSummary
 
 	// Create & execute the http request
-	resp, err := client.Do(rest.Context, http.MethodGet, "/images/json", url.Values{}, nil, nil)
+	q := url.Values{}
+	opts.Filters.AddTo(q)
+	resp, err := client.Do(ctx, http.MethodGet, "/images/json", q, nil, nil)
 	if err != nil {
 		return nil, &ce.CustomError{Title: "Unable to list images", Message: err.Error()}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		return nil, &ce.CustomError{Title: "http request returned an error", Message: "GET /images/json returned " + resp.Status}
+	if aerr := rest.CheckResponse(resp); aerr != nil {
+		return nil, &ce.CustomError{Title: "Unable to list images", Message: aerr.Error()}
 	}
 
 	// Decode JSON only if we actually have content
@@ -47,7 +46,12 @@ func ImagesList(client *rest.Client, displayOutput bool) ([]ImageSummary, *ce.Cu
 	// 1. Parse all images
 	for _, img := range images {
 		// 2. Parse all tags off an image if the daemon hosts multiple variants (tags) of a given image
-		for _, tag := range img.RepoTags {
+		// Untagged (dangling) images still get a row, as with `docker images`
+		tags := img.RepoTags
+		if len(tags) == 0 {
+			tags = []string{"<none>:<none>"}
+		}
+		for _, tag := range tags {
 			var iInfo ImageSummary
 
 			iInfo.RepoImgName, iInfo.ImgTag = extras.SplitURI(tag)
@@ -65,103 +69,5 @@ func ImagesList(client *rest.Client, displayOutput bool) ([]ImageSummary, *ce.Cu
 		}
 	}
 
-	// If we're not supposed to display anything, return the parsed list.
-	if !displayOutput {
-		return iInfoSlice, nil
-	}
-
-	// Optional: write JSON payload to a file and/or render JSON to stdout.
-	// This is only done when displayOutput is true (i.e., list commands).
-	var payloadBytes []byte
-	if extras.OutputFile != "" {
-		b, cerr := extras.Send2File(iInfoSlice, extras.OutputFile)
-		if cerr != nil {
-			return nil, cerr
-		}
-		payloadBytes = b
-	}
-
-	// --format takes precedence over --json, tables and ignores --quiet.
-	if extras.OutputFormat != "" {
-		rows, cerr := extras.ExtractFormatRows(iInfoSlice, extras.OutputFormat)
-		if cerr != nil {
-			return nil, cerr
-		}
-		if cerr := extras.PrintFormatRows(rows); cerr != nil {
-			return nil, cerr
-		}
-		return iInfoSlice, nil
-	}
-
-	if extras.OutputJSON {
-		// Marshal once if we didn't already (for --file).
-		if payloadBytes == nil {
-			b, cerr := extras.MarshalJSON(iInfoSlice)
-			if cerr != nil {
-				return nil, cerr
-			}
-			payloadBytes = b
-		}
-
-		hfjson.Print(payloadBytes)
-		return iInfoSlice, nil
-	}
-
-	// JSON output not requested; if quiet, return data only.
-	if rest.QuietOutput {
-		return iInfoSlice, nil
-	}
-
-	// Build and render the table ONCE
-	t := table.NewWriter()
-	t.SetOutputMirror(os.Stdout)
-	t.AppendHeader(table.Row{
-		"Repository/image name",
-		"Image tag",
-		"Image ID",
-		"Creation time",
-		"Size",
-		"# containers",
-	})
-
-	// When there are no images, append a single empty row to keep the
-	// table borders and layout intact.
-	if len(iInfoSlice) == 0 {
-		t.AppendRow(table.Row{"", "", "", "", "", ""})
-	} else {
-		for _, imgspec := range iInfoSlice {
-			// imgspec.ID is already stripped of "sha256:" above; trim to 12 chars for display
-			displayID := imgspec.ID
-			if len(displayID) >= 12 {
-				displayID = displayID[:12]
-			}
-
-			t.AppendRow(table.Row{
-				imgspec.RepoImgName,
-				imgspec.ImgTag,
-				displayID,
-				time.Unix(imgspec.Created, 0).Format("2006.01.02 15:04:05"),
-				formatSize(imgspec.Size),
-				imgspec.Containers,
-			})
-		}
-	}
-
-	t.SortBy([]table.SortBy{
-		{Name: "Repository/image name", Mode: table.Asc},
-	})
-	t.SetStyle(table.StyleColoredBlackOnBlueWhite)
-	t.Style().Format.Header = text.FormatDefault
-	t.SetRowPainter(func(row table.Row) text.Colors {
-		switch row[5] {
-		case 0:
-			return text.Colors{text.FgHiWhite}
-		default:
-			//return text.Colors{text.FgHiGreen}
-			return text.Colors{text.FgHiGreen}
-		}
-	})
-
-	t.Render()
 	return iInfoSlice, nil
 }
@@ -10,43 +10,44 @@
 package images
 
 import (
+	"context"
 	"dtools2/auth"
 	"dtools2/rest"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"os"
 
 	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/moby/term"
 )
 
 // ImagePull is the Cobra-facing helper used by `dtools2 images pull`.
-func ImagePull(client *rest.Client, ref string) error {
-	return PullRef(client, ref, os.Stdout)
+func ImagePull(ctx context.Context, client *rest.Client, ref string, out io.Writer) error {
+	return PullRef(ctx, client, ref, out)
 }
 
 // PullRef pulls an image reference (eg: "alpine:latest") via the daemon,
 // streaming progress (same output as `docker pull`).
 //
-// The caller controls output (e.g. pass io.Discard for quiet mode).
-func PullRef(client *rest.Client, ref string, out io.Writer) error {
+// The caller controls output (nil or io.Discard for quiet mode).
+func PullRef(ctx context.Context, client *rest.Client, ref string, out io.Writer) error {
 	opts := PullOptions{
 		ImageTag: ref,
 		Registry: registryFromImageRef(ref),
 	}
-	return Pull(client, opts, out)
+	return Pull(ctx, client, opts, out)
 }
 
 // Pull pulls an image from a registry via the daemon, streaming progress
 // using Docker's own jsonmessage renderer (same output as `docker pull`).
-func Pull(client *rest.Client, opts PullOptions, out io.Writer) error {
+// A nil out discards the progress.
+func Pull(ctx context.Context, client *rest.Client, opts PullOptions, out io.Writer) error {
 	if opts.ImageTag == "" {
 		return fmt.Errorf("image reference is required")
 	}
 	if out == nil {
-		out = os.Stdout
+		out = io.Discard
 	}
 
 	repo, tag := splitRepoTag(opts.ImageTag)
@@ -68,12 +69,18 @@ func Pull(client *rest.Client, opts PullOptions, out io.Writer) error {
 		headers.Set("X-Registry-Auth", h)
 	}
 
-	resp, err := client.Do(rest.Context, http.MethodPost, "/images/create", q, nil, headers)
+	resp, err := client.Do(rest.StreamingContext(ctx), http.MethodPost, "/images/create", q, nil, headers)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
+	// Errors raised before the pull starts (unknown image, bad credentials...) come back as a plain
+	// JSON error and not as a progress stream.
+	if aerr := rest.CheckResponse(resp); aerr != nil {
+		return aerr
+	}
+
 	// Use Docker's JSON progress machinery to render output.
 	termFd, isTerm := term.GetFdInfo(out)
 
@@ -85,12 +92,6 @@ func Pull(client *rest.Client, opts PullOptions, out io.Writer) error {
 		return err
 	}
 
-	// If the daemon returned a plain HTTP error without a JSON body,
-	// still surface that.
-	if resp.StatusCode >= 400 {
-		return fmt.Errorf("image pull failed: %s", resp.Status)
-	}
-
 	// No extra "Image pull completed." message: docker doesn't print one.
 	return nil
 }
@@ -6,10 +6,11 @@
 package images
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
-	"os"
 
 	"github.com/docker/docker/pkg/jsonmessage"
 	ce "github.com/jeanfrancoisgratton/customError/v3"
@@ -20,8 +21,8 @@ import (
 )
 
 // ImagePush uses the daemon to push the image to its registry,
-// streaming output EXACTLY like `docker push`.
-func ImagePush(client *rest.Client, ref string) *ce.CustomError {
+// streaming output EXACTLY like `docker push` to out (nil discards it).
+func ImagePush(ctx context.Context, client *rest.Client, ref string, out io.Writer) *ce.CustomError {
 	repo, tag := splitRepoTag(ref)
 	if tag == "" {
 		tag = "latest"
@@ -43,20 +44,22 @@ func ImagePush(client *rest.Client, ref string) *ce.CustomError {
 
 	path := fmt.Sprintf("/images/%s/push", repo)
 
-	resp, err := client.Do(rest.Context, http.MethodPost, path, q, nil, headers)
+	resp, err := client.Do(rest.StreamingContext(ctx), http.MethodPost, path, q, nil, headers)
 	if err != nil {
 		return &ce.CustomError{Title: "error pushing image", Message: err.Error()}
 	}
 	defer resp.Body.Close()
 
-	termFd, isTerm := term.GetFdInfo(os.Stdout)
-	if err := jsonmessage.DisplayJSONMessagesStream(resp.Body, os.Stdout, termFd, isTerm, nil); err != nil {
-		return &ce.CustomError{Title: "error displaying json messages", Message: err.Error()}
+	if aerr := rest.CheckResponse(resp); aerr != nil {
+		return &ce.CustomError{Title: "error pushing image", Message: aerr.Error()}
 	}
 
-	if resp.StatusCode >= 400 {
-		return &ce.CustomError{Title: "http request error", Message: resp.Status}
+	if out == nil {
+		out = io.Discard
+	}
+	termFd, isTerm := term.GetFdInfo(out)
+	if err := jsonmessage.DisplayJSONMessagesStream(resp.Body, out, termFd, isTerm, nil); err != nil {
+		return &ce.CustomError{Title: "error displaying json messages", Message: err.Error()}
 	}
-
 	return nil
 }
@@ -6,66 +6,64 @@
 package images
 
 import (
+	"context"
 	"dtools2/blacklist"
+	"dtools2/extras"
 	"dtools2/rest"
-	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
 
 	ce "github.com/jeanfrancoisgratton/customError/v3"
-	hftx "github.com/jeanfrancoisgratton/helperFunctions/v4/terminalfx"
 )
 
-func RemoveImage(client *rest.Client, imglist []string) *ce.CustomError {
+// RemoveImage removes the images from the daemon, and returns those actually removed.
+// Blacklisted images are skipped, unless opts.IgnoreBlacklist is set.
+func RemoveImage(ctx context.Context, client *rest.Client, imglist []string, opts RemoveOptions) ([]string, *ce.CustomError) {
+	var removed []string
+
 	for _, img := range imglist {
 		isBL, err := blacklist.IsResourceBlackListed("images", img)
 		if err != nil {
-			return err
+			return removed, err
 		}
 		if isBL {
-			if !rest.QuietOutput {
-				fmt.Println(hftx.WarningSign(" Image " + img + " is blacklisted"))
-			}
-			if RemoveBlacklisted {
-				if !rest.QuietOutput {
-					fmt.Println(hftx.InfoSign("Force removal flag is present, continuing"))
-					if err := remove(client, img); err != nil {
-						return err
-					}
-				}
-			} else {
-				if !rest.QuietOutput {
-					fmt.Println(hftx.InfoSign("Removal flag is absent, skipping container"))
-				}
-			}
-		} else {
-			if err := remove(client, img); err != nil {
-				return err
+			if !opts.IgnoreBlacklist {
+				opts.OnEvent.Emit(extras.Event{Resource: "image", Name: img, Action: extras.EventSkipped, Message: "blacklisted"})
+				continue
 			}
+			opts.OnEvent.Emit(extras.Event{Resource: "image", Name: img, Action: extras.EventBlacklisted})
+		}
+		if err := remove(ctx, client, img, opts); err != nil {
+			return removed, err
 		}
+		removed = append(removed, img)
 	}
-	return nil
+	return removed, nil
 }
 
 // The actual removal function
 
-func remove(client *rest.Client, imagename string) *ce.CustomError {
+func remove(ctx context.Context, client *rest.Client, imagename string, opts RemoveOptions) *ce.CustomError {
 	q := url.Values{}
-	q.Set("force", strconv.FormatBool(ForceRemove))
+	q.Set("force", strconv.FormatBool(opts.Force))
 
 	path := "/images/" + imagename
-	resp, derr := client.Do(rest.Context, http.MethodDelete, path, q, nil, nil)
+	resp, derr := client.Do(ctx, http.MethodDelete, path, q, nil, nil)
 	if derr != nil {
 		return &ce.CustomError{Title: "Unable to post DELETE", Message: derr.Error()}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		return &ce.CustomError{Title: "DELETE request returned an error", Message: "http requested returned " + resp.Status}
-	}
-	if !rest.QuietOutput {
-		fmt.Println(hftx.InProgressSign("Image " + imagename + hftx.Red(" REMOVED")))
+	if aerr := rest.CheckResponse(resp); aerr != nil {
+		switch {
+		case rest.IsNotFound(aerr):
+			return &ce.CustomError{Title: "Unable to remove image " + imagename, Message: "No such image"}
+		case rest.IsConflict(aerr) || rest.IsInUse(aerr):
+			return &ce.CustomError{Title: "Unable to remove image " + imagename, Message: "Image is in use or has dependent images, use --force to remove it anyway: " + aerr.Error()}
+		}
+		return &ce.CustomError{Title: "Unable to remove image " + imagename, Message: aerr.Error()}
 	}
+	opts.OnEvent.Emit(extras.Event{Resource: "image", Name: imagename, Action: extras.EventRemoved})
 	return nil
 }
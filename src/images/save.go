@@ -6,6 +6,7 @@
 package images
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"net/url"
@@ -20,7 +21,7 @@ import (
 //   - .tar, .tar.gz/.tgz, .tar.bz2/.tbz2
 //
 // xz output is intentionally not supported.
-func ImageSave(client *rest.Client, images []string, outFile string) *ce.CustomError {
+func ImageSave(ctx context.Context, client *rest.Client, images []string, outFile string) *ce.CustomError {
 	w, err := openArchiveWriter(outFile)
 	if err != nil {
 		return err
@@ -38,18 +39,14 @@ func ImageSave(client *rest.Client, images []string, outFile string) *ce.CustomE
 		return &ce.CustomError{Title: "Unable to same image(s)", Message: "at least one non-empty image reference is required"}
 	}
 
-	resp, derr := client.Do(rest.Context, http.MethodGet, "/images/get", q, nil, nil)
+	resp, derr := client.Do(rest.StreamingContext(ctx), http.MethodGet, "/images/get", q, nil, nil)
 	if derr != nil {
 		return &ce.CustomError{Title: "Error fetching images list", Message: derr.Error()}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		msg, _ := readSmallBody(resp.Body, 8192)
-		if msg != "" {
-			return &ce.CustomError{Title: "image save failed", Message: "http response is " + resp.Status + " (" + msg + ")"}
-		}
-		return &ce.CustomError{Title: "image save failed", Message: "http response is " + resp.Status}
+	if aerr := rest.CheckResponse(resp); aerr != nil {
+		return &ce.CustomError{Title: "image save failed", Message: aerr.Error()}
 	}
 
 	if _, err := io.Copy(w, resp.Body); err != nil {
@@ -6,19 +6,18 @@
 package images
 
 import (
+	"context"
 	"dtools2/extras"
 	"dtools2/rest"
-	"fmt"
 	"net/http"
 	"net/url"
 
 	ce "github.com/jeanfrancoisgratton/customError/v3"
-	hftx "github.com/jeanfrancoisgratton/helperFunctions/v4/terminalfx"
 )
 
 // Retag an image
 
-func TagImage(client *rest.Client, oldtag, newtag string) *ce.CustomError {
+func TagImage(ctx context.Context, client *rest.Client, oldtag, newtag string) *ce.CustomError {
 
 	repo, tag := extras.SplitURI(newtag)
 
@@ -28,17 +27,14 @@ func TagImage(client *rest.Client, oldtag, newtag string) *ce.CustomError {
 	q.Set("repo", repo)
 	q.Set("tag", tag)
 
-	resp, err := client.Do(rest.Context, http.MethodPost, path, q, nil, nil)
+	resp, err := client.Do(ctx, http.MethodPost, path, q, nil, nil)
 	if err != nil {
 		return &ce.CustomError{Title: "Unable to POST request", Message: err.Error()}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-		return &ce.CustomError{Title: "POST request returned an error", Message: "http requested returned " + resp.Status}
-	}
-	if !rest.QuietOutput {
-		fmt.Println("Image " + hftx.Green(oldtag) + " tagged as " + hftx.Green(newtag))
+	if aerr := rest.CheckResponse(resp); aerr != nil {
+		return &ce.CustomError{Title: "Unable to tag image " + oldtag, Message: aerr.Error()}
 	}
 	return nil
 }
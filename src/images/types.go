@@ -5,10 +5,10 @@
 
 package images
 
-import "io"
-
-var ForceRemove = false
-var RemoveBlacklisted = false
+import (
+	"dtools2/extras"
+	"io"
+)
 
 // PullOptions controls how an image is pulled.
 type PullOptions struct {
@@ -16,6 +16,27 @@ type PullOptions struct {
 	Registry string // registry to use for auth header; if empty, no auth header is sent
 }
 
+// ListOptions controls ImagesList().
+type ListOptions struct {
+	Filters extras.Filters
+}
+
+// RemoveOptions controls RemoveImage().
+type RemoveOptions struct {
+	Force           bool // remove the image even if used by a container
+	IgnoreBlacklist bool // remove blacklisted images anyway
+	OnEvent         extras.EventFunc
+}
+
+// CommitOptions controls ImageCommit(), our `docker commit`.
+type CommitOptions struct {
+	Container string   // name or ID of the container to commit
+	RepoTag   string   // repository:tag of the new image (mandatory)
+	Author    string   // -a
+	Message   string   // -m
+	Changes   []string // -c, Dockerfile instructions
+}
+
 type ImageSummary struct {
 	ID          string            `json:"Id"`
 	ParentID    string            `json:"ParentId.omitempty"`
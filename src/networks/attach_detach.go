@@ -7,52 +7,56 @@ package networks
 
 import (
 	"bytes"
+	"context"
 	"dtools2/rest"
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"net/url"
 
 	ce "github.com/jeanfrancoisgratton/customError/v3"
-	hftx "github.com/jeanfrancoisgratton/helperFunctions/v4/terminalfx"
 )
 
-func AttachNetwork(client *rest.Client, network, container string) *ce.CustomError {
-	// First, we set the request parameters payload. For now, we only support the container name as param, we
-	// Do not modify the EndpointConfig options. Maybe later ? Maybe, but for now I do not see the point of it
+// AttachNetwork connects a container to a network
+func AttachNetwork(ctx context.Context, client *rest.Client, network, container string) *ce.CustomError {
+	return AttachNetworkEndpoint(ctx, client, network, container, nil)
+}
 
-	requestPayload, jerr := json.Marshal(NetworkConnectRequest{Container: container, EndpointConfig: nil})
+// AttachNetworkEndpoint connects a container to a network with the given endpoint settings (aliases, static IPs...);
+// a nil endpoint leaves them to the daemon
+func AttachNetworkEndpoint(ctx context.Context, client *rest.Client, network, container string, endpoint *EndpointSettings) *ce.CustomError {
+	requestPayload, jerr := json.Marshal(NetworkConnectRequest{Container: container, EndpointConfig: endpoint})
 	if jerr != nil {
 		return &ce.CustomError{Title: "Unable to marshal the JSON payload", Message: jerr.Error()}
 	}
 	headers := http.Header{}
 	headers.Set("Content-Type", "application/json")
 
-	if id, err := Name2ID(client, network); err != nil {
+	if id, err := Name2ID(ctx, client, network); err != nil {
 		return err
 	} else {
-		if aerr := attachDetach_action(client, true, requestPayload, headers, id, network); aerr != nil {
+		if aerr := attachDetach_action(ctx, client, true, requestPayload, headers, id, network); aerr != nil {
 			return aerr
 		}
 	}
 	return nil
 }
 
-func DetachNetwork(client *rest.Client, network, container string) *ce.CustomError {
+// DetachNetwork disconnects a container from a network; force is the API's own Force flag
+func DetachNetwork(ctx context.Context, client *rest.Client, network, container string, force bool) *ce.CustomError {
 	// First, we set the request parameters payload. For now, we only support the container name as param, we
 	// Do not modify the EndpointConfig options. Maybe later ? Maybe, but for now I do not see the point of it
 
-	requestPayload, jerr := json.Marshal(NetworkDisconnectRequest{Container: container, Force: ForceNetworkDetach})
+	requestPayload, jerr := json.Marshal(NetworkDisconnectRequest{Container: container, Force: force})
 	if jerr != nil {
 		return &ce.CustomError{Title: "Unable to marshal the JSON payload", Message: jerr.Error()}
 	}
 	headers := http.Header{}
 	headers.Set("Content-Type", "application/json")
 
-	if id, err := Name2ID(client, network); err != nil {
+	if id, err := Name2ID(ctx, client, network); err != nil {
 		return err
 	} else {
-		if aerr := attachDetach_action(client, false, requestPayload, headers, id, network); aerr != nil {
+		if aerr := attachDetach_action(ctx, client, false, requestPayload, headers, id, network); aerr != nil {
 			return aerr
 		}
 	}
@@ -61,7 +65,7 @@ func DetachNetwork(client *rest.Client, network, container string) *ce.CustomErr
 
 // attachDetach_action :
 // As both attach (connect) and detach (disconnect) share much of the same code, it made sense to merge the actual actions in a single function
-func attachDetach_action(client *rest.Client, attachAction bool, requestPayload []byte, headers http.Header, id, networkname string) *ce.CustomError {
+func attachDetach_action(ctx context.Context, client *rest.Client, attachAction bool, requestPayload []byte, headers http.Header, id, networkname string) *ce.CustomError {
 	path := "/networks/" + id
 	action := "connect"
 
@@ -71,19 +75,15 @@ func attachDetach_action(client *rest.Client, attachAction bool, requestPayload
 		path += "/disconnect"
 		action = "disconnect"
 	}
-	resp, err := client.Do(rest.Context, http.MethodPost, path,
+	resp, err := client.Do(ctx, http.MethodPost, path,
 		url.Values{}, bytes.NewReader(requestPayload), headers)
 	if err != nil {
 		return &ce.CustomError{Title: "Unable to " + action + " network", Message: err.Error()}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return &ce.CustomError{Title: "HTTP request returned an error", Message: resp.Status}
-	}
-
-	if !rest.QuietOutput {
-		fmt.Println(hftx.GreenGoSign("Network " + hftx.Green(networkname) + " " + action + "ed"))
+	if aerr := rest.CheckResponse(resp); aerr != nil {
+		return &ce.CustomError{Title: "Unable to " + action + " network " + networkname, Message: aerr.Error()}
 	}
 	return nil
 }
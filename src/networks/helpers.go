@@ -6,7 +6,9 @@
 package networks
 
 import (
+	"context"
 	"dtools2/containers"
+	"dtools2/extras"
 	"dtools2/rest"
 	"encoding/json"
 	"fmt"
@@ -17,19 +19,18 @@ import (
 )
 
 // fetchNetworkList fetches the daemon's network summaries.
-func fetchNetworkList(client *rest.Client) ([]NetworkSummary, *ce.CustomError) {
-	resp, err := client.Do(rest.Context, http.MethodGet, "/networks", url.Values{}, nil, nil)
+func fetchNetworkList(ctx context.Context, client *rest.Client, filters extras.Filters) ([]NetworkSummary, *ce.CustomError) {
+	q := url.Values{}
+	filters.AddTo(q)
+	resp, err := client.Do(ctx, http.MethodGet, "/networks", q, nil, nil)
 	if err != nil {
 		return nil, &ce.CustomError{Title: "Unable to list networks", Message: err.Error()}
 	}
 	defer resp.Body.Close()
 
 	// Docker: 200 OK (JSON array). Some implementations may return 204 when empty.
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		return nil, &ce.CustomError{
-			Title:   "http request returned an error",
-			Message: "GET /networks returned " + resp.Status,
-		}
+	if aerr := rest.CheckResponse(resp); aerr != nil {
+		return nil, &ce.CustomError{Title: "Unable to list networks", Message: aerr.Error()}
 	}
 
 	// 204 => empty list
@@ -73,8 +74,8 @@ func computeNetworkUsage(cs []containers.ContainerSummary) (bool, map[string]str
 }
 
 // Name2ID takes the human-readable network name and returns its docker/podman ID.
-func Name2ID(client *rest.Client, networkName string) (string, *ce.CustomError) {
-	ns, err := fetchNetworkList(client)
+func Name2ID(ctx context.Context, client *rest.Client, networkName string) (string, *ce.CustomError) {
+	ns, err := fetchNetworkList(ctx, client, nil)
 	if err != nil {
 		return "", err
 	}
@@ -92,8 +93,8 @@ func Name2ID(client *rest.Client, networkName string) (string, *ce.CustomError)
 }
 
 // ID2Name takes a network ID and returns its human-readable name.
-func ID2Name(client *rest.Client, networkID string) (string, *ce.CustomError) {
-	ns, err := fetchNetworkList(client)
+func ID2Name(ctx context.Context, client *rest.Client, networkID string) (string, *ce.CustomError) {
+	ns, err := fetchNetworkList(ctx, client, nil)
 	if err != nil {
 		return "", err
 	}
@@ -1,80 +1,70 @@
 package networks
 
 import (
+	"context"
 	"dtools2/blacklist"
+	"dtools2/extras"
 	"dtools2/rest"
-	"fmt"
+	"errors"
 	"net/http"
 	"net/url"
-	"strconv"
 
 	ce "github.com/jeanfrancoisgratton/customError/v3"
-	hftx "github.com/jeanfrancoisgratton/helperFunctions/v4/terminalfx"
 )
 
-func RemoveNetwork(client *rest.Client, netList []string) *ce.CustomError {
+// RemoveNetwork removes the networks, and returns those actually removed.
+// Blacklisted networks are skipped, unless opts.IgnoreBlacklist is set.
+func RemoveNetwork(ctx context.Context, client *rest.Client, netList []string, opts RemoveOptions) ([]string, *ce.CustomError) {
+	var removed []string
+
 	for _, net := range netList {
 		isBL, err := blacklist.IsResourceBlackListed("networks", net)
 		if err != nil {
-			return err
+			return removed, err
 		}
 		if isBL {
-			if !rest.QuietOutput {
-				fmt.Println(hftx.WarningSign(" Network " + net + " is blacklisted"))
-			}
-			if RemoveBlacklisted {
-				if !rest.QuietOutput {
-					fmt.Println(hftx.InfoSign("Force removal flag is present, continuing"))
-					if err := removeNet(client, net); err != nil {
-						return err
-					}
-				}
-			} else {
-				if !rest.QuietOutput {
-					fmt.Println(hftx.InfoSign("Removal flag is absent, skipping network"))
-				}
-			}
-		} else {
-			if err := removeNet(client, net); err != nil {
-				return err
+			if !opts.IgnoreBlacklist {
+				opts.OnEvent.Emit(extras.Event{Resource: "network", Name: net, Action: extras.EventSkipped, Message: "blacklisted"})
+				continue
 			}
+			opts.OnEvent.Emit(extras.Event{Resource: "network", Name: net, Action: extras.EventBlacklisted})
 		}
+		if err := removeNet(ctx, client, net, opts.OnEvent); err != nil {
+			return removed, err
+		}
+		removed = append(removed, net)
 	}
-	return nil
+	return removed, nil
 }
 
-func removeNet(client *rest.Client, networkName string) *ce.CustomError {
+func removeNet(ctx context.Context, client *rest.Client, networkName string, onEvent extras.EventFunc) *ce.CustomError {
 	var id string
 	var err *ce.CustomError
 
 	q := url.Values{}
 
-	if id, err = Name2ID(client, networkName); err != nil {
+	if id, err = Name2ID(ctx, client, networkName); err != nil {
 		return err
 	}
 	path := "/networks/" + id
-	resp, derr := client.Do(rest.Context, http.MethodDelete, path, q, nil, nil)
+	resp, derr := client.Do(ctx, http.MethodDelete, path, q, nil, nil)
 	if derr != nil {
 		return &ce.CustomError{Title: "Unable to post DELETE", Message: derr.Error()}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusNoContent {
-		title := "Unable to post DELETE"
-		msg := ""
-		switch resp.StatusCode {
-		case http.StatusForbidden:
+	if aerr := rest.CheckResponse(resp); aerr != nil {
+		msg := aerr.Error()
+		switch {
+		case rest.IsInUse(aerr):
+			msg = "The network " + networkName + " still has containers attached"
+		case errors.Is(aerr, rest.ErrForbidden):
 			msg = "The network " + networkName + " is not allowed to be removed (built-in)"
-		case http.StatusNotFound:
+		case rest.IsNotFound(aerr):
 			msg = "The network " + networkName + " is not found"
-		default:
-			title = "DELETE request http request failed with status code " + strconv.Itoa(resp.StatusCode)
-			msg = derr.Error()
 		}
-		return &ce.CustomError{Title: title, Message: msg}
-	}
-	if !rest.QuietOutput {
-		fmt.Println(hftx.InProgressSign("Network " + networkName + hftx.Red(" REMOVED")))
+		return &ce.CustomError{Title: "Unable to remove the network", Message: msg}
 	}
+	onEvent.Emit(extras.Event{Resource: "network", Name: networkName, Action: extras.EventRemoved})
 	return nil
 }
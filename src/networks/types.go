@@ -5,12 +5,18 @@
 
 package networks
 
-var NetworkDriverName = "bridge"
-var NetworkInternalUse = false
-var NetworkAttachable = false
-var NetworkEnableIPv6 = false
-var RemoveBlacklisted = false
-var ForceNetworkDetach = false
+import "dtools2/extras"
+
+// ListOptions controls NetworkList().
+type ListOptions struct {
+	Filters extras.Filters // applied to the networks, not to the containers looked up for the usage
+}
+
+// RemoveOptions controls RemoveNetwork().
+type RemoveOptions struct {
+	IgnoreBlacklist bool // remove blacklisted networks anyway
+	OnEvent         extras.EventFunc
+}
 
 type IPAMConfig struct {
 	Subnet     string            `json:"Subnet,omitempty"`
@@ -63,15 +69,21 @@ type NetworkConnectRequest struct {
 	EndpointConfig *EndpointSettings `json:"EndpointConfig,omitempty"`
 }
 
+// EndpointIPAMConfig holds the static addresses of a container on a network (--ip, --ip6)
+type EndpointIPAMConfig struct {
+	IPv4Address string `json:"IPv4Address,omitempty"`
+	IPv6Address string `json:"IPv6Address,omitempty"`
+}
+
 type NetworkDisconnectRequest struct {
 	Container string `json:"Container"`
 	Force     bool   `json:"Force,omitempty"`
 }
 
 type EndpointSettings struct {
-	IPAMConfig *IPAMConfig `json:"IPAMConfig,omitempty"`
-	Links      []string    `json:"Links,omitempty"`
-	Aliases    []string    `json:"Aliases,omitempty"`
+	IPAMConfig *EndpointIPAMConfig `json:"IPAMConfig,omitempty"`
+	Links      []string            `json:"Links,omitempty"`
+	Aliases    []string            `json:"Aliases,omitempty"`
 	// Keep the rest optional; Docker may return more fields than you send.
 	MacAddress string            `json:"MacAddress,omitempty"`
 	DriverOpts map[string]string `json:"DriverOpts,omitempty"`
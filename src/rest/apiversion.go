@@ -16,22 +16,34 @@ import (
 	"strings"
 )
 
-// NegotiateAPIVersion queries /version without a version prefix and returns ApiVersion.
-// Caller is expected to call client.SetAPIVersion() with the returned value.
-func NegotiateAPIVersion(ctx context.Context, client *Client) (string, error) {
+// ServerVersion queries /version without a version prefix, and records which engine answered
+// (see Client.Engine).
+func ServerVersion(ctx context.Context, client *Client) (*VersionInfo, error) {
 	resp, err := client.Do(ctx, http.MethodGet, "/version", nil, nil, nil)
 	if err != nil {
-		return "", fmt.Errorf("GET /version failed: %w", err)
+		return nil, fmt.Errorf("GET /version failed: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("GET /version returned %s", resp.Status)
+	if aerr := CheckResponse(resp); aerr != nil {
+		return nil, aerr
 	}
 
-	var info versionInfo
+	var info VersionInfo
 	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
-		return "", fmt.Errorf("decoding /version response failed: %w", err)
+		return nil, fmt.Errorf("decoding /version response failed: %w", err)
+	}
+
+	client.engine = detectEngine(info)
+	return &info, nil
+}
+
+// NegotiateAPIVersion queries /version and returns ApiVersion.
+// Caller is expected to call client.SetAPIVersion() with the returned value.
+func NegotiateAPIVersion(ctx context.Context, client *Client) (string, error) {
+	info, err := ServerVersion(ctx, client)
+	if err != nil {
+		return "", err
 	}
 
 	if info.ApiVersion == "" {
@@ -14,20 +14,43 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
-
-	hftx "github.com/jeanfrancoisgratton/helperFunctions/v4/terminalfx"
 )
 
 // NewClient builds a Client from Config.
-// If Host is empty, DOCKER_HOST or the standard Docker default is used.
+// If Host is empty, DOCKER_HOST is used, then whatever DefaultHost() finds (Docker or Podman sockets).
+// ssh://[user@]host[:port][/socket] hosts are reached through an SSH session (see ssh.go).
+// With ReplayFile set, the host is ignored altogether (see record.go).
 func NewClient(cfg Config) (*Client, error) {
+	if cfg.ReplayFile != "" {
+		if cfg.RecordFile != "" {
+			return nil, fmt.Errorf("cannot record and replay at the same time")
+		}
+		rp, err := newReplayer(cfg.ReplayFile)
+		if err != nil {
+			return nil, err
+		}
+		baseURL, _ := url.Parse("http://d")
+		return &Client{
+			httpClient: &http.Client{Transport: rp},
+			baseURL:    baseURL,
+			apiVersion: strings.TrimSpace(cfg.APIVersion),
+			replayer:   rp,
+
+			connectTimeout:    orDefault(cfg.ConnectTimeout, DefaultConnectTimeout),
+			headerTimeout:     orDefault(cfg.ResponseHeaderTimeout, DefaultResponseHeaderTimeout),
+			idleTimeout:       orDefault(cfg.IdleTimeout, DefaultIdleTimeout),
+			streamIdleTimeout: orDefault(cfg.StreamIdleTimeout, DefaultStreamIdleTimeout),
+		}, nil
+	}
+
 	host := cfg.Host
 	if host == "" {
 		host = os.Getenv("DOCKER_HOST")
 		if host == "" {
-			// Standard default for local Docker.
-			host = "unix:///var/run/docker.sock"
+			host = DefaultHost()
 		}
 	}
 
@@ -37,17 +60,38 @@ func NewClient(cfg Config) (*Client, error) {
 	if !isUnix && !strings.Contains(host, "://") {
 		host = "tcp://" + host
 	}
-	if !QuietOutput && ConnectURI != "" {
-		fmt.Println(fmt.Sprintf("%s: %s\n", hftx.InfoSign("Connected to"), hftx.Blue(host)))
-	}
+
+	// No overall http.Client timeout: it would cut long streams (pull, push, logs -f...) regardless
+	// of their progress. Each phase gets its own timeout instead (see timeouts.go).
+	connectTimeout := orDefault(cfg.ConnectTimeout, DefaultConnectTimeout)
+	dialer := &net.Dialer{Timeout: connectTimeout}
 
 	var (
 		transport *http.Transport
 		baseURL   *url.URL
 		unixPath  string
+		sshDial   *sshDialer
 	)
 
-	if isUnix {
+	if strings.HasPrefix(host, "ssh://") {
+		u, err := url.Parse(host)
+		if err != nil {
+			return nil, fmt.Errorf("invalid host %q: %w", host, err)
+		}
+		if sshDial, err = newSSHDialer(u, connectTimeout); err != nil {
+			return nil, err
+		}
+
+		transport = &http.Transport{
+			Proxy:           nil,
+			DialContext:     sshDial.DialContext,
+			MaxIdleConns:    10,
+			IdleConnTimeout: 90 * time.Second,
+		}
+
+		// Same as for unix sockets: only the path matters.
+		baseURL, _ = url.Parse("http://d")
+	} else if isUnix {
 		// Strip unix:// prefix and keep the socket path.
 		unixPath = strings.TrimPrefix(host, "unix://")
 		if unixPath == "" {
@@ -57,8 +101,7 @@ func NewClient(cfg Config) (*Client, error) {
 		transport = &http.Transport{
 			Proxy: nil,
 			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
-				// Keep the same 30s dial timeout as before.
-				return net.DialTimeout("unix", unixPath, 30*time.Second)
+				return dialer.DialContext(ctx, "unix", unixPath)
 			},
 		}
 
@@ -88,6 +131,7 @@ func NewClient(cfg Config) (*Client, error) {
 		}
 		u.Scheme = scheme
 
+		applyCertPathDefaults(&cfg)
 		tlsConfig, err := buildTLSConfig(cfg)
 		if err != nil {
 			return nil, fmt.Errorf("failed to build TLS config: %w", err)
@@ -95,6 +139,7 @@ func NewClient(cfg Config) (*Client, error) {
 
 		transport = &http.Transport{
 			Proxy:               http.ProxyFromEnvironment,
+			DialContext:         dialer.DialContext,
 			TLSClientConfig:     tlsConfig,
 			MaxIdleConns:        100,
 			IdleConnTimeout:     90 * time.Second,
@@ -104,14 +149,17 @@ func NewClient(cfg Config) (*Client, error) {
 		baseURL = u
 	}
 
-	timeout := cfg.Timeout
-	if timeout == 0 {
-		timeout = 60 * time.Second
-	}
-
 	httpClient := &http.Client{
 		Transport: transport,
-		Timeout:   timeout,
+	}
+
+	var rec *recorder
+	if cfg.RecordFile != "" {
+		var err error
+		if rec, err = newRecorder(cfg.RecordFile); err != nil {
+			return nil, err
+		}
+		httpClient.Transport = &recordingTransport{next: transport, rec: rec}
 	}
 
 	return &Client{
@@ -120,6 +168,13 @@ func NewClient(cfg Config) (*Client, error) {
 		apiVersion: strings.TrimSpace(cfg.APIVersion),
 		isUnix:     isUnix,
 		unixPath:   unixPath,
+		sshDialer:  sshDial,
+		recorder:   rec,
+
+		connectTimeout:    connectTimeout,
+		headerTimeout:     orDefault(cfg.ResponseHeaderTimeout, DefaultResponseHeaderTimeout),
+		idleTimeout:       orDefault(cfg.IdleTimeout, DefaultIdleTimeout),
+		streamIdleTimeout: orDefault(cfg.StreamIdleTimeout, DefaultStreamIdleTimeout),
 	}, nil
 }
 
@@ -151,8 +206,12 @@ func (c *Client) Do(
 		u.RawQuery = query.Encode()
 	}
 
+	headerTimeout, idleTimeout := c.timeoutsFor(ctx)
+	ctx, cancel := context.WithCancel(ctx)
+
 	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
 	if err != nil {
+		cancel()
 		return nil, err
 	}
 
@@ -162,7 +221,51 @@ func (c *Client) Do(
 		}
 	}
 
-	return c.httpClient.Do(req)
+	// The response-header timeout starts once the request body (if any) is sent:
+	// uploading a large tarball to /images/load must not count against it.
+	var (
+		headerTimer    *time.Timer
+		headerTimedOut atomic.Bool
+		timerMu        sync.Mutex
+	)
+	startHeaderTimer := func() {
+		if headerTimeout <= 0 {
+			return
+		}
+		timerMu.Lock()
+		defer timerMu.Unlock()
+		headerTimer = time.AfterFunc(headerTimeout, func() {
+			headerTimedOut.Store(true)
+			cancel()
+		})
+	}
+	if req.Body != nil && req.Body != http.NoBody {
+		req.Body = &sentNotifier{ReadCloser: req.Body, fn: startHeaderTimer}
+	} else {
+		startHeaderTimer()
+	}
+
+	resp, err := c.httpClient.Do(req)
+
+	timerMu.Lock()
+	if headerTimer != nil {
+		headerTimer.Stop()
+	}
+	timerMu.Unlock()
+	if headerTimedOut.Load() {
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		cancel()
+		return nil, fmt.Errorf("%s %s: no response from the daemon after %s", method, path, headerTimeout)
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	resp.Body = newIdleTimeoutBody(resp.Body, idleTimeout, cancel)
+	return resp, nil
 }
 
 // SocketPath returns the Unix socket path, if using a Unix transport.
@@ -172,3 +275,14 @@ func (c *Client) SocketPath() string {
 	}
 	return c.unixPath
 }
+
+// Close releases the idle connections to the daemon and, for ssh:// hosts, the SSH session and the
+// ssh-agent connection. The client may still be used afterwards: it reconnects on the next request.
+func (c *Client) Close() {
+	if t, ok := c.httpClient.Transport.(interface{ CloseIdleConnections() }); ok {
+		t.CloseIdleConnections()
+	}
+	if c.sshDialer != nil {
+		c.sshDialer.close()
+	}
+}
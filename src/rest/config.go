@@ -7,16 +7,56 @@ package rest
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 )
 
-// ConfigFromEnv is a helper if later I eventually want to mirror DOCKER_* envs more closely.
+// ConfigFromEnv builds a Config from the DOCKER_* environment variables, the same way the docker CLI does.
+// Unset variables leave their field empty, so that NewClient falls back on its own defaults.
+//
+// DOCKER_TLS_VERIFY (any non-empty value) enables TLS with server verification,
+// DOCKER_TLS enables TLS without it. The certificates themselves come from DOCKER_CERT_PATH (see applyCertPathDefaults).
 func ConfigFromEnv() Config {
-	// This is intentionally minimal for now. You can extend it later.
-	host := os.Getenv("DOCKER_HOST")
-	if host == "" {
-		host = "unix:///var/run/docker.sock"
-	}
+	tlsVerify := os.Getenv("DOCKER_TLS_VERIFY") != ""
+	tls := os.Getenv("DOCKER_TLS") != ""
+
 	return Config{
-		Host: host,
+		Host:               strings.TrimSpace(os.Getenv("DOCKER_HOST")),
+		APIVersion:         strings.TrimPrefix(strings.TrimSpace(os.Getenv("DOCKER_API_VERSION")), "v"),
+		UseTLS:             tlsVerify || tls,
+		InsecureSkipVerify: tls && !tlsVerify,
+	}
+}
+
+// applyCertPathDefaults fills the TLS file paths that were not given explicitly, using the docker CLI
+// layout: ca.pem, cert.pem and key.pem in DOCKER_CERT_PATH, or ~/.docker when unset.
+// Files that do not exist are simply skipped; the client certificate is only used as a cert+key pair.
+func applyCertPathDefaults(cfg *Config) {
+	if !cfg.UseTLS {
+		return
+	}
+
+	dir := os.Getenv("DOCKER_CERT_PATH")
+	if dir == "" {
+		dir = filepath.Join(os.Getenv("HOME"), ".docker")
 	}
+	dir = NormalizePath(dir)
+
+	if cfg.CACertPath == "" && fileExists(filepath.Join(dir, "ca.pem")) {
+		cfg.CACertPath = filepath.Join(dir, "ca.pem")
+	}
+	if cfg.CertPath == "" && cfg.KeyPath == "" &&
+		fileExists(filepath.Join(dir, "cert.pem")) && fileExists(filepath.Join(dir, "key.pem")) {
+		cfg.CertPath = filepath.Join(dir, "cert.pem")
+		cfg.KeyPath = filepath.Join(dir, "key.pem")
+	}
+
+	cfg.CACertPath = NormalizePath(cfg.CACertPath)
+	cfg.CertPath = NormalizePath(cfg.CertPath)
+	cfg.KeyPath = NormalizePath(cfg.KeyPath)
+}
+
+func fileExists(path string) bool {
+	st, err := os.Stat(path)
+	return err == nil && !st.IsDir()
 }
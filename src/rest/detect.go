@@ -0,0 +1,121 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 14:32
+// Original filename: src/rest/detect.go
+
+package rest
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const dockerDefaultSocket = "/var/run/docker.sock"
+
+// DefaultHost picks the daemon to talk to when neither -H nor DOCKER_HOST is set.
+//
+// CONTAINER_HOST (podman-remote's own variable) wins when set. Otherwise the local sockets are probed
+// in this order: Docker, rootless Podman ($XDG_RUNTIME_DIR/podman/podman.sock), system Podman
+// (/run/podman/podman.sock). The first one we can actually connect to is used; failing that, the first
+// one that exists (so that errors, e.g. permission denied, are about the right socket), and failing
+// that, the Docker default.
+func DefaultHost() string {
+	if h := strings.TrimSpace(os.Getenv("CONTAINER_HOST")); h != "" {
+		return h
+	}
+
+	candidates := localSocketCandidates()
+
+	firstExisting := ""
+	for _, sock := range candidates {
+		st, err := os.Stat(sock)
+		if err != nil || st.Mode()&os.ModeSocket == 0 {
+			continue
+		}
+		if firstExisting == "" {
+			firstExisting = sock
+		}
+		if conn, err := net.DialTimeout("unix", sock, 500*time.Millisecond); err == nil {
+			_ = conn.Close()
+			return "unix://" + sock
+		}
+	}
+
+	if firstExisting != "" {
+		return "unix://" + firstExisting
+	}
+	return "unix://" + dockerDefaultSocket
+}
+
+// localSocketCandidates lists the well-known socket paths, in probing order
+func localSocketCandidates() []string {
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		runtimeDir = filepath.Join("/run/user", strconv.Itoa(os.Getuid()))
+	}
+
+	return []string{
+		dockerDefaultSocket,
+		filepath.Join(runtimeDir, "podman", "podman.sock"),
+		"/run/podman/podman.sock",
+	}
+}
+
+// detectEngine tells Docker and Podman apart from a /version payload.
+// Podman lists a "Podman Engine" component, Docker an "Engine" one and a "Docker Engine - ..." platform.
+func detectEngine(info VersionInfo) Engine {
+	e := Engine{Kind: EngineUnknown, Name: info.Platform.Name, Version: info.Version, APIVersion: info.ApiVersion}
+
+	for _, c := range info.Components {
+		name := strings.ToLower(c.Name)
+		switch {
+		case strings.Contains(name, "podman"):
+			return Engine{Kind: EnginePodman, Name: c.Name, Version: c.Version, APIVersion: info.ApiVersion}
+		case name == "engine":
+			e.Kind = EngineDocker
+			if c.Version != "" {
+				e.Version = c.Version
+			}
+		}
+	}
+
+	if e.Kind == EngineUnknown {
+		platform := strings.ToLower(info.Platform.Name)
+		switch {
+		case strings.Contains(platform, "podman"):
+			e.Kind = EnginePodman
+		case strings.Contains(platform, "docker"):
+			e.Kind = EngineDocker
+		}
+	}
+	if e.Name == "" {
+		switch e.Kind {
+		case EngineDocker:
+			e.Name = "Docker Engine"
+		case EnginePodman:
+			e.Name = "Podman Engine"
+		}
+	}
+	return e
+}
+
+// String returns a human-readable description, such as "Podman Engine 5.2.1 (API v1.41)"
+func (e Engine) String() string {
+	if e.Kind == EngineUnknown && e.Name == "" {
+		return "unknown engine"
+	}
+	s := strings.TrimSpace(e.Name + " " + e.Version)
+	if e.APIVersion != "" {
+		s += " (API v" + e.APIVersion + ")"
+	}
+	return s
+}
+
+// Engine returns the engine detected when /version was last queried (EngineUnknown before that)
+func (c *Client) Engine() Engine {
+	return c.engine
+}
@@ -0,0 +1,132 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 15:20
+// Original filename: src/rest/errors.go
+
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Sentinels matched by APIError.Is, so callers can write errors.Is(err, rest.ErrNotFound)
+// (or use the Is* shortcuts) instead of comparing status codes or messages.
+var (
+	ErrNotFound    = errors.New("not found")
+	ErrConflict    = errors.New("conflict")
+	ErrInUse       = errors.New("resource in use")
+	ErrNotModified = errors.New("not modified")
+	ErrForbidden   = errors.New("forbidden")
+)
+
+// APIError is a non-2xx answer from the daemon
+type APIError struct {
+	Method     string
+	Path       string // API path, without the /v<version> prefix
+	StatusCode int
+	Status     string // e.g. "404 Not Found"
+	Message    string // the daemon's own explanation, when it gave one
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s %s returned %s", e.Method, e.Path, e.Status)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// Is maps the status code (and, for "in use", the message) onto the package sentinels
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrNotModified:
+		return e.StatusCode == http.StatusNotModified
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrInUse:
+		// Docker answers 409 for volumes and images, 403 for networks with endpoints; Podman sometimes 500.
+		return e.StatusCode >= 400 && inUseMessage(e.Message)
+	}
+	return false
+}
+
+func inUseMessage(msg string) bool {
+	msg = strings.ToLower(msg)
+	for _, hint := range []string{"in use", "is being used", "being used by", "is using", "active endpoints", "has dependent"} {
+		if strings.Contains(msg, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsNotFound reports whether err is a daemon 404 (no such container, image, volume...)
+func IsNotFound(err error) bool { return errors.Is(err, ErrNotFound) }
+
+// IsConflict reports whether err is a daemon 409
+func IsConflict(err error) bool { return errors.Is(err, ErrConflict) }
+
+// IsInUse reports whether the daemon refused because the resource is still used by something else
+func IsInUse(err error) bool { return errors.Is(err, ErrInUse) }
+
+// IsNotModified reports whether err is a 304 (container already started/stopped...)
+func IsNotModified(err error) bool { return errors.Is(err, ErrNotModified) }
+
+// CheckResponse returns nil for a successful (2xx) response, and an *APIError otherwise.
+// On error, the (bounded) body is consumed to extract the daemon message; the caller still closes it.
+func CheckResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	return NewAPIError(resp)
+}
+
+// NewAPIError builds an *APIError from a failed response
+func NewAPIError(resp *http.Response) *APIError {
+	e := &APIError{StatusCode: resp.StatusCode, Status: resp.Status}
+	if resp.Request != nil {
+		e.Method = resp.Request.Method
+		if resp.Request.URL != nil {
+			e.Path = stripVersionPrefix(resp.Request.URL.Path)
+		}
+	}
+	if e.Status == "" {
+		e.Status = fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	if resp.Body != nil {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		e.Message = daemonMessage(b)
+	}
+	return e
+}
+
+// daemonMessage extracts the human part of an error body: {"message": "..."} for Docker
+// (Podman adds "cause" and "response"), or the raw text otherwise.
+func daemonMessage(body []byte) string {
+	var payload struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &payload); err == nil && payload.Message != "" {
+		return strings.TrimSpace(payload.Message)
+	}
+	return strings.TrimSpace(string(body))
+}
+
+var versionPrefix = regexp.MustCompile(`^/v[0-9]+(\.[0-9]+)*/`)
+
+func stripVersionPrefix(path string) string {
+	if loc := versionPrefix.FindStringIndex(path); loc != nil {
+		return path[loc[1]-1:]
+	}
+	return path
+}
@@ -0,0 +1,204 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 18:55
+// Original filename: src/rest/fakedaemon/archive.go
+
+package fakedaemon
+
+import (
+	"archive/tar"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The archive endpoints (GET/HEAD/PUT /containers/{id}/archive) work on Container.Files.
+
+// stat returns the entry at p; directories implied by deeper entries exist too. The caller holds d.mu
+func (c *container) stat(p string) (File, bool) {
+	p = path.Clean("/" + p)
+	if p == "/" {
+		return File{Mode: os.ModeDir | 0o755}, true
+	}
+	if f, ok := c.Files[p]; ok {
+		return f, true
+	}
+	for name := range c.Files {
+		if strings.HasPrefix(name, p+"/") {
+			return File{Mode: os.ModeDir | 0o755}, true
+		}
+	}
+	return File{}, false
+}
+
+func statHeader(name string, f File) string {
+	st := pathStat{Name: name, Size: int64(len(f.Data)), Mode: f.Mode, Mtime: orNow(f.ModTime), LinkTarget: f.LinkTarget}
+	b, _ := json.Marshal(st)
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// getArchive answers HEAD (stat only) and GET (a tar of the path). A path ending in "/." yields the
+// contents of the directory, without the directory itself, as with the real daemons.
+func (d *Daemon) getArchive(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	c := d.lookupContainer(w, r)
+	if c == nil {
+		return
+	}
+	reqPath := r.URL.Query().Get("path")
+	if reqPath == "" {
+		writeError(w, http.StatusBadRequest, "path parameter is required")
+		return
+	}
+	contentsOnly := strings.HasSuffix(reqPath, "/.")
+	p := path.Clean("/" + reqPath)
+	f, ok := c.stat(p)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Could not find the file %s in container %s", reqPath, r.PathValue("id"))
+		return
+	}
+
+	w.Header().Set("X-Docker-Container-Path-Stat", statHeader(path.Base(p), f))
+	if r.Method == http.MethodHead {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-tar")
+	w.WriteHeader(http.StatusOK)
+	tw := tar.NewWriter(w)
+	defer tw.Close()
+
+	if !f.Mode.IsDir() {
+		_ = writeTarEntry(tw, path.Base(p), f)
+		return
+	}
+
+	prefix := path.Base(p)
+	if contentsOnly || p == "/" {
+		prefix = ""
+	} else {
+		_ = writeTarEntry(tw, prefix, f)
+	}
+	for _, name := range c.tree(p) {
+		rel := strings.TrimPrefix(name, strings.TrimSuffix(p, "/")+"/")
+		entry, _ := c.stat(name)
+		_ = writeTarEntry(tw, path.Join(prefix, rel), entry)
+	}
+}
+
+// tree lists every path under dir (implied directories included), parents first
+func (c *container) tree(dir string) []string {
+	seen := map[string]bool{}
+	base := strings.TrimSuffix(dir, "/") + "/"
+	for name := range c.Files {
+		if !strings.HasPrefix(name, base) {
+			continue
+		}
+		for p := name; strings.HasPrefix(p, base); p = path.Dir(p) {
+			seen[p] = true
+		}
+	}
+	out := slices.Sorted(maps.Keys(seen))
+	sort.Strings(out)
+	return out
+}
+
+func writeTarEntry(tw *tar.Writer, name string, f File) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    int64(f.Mode.Perm()),
+		ModTime: orNow(f.ModTime),
+	}
+	switch {
+	case f.Mode.IsDir():
+		hdr.Typeflag = tar.TypeDir
+		hdr.Name += "/"
+	case f.Mode&os.ModeSymlink != 0:
+		hdr.Typeflag = tar.TypeSymlink
+		hdr.Linkname = f.LinkTarget
+	default:
+		hdr.Typeflag = tar.TypeReg
+		hdr.Size = int64(len(f.Data))
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if hdr.Typeflag == tar.TypeReg {
+		_, err := tw.Write(f.Data)
+		return err
+	}
+	return nil
+}
+
+// putArchive extracts the tar in the request body into the directory given by ?path=
+func (d *Daemon) putArchive(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	c := d.lookupContainer(w, r)
+	if c == nil {
+		return
+	}
+	dir := path.Clean("/" + r.URL.Query().Get("path"))
+	f, ok := c.stat(dir)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Could not find the file %s in container %s", dir, r.PathValue("id"))
+		return
+	}
+	if !f.Mode.IsDir() {
+		writeError(w, http.StatusBadRequest, "extraction point is not a directory")
+		return
+	}
+
+	tr := tar.NewReader(r.Body)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid tar stream: %s", err)
+			return
+		}
+		name := path.Clean("/" + hdr.Name)
+		if name == "/" {
+			continue
+		}
+		target := path.Join(dir, name)
+		entry := File{Mode: os.FileMode(hdr.Mode).Perm(), ModTime: hdr.ModTime}
+		if entry.ModTime.IsZero() {
+			entry.ModTime = time.Now()
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			entry.Mode |= os.ModeDir
+		case tar.TypeSymlink:
+			entry.Mode |= os.ModeSymlink
+			entry.LinkTarget = hdr.Linkname
+		case tar.TypeReg:
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid tar stream: %s", err)
+				return
+			}
+			entry.Data = data
+		default:
+			continue
+		}
+		c.Files[target] = entry
+	}
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,815 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 18:35
+// Original filename: src/rest/fakedaemon/containers.go
+
+package fakedaemon
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AddContainer seeds a container, and returns it as stored (ID, defaults...).
+// Its image and networks do not need to exist; its named volumes are created when missing.
+func (d *Daemon) AddContainer(c Container) Container {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.addContainer(c).Container
+}
+
+// Containers returns a snapshot of every container, sorted by name
+func (d *Daemon) Containers() []Container {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	out := make([]Container, 0, len(d.containers))
+	for _, c := range d.containers {
+		out = append(out, c.Container)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Container looks a container up by name, ID or ID prefix
+func (d *Daemon) Container(ref string) (Container, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if c := d.findContainer(ref); c != nil {
+		return c.Container, true
+	}
+	return Container{}, false
+}
+
+func (d *Daemon) addContainer(c Container) *container {
+	if c.ID == "" {
+		c.ID = newID()
+	}
+	if c.Name == "" {
+		c.Name = "fake_" + shortID(c.ID)
+	}
+	c.Name = strings.TrimPrefix(c.Name, "/")
+	if c.Hostname == "" {
+		c.Hostname = shortID(c.ID)
+	}
+	if c.State == "" {
+		c.State = StateCreated
+	}
+	c.Created = orNow(c.Created)
+	if c.Networks == nil {
+		c.Networks = []string{"bridge"}
+	}
+	if c.ImageID == "" {
+		if img := d.findImage(c.Image); img != nil {
+			c.ImageID = img.ID
+		}
+	}
+	if c.Files == nil {
+		c.Files = make(map[string]File)
+	}
+	for _, m := range c.Mounts {
+		if m.Type == "volume" && m.Name != "" && d.volumes[m.Name] == nil {
+			d.addVolume(&Volume{Name: m.Name})
+		}
+	}
+
+	ct := &container{Container: c, stopped: make(chan struct{}), exited: make(chan struct{}), removed: make(chan struct{}),
+		baseline: maps.Clone(c.Files), endpoints: make(map[string]endpointConfig)}
+	if c.State != StateRunning && c.State != StatePaused {
+		close(ct.stopped)
+	}
+	d.containers[c.ID] = ct
+	return ct
+}
+
+// findContainer resolves a name, full ID or unique ID prefix; the caller holds d.mu
+func (d *Daemon) findContainer(ref string) *container {
+	ref = strings.TrimPrefix(ref, "/")
+	if c, ok := d.containers[ref]; ok {
+		return c
+	}
+	var match *container
+	for _, c := range d.containers {
+		if c.Name == ref {
+			return c
+		}
+		if len(ref) >= 3 && strings.HasPrefix(c.ID, ref) {
+			if match != nil {
+				return nil
+			}
+			match = c
+		}
+	}
+	return match
+}
+
+// lookupContainer is findContainer for the handlers: it answers 404 itself; the caller holds d.mu
+func (d *Daemon) lookupContainer(w http.ResponseWriter, r *http.Request) *container {
+	ref := r.PathValue("id")
+	c := d.findContainer(ref)
+	if c == nil {
+		writeError(w, http.StatusNotFound, "No such container: %s", ref)
+	}
+	return c
+}
+
+func (c *container) status() string {
+	switch c.State {
+	case StateRunning:
+		switch c.Health {
+		case "":
+			return "Up Less than a second"
+		case "starting":
+			return "Up Less than a second (health: starting)"
+		default:
+			return "Up Less than a second (" + c.Health + ")"
+		}
+	case StatePaused:
+		return "Up Less than a second (Paused)"
+	case StateExited:
+		return fmt.Sprintf("Exited (%d) Less than a second ago", c.ExitCode)
+	default:
+		return "Created"
+	}
+}
+
+func (d *Daemon) setRunning(c *container) {
+	if c.State != StateRunning && c.State != StatePaused {
+		c.stopped = make(chan struct{})
+	}
+	c.State = StateRunning
+	c.ExitCode = 0
+}
+
+// setExited stops a container; --rm containers go away right after
+func (d *Daemon) setExited(c *container, exitCode int) {
+	c.ExitCode = exitCode
+	d.stop(c)
+	c.State = StateExited
+	if c.AutoRemove {
+		d.deleteContainer(c)
+	}
+}
+
+// stop wakes up the waiters of a running (or paused) container; the caller sets the new state
+func (d *Daemon) stop(c *container) {
+	if c.State != StateRunning && c.State != StatePaused {
+		return
+	}
+	close(c.stopped)
+	close(c.exited)
+	c.exited = make(chan struct{})
+}
+
+func (d *Daemon) deleteContainer(c *container) {
+	delete(d.containers, c.ID)
+	close(c.removed)
+}
+
+func (d *Daemon) summary(c *container, withSize bool) map[string]any {
+	nets := map[string]endpointSettings{}
+	for i, name := range c.Networks {
+		ep := endpointSettings{endpointConfig: c.endpoints[name], EndpointID: shortID(newID())}
+		if n := d.findNetwork(name); n != nil {
+			ep.NetworkID = n.ID
+		}
+		if name != "host" && name != "none" {
+			ep.Gateway = "172.17.0.1"
+			ep.IPAddress = fmt.Sprintf("172.17.0.%d", i+2)
+			ep.IPPrefixLen = 16
+		}
+		nets[name] = ep
+	}
+
+	mounts := make([]map[string]any, 0, len(c.Mounts))
+	for _, m := range c.Mounts {
+		mounts = append(mounts, map[string]any{
+			"Type": m.Type, "Name": m.Name, "Source": m.Source, "Destination": m.Destination, "RW": m.RW,
+		})
+	}
+	ports := make([]map[string]any, 0, len(c.Ports))
+	for _, p := range c.Ports {
+		ports = append(ports, map[string]any{"PrivatePort": p.PrivatePort, "PublicPort": p.PublicPort, "Type": p.Type, "IP": p.IP})
+	}
+
+	s := map[string]any{
+		"Id":              c.ID,
+		"Names":           []string{"/" + c.Name},
+		"Image":           c.Image,
+		"ImageID":         c.ImageID,
+		"Command":         strings.Join(c.command(), " "),
+		"Created":         c.Created.Unix(),
+		"Ports":           ports,
+		"Labels":          orEmpty(c.Labels),
+		"State":           c.State,
+		"Status":          c.status(),
+		"Mounts":          mounts,
+		"NetworkSettings": map[string]any{"Networks": nets},
+	}
+	if c.Health != "" {
+		s["Health"] = map[string]any{"Status": c.Health, "FailingStreak": 0}
+	}
+	if withSize {
+		var size int64
+		for _, f := range c.Files {
+			size += int64(len(f.Data))
+		}
+		s["SizeRw"] = size
+		s["SizeRootFs"] = size
+	}
+	return s
+}
+
+// command is what the container runs: its entrypoint followed by its cmd
+func (c *container) command() []string {
+	return append(slices.Clone(c.Entrypoint), c.Cmd...)
+}
+
+func orEmpty(m map[string]string) map[string]string {
+	if m == nil {
+		return map[string]string{}
+	}
+	return m
+}
+
+// --- handlers ---
+
+func (d *Daemon) listContainers(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	f, err := parseFilters(r, "status", "name", "label", "ancestor", "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "%s", err)
+		return
+	}
+	all, size := queryBool(r, "all"), queryBool(r, "size")
+	out := []map[string]any{}
+	for _, id := range slices.Sorted(maps.Keys(d.containers)) {
+		c := d.containers[id]
+		if !all && c.State != StateRunning && c.State != StatePaused && f["status"] == nil {
+			continue
+		}
+		if !f.matchAny("status", func(v string) bool { return v == c.State }) || !f.name(c.Name) || !f.labels(c.Labels) ||
+			!f.matchAny("id", func(v string) bool { return strings.HasPrefix(c.ID, v) }) ||
+			!f.matchAny("ancestor", func(v string) bool { img := d.findImage(v); return img != nil && img.ID == c.ImageID }) {
+			continue
+		}
+		out = append(out, d.summary(c, size))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (d *Daemon) createContainer(w http.ResponseWriter, r *http.Request) {
+	var raw json.RawMessage
+	if err := decodeBody(r, &raw); err != nil {
+		writeError(w, http.StatusBadRequest, "%s", err)
+		return
+	}
+	var req containerCreateRequest
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON payload: %s", err)
+			return
+		}
+	}
+	var hc hostConfig
+	if len(req.HostConfig) > 0 {
+		if err := json.Unmarshal(req.HostConfig, &hc); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid HostConfig: %s", err)
+			return
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	name := r.URL.Query().Get("name")
+	if name != "" {
+		if other := d.findContainer(name); other != nil && other.Name == strings.TrimPrefix(name, "/") {
+			writeError(w, http.StatusConflict, "Conflict. The container name \"/%s\" is already in use by container \"%s\". You have to remove (or rename) that container to be able to reuse that name.", other.Name, other.ID)
+			return
+		}
+	}
+	img := d.findImage(req.Image)
+	if img == nil {
+		writeError(w, http.StatusNotFound, "No such image: %s", req.Image)
+		return
+	}
+
+	networkMode := hc.NetworkMode
+	if networkMode == "" || networkMode == "default" {
+		networkMode = "bridge"
+	}
+	if d.findNetwork(networkMode) == nil {
+		writeError(w, http.StatusNotFound, "network %s not found", networkMode)
+		return
+	}
+
+	// As with docker, the image config provides the defaults: env and labels are merged by key, and the image
+	// cmd is only kept when neither the entrypoint nor the cmd are overridden
+	c := Container{
+		Name:       name,
+		Image:      req.Image,
+		ImageID:    img.ID,
+		Entrypoint: req.Entrypoint,
+		Cmd:        req.Cmd,
+		Env:        mergeEnv(img.Env, req.Env),
+		Labels:     mergeLabels(img.Labels, req.Labels),
+		User:       cmp.Or(req.User, img.User),
+		WorkingDir: cmp.Or(req.WorkingDir, img.WorkingDir),
+		Hostname:   req.Hostname,
+		TTY:        req.Tty,
+		Networks:   []string{networkMode},
+		AutoRemove: hc.AutoRemove,
+		Config:     raw,
+		HostConfig: req.HostConfig,
+	}
+	if req.Entrypoint == nil {
+		c.Entrypoint = img.Entrypoint
+		if req.Cmd == nil {
+			c.Cmd = img.Cmd
+		}
+	}
+	if req.Healthcheck != nil && len(req.Healthcheck.Test) > 0 && req.Healthcheck.Test[0] != "NONE" {
+		c.Health = "starting"
+	}
+	for _, m := range hc.Mounts {
+		mt := Mount{Type: m.Type, Source: m.Source, Destination: m.Target, RW: !m.ReadOnly}
+		if m.Type == "volume" {
+			mt.Name = m.Source
+			if mt.Name == "" {
+				mt.Name = d.anonymousVolume()
+			}
+		}
+		c.Mounts = append(c.Mounts, mt)
+	}
+	for _, b := range hc.Binds {
+		parts := strings.Split(b, ":")
+		if len(parts) < 2 {
+			continue
+		}
+		mt := Mount{Type: "bind", Source: parts[0], Destination: parts[1], RW: !(len(parts) > 2 && strings.Contains(parts[2], "ro"))}
+		if !strings.HasPrefix(parts[0], "/") {
+			mt.Type, mt.Name = "volume", parts[0]
+		}
+		c.Mounts = append(c.Mounts, mt)
+	}
+	for dst := range req.Volumes {
+		c.Mounts = append(c.Mounts, Mount{Type: "volume", Name: d.anonymousVolume(), Destination: dst, RW: true})
+	}
+	for spec, bindings := range hc.PortBindings {
+		port, proto, _ := strings.Cut(spec, "/")
+		private, _ := strconv.Atoi(port)
+		for _, b := range bindings {
+			public, _ := strconv.Atoi(b.HostPort)
+			c.Ports = append(c.Ports, Port{PrivatePort: uint16(private), PublicPort: uint16(public), Type: proto, IP: b.HostIP})
+		}
+	}
+
+	ct := d.addContainer(c)
+	if ep, ok := req.NetworkingConfig.EndpointsConfig[networkMode]; ok {
+		ct.endpoints[networkMode] = ep
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{"Id": ct.ID, "Warnings": []string{}})
+}
+
+// mergeEnv returns the image env overridden, variable by variable, by the container one
+func mergeEnv(image, ctr []string) []string {
+	env := slices.Clone(image)
+	for _, e := range ctr {
+		key, _, _ := strings.Cut(e, "=")
+		i := slices.IndexFunc(env, func(ie string) bool { k, _, _ := strings.Cut(ie, "="); return k == key })
+		if i >= 0 {
+			env[i] = e
+		} else {
+			env = append(env, e)
+		}
+	}
+	return env
+}
+
+func mergeLabels(image, ctr map[string]string) map[string]string {
+	if len(image) == 0 {
+		return ctr
+	}
+	labels := maps.Clone(image)
+	maps.Copy(labels, ctr)
+	return labels
+}
+
+func (d *Daemon) anonymousVolume() string {
+	v := &Volume{Name: newID(), Labels: map[string]string{"com.docker.volume.anonymous": ""}}
+	d.addVolume(v)
+	return v.Name
+}
+
+func (d *Daemon) inspectContainer(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	c := d.lookupContainer(w, r)
+	if c == nil {
+		return
+	}
+	s := d.summary(c, false)
+	hostConfig := c.HostConfig
+	if len(hostConfig) == 0 {
+		hostConfig = json.RawMessage(`{}`)
+	}
+	state := map[string]any{
+		"Status":   c.State,
+		"Running":  c.State == StateRunning || c.State == StatePaused,
+		"Paused":   c.State == StatePaused,
+		"ExitCode": c.ExitCode,
+	}
+	if c.Health != "" {
+		state["Health"] = map[string]any{"Status": c.Health, "FailingStreak": 0, "Log": []any{}}
+	}
+	config := map[string]any{}
+	if len(c.Config) > 0 {
+		_ = json.Unmarshal(c.Config, &config)
+	}
+	maps.Copy(config, map[string]any{
+		"Image":      c.Image,
+		"Entrypoint": c.Entrypoint,
+		"Cmd":        c.Cmd,
+		"Env":        c.Env,
+		"Labels":     orEmpty(c.Labels),
+		"User":       c.User,
+		"WorkingDir": c.WorkingDir,
+		"Hostname":   c.Hostname,
+		"Tty":        c.TTY,
+	})
+	delete(config, "HostConfig")
+	delete(config, "NetworkingConfig")
+	writeJSON(w, http.StatusOK, map[string]any{
+		"Id":              c.ID,
+		"Name":            "/" + c.Name,
+		"Created":         c.Created.UTC().Format(time.RFC3339Nano),
+		"Image":           c.ImageID,
+		"State":           state,
+		"Config":          config,
+		"HostConfig":      hostConfig,
+		"Mounts":          s["Mounts"],
+		"NetworkSettings": s["NetworkSettings"],
+	})
+}
+
+func (d *Daemon) startContainer(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	c := d.lookupContainer(w, r)
+	if c == nil {
+		return
+	}
+	switch c.State {
+	case StateRunning:
+		w.WriteHeader(http.StatusNotModified)
+		return
+	case StatePaused:
+		writeError(w, http.StatusConflict, "cannot start a paused container, try unpause instead")
+		return
+	}
+	d.setRunning(c)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (d *Daemon) stopContainer(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	c := d.lookupContainer(w, r)
+	if c == nil {
+		return
+	}
+	if c.State != StateRunning && c.State != StatePaused {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	d.setExited(c, 0)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (d *Daemon) killContainer(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	c := d.lookupContainer(w, r)
+	if c == nil {
+		return
+	}
+	if c.State != StateRunning && c.State != StatePaused {
+		writeError(w, http.StatusConflict, "Cannot kill container: %s: container %s is not running", r.PathValue("id"), c.ID)
+		return
+	}
+	d.setExited(c, 137)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (d *Daemon) restartContainer(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	c := d.lookupContainer(w, r)
+	if c == nil {
+		return
+	}
+	if c.State == StateRunning || c.State == StatePaused {
+		d.stop(c)
+		c.State = StateExited
+	}
+	d.setRunning(c)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (d *Daemon) pauseContainer(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	c := d.lookupContainer(w, r)
+	if c == nil {
+		return
+	}
+	switch c.State {
+	case StatePaused:
+		writeError(w, http.StatusConflict, "container %s is already paused", c.ID)
+	case StateRunning:
+		c.State = StatePaused
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusConflict, "container %s is not running", c.ID)
+	}
+}
+
+func (d *Daemon) unpauseContainer(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	c := d.lookupContainer(w, r)
+	if c == nil {
+		return
+	}
+	if c.State != StatePaused {
+		writeError(w, http.StatusConflict, "container %s is not paused", c.ID)
+		return
+	}
+	c.State = StateRunning
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (d *Daemon) renameContainer(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	c := d.lookupContainer(w, r)
+	if c == nil {
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Query().Get("name"), "/")
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "Neither old nor new names may be empty")
+		return
+	}
+	for _, other := range d.containers {
+		if other.Name == name && other != c {
+			writeError(w, http.StatusConflict, "Conflict. The container name \"/%s\" is already in use by container \"%s\".", name, other.ID)
+			return
+		}
+	}
+	c.Name = name
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// updateContainer merges the new limits and restart policy into the stored HostConfig
+func (d *Daemon) updateContainer(w http.ResponseWriter, r *http.Request) {
+	var req map[string]any
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "%s", err)
+		return
+	}
+	if mem, ok := req["Memory"].(float64); ok && mem > 0 && mem < 6*1024*1024 {
+		writeError(w, http.StatusBadRequest, "Minimum memory limit allowed is 6MB")
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	c := d.lookupContainer(w, r)
+	if c == nil {
+		return
+	}
+	hc := map[string]any{}
+	if len(c.HostConfig) > 0 {
+		_ = json.Unmarshal(c.HostConfig, &hc)
+	}
+	maps.Copy(hc, req)
+	c.HostConfig, _ = json.Marshal(hc)
+	writeJSON(w, http.StatusOK, map[string]any{"Warnings": []string{}})
+}
+
+// waitContainer blocks until the wait condition is met (or the client goes away)
+func (d *Daemon) waitContainer(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	c := d.lookupContainer(w, r)
+	if c == nil {
+		d.mu.Unlock()
+		return
+	}
+	var done chan struct{}
+	switch cond := r.URL.Query().Get("condition"); cond {
+	case "", "not-running":
+		done = c.stopped
+	case "next-exit":
+		done = c.exited
+	case "removed":
+		done = c.removed
+	default:
+		d.mu.Unlock()
+		writeError(w, http.StatusBadRequest, "invalid condition: %q", cond)
+		return
+	}
+	d.mu.Unlock()
+
+	select {
+	case <-done:
+	case <-r.Context().Done():
+		return
+	}
+
+	d.mu.Lock()
+	code := c.ExitCode
+	d.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]any{"StatusCode": code})
+}
+
+func (d *Daemon) resizeContainer(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if c := d.lookupContainer(w, r); c != nil {
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// containerLogs serves Container.Logs: raw for TTY containers, multiplexed (stdout frames) otherwise
+func (d *Daemon) containerLogs(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	c := d.lookupContainer(w, r)
+	if c == nil {
+		d.mu.Unlock()
+		return
+	}
+	logs, tty := c.Logs, c.TTY
+	d.mu.Unlock()
+
+	if tail := r.URL.Query().Get("tail"); tail != "" && tail != "all" {
+		if n, err := strconv.Atoi(tail); err == nil && n >= 0 {
+			lines := strings.SplitAfter(logs, "\n")
+			if lines[len(lines)-1] == "" {
+				lines = lines[:len(lines)-1]
+			}
+			if n < len(lines) {
+				lines = lines[len(lines)-n:]
+			}
+			logs = strings.Join(lines, "")
+		}
+	}
+
+	if tty {
+		w.Header().Set("Content-Type", "application/vnd.docker.raw-stream")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(logs))
+		return
+	}
+
+	var buf bytes.Buffer
+	for _, line := range strings.SplitAfter(logs, "\n") {
+		if line == "" {
+			continue
+		}
+		hdr := [8]byte{1}
+		binary.BigEndian.PutUint32(hdr[4:], uint32(len(line)))
+		buf.Write(hdr[:])
+		buf.WriteString(line)
+	}
+	w.Header().Set("Content-Type", "application/vnd.docker.multiplexed-stream")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
+
+func (d *Daemon) removeContainer(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	c := d.lookupContainer(w, r)
+	if c == nil {
+		return
+	}
+	if (c.State == StateRunning || c.State == StatePaused) && !queryBool(r, "force") {
+		writeError(w, http.StatusConflict, "cannot remove container \"/%s\": container is running: stop the container before removing or force remove", c.Name)
+		return
+	}
+	d.stop(c)
+	d.deleteContainer(c)
+
+	// v=true also removes the anonymous volumes
+	if queryBool(r, "v") {
+		for _, m := range c.Mounts {
+			if v := d.volumes[m.Name]; m.Type == "volume" && v != nil {
+				if _, anon := v.Labels["com.docker.volume.anonymous"]; anon && len(d.volumeUsers(v.Name)) == 0 {
+					delete(d.volumes, v.Name)
+				}
+			}
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// containerTop reports a single process: the container command, as PID 1. ps_args are ignored.
+func (d *Daemon) containerTop(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	c := d.lookupContainer(w, r)
+	if c == nil {
+		return
+	}
+	if c.State != StateRunning && c.State != StatePaused {
+		writeError(w, http.StatusConflict, "Container %s is not running", r.PathValue("id"))
+		return
+	}
+	cmd := strings.Join(c.command(), " ")
+	if cmd == "" {
+		cmd = "/bin/sh"
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"Titles":    []string{"UID", "PID", "PPID", "C", "STIME", "TTY", "TIME", "CMD"},
+		"Processes": [][]string{{"root", "1", "0", "0", c.Created.Format("15:04"), "?", "00:00:00", cmd}},
+	})
+}
+
+// containerChanges compares Files with what was seeded. As with docker, the existing parents of an added
+// or deleted entry are reported as changed.
+func (d *Daemon) containerChanges(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	c := d.lookupContainer(w, r)
+	if c == nil {
+		return
+	}
+	base := &container{Container: Container{Files: c.baseline}}
+	kinds := map[string]int{}
+	touchParents := func(p string) {
+		for dir := path.Dir(p); dir != "/"; dir = path.Dir(dir) {
+			if _, ok := base.stat(dir); ok {
+				if _, seen := kinds[dir]; !seen {
+					kinds[dir] = 0
+				}
+			}
+		}
+	}
+	for p, f := range c.Files {
+		old, ok := c.baseline[p]
+		switch {
+		case !ok:
+			if _, implied := base.stat(p); implied {
+				continue // a directory that already existed, now explicit
+			}
+			kinds[p] = 1
+			touchParents(p)
+		case string(old.Data) != string(f.Data) || old.Mode != f.Mode || old.LinkTarget != f.LinkTarget:
+			kinds[p] = 0
+		}
+	}
+	for p := range c.baseline {
+		if _, ok := c.stat(p); !ok {
+			kinds[p] = 2
+			touchParents(p)
+		}
+	}
+
+	out := []map[string]any{}
+	for _, p := range slices.Sorted(maps.Keys(kinds)) {
+		out = append(out, map[string]any{"Path": p, "Kind": kinds[p]})
+	}
+	if len(out) == 0 {
+		writeJSON(w, http.StatusOK, nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, out)
+}
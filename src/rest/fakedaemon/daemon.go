@@ -0,0 +1,287 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 18:20
+// Original filename: src/rest/fakedaemon/daemon.go
+
+// Package fakedaemon is an in-process stand-in for the Docker Engine API, meant for integration tests
+// and for programs embedding the dtools packages.
+//
+// It serves, over a unix socket in a temporary directory, the subset of the API dtools uses: version and
+// info, containers (list, create, start, stop, kill, restart, pause, rename, remove, wait, logs, stats, top,
+// changes and the archive endpoints behind cp), images (list, pull, tag, commit, remove), volumes and networks.
+// Everything lives in memory: no process is ever run, and a container filesystem is whatever was put in
+// Container.Files (or copied there with cp). The hijacked endpoints (attach, exec) and build are not served.
+//
+//	d, err := fakedaemon.Start(fakedaemon.Options{})
+//	defer d.Close()
+//	d.AddImage(fakedaemon.Image{RepoTags: []string{"nginx:latest"}})
+//	d.AddContainer(fakedaemon.Container{Name: "web", Image: "nginx:latest", State: fakedaemon.StateRunning})
+//	client, err := d.NewClient()
+//	errs := containers.StopContainers(ctx, client, []string{"web"}, containers.StopOptions{})
+//
+// In a test, StartT(t) does the same in one call and closes everything when the test ends.
+package fakedaemon
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"dtools2/rest"
+)
+
+// Options controls Start()
+type Options struct {
+	APIVersion string // returned by /version; "1.43" when empty
+	Podman     bool   // identify as Podman instead of Docker in /version
+
+	// Wrap, when set, wraps the handler of the API: to delay or throttle the responses, serve extra paths...
+	Wrap func(http.Handler) http.Handler
+}
+
+// Daemon is a running fake engine
+type Daemon struct {
+	opts   Options
+	dir    string
+	socket string
+	ln     net.Listener
+	srv    *http.Server
+
+	mu         sync.Mutex
+	containers map[string]*container // keyed by ID
+	images     map[string]*Image     // keyed by ID
+	volumes    map[string]*Volume    // keyed by name
+	networks   map[string]*Network   // keyed by ID
+	requests   []string
+}
+
+// Start creates the socket and serves the API until Close is called.
+// The daemon starts with the three predefined networks (bridge, host and none) and nothing else.
+func Start(opts Options) (*Daemon, error) {
+	if opts.APIVersion == "" {
+		opts.APIVersion = "1.43"
+	}
+
+	dir, err := os.MkdirTemp("", "fakedaemon-")
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the socket directory: %w", err)
+	}
+	socket := filepath.Join(dir, "docker.sock")
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, fmt.Errorf("unable to listen on %s: %w", socket, err)
+	}
+
+	d := &Daemon{
+		opts:       opts,
+		dir:        dir,
+		socket:     socket,
+		ln:         ln,
+		containers: make(map[string]*container),
+		images:     make(map[string]*Image),
+		volumes:    make(map[string]*Volume),
+		networks:   make(map[string]*Network),
+	}
+	for _, n := range []string{"bridge", "host", "none"} {
+		driver := n
+		if n == "none" {
+			driver = "null"
+		}
+		d.addNetwork(&Network{Name: n, Driver: driver})
+	}
+
+	handler := d.routes()
+	if opts.Wrap != nil {
+		handler = opts.Wrap(handler)
+	}
+	d.srv = &http.Server{Handler: handler}
+	go func() { _ = d.srv.Serve(ln) }()
+	return d, nil
+}
+
+// Close stops serving and removes the socket
+func (d *Daemon) Close() error {
+	err := d.srv.Close()
+	_ = os.RemoveAll(d.dir)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// StartT starts a daemon for a test and returns it with a client connected to it; both are closed when the
+// test ends
+func StartT(t testing.TB) (*Daemon, *rest.Client) {
+	t.Helper()
+	d, err := Start(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = d.Close() })
+	client, err := d.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+	return d, client
+}
+
+// Host is the value to use as DOCKER_HOST (or rest.Config.Host)
+func (d *Daemon) Host() string {
+	return "unix://" + d.socket
+}
+
+// NewClient returns a REST client talking to the daemon, with its API version set and its engine detected
+func (d *Daemon) NewClient() (*rest.Client, error) {
+	client, err := rest.NewClient(rest.Config{Host: d.Host(), APIVersion: d.opts.APIVersion})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := rest.ServerVersion(context.Background(), client); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// Requests returns the calls served so far, as "METHOD /path" (without the version prefix nor the query)
+func (d *Daemon) Requests() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.requests...)
+}
+
+var versionPrefix = regexp.MustCompile(`^/v[0-9]+(\.[0-9]+)*/`)
+
+// routes wires the endpoints; the /v<version> prefix is optional, as with the real daemons
+func (d *Daemon) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /_ping", d.ping)
+	mux.HandleFunc("GET /version", d.version)
+	mux.HandleFunc("GET /info", d.info)
+
+	mux.HandleFunc("GET /containers/json", d.listContainers)
+	mux.HandleFunc("POST /containers/create", d.createContainer)
+	mux.HandleFunc("GET /containers/{id}/json", d.inspectContainer)
+	mux.HandleFunc("POST /containers/{id}/start", d.startContainer)
+	mux.HandleFunc("POST /containers/{id}/stop", d.stopContainer)
+	mux.HandleFunc("POST /containers/{id}/kill", d.killContainer)
+	mux.HandleFunc("POST /containers/{id}/restart", d.restartContainer)
+	mux.HandleFunc("POST /containers/{id}/pause", d.pauseContainer)
+	mux.HandleFunc("POST /containers/{id}/unpause", d.unpauseContainer)
+	mux.HandleFunc("POST /containers/{id}/rename", d.renameContainer)
+	mux.HandleFunc("POST /containers/{id}/update", d.updateContainer)
+	mux.HandleFunc("POST /containers/{id}/wait", d.waitContainer)
+	mux.HandleFunc("POST /containers/{id}/resize", d.resizeContainer)
+	mux.HandleFunc("GET /containers/{id}/logs", d.containerLogs)
+	mux.HandleFunc("GET /containers/{id}/stats", d.containerStats)
+	mux.HandleFunc("GET /containers/{id}/top", d.containerTop)
+	mux.HandleFunc("GET /containers/{id}/changes", d.containerChanges)
+	mux.HandleFunc("DELETE /containers/{id}", d.removeContainer)
+	// GET also matches HEAD: both are handled by getArchive
+	mux.HandleFunc("GET /containers/{id}/archive", d.getArchive)
+	mux.HandleFunc("PUT /containers/{id}/archive", d.putArchive)
+
+	mux.HandleFunc("GET /images/json", d.listImages)
+	mux.HandleFunc("POST /images/create", d.pullImage)
+	mux.HandleFunc("POST /commit", d.commitContainer)
+	// Image names may contain slashes, so the image routes dispatch on the suffix themselves.
+	mux.HandleFunc("GET /images/{name...}", d.getImage)
+	mux.HandleFunc("POST /images/{name...}", d.postImage)
+	mux.HandleFunc("DELETE /images/{name...}", d.removeImage)
+
+	mux.HandleFunc("GET /volumes", d.listVolumes)
+	mux.HandleFunc("POST /volumes/create", d.createVolume)
+	mux.HandleFunc("GET /volumes/{name}", d.inspectVolume)
+	mux.HandleFunc("DELETE /volumes/{name}", d.removeVolume)
+
+	mux.HandleFunc("GET /networks", d.listNetworks)
+	mux.HandleFunc("POST /networks/create", d.createNetwork)
+	mux.HandleFunc("GET /networks/{id}", d.inspectNetwork)
+	mux.HandleFunc("DELETE /networks/{id}", d.removeNetwork)
+	mux.HandleFunc("POST /networks/{id}/connect", d.connectNetwork)
+	mux.HandleFunc("POST /networks/{id}/disconnect", d.disconnectNetwork)
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotImplemented, "fakedaemon: %s %s is not implemented", r.Method, r.URL.Path)
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if loc := versionPrefix.FindStringIndex(r.URL.Path); loc != nil {
+			r.URL.Path = r.URL.Path[loc[1]-1:]
+			r.URL.RawPath = ""
+		}
+		d.mu.Lock()
+		d.requests = append(d.requests, r.Method+" "+r.URL.Path)
+		d.mu.Unlock()
+
+		w.Header().Set("Api-Version", d.opts.APIVersion)
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// --- helpers ---
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError answers like the daemons do: {"message": "..."}
+func writeError(w http.ResponseWriter, status int, format string, args ...any) {
+	writeJSON(w, status, map[string]string{"message": fmt.Sprintf(format, args...)})
+}
+
+func decodeBody(r *http.Request, v any) error {
+	if r.Body == nil || r.ContentLength == 0 {
+		return nil
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return fmt.Errorf("invalid JSON payload: %w", err)
+	}
+	return nil
+}
+
+func queryBool(r *http.Request, key string) bool {
+	switch strings.ToLower(r.URL.Query().Get(key)) {
+	case "1", "true", "yes":
+		return true
+	}
+	return false
+}
+
+func newID() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// shortID is the 12-character form shown by the CLIs
+func shortID(id string) string {
+	id = strings.TrimPrefix(id, "sha256:")
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+func orNow(t time.Time) time.Time {
+	if t.IsZero() {
+		return time.Now()
+	}
+	return t
+}
@@ -0,0 +1,93 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 20:30
+// Original filename: src/rest/fakedaemon/filters.go
+
+package fakedaemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// filters is the decoded `filters` query parameter of the list endpoints
+type filters map[string][]string
+
+// parseFilters decodes ?filters=, in both the current ({"key":["v"]}) and the legacy
+// ({"key":{"v":true}}) forms, and rejects the keys the endpoint does not know, as the daemons do.
+func parseFilters(r *http.Request, allowed ...string) (filters, error) {
+	raw := r.URL.Query().Get("filters")
+	if raw == "" {
+		return filters{}, nil
+	}
+	f := filters{}
+	if err := json.Unmarshal([]byte(raw), &f); err != nil {
+		var legacy map[string]map[string]bool
+		if json.Unmarshal([]byte(raw), &legacy) != nil {
+			return nil, fmt.Errorf("invalid filters: %s", raw)
+		}
+		for k, values := range legacy {
+			for v := range values {
+				f[k] = append(f[k], v)
+			}
+		}
+	}
+	for k := range f {
+		known := false
+		for _, a := range allowed {
+			known = known || k == a
+		}
+		if !known {
+			return nil, fmt.Errorf("invalid filter '%s'", k)
+		}
+	}
+	return f, nil
+}
+
+// matchAny is true when key is not filtered on, or when one of its values satisfies fn
+func (f filters) matchAny(key string, fn func(string) bool) bool {
+	values, ok := f[key]
+	if !ok {
+		return true
+	}
+	for _, v := range values {
+		if fn(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// labels is true when every label filter ("key" or "key=value") matches
+func (f filters) labels(labels map[string]string) bool {
+	for _, l := range f["label"] {
+		k, v, withValue := strings.Cut(l, "=")
+		got, ok := labels[k]
+		if !ok || (withValue && got != v) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchBool is true when key is not filtered on, or matches b
+func (f filters) matchBool(key string, b bool) bool {
+	return f.matchAny(key, func(v string) bool { return (v == "true" || v == "1") == b })
+}
+
+func (f filters) name(name string) bool {
+	return f.matchAny("name", func(v string) bool { return strings.Contains(name, v) })
+}
+
+// reference matches an image tag against a reference filter such as "nginx", "nginx:*" or "*/alpine:3.*"
+func matchReference(pattern, tag string) bool {
+	if ok, _ := path.Match(pattern, tag); ok {
+		return true
+	}
+	repo, _, _ := strings.Cut(tag, ":")
+	ok, _ := path.Match(pattern, repo)
+	return ok
+}
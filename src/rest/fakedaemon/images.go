@@ -0,0 +1,310 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 19:05
+// Original filename: src/rest/fakedaemon/images.go
+
+package fakedaemon
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// AddImage seeds an image, and returns it as stored
+func (d *Daemon) AddImage(img Image) Image {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return *d.addImage(img)
+}
+
+// Images returns a snapshot of every image, sorted by ID
+func (d *Daemon) Images() []Image {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	out := make([]Image, 0, len(d.images))
+	for _, id := range slices.Sorted(maps.Keys(d.images)) {
+		img := *d.images[id]
+		img.RepoTags = append([]string(nil), img.RepoTags...)
+		out = append(out, img)
+	}
+	return out
+}
+
+func (d *Daemon) addImage(img Image) *Image {
+	if img.ID == "" {
+		img.ID = newID()
+	}
+	if !strings.HasPrefix(img.ID, "sha256:") {
+		img.ID = "sha256:" + img.ID
+	}
+	img.Created = orNow(img.Created)
+	for i, t := range img.RepoTags {
+		img.RepoTags[i] = normalizeTag(t)
+		// A tag belongs to a single image: moving it leaves the previous owner without it.
+		d.untag(img.RepoTags[i])
+	}
+	d.images[img.ID] = &img
+	return &img
+}
+
+// normalizeTag adds the implied :latest
+func normalizeTag(ref string) string {
+	slash := strings.LastIndex(ref, "/")
+	if strings.LastIndex(ref, ":") > slash {
+		return ref
+	}
+	return ref + ":latest"
+}
+
+func (d *Daemon) untag(tag string) {
+	for _, img := range d.images {
+		for i, t := range img.RepoTags {
+			if t == tag {
+				img.RepoTags = append(img.RepoTags[:i], img.RepoTags[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// findImage resolves a tag (with or without :latest), an ID (with or without sha256:) or an ID prefix
+func (d *Daemon) findImage(ref string) *Image {
+	if ref == "" {
+		return nil
+	}
+	tag := normalizeTag(ref)
+	id := strings.TrimPrefix(ref, "sha256:")
+	for _, img := range d.images {
+		for _, t := range img.RepoTags {
+			if t == tag {
+				return img
+			}
+		}
+	}
+	var match *Image
+	for _, img := range d.images {
+		full := strings.TrimPrefix(img.ID, "sha256:")
+		if full == id {
+			return img
+		}
+		if len(id) >= 3 && strings.HasPrefix(full, id) {
+			if match != nil {
+				return nil
+			}
+			match = img
+		}
+	}
+	return match
+}
+
+// imageUsers lists the containers (running or not) created from img
+func (d *Daemon) imageUsers(img *Image) []*container {
+	var out []*container
+	for _, c := range d.containers {
+		if c.ImageID == img.ID {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// --- handlers ---
+
+func (d *Daemon) listImages(w http.ResponseWriter, r *http.Request) {
+	f, err := parseFilters(r, "dangling", "label", "reference")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "%s", err)
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	out := []map[string]any{}
+	for _, id := range slices.Sorted(maps.Keys(d.images)) {
+		img := d.images[id]
+		if !f.matchBool("dangling", len(img.RepoTags) == 0) || !f.labels(img.Labels) ||
+			!f.matchAny("reference", func(v string) bool {
+				for _, t := range img.RepoTags {
+					if matchReference(v, t) {
+						return true
+					}
+				}
+				return false
+			}) {
+			continue
+		}
+		tags := img.RepoTags
+		if len(tags) == 0 {
+			tags = []string{"<none>:<none>"}
+		}
+		out = append(out, map[string]any{
+			"Id":          img.ID,
+			"ParentId":    "",
+			"RepoTags":    tags,
+			"RepoDigests": []string{},
+			"Created":     img.Created.Unix(),
+			"Size":        img.Size,
+			"VirtualSize": img.Size,
+			"SharedSize":  -1,
+			"Labels":      orEmpty(img.Labels),
+			"Containers":  len(d.imageUsers(img)),
+		})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// pullImage "pulls" by creating the image when the reference is unknown
+func (d *Daemon) pullImage(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	ref := q.Get("fromImage")
+	if ref == "" {
+		writeError(w, http.StatusBadRequest, "fromImage is required")
+		return
+	}
+	if tag := q.Get("tag"); tag != "" {
+		ref += ":" + tag
+	}
+	ref = normalizeTag(ref)
+
+	d.mu.Lock()
+	status := "Status: Image is up to date for " + ref
+	if d.findImage(ref) == nil {
+		d.addImage(Image{RepoTags: []string{ref}, Size: 5 * 1024 * 1024})
+		status = "Status: Downloaded newer image for " + ref
+	}
+	d.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	_ = enc.Encode(map[string]string{"status": "Pulling from " + strings.SplitN(ref, ":", 2)[0]})
+	_ = enc.Encode(map[string]string{"status": status})
+}
+
+// commitContainer makes a new image out of a container
+func (d *Daemon) commitContainer(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	c := d.findContainer(q.Get("container"))
+	if c == nil {
+		writeError(w, http.StatusNotFound, "No such container: %s", q.Get("container"))
+		return
+	}
+	img := Image{Labels: c.Labels}
+	if base := d.images[c.ImageID]; base != nil {
+		img.Size = base.Size
+	}
+	if repo := q.Get("repo"); repo != "" {
+		tag := q.Get("tag")
+		if tag == "" {
+			tag = "latest"
+		}
+		img.RepoTags = []string{repo + ":" + tag}
+	}
+	stored := d.addImage(img)
+	writeJSON(w, http.StatusCreated, map[string]string{"Id": stored.ID})
+}
+
+// getImage serves GET /images/{name}/json
+func (d *Daemon) getImage(w http.ResponseWriter, r *http.Request) {
+	name, ok := strings.CutSuffix(r.PathValue("name"), "/json")
+	if !ok {
+		writeError(w, http.StatusNotImplemented, "fakedaemon: %s %s is not implemented", r.Method, r.URL.Path)
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	img := d.findImage(name)
+	if img == nil {
+		writeError(w, http.StatusNotFound, "No such image: %s", name)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"Id":       img.ID,
+		"RepoTags": img.RepoTags,
+		"Created":  img.Created.UTC().Format("2006-01-02T15:04:05.999999999Z07:00"),
+		"Size":     img.Size,
+		"Config": map[string]any{
+			"Labels": orEmpty(img.Labels), "Entrypoint": img.Entrypoint, "Cmd": img.Cmd, "Env": img.Env,
+			"User": img.User, "WorkingDir": img.WorkingDir,
+		},
+	})
+}
+
+// postImage serves POST /images/{name}/tag
+func (d *Daemon) postImage(w http.ResponseWriter, r *http.Request) {
+	name, ok := strings.CutSuffix(r.PathValue("name"), "/tag")
+	if !ok {
+		writeError(w, http.StatusNotImplemented, "fakedaemon: %s %s is not implemented", r.Method, r.URL.Path)
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	img := d.findImage(name)
+	if img == nil {
+		writeError(w, http.StatusNotFound, "No such image: %s", name)
+		return
+	}
+	q := r.URL.Query()
+	tag := q.Get("repo")
+	if t := q.Get("tag"); t != "" {
+		tag += ":" + t
+	}
+	tag = normalizeTag(tag)
+	d.untag(tag)
+	img.RepoTags = append(img.RepoTags, tag)
+	sort.Strings(img.RepoTags)
+	w.WriteHeader(http.StatusCreated)
+}
+
+// removeImage untags when the reference is one of several tags, and deletes the image otherwise
+func (d *Daemon) removeImage(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	img := d.findImage(name)
+	if img == nil {
+		writeError(w, http.StatusNotFound, "No such image: %s", name)
+		return
+	}
+	force := queryBool(r, "force")
+
+	tag := normalizeTag(name)
+	isTag := false
+	for _, t := range img.RepoTags {
+		isTag = isTag || t == tag
+	}
+	if isTag && len(img.RepoTags) > 1 {
+		d.untag(tag)
+		writeJSON(w, http.StatusOK, []map[string]string{{"Untagged": tag}})
+		return
+	}
+
+	if users := d.imageUsers(img); len(users) > 0 && !force {
+		writeError(w, http.StatusConflict, "conflict: unable to remove repository reference \"%s\" (must force) - container %s is using its referenced image %s", name, shortID(users[0].ID), shortID(img.ID))
+		return
+	}
+
+	out := []map[string]string{}
+	for _, t := range img.RepoTags {
+		out = append(out, map[string]string{"Untagged": t})
+	}
+	out = append(out, map[string]string{"Deleted": img.ID})
+	delete(d.images, img.ID)
+	writeJSON(w, http.StatusOK, out)
+}
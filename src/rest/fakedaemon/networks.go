@@ -0,0 +1,275 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 19:25
+// Original filename: src/rest/fakedaemon/networks.go
+
+package fakedaemon
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AddNetwork seeds a network, and returns it as stored
+func (d *Daemon) AddNetwork(n Network) Network {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return *d.addNetwork(&n)
+}
+
+// Networks returns a snapshot of every network, sorted by name
+func (d *Daemon) Networks() []Network {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	out := make([]Network, 0, len(d.networks))
+	for _, n := range d.sortedNetworks() {
+		out = append(out, *n)
+	}
+	return out
+}
+
+func (d *Daemon) addNetwork(n *Network) *Network {
+	if n.ID == "" {
+		n.ID = newID()
+	}
+	if n.Driver == "" {
+		n.Driver = "bridge"
+	}
+	if n.Scope == "" {
+		n.Scope = "local"
+	}
+	n.Created = orNow(n.Created)
+	d.networks[n.ID] = n
+	return n
+}
+
+// sortedNetworks returns the stored networks sorted by name; the caller holds d.mu
+func (d *Daemon) sortedNetworks() []*Network {
+	out := make([]*Network, 0, len(d.networks))
+	for _, n := range d.networks {
+		out = append(out, n)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// findNetwork resolves a name, full ID or unique ID prefix
+func (d *Daemon) findNetwork(ref string) *Network {
+	if n, ok := d.networks[ref]; ok {
+		return n
+	}
+	var match *Network
+	for _, n := range d.networks {
+		if n.Name == ref {
+			return n
+		}
+		if len(ref) >= 3 && strings.HasPrefix(n.ID, ref) {
+			if match != nil {
+				return nil
+			}
+			match = n
+		}
+	}
+	return match
+}
+
+func (d *Daemon) lookupNetwork(w http.ResponseWriter, r *http.Request) *Network {
+	n := d.findNetwork(r.PathValue("id"))
+	if n == nil {
+		writeError(w, http.StatusNotFound, "network %s not found", r.PathValue("id"))
+	}
+	return n
+}
+
+// networkUsers lists the containers attached to the network
+func (d *Daemon) networkUsers(n *Network) []*container {
+	var out []*container
+	for _, c := range d.containers {
+		for _, name := range c.Networks {
+			if name == n.Name {
+				out = append(out, c)
+				break
+			}
+		}
+	}
+	return out
+}
+
+func (d *Daemon) networkJSON(n *Network) map[string]any {
+	attached := map[string]any{}
+	for _, c := range d.networkUsers(n) {
+		attached[c.ID] = map[string]string{"Name": c.Name}
+	}
+	return map[string]any{
+		"Name":       n.Name,
+		"Id":         n.ID,
+		"Created":    n.Created.UTC().Format(time.RFC3339Nano),
+		"Scope":      n.Scope,
+		"Driver":     n.Driver,
+		"EnableIPv6": n.EnableIPv6,
+		"Internal":   n.Internal,
+		"Attachable": n.Attachable,
+		"Ingress":    false,
+		"IPAM":       map[string]any{"Driver": "default", "Config": []any{}},
+		"Options":    orEmpty(n.Options),
+		"Labels":     orEmpty(n.Labels),
+		"Containers": attached,
+	}
+}
+
+// --- handlers ---
+
+func (d *Daemon) listNetworks(w http.ResponseWriter, r *http.Request) {
+	f, err := parseFilters(r, "driver", "id", "label", "name")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "%s", err)
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	out := []map[string]any{}
+	for _, n := range d.sortedNetworks() {
+		if !f.name(n.Name) || !f.labels(n.Labels) || !f.matchAny("driver", func(s string) bool { return s == n.Driver }) ||
+			!f.matchAny("id", func(s string) bool { return strings.HasPrefix(n.ID, s) }) {
+			continue
+		}
+		out = append(out, d.networkJSON(n))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (d *Daemon) createNetwork(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name       string            `json:"Name"`
+		Driver     string            `json:"Driver"`
+		Internal   bool              `json:"Internal"`
+		Attachable bool              `json:"Attachable"`
+		EnableIPv6 bool              `json:"EnableIPv6"`
+		Options    map[string]string `json:"Options"`
+		Labels     map[string]string `json:"Labels"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "%s", err)
+		return
+	}
+	if req.Name == "" {
+		writeError(w, http.StatusBadRequest, "network name is required")
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, n := range d.networks {
+		if n.Name == req.Name {
+			writeError(w, http.StatusConflict, "network with name %s already exists", req.Name)
+			return
+		}
+	}
+	n := d.addNetwork(&Network{
+		Name: req.Name, Driver: req.Driver, Internal: req.Internal, Attachable: req.Attachable,
+		EnableIPv6: req.EnableIPv6, Options: req.Options, Labels: req.Labels,
+	})
+	writeJSON(w, http.StatusCreated, map[string]string{"Id": n.ID, "Warning": ""})
+}
+
+func (d *Daemon) inspectNetwork(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if n := d.lookupNetwork(w, r); n != nil {
+		writeJSON(w, http.StatusOK, d.networkJSON(n))
+	}
+}
+
+func (d *Daemon) removeNetwork(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	n := d.lookupNetwork(w, r)
+	if n == nil {
+		return
+	}
+	switch n.Name {
+	case "bridge", "host", "none":
+		writeError(w, http.StatusForbidden, "%s is a pre-defined network and cannot be removed", n.Name)
+		return
+	}
+	if len(d.networkUsers(n)) > 0 {
+		writeError(w, http.StatusForbidden, "error while removing network: network %s id %s has active endpoints", n.Name, n.ID)
+		return
+	}
+	delete(d.networks, n.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (d *Daemon) connectNetwork(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Container      string         `json:"Container"`
+		EndpointConfig endpointConfig `json:"EndpointConfig"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "%s", err)
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	n := d.lookupNetwork(w, r)
+	if n == nil {
+		return
+	}
+	c := d.findContainer(req.Container)
+	if c == nil {
+		writeError(w, http.StatusNotFound, "No such container: %s", req.Container)
+		return
+	}
+	for _, name := range c.Networks {
+		if name == n.Name {
+			writeError(w, http.StatusForbidden, "endpoint with name %s already exists in network %s", c.Name, n.Name)
+			return
+		}
+	}
+	c.Networks = append(c.Networks, n.Name)
+	c.endpoints[n.Name] = req.EndpointConfig
+	w.WriteHeader(http.StatusOK)
+}
+
+func (d *Daemon) disconnectNetwork(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Container string `json:"Container"`
+		Force     bool   `json:"Force"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "%s", err)
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	n := d.lookupNetwork(w, r)
+	if n == nil {
+		return
+	}
+	c := d.findContainer(req.Container)
+	if c == nil {
+		writeError(w, http.StatusNotFound, "No such container: %s", req.Container)
+		return
+	}
+	for i, name := range c.Networks {
+		if name == n.Name {
+			c.Networks = append(c.Networks[:i], c.Networks[i+1:]...)
+			delete(c.endpoints, n.Name)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+	}
+	writeError(w, http.StatusForbidden, "container %s is not connected to network %s", c.ID, n.Name)
+}
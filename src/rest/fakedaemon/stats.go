@@ -0,0 +1,109 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 21:20
+// Original filename: src/rest/fakedaemon/stats.go
+
+package fakedaemon
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// A running container is reported using a quarter of a CPU (25% on the 4 CPUs of the fake host),
+// 64 MiB of memory out of 8 GiB, 16 MiB of which are inactive page cache, in cgroup v2 form.
+const (
+	statsCPUs       = 4
+	statsCPUPerTick = 250 * time.Millisecond
+)
+
+func statsPayload(c *container, tick int) map[string]any {
+	running := c.State == StateRunning
+	cpu := func(t int) map[string]any {
+		if t < 0 || !running {
+			return map[string]any{"cpu_usage": map[string]any{"total_usage": 0}, "system_cpu_usage": 0, "online_cpus": 0}
+		}
+		return map[string]any{
+			"cpu_usage":        map[string]any{"total_usage": int64(t+1) * int64(statsCPUPerTick)},
+			"system_cpu_usage": int64(t+1) * int64(time.Second) * statsCPUs,
+			"online_cpus":      statsCPUs,
+		}
+	}
+	p := map[string]any{
+		"id":           c.ID,
+		"name":         "/" + c.Name,
+		"read":         time.Now().UTC().Format(time.RFC3339Nano),
+		"pids_stats":   map[string]any{},
+		"cpu_stats":    cpu(tick),
+		"precpu_stats": cpu(tick - 1),
+		"memory_stats": map[string]any{},
+		"blkio_stats":  map[string]any{"io_service_bytes_recursive": nil},
+	}
+	if running {
+		p["pids_stats"] = map[string]any{"current": 3}
+		p["memory_stats"] = map[string]any{
+			"usage": 64 << 20,
+			"limit": 8 << 30,
+			"stats": map[string]any{"inactive_file": 16 << 20, "active_file": 8 << 20},
+		}
+		p["blkio_stats"] = map[string]any{"io_service_bytes_recursive": []map[string]any{
+			{"major": 8, "minor": 0, "op": "read", "value": 1 << 20},
+			{"major": 8, "minor": 0, "op": "write", "value": int64(tick+1) * 4096},
+		}}
+		p["networks"] = map[string]any{"eth0": map[string]any{"rx_bytes": int64(tick+1) * 1500, "tx_bytes": int64(tick+1) * 500}}
+	}
+	return p
+}
+
+// containerStats serves GET /containers/{id}/stats. As with docker, a one-shot reading (stream=false) already
+// carries precpu_stats; a stream sends a reading every second until the container stops or the client leaves.
+func (d *Daemon) containerStats(w http.ResponseWriter, r *http.Request) {
+	stream := r.URL.Query().Get("stream") == "" || queryBool(r, "stream")
+
+	d.mu.Lock()
+	c := d.lookupContainer(w, r)
+	if c == nil {
+		d.mu.Unlock()
+		return
+	}
+	tick := 0
+	if !stream {
+		tick = 1
+	}
+	payload := statsPayload(c, tick)
+	d.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	_ = enc.Encode(payload)
+	if !stream {
+		return
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+
+		tick++
+		d.mu.Lock()
+		running := d.containers[c.ID] == c && c.State == StateRunning
+		payload = statsPayload(c, tick)
+		d.mu.Unlock()
+		if !running {
+			return
+		}
+		if err := enc.Encode(payload); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 19:35
+// Original filename: src/rest/fakedaemon/system.go
+
+package fakedaemon
+
+import (
+	"net/http"
+	"runtime"
+)
+
+const fakeVersion = "24.0.0-fake"
+
+func (d *Daemon) ping(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("OSType", "linux")
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("OK"))
+}
+
+// version identifies the engine the way rest.DetectEngine expects it: Podman names its component "Podman Engine"
+func (d *Daemon) version(w http.ResponseWriter, r *http.Request) {
+	platform, component := "Docker Engine - Community", "Engine"
+	if d.opts.Podman {
+		platform, component = "linux/"+runtime.GOARCH+" (fakedaemon)", "Podman Engine"
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"Platform": map[string]string{"Name": platform},
+		"Components": []map[string]any{{
+			"Name":    component,
+			"Version": fakeVersion,
+			"Details": map[string]string{"ApiVersion": d.opts.APIVersion, "MinAPIVersion": "1.24", "Os": "linux", "Arch": runtime.GOARCH},
+		}},
+		"Version":       fakeVersion,
+		"ApiVersion":    d.opts.APIVersion,
+		"MinAPIVersion": "1.24",
+		"Os":            "linux",
+		"Arch":          runtime.GOARCH,
+		"KernelVersion": "fake",
+	})
+}
+
+func (d *Daemon) info(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var running, paused, stopped int
+	for _, c := range d.containers {
+		switch c.State {
+		case StateRunning:
+			running++
+		case StatePaused:
+			paused++
+		default:
+			stopped++
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"ID":                "fakedaemon",
+		"Name":              "fakedaemon",
+		"Containers":        len(d.containers),
+		"ContainersRunning": running,
+		"ContainersPaused":  paused,
+		"ContainersStopped": stopped,
+		"Images":            len(d.images),
+		"Driver":            "overlay2",
+		"ServerVersion":     fakeVersion,
+		"OperatingSystem":   "fakedaemon",
+		"OSType":            "linux",
+		"Architecture":      runtime.GOARCH,
+		"NCPU":              runtime.NumCPU(),
+		"MemTotal":          int64(8 << 30),
+		"DockerRootDir":     "/var/lib/docker",
+	})
+}
@@ -0,0 +1,193 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 18:20
+// Original filename: src/rest/fakedaemon/types.go
+
+package fakedaemon
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// Container states, as reported by the API
+const (
+	StateCreated = "created"
+	StateRunning = "running"
+	StatePaused  = "paused"
+	StateExited  = "exited"
+)
+
+// Container is the daemon-side view of a container. Only Name and Image are needed to seed one;
+// the other fields get sensible defaults.
+type Container struct {
+	ID         string
+	Name       string // without the leading slash
+	Image      string // the reference it was created from
+	ImageID    string
+	Entrypoint []string
+	Cmd        []string
+	Env        []string
+	Labels     map[string]string
+	User       string
+	WorkingDir string
+	Hostname   string // the short ID when empty
+	TTY        bool
+	State      string // one of the State* constants; created when empty
+	ExitCode   int
+	Health     string // healthcheck status while running: starting, healthy or unhealthy; "" when there is no healthcheck
+	Created    time.Time
+	Mounts     []Mount
+	Networks   []string // names of the networks the container is attached to; bridge when nil
+	Ports      []Port
+
+	AutoRemove bool            // removed by the daemon once stopped (--rm)
+	Config     json.RawMessage // as sent to /containers/create; inspect returns it under the fields above
+	HostConfig json.RawMessage // as sent to /containers/create, returned as is by inspect
+
+	// Served by /containers/{id}/logs, as the container's stdout
+	Logs string
+
+	// The container filesystem, for the archive (cp) endpoints, keyed by absolute path.
+	// Parent directories are implied; "/" always exists. What is seeded here stands for the image
+	// content: /changes reports what differs from it afterwards.
+	Files map[string]File
+}
+
+type Mount struct {
+	Type        string // bind, volume or tmpfs
+	Name        string // volume name
+	Source      string
+	Destination string
+	RW          bool
+}
+
+type Port struct {
+	PrivatePort uint16
+	PublicPort  uint16
+	Type        string
+	IP          string
+}
+
+// File is an entry of a container filesystem; use os.ModeDir for directories and os.ModeSymlink for links
+type File struct {
+	Data       []byte
+	Mode       os.FileMode
+	ModTime    time.Time
+	LinkTarget string
+}
+
+// container adds the daemon bookkeeping to Container
+type container struct {
+	Container
+	stopped  chan struct{}   // closed when the container stops; waited on by /wait?condition=not-running
+	exited   chan struct{}   // closed then renewed at every exit; waited on by /wait?condition=next-exit
+	removed  chan struct{}   // closed when the container is removed; waited on by /wait?condition=removed
+	baseline map[string]File // Files as seeded, i.e. the image layer /changes compares against
+	// Endpoint settings sent to /containers/create or /networks/{id}/connect, by network name
+	endpoints map[string]endpointConfig
+}
+
+type Image struct {
+	ID       string // sha256:... ; generated when empty
+	RepoTags []string
+	Created  time.Time
+	Size     int64
+	Labels   map[string]string
+
+	// Config defaults, inherited by the containers created from the image
+	Entrypoint []string
+	Cmd        []string
+	Env        []string
+	User       string
+	WorkingDir string
+}
+
+type Volume struct {
+	Name       string
+	Driver     string // local when empty
+	Mountpoint string
+	Labels     map[string]string
+	Options    map[string]string
+	Created    time.Time
+}
+
+type Network struct {
+	ID         string
+	Name       string
+	Driver     string // bridge when empty
+	Scope      string // local when empty
+	Internal   bool
+	Attachable bool
+	EnableIPv6 bool
+	Labels     map[string]string
+	Options    map[string]string
+	Created    time.Time
+}
+
+// --- wire formats (subsets of the Engine API schemas) ---
+
+type containerCreateRequest struct {
+	Image        string              `json:"Image"`
+	Cmd          []string            `json:"Cmd"`
+	Entrypoint   []string            `json:"Entrypoint"`
+	Env          []string            `json:"Env"`
+	Labels       map[string]string   `json:"Labels"`
+	Tty          bool                `json:"Tty"`
+	Volumes      map[string]struct{} `json:"Volumes"`
+	ExposedPorts map[string]struct{} `json:"ExposedPorts"`
+	User         string              `json:"User"`
+	WorkingDir   string              `json:"WorkingDir"`
+	Hostname     string              `json:"Hostname"`
+	Healthcheck  *struct {
+		Test []string `json:"Test"`
+	} `json:"Healthcheck"`
+	HostConfig       json.RawMessage `json:"HostConfig"`
+	NetworkingConfig struct {
+		EndpointsConfig map[string]endpointConfig `json:"EndpointsConfig"`
+	} `json:"NetworkingConfig"`
+}
+
+type hostConfig struct {
+	AutoRemove  bool     `json:"AutoRemove"`
+	Binds       []string `json:"Binds"`
+	NetworkMode string   `json:"NetworkMode"`
+	Mounts      []struct {
+		Type     string `json:"Type"`
+		Source   string `json:"Source"`
+		Target   string `json:"Target"`
+		ReadOnly bool   `json:"ReadOnly"`
+	} `json:"Mounts"`
+	PortBindings map[string][]struct {
+		HostIP   string `json:"HostIp"`
+		HostPort string `json:"HostPort"`
+	} `json:"PortBindings"`
+}
+
+// endpointConfig is what a client may set on an endpoint
+type endpointConfig struct {
+	Aliases    []string `json:"Aliases,omitempty"`
+	IPAMConfig *struct {
+		IPv4Address string `json:"IPv4Address,omitempty"`
+		IPv6Address string `json:"IPv6Address,omitempty"`
+	} `json:"IPAMConfig,omitempty"`
+	MacAddress string `json:"MacAddress,omitempty"`
+}
+
+type endpointSettings struct {
+	endpointConfig
+	NetworkID   string `json:"NetworkID"`
+	EndpointID  string `json:"EndpointID"`
+	Gateway     string `json:"Gateway"`
+	IPAddress   string `json:"IPAddress"`
+	IPPrefixLen int    `json:"IPPrefixLen"`
+}
+
+type pathStat struct {
+	Name       string      `json:"name"`
+	Size       int64       `json:"size"`
+	Mode       os.FileMode `json:"mode"`
+	Mtime      time.Time   `json:"mtime"`
+	LinkTarget string      `json:"linkTarget"`
+}
@@ -0,0 +1,156 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 19:15
+// Original filename: src/rest/fakedaemon/volumes.go
+
+package fakedaemon
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AddVolume seeds a volume, and returns it as stored
+func (d *Daemon) AddVolume(v Volume) Volume {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return *d.addVolume(&v)
+}
+
+// Volumes returns a snapshot of every volume, sorted by name
+func (d *Daemon) Volumes() []Volume {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	out := make([]Volume, 0, len(d.volumes))
+	for _, name := range slices.Sorted(maps.Keys(d.volumes)) {
+		out = append(out, *d.volumes[name])
+	}
+	return out
+}
+
+func (d *Daemon) addVolume(v *Volume) *Volume {
+	if v.Name == "" {
+		v.Name = newID()
+	}
+	if v.Driver == "" {
+		v.Driver = "local"
+	}
+	if v.Mountpoint == "" {
+		v.Mountpoint = "/var/lib/docker/volumes/" + v.Name + "/_data"
+	}
+	v.Created = orNow(v.Created)
+	d.volumes[v.Name] = v
+	return v
+}
+
+// volumeUsers lists the IDs of the containers (running or not) mounting the volume
+func (d *Daemon) volumeUsers(name string) []string {
+	var out []string
+	for _, c := range d.containers {
+		for _, m := range c.Mounts {
+			if m.Type == "volume" && m.Name == name {
+				out = append(out, c.ID)
+				break
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func volumeJSON(v *Volume) map[string]any {
+	return map[string]any{
+		"Name":       v.Name,
+		"Driver":     v.Driver,
+		"Mountpoint": v.Mountpoint,
+		"CreatedAt":  v.Created.UTC().Format(time.RFC3339),
+		"Labels":     orEmpty(v.Labels),
+		"Scope":      "local",
+		"Options":    orEmpty(v.Options),
+	}
+}
+
+// --- handlers ---
+
+func (d *Daemon) listVolumes(w http.ResponseWriter, r *http.Request) {
+	f, err := parseFilters(r, "dangling", "driver", "label", "name")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "%s", err)
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	vols := []map[string]any{}
+	for _, name := range slices.Sorted(maps.Keys(d.volumes)) {
+		v := d.volumes[name]
+		if !f.matchBool("dangling", len(d.volumeUsers(name)) == 0) || !f.name(v.Name) || !f.labels(v.Labels) ||
+			!f.matchAny("driver", func(s string) bool { return s == v.Driver }) {
+			continue
+		}
+		vols = append(vols, volumeJSON(v))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"Volumes": vols, "Warnings": nil})
+}
+
+func (d *Daemon) createVolume(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name       string            `json:"Name"`
+		Driver     string            `json:"Driver"`
+		DriverOpts map[string]string `json:"DriverOpts"`
+		Labels     map[string]string `json:"Labels"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "%s", err)
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// As with Docker, creating an existing volume simply returns it.
+	v := d.volumes[req.Name]
+	if v == nil {
+		v = d.addVolume(&Volume{Name: req.Name, Driver: req.Driver, Labels: req.Labels, Options: req.DriverOpts})
+	}
+	writeJSON(w, http.StatusCreated, volumeJSON(v))
+}
+
+func (d *Daemon) inspectVolume(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	v := d.volumes[r.PathValue("name")]
+	if v == nil {
+		writeError(w, http.StatusNotFound, "get %s: no such volume", r.PathValue("name"))
+		return
+	}
+	writeJSON(w, http.StatusOK, volumeJSON(v))
+}
+
+func (d *Daemon) removeVolume(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	name := r.PathValue("name")
+	if d.volumes[name] == nil {
+		if queryBool(r, "force") {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeError(w, http.StatusNotFound, "get %s: no such volume", name)
+		return
+	}
+	if users := d.volumeUsers(name); len(users) > 0 {
+		writeError(w, http.StatusConflict, "remove %s: volume is in use - [%s]", name, strings.Join(users, ", "))
+		return
+	}
+	delete(d.volumes, name)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -46,8 +46,11 @@ func buildTLSConfig(cfg Config) (*tls.Config, error) {
 		tlsConfig.RootCAs = sysPool
 	}
 
-	// Client certificate
-	if cfg.CertPath != "" && cfg.KeyPath != "" {
+	// Client certificate; half of the pair is a mistake, not a reason to connect without one
+	if (cfg.CertPath == "") != (cfg.KeyPath == "") {
+		return nil, fmt.Errorf("the client certificate and key go together (cert %q, key %q)", cfg.CertPath, cfg.KeyPath)
+	}
+	if cfg.CertPath != "" {
 		cert, err := tls.LoadX509KeyPair(cfg.CertPath, cfg.KeyPath)
 		if err != nil {
 			return nil, fmt.Errorf("unable to load client cert/key (%q, %q): %w", cfg.CertPath, cfg.KeyPath, err)
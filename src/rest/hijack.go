@@ -55,11 +55,20 @@ func (c *Client) Hijack(
 		reqPath = reqPath + "?" + query.Encode()
 	}
 
-	// Dial.
-	conn, err := c.dial(ctx)
+	// Dial (or pick the recorded stream when replaying).
+	var conn net.Conn
+	var err error
+	if c.replayer != nil {
+		conn, err = c.replayer.conn(method, path, query)
+	} else {
+		conn, err = c.dial(ctx)
+	}
 	if err != nil {
 		return nil, err
 	}
+	if c.recorder != nil {
+		conn = c.recorder.wrapConn(conn, method, path, query, headers, body)
+	}
 
 	// Ensure we close on errors.
 	br := bufio.NewReader(conn)
@@ -122,6 +131,11 @@ func (c *Client) Hijack(
 		return nil, err
 	}
 
+	// Bound the wait for the response headers; the raw stream that follows has no deadline.
+	if headerTimeout, _ := c.timeoutsFor(ctx); headerTimeout > 0 {
+		_ = conn.SetReadDeadline(time.Now().Add(headerTimeout))
+	}
+
 	// Read status line.
 	statusLine, err := br.ReadString('\n')
 	if err != nil {
@@ -148,6 +162,7 @@ func (c *Client) Hijack(
 		return nil, err
 	}
 	respHdr := http.Header(mh)
+	_ = conn.SetReadDeadline(time.Time{})
 
 	// Success criteria:
 	// - for hijacked/upgrade endpoints: Docker replies 101 Switching Protocols
@@ -169,15 +184,21 @@ func (c *Client) Hijack(
 	b, _ := io.ReadAll(io.LimitReader(br, 64*1024))
 	_ = conn.Close()
 
-	msg := strings.TrimSpace(string(b))
-	if msg != "" {
-		return nil, fmt.Errorf("%s %s returned HTTP %d: %s", method, finalPath, code, msg)
+	return nil, &APIError{
+		Method:     method,
+		Path:       path,
+		StatusCode: code,
+		Status:     fmt.Sprintf("%d %s", code, http.StatusText(code)),
+		Message:    daemonMessage(b),
 	}
-	return nil, fmt.Errorf("%s %s returned HTTP %d", method, finalPath, code)
 }
 
 func (c *Client) dial(ctx context.Context) (net.Conn, error) {
-	d := &net.Dialer{Timeout: 30 * time.Second}
+	d := &net.Dialer{Timeout: c.connectTimeout}
+
+	if c.sshDialer != nil {
+		return c.sshDialer.DialContext(ctx, "unix", c.sshDialer.socketPath)
+	}
 
 	if c.isUnix {
 		if c.unixPath == "" {
@@ -192,7 +213,11 @@ func (c *Client) dial(ctx context.Context) (net.Conn, error) {
 	}
 
 	if strings.EqualFold(c.baseURL.Scheme, "https") {
-		t, ok := c.httpClient.Transport.(*http.Transport)
+		rt := c.httpClient.Transport
+		if r, ok := rt.(*recordingTransport); ok {
+			rt = r.next
+		}
+		t, ok := rt.(*http.Transport)
 		if !ok {
 			return nil, errors.New("unexpected transport type")
 		}
@@ -0,0 +1,392 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 17:50
+// Original filename: src/rest/record.go
+
+package rest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Record/replay fixtures
+//
+// With Config.RecordFile set, every exchange with the daemon (Do and Hijack) is appended to that file,
+// one JSON object per line (NDJSON). With Config.ReplayFile set, no daemon is contacted at all: the
+// client answers from such a file instead. Exchanges are looked up by method, path (without the
+// /v<version> prefix) and query; when the same call was recorded several times, the answers are served
+// in order and the last one is repeated once they run out.
+//
+// An exchange is written when its response body (or hijacked stream) is closed, and the credentials
+// carried in the headers listed in redactedHeaders never reach the file. Only the first maxRecordedBody
+// bytes of a body are kept, so that recording save, load, cp or build does not hold whole tarballs in
+// memory: a truncated body has its full size in RequestBodySize/ResponseBodySize, and replays as cut.
+
+// Exchange is one line of a fixture file
+type Exchange struct {
+	Kind           string      `json:"kind"` // "http" for Do(), "hijack" for Hijack()
+	Method         string      `json:"method"`
+	Path           string      `json:"path"` // API path, without the /v<version> prefix
+	Query          string      `json:"query,omitempty"`
+	RequestHeader  http.Header `json:"requestHeader,omitempty"`
+	RequestBody    Payload     `json:"requestBody,omitempty"`
+	StatusCode     int         `json:"statusCode,omitempty"` // http only
+	ResponseHeader http.Header `json:"responseHeader,omitempty"`
+	ResponseBody   Payload     `json:"responseBody,omitempty"` // for hijack: the raw stream, status line and headers included
+
+	// Set only when the body was truncated: its actual size, in bytes
+	RequestBodySize  int64 `json:"requestBodySize,omitempty"`
+	ResponseBodySize int64 `json:"responseBodySize,omitempty"`
+}
+
+// maxRecordedBody is the most bytes of a request or response body stored in a fixture
+var maxRecordedBody int64 = 1 << 20
+
+// cappedBuffer keeps the first maxRecordedBody bytes written to it, and counts the others
+type cappedBuffer struct {
+	buf  bytes.Buffer
+	size int64
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := maxRecordedBody - int64(b.buf.Len()); room > 0 {
+		b.buf.Write(p[:min(int64(len(p)), room)])
+	}
+	b.size += int64(len(p))
+	return len(p), nil
+}
+
+// payload returns the bytes kept, and the full size when some were dropped
+func (b *cappedBuffer) payload() (Payload, int64) {
+	if b.size > int64(b.buf.Len()) {
+		return b.buf.Bytes(), b.size
+	}
+	return b.buf.Bytes(), 0
+}
+
+// Payload is a body as stored in a fixture: a plain JSON string when it is valid UTF-8 (which keeps
+// the fixtures readable and editable), {"base64": "..."} otherwise (tarballs, multiplexed streams...).
+type Payload []byte
+
+func (p Payload) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(p) {
+		return json.Marshal(string(p))
+	}
+	return json.Marshal(struct {
+		Base64 string `json:"base64"`
+	}{base64.StdEncoding.EncodeToString(p)})
+}
+
+func (p *Payload) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*p = Payload(s)
+		return nil
+	}
+	var enc struct {
+		Base64 string `json:"base64"`
+	}
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	raw, err := base64.StdEncoding.DecodeString(enc.Base64)
+	if err != nil {
+		return err
+	}
+	*p = raw
+	return nil
+}
+
+// Headers whose value is replaced by "<redacted>" in the fixtures
+var redactedHeaders = []string{"Authorization", "X-Registry-Auth", "X-Registry-Config"}
+
+func redactHeader(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	out := h.Clone()
+	for _, k := range redactedHeaders {
+		if _, ok := out[http.CanonicalHeaderKey(k)]; ok {
+			out.Set(k, "<redacted>")
+		}
+	}
+	return out
+}
+
+// recorder appends exchanges to the fixture file; it is shared by every call of a client
+type recorder struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+func newRecorder(path string) (*recorder, error) {
+	f, err := os.OpenFile(NormalizePath(path), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the record file: %w", err)
+	}
+	return &recorder{f: f}, nil
+}
+
+// write stores one exchange; a failing write must never break the call being recorded, so errors are dropped
+func (r *recorder) write(x Exchange) {
+	line, err := json.Marshal(x)
+	if err != nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, _ = r.f.Write(append(line, '\n'))
+}
+
+// recordingTransport records the exchanges made through Do()
+type recordingTransport struct {
+	next http.RoundTripper
+	rec  *recorder
+}
+
+// CloseIdleConnections lets Client.Close() reach the wrapped transport
+func (t *recordingTransport) CloseIdleConnections() {
+	if c, ok := t.next.(interface{ CloseIdleConnections() }); ok {
+		c.CloseIdleConnections()
+	}
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	x := Exchange{
+		Kind:          "http",
+		Method:        req.Method,
+		Path:          stripVersionPrefix(req.URL.Path),
+		Query:         req.URL.RawQuery,
+		RequestHeader: redactHeader(req.Header),
+	}
+
+	// The request body is copied as it is sent, so that large uploads are not buffered before the call.
+	var reqBody cappedBuffer
+	if req.Body != nil && req.Body != http.NoBody {
+		req.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.TeeReader(req.Body, &reqBody), req.Body}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	x.StatusCode = resp.StatusCode
+	x.ResponseHeader = resp.Header.Clone()
+	resp.Body = &recordingBody{ReadCloser: resp.Body, done: func(body *cappedBuffer) {
+		x.RequestBody, x.RequestBodySize = reqBody.payload()
+		x.ResponseBody, x.ResponseBodySize = body.payload()
+		t.rec.write(x)
+	}}
+	return resp, nil
+}
+
+// recordingBody keeps a copy of what the caller reads, and hands it over on Close
+type recordingBody struct {
+	io.ReadCloser
+	buf  cappedBuffer
+	once sync.Once
+	done func(*cappedBuffer)
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.Write(p[:n])
+	return n, err
+}
+
+func (b *recordingBody) Close() error {
+	b.once.Do(func() { b.done(&b.buf) })
+	return b.ReadCloser.Close()
+}
+
+// recordingConn does the same for a hijacked connection: the raw bytes read from the daemon are stored
+type recordingConn struct {
+	net.Conn
+	buf  cappedBuffer
+	mu   sync.Mutex
+	once sync.Once
+	done func(*cappedBuffer)
+}
+
+func (c *recordingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.mu.Lock()
+	c.buf.Write(p[:n])
+	c.mu.Unlock()
+	return n, err
+}
+
+func (c *recordingConn) Close() error {
+	c.once.Do(func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.done(&c.buf)
+	})
+	return c.Conn.Close()
+}
+
+func (r *recorder) wrapConn(conn net.Conn, method, path string, query url.Values, headers http.Header, body []byte) net.Conn {
+	x := Exchange{
+		Kind:   "hijack",
+		Method: method,
+		Path:   path,
+		Query:  query.Encode(),
+	}
+	var reqBody cappedBuffer
+	reqBody.Write(body)
+	x.RequestBody, x.RequestBodySize = reqBody.payload()
+	return &recordingConn{Conn: conn, done: func(raw *cappedBuffer) {
+		x.RequestHeader = redactHeader(headers)
+		x.ResponseBody, x.ResponseBodySize = raw.payload()
+		r.write(x)
+	}}
+}
+
+// replayer serves the exchanges of a fixture file
+type replayer struct {
+	mu        sync.Mutex
+	exchanges map[string][]Exchange
+}
+
+func replayKey(method, path, query string) string {
+	return method + " " + path + "?" + query
+}
+
+func newReplayer(path string) (*replayer, error) {
+	f, err := os.Open(NormalizePath(path))
+	if err != nil {
+		return nil, fmt.Errorf("unable to open the replay file: %w", err)
+	}
+	defer f.Close()
+
+	rp := &replayer{exchanges: make(map[string][]Exchange)}
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<30)
+	for line := 1; sc.Scan(); line++ {
+		b := bytes.TrimSpace(sc.Bytes())
+		if len(b) == 0 {
+			continue
+		}
+		var x Exchange
+		if err := json.Unmarshal(b, &x); err != nil {
+			return nil, fmt.Errorf("%s, line %d: %w", path, line, err)
+		}
+		k := replayKey(x.Method, stripVersionPrefix(x.Path), x.Query)
+		rp.exchanges[k] = append(rp.exchanges[k], x)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read the replay file: %w", err)
+	}
+	return rp, nil
+}
+
+// next returns the answer to a call; the last recorded answer is kept for the calls that follow
+func (rp *replayer) next(kind, method, path, query string) (Exchange, error) {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	k := replayKey(method, stripVersionPrefix(path), query)
+	xs := rp.exchanges[k]
+	if len(xs) == 0 {
+		return Exchange{}, fmt.Errorf("replay: no recorded exchange for %s %s", method, strings.TrimSuffix(k[len(method)+1:], "?"))
+	}
+	x := xs[0]
+	if len(xs) > 1 {
+		rp.exchanges[k] = xs[1:]
+	}
+	if x.Kind != kind {
+		return Exchange{}, fmt.Errorf("replay: %s %s was recorded as a %s exchange", method, path, x.Kind)
+	}
+	return x, nil
+}
+
+// RoundTrip makes the replayer usable as the transport of Do()
+func (rp *replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_, _ = io.Copy(io.Discard, req.Body)
+		_ = req.Body.Close()
+	}
+	x, err := rp.next("http", req.Method, req.URL.Path, req.URL.RawQuery)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode:    x.StatusCode,
+		Status:        fmt.Sprintf("%d %s", x.StatusCode, http.StatusText(x.StatusCode)),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        x.ResponseHeader,
+		Body:          io.NopCloser(bytes.NewReader(x.ResponseBody)),
+		ContentLength: int64(len(x.ResponseBody)),
+		Request:       req,
+	}, nil
+}
+
+// conn returns a connection replaying a recorded hijacked stream; what the caller writes is dropped
+func (rp *replayer) conn(method, path string, query url.Values) (net.Conn, error) {
+	x, err := rp.next("hijack", method, path, query.Encode())
+	if err != nil {
+		return nil, err
+	}
+	return &replayConn{r: bytes.NewReader(x.ResponseBody)}, nil
+}
+
+type replayConn struct {
+	r      *bytes.Reader
+	mu     sync.Mutex
+	closed bool
+}
+
+func (c *replayConn) Read(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return 0, net.ErrClosed
+	}
+	return c.r.Read(p)
+}
+
+func (c *replayConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return 0, net.ErrClosed
+	}
+	return len(p), nil
+}
+
+func (c *replayConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	return nil
+}
+
+func (c *replayConn) LocalAddr() net.Addr              { return replayAddr{} }
+func (c *replayConn) RemoteAddr() net.Addr             { return replayAddr{} }
+func (c *replayConn) SetDeadline(time.Time) error      { return nil }
+func (c *replayConn) SetReadDeadline(time.Time) error  { return nil }
+func (c *replayConn) SetWriteDeadline(time.Time) error { return nil }
+
+type replayAddr struct{}
+
+func (replayAddr) Network() string { return "replay" }
+func (replayAddr) String() string  { return "replay" }
@@ -0,0 +1,136 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 07:10
+// Original filename: src/rest/record_test.go
+
+package rest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordTruncatesLargeBodies(t *testing.T) {
+	defer func(n int64) { maxRecordedBody = n }(maxRecordedBody)
+	maxRecordedBody = 16
+
+	large := strings.Repeat("x", 1000)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = io.WriteString(w, large)
+	}))
+	defer srv.Close()
+
+	fixture := filepath.Join(t.TempDir(), "record.ndjson")
+	c, err := NewClient(Config{Host: srv.URL, RecordFile: fixture})
+	if err != nil {
+		t.Fatal(err)
+	}
+	headers := http.Header{"X-Registry-Auth": {"secret"}}
+	resp, err := c.Do(context.Background(), http.MethodPost, "/images/load", nil, strings.NewReader(strings.Repeat("y", 500)), headers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(got) != large {
+		t.Fatalf("the caller got %d bytes, want %d", len(got), len(large))
+	}
+
+	xs := readFixture(t, fixture)
+	if len(xs) != 1 {
+		t.Fatalf("%d exchanges recorded, want 1", len(xs))
+	}
+	x := xs[0]
+	if len(x.RequestBody) != 16 || x.RequestBodySize != 500 {
+		t.Errorf("request body: kept %d bytes of %d, want 16 of 500", len(x.RequestBody), x.RequestBodySize)
+	}
+	if len(x.ResponseBody) != 16 || x.ResponseBodySize != 1000 {
+		t.Errorf("response body: kept %d bytes of %d, want 16 of 1000", len(x.ResponseBody), x.ResponseBodySize)
+	}
+	if v := x.RequestHeader.Get("X-Registry-Auth"); v != "<redacted>" {
+		t.Errorf("X-Registry-Auth recorded as %q", v)
+	}
+}
+
+func TestRecordKeepsSmallBodies(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"ok":true}`)
+	}))
+	defer srv.Close()
+
+	fixture := filepath.Join(t.TempDir(), "record.ndjson")
+	c, err := NewClient(Config{Host: srv.URL, RecordFile: fixture})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.Do(context.Background(), http.MethodGet, "/_ping", nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	x := readFixture(t, fixture)[0]
+	if string(x.ResponseBody) != `{"ok":true}` || x.ResponseBodySize != 0 {
+		t.Errorf("response body recorded as %q (size %d)", x.ResponseBody, x.ResponseBodySize)
+	}
+}
+
+func TestReplayFixture(t *testing.T) {
+	c, err := NewClient(Config{ReplayFile: filepath.Join("testdata", "lsc.ndjson")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.Do(context.Background(), http.MethodGet, "/containers/json", url.Values{"all": {"true"}, "size": {"false"}}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d, want 200", resp.StatusCode)
+	}
+	var cs []struct{ Names []string }
+	if err := json.NewDecoder(resp.Body).Decode(&cs); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, c := range cs {
+		names = append(names, c.Names[0])
+	}
+	if want := "/web /demo-db-1 /demo-app-1 /db /job"; strings.Join(names, " ") != want {
+		t.Errorf("replayed containers %v, want %s", names, want)
+	}
+
+	if _, err := c.Do(context.Background(), http.MethodGet, "/images/json", nil, nil, nil); err == nil {
+		t.Error("an exchange missing from the fixture was answered")
+	}
+}
+
+func readFixture(t *testing.T, path string) []Exchange {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var xs []Exchange
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	for sc.Scan() {
+		var x Exchange
+		if err := json.Unmarshal(sc.Bytes(), &x); err != nil {
+			t.Fatal(err)
+		}
+		xs = append(xs, x)
+	}
+	return xs
+}
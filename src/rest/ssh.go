@@ -0,0 +1,326 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 09:40
+// Original filename: src/rest/ssh.go
+
+package rest
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const defaultRemoteSocket = "/var/run/docker.sock"
+
+// newSSHDialer prepares (but does not open) the SSH session used to reach a remote daemon.
+// The URL format is ssh://[user@]host[:port][/path/to/docker.sock]; host may be an alias from ~/.ssh/config.
+func newSSHDialer(u *url.URL, connectTimeout time.Duration) (*sshDialer, error) {
+	alias := u.Hostname()
+	if alias == "" {
+		return nil, fmt.Errorf("ssh host %q is missing hostname", u.String())
+	}
+	hostCfg := loadSSHHostConfig(alias)
+
+	hostname := hostCfg.HostName
+	if hostname == "" {
+		hostname = alias
+	}
+	port := u.Port()
+	if port == "" {
+		port = hostCfg.Port
+	}
+	if port == "" {
+		port = "22"
+	}
+	user := u.User.Username()
+	if user == "" {
+		user = hostCfg.User
+	}
+	if user == "" {
+		user = os.Getenv("USER")
+	}
+	socketPath := u.Path
+	if socketPath == "" || socketPath == "/" {
+		socketPath = defaultRemoteSocket
+	}
+
+	addr := net.JoinHostPort(hostname, port)
+
+	hostKeyCallback, err := sshHostKeyCallback(hostCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &sshDialer{
+		addr:       addr,
+		socketPath: socketPath,
+	}
+	d.config = &ssh.ClientConfig{
+		User:              user,
+		Auth:              []ssh.AuthMethod{ssh.PublicKeysCallback(d.signers(hostCfg))},
+		HostKeyCallback:   hostKeyCallback,
+		HostKeyAlgorithms: knownHostKeyAlgorithms(hostKeyCallback, addr),
+		Timeout:           connectTimeout,
+	}
+	return d, nil
+}
+
+// DialContext opens a new stream to the remote daemon socket. The network and address arguments
+// are ignored; they exist so the method can be plugged into http.Transport as is.
+func (d *sshDialer) DialContext(ctx context.Context, _, _ string) (net.Conn, error) {
+	client, err := d.sshClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := client.DialContext(ctx, "unix", d.socketPath)
+	if err == nil {
+		return &sshConn{Conn: conn}, nil
+	}
+
+	// The session may have been dropped by the remote end (idle timeout, sshd restart...).
+	// Reconnect once before giving up.
+	d.reset(client)
+	if client, err = d.sshClient(ctx); err != nil {
+		return nil, err
+	}
+	conn, err = client.DialContext(ctx, "unix", d.socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to reach %s on %s: %w", d.socketPath, d.addr, err)
+	}
+	return &sshConn{Conn: conn}, nil
+}
+
+// Read returns os.ErrDeadlineExceeded once the stream was closed by its read deadline
+func (c *sshConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if err != nil {
+		c.mu.Lock()
+		if c.expired {
+			err = os.ErrDeadlineExceeded
+		}
+		c.mu.Unlock()
+	}
+	return n, err
+}
+
+// SetReadDeadline closes the stream when t passes; a zero t cancels the deadline
+func (c *sshConn) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	if t.IsZero() || c.expired {
+		return nil
+	}
+	c.timer = time.AfterFunc(time.Until(t), func() {
+		c.mu.Lock()
+		c.expired = true
+		c.mu.Unlock()
+		_ = c.Conn.Close()
+	})
+	return nil
+}
+
+// SetDeadline only bounds the reads, which is what the callers wait on
+func (c *sshConn) SetDeadline(t time.Time) error {
+	return c.SetReadDeadline(t)
+}
+
+func (c *sshConn) Close() error {
+	_ = c.SetReadDeadline(time.Time{})
+	return c.Conn.Close()
+}
+
+// sshClient returns the shared SSH session, establishing it on first use.
+func (d *sshDialer) sshClient(ctx context.Context) (*ssh.Client, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.client != nil {
+		return d.client, nil
+	}
+
+	nd := &net.Dialer{Timeout: d.config.Timeout}
+	conn, err := nd.DialContext(ctx, "tcp", d.addr)
+	if err != nil {
+		return nil, fmt.Errorf("ssh: unable to connect to %s: %w", d.addr, err)
+	}
+
+	// Bound the handshake itself; the deadline is lifted once the session is up.
+	_ = conn.SetDeadline(time.Now().Add(d.config.Timeout))
+	c, chans, reqs, err := ssh.NewClientConn(conn, d.addr, d.config)
+	if err != nil {
+		_ = conn.Close()
+		d.closeAgentLocked()
+		return nil, fmt.Errorf("ssh: handshake with %s failed: %w", d.addr, err)
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	d.client = ssh.NewClient(c, chans, reqs)
+	return d.client, nil
+}
+
+func (d *sshDialer) reset(stale *ssh.Client) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.client == stale {
+		d.closeLocked()
+	}
+}
+
+// close ends the SSH session and releases the agent connection; the next dial opens new ones.
+func (d *sshDialer) close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.closeLocked()
+}
+
+func (d *sshDialer) closeLocked() {
+	if d.client != nil {
+		_ = d.client.Close()
+		d.client = nil
+	}
+	d.closeAgentLocked()
+}
+
+func (d *sshDialer) closeAgentLocked() {
+	if d.agentConn != nil {
+		_ = d.agentConn.Close()
+		d.agentConn = nil
+	}
+}
+
+// signers collects the keys offered to the server: whatever the agent holds first, then the
+// unencrypted identity files (from ~/.ssh/config, or the usual defaults).
+// Passphrase-protected keys are expected to be loaded in the agent.
+func (d *sshDialer) signers(hostCfg sshHostConfig) func() ([]ssh.Signer, error) {
+	return func() ([]ssh.Signer, error) {
+		var signers []ssh.Signer
+
+		sock := hostCfg.IdentityAgent
+		if sock == "" || sock == "SSH_AUTH_SOCK" {
+			sock = os.Getenv("SSH_AUTH_SOCK")
+		}
+		if sock != "" && sock != "none" {
+			// Called during the handshake, with d.mu held by sshClient(). The agent connection must stay
+			// open for the whole authentication exchange, as signing happens lazily; it is kept on the
+			// dialer and closed with the session (see closeLocked()).
+			d.closeAgentLocked()
+			if conn, err := net.Dial("unix", sock); err == nil {
+				d.agentConn = conn
+				if s, err := agent.NewClient(conn).Signers(); err == nil {
+					signers = append(signers, s...)
+				}
+			}
+		}
+
+		files := hostCfg.IdentityFiles
+		if len(files) == 0 {
+			sshDir := filepath.Join(os.Getenv("HOME"), ".ssh")
+			files = []string{
+				filepath.Join(sshDir, "id_ed25519"),
+				filepath.Join(sshDir, "id_ecdsa"),
+				filepath.Join(sshDir, "id_rsa"),
+			}
+		}
+		for _, f := range files {
+			pem, err := os.ReadFile(f)
+			if err != nil {
+				continue
+			}
+			s, err := ssh.ParsePrivateKey(pem)
+			if err != nil {
+				// Most likely *ssh.PassphraseMissingError; the agent is the way to go for those.
+				continue
+			}
+			signers = append(signers, s)
+		}
+
+		if len(signers) == 0 {
+			return nil, errors.New("no usable SSH key found (is ssh-agent running?)")
+		}
+		return signers, nil
+	}
+}
+
+// sshHostKeyCallback verifies the remote host key against known_hosts, unless the
+// ssh config explicitly disables it with StrictHostKeyChecking no.
+func sshHostKeyCallback(hostCfg sshHostConfig) (ssh.HostKeyCallback, error) {
+	if hostCfg.StrictHostKeyChecking == "no" || hostCfg.StrictHostKeyChecking == "off" {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+
+	files := hostCfg.KnownHostsFiles
+	if len(files) == 0 {
+		files = []string{filepath.Join(os.Getenv("HOME"), ".ssh", "known_hosts")}
+	}
+	files = append(files, "/etc/ssh/ssh_known_hosts")
+
+	var existing []string
+	for _, f := range files {
+		if _, err := os.Stat(f); err == nil {
+			existing = append(existing, f)
+		}
+	}
+	if len(existing) == 0 {
+		return nil, errors.New("no known_hosts file found; connect once with ssh to record the host key")
+	}
+
+	cb, err := knownhosts.New(existing...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load known_hosts: %w", err)
+	}
+	return cb, nil
+}
+
+// knownHostKeyAlgorithms returns the host key algorithms already recorded for addr, so that the
+// server is asked for a key type we can actually verify. Nil means "library defaults".
+func knownHostKeyAlgorithms(cb ssh.HostKeyCallback, addr string) []string {
+	// knownhosts has no lookup API: probing it with a throwaway key yields a KeyError listing the known keys.
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil
+	}
+	probe, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		return nil
+	}
+
+	var keyErr *knownhosts.KeyError
+	if err := cb(addr, &net.TCPAddr{IP: net.IPv4zero}, probe); !errors.As(err, &keyErr) || len(keyErr.Want) == 0 {
+		return nil
+	}
+
+	var algos []string
+	seen := map[string]bool{}
+	for _, k := range keyErr.Want {
+		types := []string{k.Key.Type()}
+		if k.Key.Type() == ssh.KeyAlgoRSA {
+			types = []string{ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSA}
+		}
+		for _, t := range types {
+			if !seen[t] {
+				seen[t] = true
+				algos = append(algos, t)
+			}
+		}
+	}
+	return algos
+}
@@ -0,0 +1,47 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 08:10
+// Original filename: src/rest/ssh_test.go
+
+package rest
+
+import (
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSSHConnReadDeadline(t *testing.T) {
+	local, remote := net.Pipe()
+	defer remote.Close()
+	conn := &sshConn{Conn: local}
+	defer conn.Close()
+
+	// A deadline that is cancelled in time leaves the stream usable
+	_ = conn.SetReadDeadline(time.Now().Add(20 * time.Millisecond))
+	_ = conn.SetReadDeadline(time.Time{})
+	time.Sleep(40 * time.Millisecond)
+	go func() { _, _ = remote.Write([]byte("ok")) }()
+	buf := make([]byte, 2)
+	if _, err := conn.Read(buf); err != nil || string(buf) != "ok" {
+		t.Fatalf("read %q, %v", buf, err)
+	}
+
+	// The remote end stays silent: the read gives up at the deadline
+	_ = conn.SetReadDeadline(time.Now().Add(20 * time.Millisecond))
+	done := make(chan error, 1)
+	go func() {
+		_, err := conn.Read(buf)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Errorf("got %v, want %v", err, os.ErrDeadlineExceeded)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("the read ignored its deadline")
+	}
+}
@@ -0,0 +1,192 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 09:12
+// Original filename: src/rest/sshconfig.go
+
+package rest
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// sshHostConfig is the subset of ssh_config(5) that matters to us when reaching a remote daemon.
+// Unsupported keywords (ProxyJump, ProxyCommand, Match blocks...) are silently ignored.
+type sshHostConfig struct {
+	HostName              string
+	User                  string
+	Port                  string
+	IdentityFiles         []string
+	IdentityAgent         string
+	KnownHostsFiles       []string
+	StrictHostKeyChecking string
+}
+
+// loadSSHHostConfig resolves the settings that apply to `alias`, reading ~/.ssh/config first and
+// /etc/ssh/ssh_config next. As with OpenSSH, the first value obtained for a keyword wins.
+func loadSSHHostConfig(alias string) sshHostConfig {
+	cfg := sshHostConfig{}
+	home := os.Getenv("HOME")
+
+	for _, f := range []string{filepath.Join(home, ".ssh", "config"), "/etc/ssh/ssh_config"} {
+		parseSSHConfigFile(f, alias, &cfg, 0)
+	}
+
+	for i := range cfg.IdentityFiles {
+		cfg.IdentityFiles[i] = expandSSHPath(cfg.IdentityFiles[i], alias)
+	}
+	for i := range cfg.KnownHostsFiles {
+		cfg.KnownHostsFiles[i] = expandSSHPath(cfg.KnownHostsFiles[i], alias)
+	}
+	cfg.IdentityAgent = expandSSHPath(cfg.IdentityAgent, alias)
+
+	return cfg
+}
+
+func parseSSHConfigFile(file, alias string, cfg *sshHostConfig, depth int) {
+	// Include loops are a thing; OpenSSH caps the depth at 16 too.
+	if depth > 16 {
+		return
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	// Keywords before the first Host/Match line apply to every host.
+	active := true
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		key, args := splitSSHConfigLine(sc.Text())
+		if key == "" {
+			continue
+		}
+
+		switch key {
+		case "host":
+			active = sshHostMatches(alias, args)
+			continue
+		case "match":
+			// Only "Match all" is understood; other criteria need a full evaluator.
+			active = len(args) == 1 && strings.EqualFold(args[0], "all")
+			continue
+		case "include":
+			if !active {
+				continue
+			}
+			for _, pattern := range args {
+				pattern = expandSSHPath(pattern, alias)
+				if !filepath.IsAbs(pattern) {
+					pattern = filepath.Join(os.Getenv("HOME"), ".ssh", pattern)
+				}
+				matches, _ := filepath.Glob(pattern)
+				for _, m := range matches {
+					parseSSHConfigFile(m, alias, cfg, depth+1)
+				}
+			}
+			continue
+		}
+
+		if !active || len(args) == 0 {
+			continue
+		}
+
+		switch key {
+		case "hostname":
+			setOnce(&cfg.HostName, args[0])
+		case "user":
+			setOnce(&cfg.User, args[0])
+		case "port":
+			setOnce(&cfg.Port, args[0])
+		case "identityagent":
+			setOnce(&cfg.IdentityAgent, args[0])
+		case "stricthostkeychecking":
+			setOnce(&cfg.StrictHostKeyChecking, strings.ToLower(args[0]))
+		case "identityfile":
+			// IdentityFile is cumulative.
+			cfg.IdentityFiles = append(cfg.IdentityFiles, args[0])
+		case "userknownhostsfile":
+			if cfg.KnownHostsFiles == nil {
+				cfg.KnownHostsFiles = append([]string{}, args...)
+			}
+		}
+	}
+}
+
+// splitSSHConfigLine returns the lowercased keyword and its arguments.
+// Both "Keyword value" and "Keyword=value" forms are accepted; double quotes group words.
+func splitSSHConfigLine(line string) (string, []string) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", nil
+	}
+
+	idx := strings.IndexAny(line, " \t=")
+	if idx < 0 {
+		return strings.ToLower(line), nil
+	}
+	key := strings.ToLower(line[:idx])
+	rest := strings.TrimLeft(line[idx:], " \t")
+	rest = strings.TrimLeft(strings.TrimPrefix(rest, "="), " \t")
+
+	var args []string
+	var cur strings.Builder
+	inQuotes := false
+	for _, r := range rest {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case (r == ' ' || r == '\t') && !inQuotes:
+			if cur.Len() > 0 {
+				args = append(args, cur.String())
+				cur.Reset()
+			}
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if cur.Len() > 0 {
+		args = append(args, cur.String())
+	}
+	return key, args
+}
+
+// sshHostMatches applies the Host pattern list semantics: at least one positive match, and no negated match.
+func sshHostMatches(alias string, patterns []string) bool {
+	matched := false
+	for _, p := range patterns {
+		negate := strings.HasPrefix(p, "!")
+		p = strings.TrimPrefix(p, "!")
+		ok, err := path.Match(strings.ToLower(p), strings.ToLower(alias))
+		if err != nil || !ok {
+			continue
+		}
+		if negate {
+			return false
+		}
+		matched = true
+	}
+	return matched
+}
+
+// expandSSHPath expands the leading ~ and the few %-tokens we can honour without a live connection.
+func expandSSHPath(p, alias string) string {
+	if p == "" {
+		return p
+	}
+	home := os.Getenv("HOME")
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		p = filepath.Join(home, strings.TrimPrefix(p, "~"))
+	}
+	return strings.NewReplacer("%d", home, "%h", alias, "%u", os.Getenv("USER"), "%%", "%").Replace(p)
+}
+
+func setOnce(dst *string, val string) {
+	if *dst == "" {
+		*dst = val
+	}
+}
@@ -0,0 +1,151 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 13:10
+// Original filename: src/rest/timeouts.go
+
+package rest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Defaults used when the matching Config field is zero.
+const (
+	DefaultConnectTimeout        = 30 * time.Second
+	DefaultResponseHeaderTimeout = 60 * time.Second
+	DefaultIdleTimeout           = 60 * time.Second
+	DefaultStreamIdleTimeout     = 5 * time.Minute
+)
+
+type callMode int
+
+const (
+	modeRegular callMode = iota
+	modeStreaming
+	modeFollow
+	modeExplicit
+)
+
+type callTimeoutsKey struct{}
+
+type callTimeouts struct {
+	mode   callMode
+	header time.Duration
+	idle   time.Duration
+}
+
+// StreamingContext marks ctx for long transfers (pull, push, load, save, cp...): the response-header
+// timeout still applies, but the body only fails after Config.StreamIdleTimeout without a single byte.
+func StreamingContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, callTimeoutsKey{}, callTimeouts{mode: modeStreaming})
+}
+
+// FollowContext marks ctx for open-ended calls (logs -f, wait, build...) which may legitimately stay
+// silent for as long as they like: neither response-header nor idle timeouts apply, only ctx itself.
+func FollowContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, callTimeoutsKey{}, callTimeouts{mode: modeFollow})
+}
+
+// WithTimeouts overrides both timeouts for the calls made with ctx.
+// Zero disables a timeout, a negative value keeps the client's default.
+func WithTimeouts(ctx context.Context, responseHeader, idle time.Duration) context.Context {
+	return context.WithValue(ctx, callTimeoutsKey{}, callTimeouts{mode: modeExplicit, header: responseHeader, idle: idle})
+}
+
+// timeoutsFor resolves the response-header and idle timeouts that apply to a call made with ctx
+func (c *Client) timeoutsFor(ctx context.Context) (header, idle time.Duration) {
+	ct, _ := ctx.Value(callTimeoutsKey{}).(callTimeouts)
+
+	switch ct.mode {
+	case modeStreaming:
+		return c.headerTimeout, c.streamIdleTimeout
+	case modeFollow:
+		return 0, 0
+	case modeExplicit:
+		header, idle = ct.header, ct.idle
+		if header < 0 {
+			header = c.headerTimeout
+		}
+		if idle < 0 {
+			idle = c.idleTimeout
+		}
+		return header, idle
+	default:
+		return c.headerTimeout, c.idleTimeout
+	}
+}
+
+// orDefault returns d, or def when d is zero
+func orDefault(d, def time.Duration) time.Duration {
+	if d == 0 {
+		return def
+	}
+	return d
+}
+
+// sentNotifier calls fn once the request body has been fully handed to the transport,
+// so that the response-header timeout does not count the upload itself.
+type sentNotifier struct {
+	io.ReadCloser
+	once sync.Once
+	fn   func()
+}
+
+func (s *sentNotifier) Read(p []byte) (int, error) {
+	n, err := s.ReadCloser.Read(p)
+	if err != nil {
+		s.once.Do(s.fn)
+	}
+	return n, err
+}
+
+func (s *sentNotifier) Close() error {
+	s.once.Do(s.fn)
+	return s.ReadCloser.Close()
+}
+
+// idleTimeoutBody cancels the request when no byte has been read for `timeout`.
+// As long as data keeps flowing, the stream can last forever.
+type idleTimeoutBody struct {
+	io.ReadCloser
+	timeout time.Duration
+	timer   *time.Timer
+	cancel  context.CancelFunc
+	expired atomic.Bool
+}
+
+func newIdleTimeoutBody(body io.ReadCloser, timeout time.Duration, cancel context.CancelFunc) io.ReadCloser {
+	b := &idleTimeoutBody{ReadCloser: body, timeout: timeout, cancel: cancel}
+	if timeout > 0 {
+		b.timer = time.AfterFunc(timeout, func() {
+			b.expired.Store(true)
+			cancel()
+		})
+	}
+	return b
+}
+
+func (b *idleTimeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if b.timer != nil && n > 0 {
+		b.timer.Reset(b.timeout)
+	}
+	if err != nil && err != io.EOF && b.expired.Load() {
+		err = fmt.Errorf("no data received from the daemon for %s: %w", b.timeout, err)
+	}
+	return n, err
+}
+
+func (b *idleTimeoutBody) Close() error {
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,97 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 08:20
+// Original filename: src/rest/timeouts_test.go
+
+package rest_test
+
+import (
+	"context"
+	"dtools2/rest"
+	"dtools2/rest/fakedaemon"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// slowDaemon serves /slow: the headers come after `header`, then `chunks` bytes, one every `gap`
+func slowDaemon(t *testing.T, header, gap time.Duration, chunks int) *rest.Client {
+	t.Helper()
+	d, err := fakedaemon.Start(fakedaemon.Options{Wrap: func(api http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !strings.HasSuffix(r.URL.Path, "/slow") {
+				api.ServeHTTP(w, r)
+				return
+			}
+			time.Sleep(header)
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			for range chunks {
+				time.Sleep(gap)
+				_, _ = w.Write([]byte("x"))
+				w.(http.Flusher).Flush()
+			}
+		})
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = d.Close() })
+
+	client, err := rest.NewClient(rest.Config{Host: d.Host(), APIVersion: "1.43",
+		ResponseHeaderTimeout: 100 * time.Millisecond, IdleTimeout: 100 * time.Millisecond, StreamIdleTimeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+func getSlow(ctx context.Context, client *rest.Client) (string, error) {
+	resp, err := client.Do(ctx, http.MethodGet, "/slow", url.Values{}, nil, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	return string(b), err
+}
+
+func TestResponseHeaderTimeout(t *testing.T) {
+	client := slowDaemon(t, 300*time.Millisecond, 0, 1)
+
+	// A regular call, like a streaming one, gives up when the headers are late
+	for _, ctx := range []context.Context{context.Background(), rest.StreamingContext(context.Background())} {
+		if _, err := getSlow(ctx, client); err == nil || !strings.Contains(err.Error(), "no response from the daemon") {
+			t.Errorf("got %v, want the response-header timeout", err)
+		}
+	}
+	// An open-ended call waits as long as it takes
+	if body, err := getSlow(rest.FollowContext(context.Background()), client); err != nil || body != "x" {
+		t.Errorf("follow: got %q, %v", body, err)
+	}
+	// So does a call whose header timeout is lifted
+	if body, err := getSlow(rest.WithTimeouts(context.Background(), 0, -1), client); err != nil || body != "x" {
+		t.Errorf("explicit: got %q, %v", body, err)
+	}
+}
+
+func TestIdleTimeout(t *testing.T) {
+	// Data keeps flowing for 5 times the idle timeout: the stream survives
+	client := slowDaemon(t, 0, 50*time.Millisecond, 10)
+	if body, err := getSlow(context.Background(), client); err != nil || body != strings.Repeat("x", 10) {
+		t.Errorf("flowing stream: got %q, %v", body, err)
+	}
+
+	// A pause longer than the idle timeout cuts a regular call, not a streaming one
+	client = slowDaemon(t, 0, 300*time.Millisecond, 2)
+	if _, err := getSlow(context.Background(), client); err == nil || !strings.Contains(err.Error(), "no data received from the daemon") {
+		t.Errorf("regular: got %v, want the idle timeout", err)
+	}
+	if body, err := getSlow(rest.StreamingContext(context.Background()), client); err != nil || body != "xx" {
+		t.Errorf("streaming: got %q, %v", body, err)
+	}
+}
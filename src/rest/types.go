@@ -7,19 +7,18 @@ package rest
 
 import (
 	"bufio"
-	"context"
 	"net"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
-)
 
-var QuietOutput = false
-var Context context.Context
-var ConnectURI string
+	"golang.org/x/crypto/ssh"
+)
 
 // Client wraps an http.Client and knows how to talk to the Docker daemon
-// via TCP (http/https) or a Unix socket, with an optional API version prefix.
+// via TCP (http/https), a Unix socket or a remote Unix socket reached over SSH,
+// with an optional API version prefix.
 type Client struct {
 	httpClient *http.Client
 	baseURL    *url.URL
@@ -27,27 +26,98 @@ type Client struct {
 
 	isUnix   bool
 	unixPath string
+
+	sshDialer *sshDialer // non-nil for ssh:// hosts
+
+	recorder *recorder // non-nil with Config.RecordFile
+	replayer *replayer // non-nil with Config.ReplayFile; no daemon is contacted then
+
+	engine Engine
+
+	connectTimeout    time.Duration
+	headerTimeout     time.Duration
+	idleTimeout       time.Duration
+	streamIdleTimeout time.Duration
+}
+
+// sshDialer multiplexes every connection to a remote daemon socket over a single SSH session.
+type sshDialer struct {
+	addr       string // host:port of the SSH server
+	socketPath string // daemon socket on the remote host
+	config     *ssh.ClientConfig
+
+	mu        sync.Mutex
+	client    *ssh.Client
+	agentConn net.Conn // the ssh-agent connection used to authenticate client; signing happens lazily over it
+}
+
+// sshConn is a stream opened by sshDialer. The SSH channels ignore deadlines, so a read deadline is kept with
+// a timer that closes the stream when it expires.
+type sshConn struct {
+	net.Conn
+
+	mu      sync.Mutex
+	timer   *time.Timer
+	expired bool
+}
+
+// VersionInfo matches the JSON returned by /version.
+type VersionInfo struct {
+	Platform struct {
+		Name string `json:"Name"`
+	} `json:"Platform"`
+	Components    []VersionComponent `json:"Components,omitempty"`
+	ApiVersion    string             `json:"ApiVersion"`
+	MinAPIVersion string             `json:"MinAPIVersion"`
+	Version       string             `json:"Version"`
+	Os            string             `json:"Os,omitempty"`
+	Arch          string             `json:"Arch,omitempty"`
+}
+
+// VersionComponent is one entry of the /version Components list (Engine, containerd, Podman Engine, conmon...)
+type VersionComponent struct {
+	Name    string `json:"Name"`
+	Version string `json:"Version"`
 }
 
-// versionInfo matches the JSON returned by /version.
-type versionInfo struct {
-	ApiVersion    string `json:"ApiVersion"`
-	MinAPIVersion string `json:"MinAPIVersion"`
-	Version       string `json:"Version"`
+// EngineKind identifies which daemon implementation answers the API
+type EngineKind string
+
+const (
+	EngineUnknown EngineKind = ""
+	EngineDocker  EngineKind = "docker"
+	EnginePodman  EngineKind = "podman"
+)
+
+// Engine describes the daemon, as detected from /version
+type Engine struct {
+	Kind       EngineKind
+	Name       string
+	Version    string
+	APIVersion string
 }
 
 // Config holds the connection parameters for the REST client.
+// The JSON tags are used when a Config is persisted as a named context.
 type Config struct {
-	Host       string // e.g. "", unix:///var/run/docker.sock, tcp://host:2376, https://host:2376
-	APIVersion string // e.g. "1.43"; empty means "negotiate"
+	Host       string `json:"Host,omitempty"`       // e.g. "", unix:///var/run/docker.sock, tcp://host:2376, https://host:2376, ssh://user@host
+	APIVersion string `json:"APIVersion,omitempty"` // e.g. "1.43"; empty means "negotiate"
+
+	UseTLS             bool   `json:"UseTLS,omitempty"`
+	CACertPath         string `json:"CACertPath,omitempty"`
+	CertPath           string `json:"CertPath,omitempty"`
+	KeyPath            string `json:"KeyPath,omitempty"`
+	InsecureSkipVerify bool   `json:"InsecureSkipVerify,omitempty"`
 
-	UseTLS             bool
-	CACertPath         string
-	CertPath           string
-	KeyPath            string
-	InsecureSkipVerify bool
+	// Timeouts; zero means the matching Default* value (see timeouts.go).
+	ConnectTimeout        time.Duration `json:"ConnectTimeout,omitempty"`        // dialing the daemon (unix, tcp or ssh)
+	ResponseHeaderTimeout time.Duration `json:"ResponseHeaderTimeout,omitempty"` // waiting for the response headers, once the request is sent
+	IdleTimeout           time.Duration `json:"IdleTimeout,omitempty"`           // max silence while reading a regular response body
+	StreamIdleTimeout     time.Duration `json:"StreamIdleTimeout,omitempty"`     // same, for calls made with StreamingContext
 
-	Timeout time.Duration // optional; if zero, a sane default is used.
+	// Record/replay fixtures (see record.go); never persisted in a context.
+	RecordFile string `json:"-"` // append every exchange with the daemon to this file
+	ReplayFile string `json:"-"` // answer from this file instead of a daemon
 }
 
 // HijackedConn holds the underlying connection and a reader positioned right after the
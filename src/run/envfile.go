@@ -0,0 +1,118 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 23:45
+// Original filename: src/run/envfile.go
+
+package run
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+)
+
+// ParseEnvFile reads a docker --env-file: one VAR=value per line, lines starting with # and blank lines ignored.
+// As with docker, values are taken verbatim (no quote removal nor trimming), and a bare VAR is inherited from
+// our own environment, or dropped when it is not set there.
+func ParseEnvFile(path string) ([]string, *ce.CustomError) {
+	return parseKeyValueFile(path, "env file", os.LookupEnv)
+}
+
+// parseLabelFile reads a --label-file, in the same format as the env files; a bare key gets an empty value
+func parseLabelFile(path string) ([]string, *ce.CustomError) {
+	return parseKeyValueFile(path, "label file", func(string) (string, bool) { return "", true })
+}
+
+func parseKeyValueFile(path, kind string, bare func(string) (string, bool)) ([]string, *ce.CustomError) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &ce.CustomError{Title: "Unable to read the " + kind, Message: err.Error()}
+	}
+	data = bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF")) // UTF-8 BOM
+
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		raw := scanner.Bytes()
+		if !utf8.Valid(raw) {
+			return nil, &ce.CustomError{Title: "Invalid " + kind, Message: fmt.Sprintf("%s: line %d contains invalid UTF-8", path, n)}
+		}
+		line := strings.TrimLeftFunc(string(raw), unicode.IsSpace)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, hasValue := strings.Cut(line, "=")
+		if key == "" {
+			return nil, &ce.CustomError{Title: "Invalid " + kind, Message: fmt.Sprintf("%s: no variable name on line %d", path, n)}
+		}
+		if !hasValue {
+			key = strings.TrimRightFunc(key, unicode.IsSpace)
+		}
+		if strings.ContainsFunc(key, unicode.IsSpace) {
+			return nil, &ce.CustomError{Title: "Invalid " + kind, Message: fmt.Sprintf("%s: variable %q on line %d contains whitespaces", path, key, n)}
+		}
+
+		if hasValue {
+			lines = append(lines, key+"="+value)
+		} else if v, ok := bare(key); ok {
+			lines = append(lines, key+"="+v)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, &ce.CustomError{Title: "Unable to read the " + kind, Message: err.Error()}
+	}
+	return lines, nil
+}
+
+// buildEnv returns the env files content followed by the -e values; bare -e VAR are inherited from our environment
+func buildEnv(opts Options) ([]string, *ce.CustomError) {
+	var env []string
+	for _, f := range opts.EnvFile {
+		lines, cerr := ParseEnvFile(f)
+		if cerr != nil {
+			return nil, cerr
+		}
+		env = append(env, lines...)
+	}
+	for _, e := range opts.Env {
+		if strings.Contains(e, "=") {
+			env = append(env, e)
+		} else if v, ok := os.LookupEnv(e); ok {
+			env = append(env, e+"="+v)
+		}
+	}
+	return env, nil
+}
+
+// buildLabels merges the label files, then the --label values; a --label without = gets an empty value
+func buildLabels(opts Options) (map[string]string, *ce.CustomError) {
+	var specs []string
+	for _, f := range opts.LabelFile {
+		lines, cerr := parseLabelFile(f)
+		if cerr != nil {
+			return nil, cerr
+		}
+		specs = append(specs, lines...)
+	}
+	specs = append(specs, opts.Labels...)
+	if len(specs) == 0 {
+		return nil, nil
+	}
+
+	labels := make(map[string]string, len(specs))
+	for _, l := range specs {
+		k, v, _ := strings.Cut(l, "=")
+		if k == "" {
+			return nil, &ce.CustomError{Title: "Invalid label", Message: fmt.Sprintf("no label name in %q", l)}
+		}
+		labels[k] = v
+	}
+	return labels, nil
+}
@@ -0,0 +1,147 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 23:10
+// Original filename: src/run/network.go
+
+package run
+
+import (
+	"context"
+	"dtools2/networks"
+	"dtools2/rest"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+)
+
+// applyNetworking sets the primary network and its endpoint (aliases, static addresses, MAC address),
+// plus the DNS settings, extra hosts and -P. The other networks are connected by connectExtraNetworks().
+func applyNetworking(req *ContainerCreateRequest, opts Options) *ce.CustomError {
+	if req.HostConfig == nil {
+		req.HostConfig = &HostConfig{}
+	}
+	hc := req.HostConfig
+	hc.PublishAllPorts = opts.PublishAll
+
+	if len(opts.Network) > 1 {
+		for _, n := range opts.Network {
+			if n == "host" || n == "none" || strings.HasPrefix(n, "container:") {
+				return &ce.CustomError{Title: "Conflicting options", Message: fmt.Sprintf("network %q cannot be combined with other networks", n)}
+			}
+		}
+	}
+
+	endpoint, cerr := primaryEndpoint(opts)
+	if cerr != nil {
+		return cerr
+	}
+	if len(opts.Network) > 0 {
+		hc.NetworkMode = opts.Network[0]
+		if endpoint != nil {
+			req.NetworkingConfig = &NetworkingConfig{EndpointsConfig: map[string]*networks.EndpointSettings{opts.Network[0]: endpoint}}
+		}
+	} else if endpoint != nil && (len(endpoint.Aliases) > 0 || endpoint.IPAMConfig != nil) {
+		return &ce.CustomError{Title: "Conflicting options", Message: "--network-alias, --ip and --ip6 need a user-defined --network"}
+	} else if endpoint != nil {
+		// Only a MAC address, on the default network
+		req.MacAddress = endpoint.MacAddress
+	}
+
+	for _, d := range opts.DNS {
+		if net.ParseIP(d) == nil {
+			return &ce.CustomError{Title: "Invalid DNS server", Message: fmt.Sprintf("%q is not an IP address", d)}
+		}
+	}
+	hc.DNS = opts.DNS
+	hc.DNSSearch = opts.DNSSearch
+	hc.DNSOptions = opts.DNSOption
+
+	for _, h := range opts.AddHost {
+		entry, cerr := parseExtraHost(h)
+		if cerr != nil {
+			return cerr
+		}
+		hc.ExtraHosts = append(hc.ExtraHosts, entry)
+	}
+	return nil
+}
+
+// primaryEndpoint builds the endpoint settings of the first network; nil when there is nothing to set
+func primaryEndpoint(opts Options) (*networks.EndpointSettings, *ce.CustomError) {
+	ep := &networks.EndpointSettings{Aliases: opts.NetworkAlias}
+
+	if opts.IP != "" || opts.IP6 != "" {
+		ep.IPAMConfig = &networks.EndpointIPAMConfig{}
+		if opts.IP != "" {
+			if ip := net.ParseIP(opts.IP); ip == nil || ip.To4() == nil {
+				return nil, &ce.CustomError{Title: "Invalid IP address", Message: fmt.Sprintf("--ip %q is not an IPv4 address", opts.IP)}
+			}
+			ep.IPAMConfig.IPv4Address = opts.IP
+		}
+		if opts.IP6 != "" {
+			if ip := net.ParseIP(opts.IP6); ip == nil || ip.To4() != nil {
+				return nil, &ce.CustomError{Title: "Invalid IP address", Message: fmt.Sprintf("--ip6 %q is not an IPv6 address", opts.IP6)}
+			}
+			ep.IPAMConfig.IPv6Address = opts.IP6
+		}
+	}
+	if opts.MacAddress != "" {
+		if _, err := net.ParseMAC(opts.MacAddress); err != nil {
+			return nil, &ce.CustomError{Title: "Invalid MAC address", Message: fmt.Sprintf("--mac-address %q: %s", opts.MacAddress, err)}
+		}
+		ep.MacAddress = opts.MacAddress
+	}
+
+	if len(ep.Aliases) == 0 && ep.IPAMConfig == nil && ep.MacAddress == "" {
+		return nil, nil
+	}
+	return ep, nil
+}
+
+// parseExtraHost parses --add-host host:ip (or host=ip); ip may be host-gateway
+func parseExtraHost(spec string) (string, *ce.CustomError) {
+	host, ip, ok := strings.Cut(spec, "=")
+	if !ok {
+		host, ip, ok = strings.Cut(spec, ":")
+	}
+	if !ok || host == "" || ip == "" {
+		return "", &ce.CustomError{Title: "Invalid extra host", Message: fmt.Sprintf("--add-host %q is not in the host:ip format", spec)}
+	}
+	ip = strings.TrimSuffix(strings.TrimPrefix(ip, "["), "]")
+	if ip != "host-gateway" && net.ParseIP(ip) == nil {
+		return "", &ce.CustomError{Title: "Invalid extra host", Message: fmt.Sprintf("invalid IP address %q in --add-host %q", ip, spec)}
+	}
+	return host + ":" + ip, nil
+}
+
+// connectExtraNetworks connects the freshly created container to its other networks, before it starts;
+// the network aliases apply to all of them
+func connectExtraNetworks(ctx context.Context, client *rest.Client, id string, opts Options) *ce.CustomError {
+	if len(opts.Network) < 2 {
+		return nil
+	}
+	var endpoint *networks.EndpointSettings
+	if len(opts.NetworkAlias) > 0 {
+		endpoint = &networks.EndpointSettings{Aliases: opts.NetworkAlias}
+	}
+	for _, n := range opts.Network[1:] {
+		if cerr := networks.AttachNetworkEndpoint(ctx, client, n, id, endpoint); cerr != nil {
+			return cerr
+		}
+	}
+	return nil
+}
+
+// removeCreatedContainer discards a container we could not finish setting up; errors are ignored
+func removeCreatedContainer(ctx context.Context, client *rest.Client, id string) {
+	q := url.Values{}
+	q.Set("force", "1")
+	q.Set("v", "1")
+	if resp, err := client.Do(ctx, http.MethodDelete, "/containers/"+id, q, nil, nil); err == nil {
+		resp.Body.Close()
+	}
+}
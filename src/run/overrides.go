@@ -0,0 +1,84 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 08:30
+// Original filename: src/run/overrides.go
+
+package run
+
+import (
+	"context"
+	"dtools2/rest"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ImageDefaults returns what the containers of an image inherit from it. The image may be gone: everything
+// the container has then counts as its own.
+func ImageDefaults(ctx context.Context, client *rest.Client, image string) ImageConfig {
+	var img struct {
+		Config ImageConfig `json:"Config"`
+	}
+	_ = getJSON(ctx, client, "/images/"+image+"/json", &img)
+	return img.Config
+}
+
+// ContainerOverrides returns the settings of a container that do not come from its image
+func ContainerOverrides(ci ContainerInspect, img ImageConfig) Overrides {
+	cfg, hc := ci.Config, ci.HostConfig
+	var o Overrides
+
+	if cfg.Hostname != ci.ID[:min(len(ci.ID), 12)] {
+		o.Hostname = cfg.Hostname
+	}
+	if cfg.User != img.User {
+		o.User = cfg.User
+	}
+	if cfg.WorkingDir != img.WorkingDir {
+		o.WorkingDir = cfg.WorkingDir
+	}
+	if !slices.Equal(cfg.Entrypoint, img.Entrypoint) && len(cfg.Entrypoint) > 0 {
+		o.Entrypoint = cfg.Entrypoint
+		o.Command = cfg.Cmd
+	} else if !slices.Equal(cfg.Cmd, img.Cmd) {
+		o.Command = cfg.Cmd
+	}
+
+	for _, e := range cfg.Env {
+		if !slices.Contains(img.Env, e) {
+			o.Env = append(o.Env, e)
+		}
+	}
+	for _, k := range slices.Sorted(maps.Keys(cfg.Labels)) {
+		if v, ok := img.Labels[k]; !ok || v != cfg.Labels[k] {
+			o.Labels = append(o.Labels, k+"="+cfg.Labels[k])
+		}
+	}
+	for _, port := range slices.Sorted(maps.Keys(hc.PortBindings)) {
+		for _, b := range hc.PortBindings[port] {
+			o.Ports = append(o.Ports, PublishSpec(port, b))
+		}
+	}
+
+	if p := hc.RestartPolicy; p != nil && p.Name != "" && p.Name != "no" {
+		o.Restart = p.Name
+		if p.Name == "on-failure" && p.MaximumRetryCount > 0 {
+			o.Restart += ":" + strconv.Itoa(p.MaximumRetryCount)
+		}
+	}
+	for _, opt := range hc.SecurityOpt {
+		// A seccomp profile was sent inline; there is no file to point --security-opt to
+		if strings.HasPrefix(opt, "seccomp=") && opt != "seccomp=unconfined" {
+			o.Seccomp = true
+			continue
+		}
+		o.SecurityOpt = append(o.SecurityOpt, opt)
+	}
+
+	if h := cfg.Healthcheck; h != nil && !reflect.DeepEqual(h, img.Healthcheck) {
+		o.Healthcheck = h
+	}
+	return o
+}
@@ -6,22 +6,16 @@
 package run
 
 import (
+	"context"
 	"dtools2/images"
 	"dtools2/rest"
 	"io"
-	"os"
 )
 
 // pullImageViaDaemon pulls an image through the daemon (same endpoint as `docker pull`).
 //
-// The actual pull implementation lives in images/pull.go; this wrapper exists so the
-// run package can:
-//   - keep its existing call sites unchanged
-//   - control output (quiet mode) without baking rest.QuietOutput into images
-func pullImageViaDaemon(client *rest.Client, ref string) error {
-	var out io.Writer = os.Stdout
-	if rest.QuietOutput {
-		out = io.Discard
-	}
-	return images.PullRef(client, ref, out)
+// The actual pull implementation lives in images/pull.go; this wrapper only exists
+// to keep the run package call sites unchanged.
+func pullImageViaDaemon(ctx context.Context, client *rest.Client, ref string, out io.Writer) error {
+	return images.PullRef(ctx, client, ref, out)
 }
@@ -7,7 +7,7 @@ package run
 
 import (
 	"bytes"
-	"dtools2/containers"
+	"context"
 	"dtools2/extras"
 	"encoding/json"
 	"fmt"
@@ -40,37 +40,41 @@ import (
 // Return values:
 //   - exitCode: container process exit code (attached mode) or 0 (detached)
 //   - containerID: non-empty in detached mode
-//
-// NOTE: This file relies on existing helpers in the same package (extras), already present in your tree:
-//   - stringsTrim(string) string
-//   - copyStdin(dst io.Writer, stop <-chan struct{})
-func RunContainer(client *rest.Client, image string, cmd []string) (exitCode int, containerID string, errCode *ce.CustomError) {
+func RunContainer(ctx context.Context, client *rest.Client, image string, cmd []string, opts Options) (exitCode int, containerID string, errCode *ce.CustomError) {
 	if image == "" {
 		return 1, "", &ce.CustomError{Title: "Missing image", Message: "no image specified"}
 	}
 
 	// Docker CLI errors when -it is used but stdin isn't a TTY.
-	if RunTTY && RunInteractive {
+	stdout, stderr := opts.Stdout, opts.Stderr
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+
+	if opts.TTY && opts.Interactive {
 		if !xterm.IsTerminal(int(os.Stdin.Fd())) {
 			return 1, "", &ce.CustomError{Title: "The input device is not a TTY", Message: "cannot allocate a TTY with non-terminal stdin"}
 		}
 	}
 
 	// Create (with auto-pull on missing image).
-	id, cerr := createContainerWithAutoPull(client, image, cmd)
+	id, cerr := CreateContainer(ctx, client, image, cmd, opts)
 	if cerr != nil {
 		return 1, "", cerr
 	}
 
-	if RunDetach {
-		if cerr := startContainer(client, id); cerr != nil {
+	if opts.Detach {
+		if cerr := startContainer(ctx, client, id); cerr != nil {
 			return 1, "", cerr
 		}
 		return 0, id, nil
 	}
 
 	// Attach BEFORE start (docker behaviour).
-	hj, cerr := AttachContainer(client, id)
+	hj, cerr := AttachContainer(ctx, client, id, opts.Interactive)
 	if cerr != nil {
 		return 1, "", cerr
 	}
@@ -81,7 +85,7 @@ func RunContainer(client *rest.Client, image string, cmd []string) (exitCode int
 	done := make(chan struct{})
 	go func() {
 		select {
-		case <-rest.Context.Done():
+		case <-ctx.Done():
 			_ = conn.Close()
 		case <-done:
 		}
@@ -93,7 +97,7 @@ func RunContainer(client *rest.Client, image string, cmd []string) (exitCode int
 
 	// Terminal raw mode + resize when allocating TTY.
 	var restoreTerm func()
-	if RunTTY && RunInteractive && xterm.IsTerminal(int(os.Stdin.Fd())) {
+	if opts.TTY && opts.Interactive && xterm.IsTerminal(int(os.Stdin.Fd())) {
 		oldState, e := xterm.MakeRaw(int(os.Stdin.Fd()))
 		if e == nil {
 			restoreTerm = func() { _ = xterm.Restore(int(os.Stdin.Fd()), oldState) }
@@ -103,12 +107,12 @@ func RunContainer(client *rest.Client, image string, cmd []string) (exitCode int
 		defer restoreTerm()
 	}
 
-	if RunTTY {
-		setupContainerResizeHandler(client, id)
+	if opts.TTY {
+		setupContainerResizeHandler(ctx, client, id)
 	}
 
 	// Start container.
-	if cerr := startContainer(client, id); cerr != nil {
+	if cerr := startContainer(ctx, client, id); cerr != nil {
 		return 1, "", cerr
 	}
 
@@ -119,15 +123,15 @@ func RunContainer(client *rest.Client, image string, cmd []string) (exitCode int
 	}
 	waitCh := make(chan wr, 1)
 	go func() {
-		code, werr := waitContainerExit(client, id)
+		code, werr := WaitContainer(ctx, client, id, WaitNotRunning)
 		waitCh <- wr{code: code, err: werr}
 	}()
 
 	// Optional signal proxying in non-TTY mode. In TTY mode, Ctrl+C is sent
 	// as bytes through the pty (raw stdin), so we mostly avoid double-sending.
 	stopSigProxy := make(chan struct{})
-	if !RunTTY {
-		go proxySignalsToContainer(client, id, stopSigProxy)
+	if !opts.TTY {
+		go proxySignalsToContainer(ctx, client, id, stopSigProxy)
 	}
 	defer close(stopSigProxy)
 
@@ -135,11 +139,11 @@ func RunContainer(client *rest.Client, image string, cmd []string) (exitCode int
 	var wg sync.WaitGroup
 	stdinStop := make(chan struct{})
 
-	if RunInteractive {
+	if opts.Interactive {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			extras.CopyStdin(conn, stdinStop)
+			extras.CopyStdin(ctx, conn, stdinStop)
 			if cw, ok := conn.(interface{ CloseWrite() error }); ok {
 				_ = cw.CloseWrite()
 			}
@@ -149,10 +153,10 @@ func RunContainer(client *rest.Client, image string, cmd []string) (exitCode int
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		if RunTTY {
-			_, _ = io.Copy(os.Stdout, reader)
+		if opts.TTY {
+			_, _ = io.Copy(stdout, reader)
 		} else {
-			_, _ = stdcopy.StdCopy(os.Stdout, os.Stderr, reader)
+			_, _ = stdcopy.StdCopy(stdout, stderr, reader)
 		}
 		close(stdinStop)
 	}()
@@ -167,71 +171,128 @@ func RunContainer(client *rest.Client, image string, cmd []string) (exitCode int
 	return r.code, "", nil
 }
 
-func createContainerWithAutoPull(client *rest.Client, image string, cmd []string) (string, *ce.CustomError) {
-	id, missing, cerr := createContainer(client, image, cmd)
-	if cerr == nil {
-		return id, nil
+// CreateContainer creates (without starting) a container from the run options, pulling the image as the
+// pull policy says, and returns its ID. This is our `docker create`.
+func CreateContainer(ctx context.Context, client *rest.Client, image string, cmd []string, opts Options) (string, *ce.CustomError) {
+	if image == "" {
+		return "", &ce.CustomError{Title: "Missing image", Message: "no image specified"}
 	}
-	if !missing {
-		return "", cerr
+	policy := opts.Pull
+	switch policy {
+	case "":
+		policy = PullMissing
+	case PullAlways, PullMissing, PullNever:
+	default:
+		return "", &ce.CustomError{Title: "Invalid pull policy", Message: policy + " is not one of " + PullAlways + ", " + PullMissing + ", " + PullNever}
 	}
 
-	// Auto-pull image then retry.
-	if err := pullImageViaDaemon(client, image); err != nil {
-		return "", &ce.CustomError{Title: "Unable to pull image", Message: err.Error()}
+	if policy == PullAlways {
+		if err := pullImageViaDaemon(ctx, client, image, opts.PullOutput); err != nil {
+			return "", &ce.CustomError{Title: "Unable to pull image", Message: err.Error()}
+		}
 	}
-	id, _, cerr = createContainer(client, image, cmd)
+
+	id, missing, cerr := createContainer(ctx, client, image, cmd, opts)
 	if cerr != nil {
+		if missing && policy == PullNever {
+			return "", &ce.CustomError{Title: "Image not found", Message: "image " + image + " is not present locally and the pull policy is never"}
+		}
+		if !missing || policy != PullMissing {
+			return "", cerr
+		}
+
+		// Auto-pull image then retry.
+		if err := pullImageViaDaemon(ctx, client, image, opts.PullOutput); err != nil {
+			return "", &ce.CustomError{Title: "Unable to pull image", Message: err.Error()}
+		}
+		if id, _, cerr = createContainer(ctx, client, image, cmd, opts); cerr != nil {
+			return "", cerr
+		}
+	}
+
+	// A half-connected container would not be what was asked for: we discard it.
+	if cerr := connectExtraNetworks(ctx, client, id, opts); cerr != nil {
+		removeCreatedContainer(ctx, client, id)
 		return "", cerr
 	}
 	return id, nil
 }
 
 // createContainer returns (id, imageMissing, customError).
-func createContainer(client *rest.Client, image string, cmd []string) (string, bool, *ce.CustomError) {
+func createContainer(ctx context.Context, client *rest.Client, image string, cmd []string, opts Options) (string, bool, *ce.CustomError) {
 	req := ContainerCreateRequest{
 		Image: image,
 		Cmd:   nil,
 		// IO
-		AttachStdin:  RunInteractive,
-		AttachStdout: !RunDetach,
-		AttachStderr: !RunDetach,
-		OpenStdin:    RunInteractive,
+		AttachStdin:  opts.Interactive,
+		AttachStdout: !opts.Detach,
+		AttachStderr: !opts.Detach,
+		OpenStdin:    opts.Interactive,
 		StdinOnce:    false,
-		Tty:          RunTTY,
+		Tty:          opts.TTY,
 
-		User:       RunUser,
-		Env:        RunEnv,
-		WorkingDir: RunWorkdir,
-		Hostname:   RunHostname,
+		User:       opts.User,
+		WorkingDir: opts.Workdir,
+		Hostname:   opts.Hostname,
 	}
 	if len(cmd) > 0 {
 		req.Cmd = cmd
 	}
-	if RunEntrypoint != "" {
+	var cerr *ce.CustomError
+	if req.Env, cerr = buildEnv(opts); cerr != nil {
+		return "", false, cerr
+	}
+	if req.Labels, cerr = buildLabels(opts); cerr != nil {
+		return "", false, cerr
+	}
+	if opts.Entrypoint != "" {
 		// docker CLI treats --entrypoint as a single binary string; we do the same.
-		req.Entrypoint = []string{RunEntrypoint}
+		req.Entrypoint = []string{opts.Entrypoint}
 	}
 
 	// HostConfig
 	hc := &HostConfig{}
-	if RunRemove {
+	if opts.Remove {
 		hc.AutoRemove = true
 	}
-	if RunNetwork != "" {
-		hc.NetworkMode = RunNetwork
+	resources, cerr := opts.Resources.Resources()
+	if cerr != nil {
+		return "", false, cerr
+	}
+	hc.Resources = resources
+	if opts.Restart != "" {
+		policy, cerr := extras.ParseRestartPolicy(opts.Restart)
+		if cerr != nil {
+			return "", false, cerr
+		}
+		if opts.Remove && policy.Name != "no" {
+			return "", false, &ce.CustomError{Title: "Conflicting options", Message: "--restart and --rm cannot be used together"}
+		}
+		hc.RestartPolicy = &policy
 	}
 	req.HostConfig = hc
 
-	if cerr := applyVolumes(&req, RunVolume); cerr != nil {
+	if cerr := applyHealthcheck(&req, opts); cerr != nil {
+		return "", false, cerr
+	}
+
+	if cerr := applySecurity(&req, opts); cerr != nil {
+		return "", false, cerr
+	}
+
+	if cerr := applyNetworking(&req, opts); cerr != nil {
 		return "", false, cerr
 	}
 
-	if cerr := applyMounts(&req, RunMount); cerr != nil {
+	if cerr := applyVolumes(&req, opts.Volume); cerr != nil {
 		return "", false, cerr
 	}
 
-	if cerr := applyPublish(&req, RunPublish); cerr != nil {
+	if cerr := applyMounts(&req, opts.Mount); cerr != nil {
+		return "", false, cerr
+	}
+
+	if cerr := applyPublish(&req, opts.Publish); cerr != nil {
 		return "", false, cerr
 	}
 
@@ -241,27 +302,22 @@ func createContainer(client *rest.Client, image string, cmd []string) (string, b
 	}
 
 	q := url.Values{}
-	if RunName != "" {
-		q.Set("name", RunName)
+	if opts.Name != "" {
+		q.Set("name", opts.Name)
 	}
 
 	headers := http.Header{}
 	headers.Set("Content-Type", "application/json")
 
-	resp, err := client.Do(rest.Context, http.MethodPost, "/containers/create", q, bytes.NewReader(payload), headers)
+	resp, err := client.Do(ctx, http.MethodPost, "/containers/create", q, bytes.NewReader(payload), headers)
 	if err != nil {
 		return "", false, &ce.CustomError{Title: "Unable to create container", Message: err.Error()}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusCreated {
-		b, _ := io.ReadAll(resp.Body)
-		msg := extras.StringsTrim(string(b))
-		if msg == "" {
-			msg = resp.Status
-		}
-		missing := resp.StatusCode == http.StatusNotFound && strings.Contains(strings.ToLower(msg), "no such image")
-		return "", missing, &ce.CustomError{Title: "Container create failed", Message: fmt.Sprintf("POST /containers/create returned %s", msg)}
+	if aerr := rest.CheckResponse(resp); aerr != nil {
+		missing := rest.IsNotFound(aerr) && strings.Contains(strings.ToLower(aerr.Error()), "no such image")
+		return "", missing, &ce.CustomError{Title: "Container create failed", Message: aerr.Error()}
 	}
 
 	var out ContainerCreateResponse
@@ -274,39 +330,28 @@ func createContainer(client *rest.Client, image string, cmd []string) (string, b
 	return out.ID, false, nil
 }
 
-func startContainer(client *rest.Client, id string) *ce.CustomError {
-	q := rest.QuietOutput
-	rest.QuietOutput = false
-	if err := containers.StartContainers(client, []string{id}); err != nil {
+// startContainer starts the freshly created container; 304 (already started) is not an error
+func startContainer(ctx context.Context, client *rest.Client, id string) *ce.CustomError {
+	path := "/containers/" + id + "/start"
+	resp, err := client.Do(ctx, http.MethodPost, path, url.Values{}, nil, nil)
+	if err != nil {
 		return &ce.CustomError{Title: "Unable to start container", Message: err.Error()}
 	}
-	rest.QuietOutput = q
-	return nil
+	defer resp.Body.Close()
 
-	//path := "/containers/" + id + "/start"
-	//resp, err := client.Do(rest.Context, http.MethodPost, path, nil, nil, nil)
-	//if err != nil {
-	//	return &ce.CustomError{Title: "Unable to start container", Message: err.Error()}
-	//}
-	//defer resp.Body.Close()
-	//
-	//if resp.StatusCode != http.StatusNoContent {
-	//	b, _ := io.ReadAll(resp.Body)
-	//	msg := extras.StringsTrim(string(b))
-	//	if msg == "" {
-	//		msg = resp.Status
-	//	}
-	//	return &ce.CustomError{Title: "Container start failed", Message: fmt.Sprintf("POST %s returned %s", path, msg)}
-	//}
-	//return nil
+	if aerr := rest.CheckResponse(resp); aerr != nil && !rest.IsNotModified(aerr) {
+		return &ce.CustomError{Title: "Container start failed", Message: aerr.Error()}
+	}
+	return nil
 }
 
-func AttachContainer(client *rest.Client, id string) (*rest.HijackedConn, *ce.CustomError) {
+// AttachContainer attaches to the container output (and input, when interactive); the caller owns the connection
+func AttachContainer(ctx context.Context, client *rest.Client, id string, interactive bool) (*rest.HijackedConn, *ce.CustomError) {
 	q := url.Values{}
 	q.Set("stream", "1")
 	q.Set("stdout", "1")
 	q.Set("stderr", "1")
-	if RunInteractive {
+	if interactive {
 		q.Set("stdin", "1")
 	}
 
@@ -314,31 +359,36 @@ func AttachContainer(client *rest.Client, id string) (*rest.HijackedConn, *ce.Cu
 	q.Set("logs", "0")
 
 	headers := http.Header{}
-	hj, err := client.Hijack(rest.Context, http.MethodPost, "/containers/"+id+"/attach", q, headers, nil, true)
+	hj, err := client.Hijack(ctx, http.MethodPost, "/containers/"+id+"/attach", q, headers, nil, true)
 	if err != nil {
 		return nil, &ce.CustomError{Title: "Unable to attach to container", Message: err.Error()}
 	}
 	return hj, nil
 }
 
-func waitContainerExit(client *rest.Client, id string) (int, *ce.CustomError) {
+// WaitContainer blocks until the container meets the condition (WaitNotRunning when empty), and returns its exit code
+func WaitContainer(ctx context.Context, client *rest.Client, id string, condition string) (int, *ce.CustomError) {
+	switch condition {
+	case "":
+		condition = WaitNotRunning
+	case WaitNotRunning, WaitNextExit, WaitRemoved:
+	default:
+		return 1, &ce.CustomError{Title: "Invalid wait condition",
+			Message: condition + " is not one of " + WaitNotRunning + ", " + WaitNextExit + ", " + WaitRemoved}
+	}
+
 	q := url.Values{}
-	q.Set("condition", "not-running")
+	q.Set("condition", condition)
 	path := "/containers/" + id + "/wait"
 
-	resp, err := client.Do(rest.Context, http.MethodPost, path, q, nil, nil)
+	resp, err := client.Do(rest.FollowContext(ctx), http.MethodPost, path, q, nil, nil)
 	if err != nil {
 		return 1, &ce.CustomError{Title: "Unable to wait for container", Message: err.Error()}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		b, _ := io.ReadAll(resp.Body)
-		msg := extras.StringsTrim(string(b))
-		if msg == "" {
-			msg = resp.Status
-		}
-		return 1, &ce.CustomError{Title: "Container wait failed", Message: fmt.Sprintf("POST %s returned %s", path, msg)}
+	if aerr := rest.CheckResponse(resp); aerr != nil {
+		return 1, &ce.CustomError{Title: "Container wait failed", Message: aerr.Error()}
 	}
 
 	var out ContainerWaitResponse
@@ -352,6 +402,51 @@ func waitContainerExit(client *rest.Client, id string) (int, *ce.CustomError) {
 	return out.StatusCode, nil
 }
 
+func applyHealthcheck(req *ContainerCreateRequest, opts Options) *ce.CustomError {
+	// As with podman, a command given as a JSON array is in exec form
+	var words []string
+	if strings.HasPrefix(strings.TrimSpace(opts.HealthCmd), "[") && json.Unmarshal([]byte(opts.HealthCmd), &words) == nil {
+		if len(opts.HealthTest) > 0 {
+			return &ce.CustomError{Title: "Conflicting options", Message: "the healthcheck command is given twice"}
+		}
+		opts.HealthCmd, opts.HealthTest = "", words
+	}
+	if opts.HealthCmd != "" && len(opts.HealthTest) > 0 {
+		return &ce.CustomError{Title: "Conflicting options", Message: "the healthcheck command is given twice"}
+	}
+
+	custom := opts.HealthCmd != "" || len(opts.HealthTest) > 0 || opts.HealthInterval != 0 || opts.HealthTimeout != 0 ||
+		opts.HealthRetries != 0 || opts.HealthStartPeriod != 0
+
+	if opts.NoHealthcheck {
+		if custom {
+			return &ce.CustomError{Title: "Conflicting options", Message: "--no-healthcheck conflicts with the --health-* options"}
+		}
+		req.Healthcheck = &Healthcheck{Test: []string{"NONE"}}
+		return nil
+	}
+	if !custom {
+		return nil
+	}
+	if opts.HealthInterval < 0 || opts.HealthTimeout < 0 || opts.HealthStartPeriod < 0 || opts.HealthRetries < 0 {
+		return &ce.CustomError{Title: "Invalid healthcheck", Message: "the --health-* durations and retries cannot be negative"}
+	}
+
+	req.Healthcheck = &Healthcheck{
+		Interval:    opts.HealthInterval,
+		Timeout:     opts.HealthTimeout,
+		StartPeriod: opts.HealthStartPeriod,
+		Retries:     opts.HealthRetries,
+	}
+	if opts.HealthCmd != "" {
+		req.Healthcheck.Test = []string{"CMD-SHELL", opts.HealthCmd}
+	}
+	if len(opts.HealthTest) > 0 {
+		req.Healthcheck.Test = append([]string{"CMD"}, opts.HealthTest...)
+	}
+	return nil
+}
+
 func applyPublish(req *ContainerCreateRequest, pubs []string) *ce.CustomError {
 	if len(pubs) == 0 {
 		return nil
@@ -367,12 +462,14 @@ func applyPublish(req *ContainerCreateRequest, pubs []string) *ce.CustomError {
 	}
 
 	for _, p := range pubs {
-		portKey, bind, cerr := parsePublishSpec(p)
+		bindings, cerr := parsePublishSpec(p)
 		if cerr != nil {
 			return cerr
 		}
-		req.ExposedPorts[portKey] = struct{}{}
-		req.HostConfig.PortBindings[portKey] = append(req.HostConfig.PortBindings[portKey], bind)
+		for portKey, bind := range bindings {
+			req.ExposedPorts[portKey] = struct{}{}
+			req.HostConfig.PortBindings[portKey] = append(req.HostConfig.PortBindings[portKey], bind)
+		}
 	}
 	return nil
 }
@@ -625,17 +722,35 @@ func looksLikeHostPath(s string) bool {
 //	-p 127.0.0.1:8080:80
 //	-p :80
 //	-p 80
+//	-p 8000-8010:8000-8010/udp          (ranges of the same size)
+//	-p 8000-8010:80                     (the daemon picks a free host port in the range)
+//	-p [::1]:8080:80
 //
-// and optional /udp or /tcp suffix on the container port, e.g. 8080:53/udp.
-func parsePublishSpec(spec string) (portKey string, bind PortBinding, errCode *ce.CustomError) {
+// and optional /udp, /tcp or /sctp suffix on the container port, e.g. 8080:53/udp.
+// It returns the binding of every container port ("80/tcp") covered by the spec.
+func parsePublishSpec(spec string) (map[string]PortBinding, *ce.CustomError) {
 	spec = strings.TrimSpace(spec)
 	if spec == "" {
-		return "", PortBinding{}, &ce.CustomError{Title: "Invalid publish", Message: "empty -p value"}
+		return nil, &ce.CustomError{Title: "Invalid publish", Message: "empty -p value"}
 	}
 
-	parts := strings.Split(spec, ":")
+	// IPv6 host addresses come bracketed: [::1]:8080:80
+	hostIP := ""
+	rest := spec
+	if strings.HasPrefix(spec, "[") {
+		end := strings.Index(spec, "]:")
+		if end < 0 {
+			return nil, &ce.CustomError{Title: "Invalid publish", Message: fmt.Sprintf("unterminated IPv6 address in -p %q", spec)}
+		}
+		hostIP, rest = spec[1:end], spec[end+2:]
+		if strings.Count(rest, ":") != 1 {
+			return nil, &ce.CustomError{Title: "Invalid publish", Message: fmt.Sprintf("unsupported -p format: %q", spec)}
+		}
+	}
+
+	parts := strings.Split(rest, ":")
 	if len(parts) > 3 {
-		return "", PortBinding{}, &ce.CustomError{Title: "Invalid publish", Message: fmt.Sprintf("unsupported -p format: %q", spec)}
+		return nil, &ce.CustomError{Title: "Invalid publish", Message: fmt.Sprintf("unsupported -p format: %q", spec)}
 	}
 
 	containerPart := parts[len(parts)-1]
@@ -647,38 +762,77 @@ func parsePublishSpec(spec string) (portKey string, bind PortBinding, errCode *c
 			proto = strings.ToLower(sp[1])
 		}
 	}
+	switch proto {
+	case "tcp", "udp", "sctp":
+	default:
+		return nil, &ce.CustomError{Title: "Invalid publish", Message: fmt.Sprintf("invalid protocol %q in -p %q", proto, spec)}
+	}
 
 	if containerPart == "" {
-		return "", PortBinding{}, &ce.CustomError{Title: "Invalid publish", Message: fmt.Sprintf("missing container port in -p %q", spec)}
+		return nil, &ce.CustomError{Title: "Invalid publish", Message: fmt.Sprintf("missing container port in -p %q", spec)}
 	}
-	if _, err := strconv.Atoi(containerPart); err != nil {
-		return "", PortBinding{}, &ce.CustomError{Title: "Invalid publish", Message: fmt.Sprintf("invalid container port in -p %q", spec)}
+	ctrStart, ctrEnd, err := parsePortRange(containerPart)
+	if err != nil {
+		return nil, &ce.CustomError{Title: "Invalid publish", Message: fmt.Sprintf("invalid container port in -p %q", spec)}
 	}
 
-	bind = PortBinding{}
+	hostPart := ""
 	switch len(parts) {
 	case 1:
 		// Only container port: random host port
-		bind.HostPort = ""
 	case 2:
-		bind.HostPort = parts[0]
+		hostPart = parts[0]
 	case 3:
-		bind.HostIP = parts[0]
-		bind.HostPort = parts[1]
+		hostIP = parts[0]
+		hostPart = parts[1]
 	}
 
-	if bind.HostPort != "" {
+	bindings := map[string]PortBinding{}
+	if hostPart == "" {
 		// allow empty host port for random assignment
-		if _, err := strconv.Atoi(bind.HostPort); err != nil {
-			return "", PortBinding{}, &ce.CustomError{Title: "Invalid publish", Message: fmt.Sprintf("invalid host port in -p %q", spec)}
+		for p := ctrStart; p <= ctrEnd; p++ {
+			bindings[strconv.Itoa(p)+"/"+proto] = PortBinding{HostIP: hostIP}
 		}
+		return bindings, nil
 	}
 
-	portKey = containerPart + "/" + proto
-	return portKey, bind, nil
+	hostStart, hostEnd, err := parsePortRange(hostPart)
+	if err != nil {
+		return nil, &ce.CustomError{Title: "Invalid publish", Message: fmt.Sprintf("invalid host port in -p %q", spec)}
+	}
+	switch {
+	case ctrStart == ctrEnd:
+		// A host range for a single container port lets the daemon pick a free one in it
+		bindings[strconv.Itoa(ctrStart)+"/"+proto] = PortBinding{HostIP: hostIP, HostPort: hostPart}
+	case hostEnd-hostStart == ctrEnd-ctrStart:
+		for i := 0; i <= ctrEnd-ctrStart; i++ {
+			bindings[strconv.Itoa(ctrStart+i)+"/"+proto] = PortBinding{HostIP: hostIP, HostPort: strconv.Itoa(hostStart + i)}
+		}
+	default:
+		return nil, &ce.CustomError{Title: "Invalid publish", Message: fmt.Sprintf("host and container port ranges differ in size in -p %q", spec)}
+	}
+	return bindings, nil
+}
+
+// parsePortRange parses 8080 or 8000-8010
+func parsePortRange(s string) (start int, end int, err error) {
+	first, last, isRange := strings.Cut(s, "-")
+	if start, err = strconv.Atoi(first); err != nil {
+		return 0, 0, err
+	}
+	end = start
+	if isRange {
+		if end, err = strconv.Atoi(last); err != nil {
+			return 0, 0, err
+		}
+	}
+	if start < 1 || end > 65535 || start > end {
+		return 0, 0, fmt.Errorf("invalid port range %s", s)
+	}
+	return start, end, nil
 }
 
-func setupContainerResizeHandler(client *rest.Client, id string) {
+func setupContainerResizeHandler(ctx context.Context, client *rest.Client, id string) {
 	resize := func() {
 		ws, err := mobyterm.GetWinsize(os.Stdout.Fd())
 		if err != nil {
@@ -688,7 +842,7 @@ func setupContainerResizeHandler(client *rest.Client, id string) {
 		q.Set("h", fmt.Sprintf("%d", ws.Height))
 		q.Set("w", fmt.Sprintf("%d", ws.Width))
 		path := "/containers/" + id + "/resize"
-		resp, err := client.Do(rest.Context, http.MethodPost, path, q, nil, nil)
+		resp, err := client.Do(ctx, http.MethodPost, path, q, nil, nil)
 		if err == nil && resp != nil {
 			_ = resp.Body.Close()
 		}
@@ -703,7 +857,7 @@ func setupContainerResizeHandler(client *rest.Client, id string) {
 		defer signal.Stop(ch)
 		for {
 			select {
-			case <-rest.Context.Done():
+			case <-ctx.Done():
 				return
 			case <-ch:
 				resize()
@@ -712,7 +866,7 @@ func setupContainerResizeHandler(client *rest.Client, id string) {
 	}()
 }
 
-func proxySignalsToContainer(client *rest.Client, id string, stop <-chan struct{}) {
+func proxySignalsToContainer(ctx context.Context, client *rest.Client, id string, stop <-chan struct{}) {
 	ch := make(chan os.Signal, 16)
 	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGHUP)
 	defer signal.Stop(ch)
@@ -721,7 +875,7 @@ func proxySignalsToContainer(client *rest.Client, id string, stop <-chan struct{
 		select {
 		case <-stop:
 			return
-		case <-rest.Context.Done():
+		case <-ctx.Done():
 			return
 		case s := <-ch:
 			sigName := signalName(s)
@@ -730,7 +884,7 @@ func proxySignalsToContainer(client *rest.Client, id string, stop <-chan struct{
 			}
 			q := url.Values{}
 			q.Set("signal", sigName)
-			resp, err := client.Do(rest.Context, http.MethodPost, "/containers/"+id+"/kill", q, nil, nil)
+			resp, err := client.Do(ctx, http.MethodPost, "/containers/"+id+"/kill", q, nil, nil)
 			if err == nil && resp != nil {
 				_ = resp.Body.Close()
 			}
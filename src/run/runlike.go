@@ -0,0 +1,357 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 00:20
+// Original filename: src/run/runlike.go
+
+package run
+
+import (
+	"context"
+	"dtools2/rest"
+	"encoding/json"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+)
+
+// RunLike inspects a container and returns the `dtools run` arguments (without "run") that would recreate it.
+// What the container inherits from its image (env, labels, cmd, user...) is left out, and every value is written
+// in the syntax our own flag parsers read back.
+func RunLike(ctx context.Context, client *rest.Client, container string) ([]string, *ce.CustomError) {
+	spec, _, cerr := RunLikeSpec(ctx, client, container)
+	if cerr != nil {
+		return nil, cerr
+	}
+	return spec.Args(), nil
+}
+
+// RunLikeSpec is RunLike() before it becomes a command line; the inspect data is returned along
+func RunLikeSpec(ctx context.Context, client *rest.Client, container string) (RunSpec, ContainerInspect, *ce.CustomError) {
+	var ci ContainerInspect
+	if cerr := getJSON(ctx, client, "/containers/"+url.PathEscape(container)+"/json", &ci); cerr != nil {
+		return RunSpec{}, ci, cerr
+	}
+
+	return runLikeSpec(ci, ImageDefaults(ctx, client, ci.Config.Image)), ci, nil
+}
+
+// Args writes the spec as `dtools run` arguments
+func (s RunSpec) Args() []string {
+	var args []string
+	for _, f := range s.Flags {
+		args = append(args, f.Name)
+		if !f.Bool() {
+			args = append(args, f.Value)
+		}
+	}
+
+	// Our run command parses flags anywhere on the line: a command argument like -g needs the -- separator
+	if slices.ContainsFunc(s.Command, func(a string) bool { return strings.HasPrefix(a, "-") }) {
+		args = append(args, "--")
+	}
+	args = append(args, s.Image)
+	return append(args, s.Command...)
+}
+
+// Bool tells whether the flag takes no value
+func (f RunFlag) Bool() bool {
+	return f.Value == ""
+}
+
+func getJSON(ctx context.Context, client *rest.Client, path string, out any) *ce.CustomError {
+	resp, err := client.Do(ctx, http.MethodGet, path, url.Values{}, nil, nil)
+	if err != nil {
+		return &ce.CustomError{Title: "Unable to GET request", Message: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	if aerr := rest.CheckResponse(resp); aerr != nil {
+		return &ce.CustomError{Title: "Unable to inspect", Message: aerr.Error()}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return &ce.CustomError{Title: "Unable to decode JSON", Message: err.Error()}
+	}
+	return nil
+}
+
+func runLikeSpec(ci ContainerInspect, img ImageConfig) RunSpec {
+	cfg, hc := ci.Config, ci.HostConfig
+	own := ContainerOverrides(ci, img)
+	name := strings.TrimPrefix(ci.Name, "/")
+	shortID := ci.ID
+	if len(shortID) > 12 {
+		shortID = shortID[:12]
+	}
+
+	flags := []RunFlag{{Name: "-d"}}
+	add := func(flag string, values ...string) {
+		for _, v := range values {
+			flags = append(flags, RunFlag{Name: flag, Value: v})
+		}
+	}
+	set := func(flag string) {
+		flags = append(flags, RunFlag{Name: flag})
+	}
+
+	if name != "" {
+		add("--name", name)
+	}
+	if cfg.Tty {
+		set("-t")
+	}
+	if cfg.OpenStdin {
+		set("-i")
+	}
+	if hc.AutoRemove {
+		set("--rm")
+	}
+	if own.Hostname != "" {
+		add("--hostname", own.Hostname)
+	}
+	if own.User != "" {
+		add("--user", own.User)
+	}
+	if own.WorkingDir != "" {
+		add("--workdir", own.WorkingDir)
+	}
+
+	// Entrypoint and command: --entrypoint takes a single binary, its arguments go before the command
+	command := own.Command
+	if len(own.Entrypoint) > 0 {
+		add("--entrypoint", own.Entrypoint[0])
+		command = slices.Concat(own.Entrypoint[1:], own.Command)
+	}
+
+	add("--env", own.Env...)
+	add("--label", own.Labels...)
+	add("--publish", own.Ports...)
+	if hc.PublishAllPorts {
+		set("--publish-all")
+	}
+
+	// Mounts: anonymous volumes are listed in Config.Volumes; those of the image come back by themselves
+	for _, m := range ci.Mounts {
+		ro := ""
+		if !m.RW {
+			ro = ":ro"
+		}
+		_, anonymous := cfg.Volumes[m.Destination]
+		switch {
+		case m.Type == "bind":
+			add("--volume", m.Source+":"+m.Destination+ro)
+		case m.Type == "volume" && anonymous:
+			if _, fromImage := img.Volumes[m.Destination]; !fromImage {
+				add("--volume", m.Destination)
+			}
+		case m.Type == "volume" && m.Name != "":
+			add("--volume", m.Name+":"+m.Destination+ro)
+		case m.Type == "tmpfs":
+			add("--mount", "type=tmpfs,dst="+m.Destination)
+		}
+	}
+	for _, dst := range slices.Sorted(maps.Keys(hc.Tmpfs)) {
+		if hc.Tmpfs[dst] != "" {
+			add("--tmpfs", dst+":"+hc.Tmpfs[dst])
+		} else {
+			add("--tmpfs", dst)
+		}
+	}
+
+	flags = append(flags, runLikeNetworks(ci, name, shortID)...)
+
+	add("--dns", hc.DNS...)
+	add("--dns-search", hc.DNSSearch...)
+	add("--dns-option", hc.DNSOptions...)
+	add("--add-host", hc.ExtraHosts...)
+
+	if own.Restart != "" {
+		add("--restart", own.Restart)
+	}
+
+	flags = append(flags, runLikeResources(hc)...)
+	flags = append(flags, runLikeSecurity(hc, own.SecurityOpt)...)
+
+	if hcheck := own.Healthcheck; hcheck != nil {
+		if len(hcheck.Test) > 0 && hcheck.Test[0] == "NONE" {
+			set("--no-healthcheck")
+		} else {
+			switch {
+			case len(hcheck.Test) < 2:
+			case hcheck.Test[0] == "CMD":
+				// The exec form is kept as a JSON array, which --health-cmd reads back as such
+				b, _ := json.Marshal(hcheck.Test[1:])
+				add("--health-cmd", string(b))
+			default:
+				add("--health-cmd", strings.Join(hcheck.Test[1:], " "))
+			}
+			if hcheck.Interval > 0 {
+				add("--health-interval", hcheck.Interval.String())
+			}
+			if hcheck.Timeout > 0 {
+				add("--health-timeout", hcheck.Timeout.String())
+			}
+			if hcheck.StartPeriod > 0 {
+				add("--health-start-period", hcheck.StartPeriod.String())
+			}
+			if hcheck.Retries > 0 {
+				add("--health-retries", strconv.Itoa(hcheck.Retries))
+			}
+		}
+	}
+
+	return RunSpec{Flags: flags, Image: cfg.Image, Command: command}
+}
+
+// PublishSpec writes a port binding back in the -p syntax
+func PublishSpec(port string, b PortBinding) string {
+	ctrPort := strings.TrimSuffix(port, "/tcp")
+	ip := b.HostIP
+	if strings.Contains(ip, ":") {
+		ip = "[" + ip + "]"
+	}
+	switch {
+	case ip != "":
+		return ip + ":" + b.HostPort + ":" + ctrPort
+	case b.HostPort != "":
+		return b.HostPort + ":" + ctrPort
+	default:
+		return ctrPort
+	}
+}
+
+// runLikeNetworks lists the primary network first; the default bridge is only named when there are others
+func runLikeNetworks(ci ContainerInspect, name, shortID string) []RunFlag {
+	var args []RunFlag
+	primary := ci.HostConfig.NetworkMode
+	eps := ci.NetworkSettings.Networks
+
+	switch {
+	case primary == "host" || primary == "none" || strings.HasPrefix(primary, "container:"):
+		return []RunFlag{{Name: "--network", Value: primary}}
+	case primary == "" || primary == "default":
+		primary = "bridge"
+	}
+
+	var others []string
+	for _, n := range slices.Sorted(maps.Keys(eps)) {
+		if n != primary {
+			others = append(others, n)
+		}
+	}
+	if primary != "bridge" || len(others) > 0 {
+		args = append(args, RunFlag{Name: "--network", Value: primary})
+	}
+	for _, n := range others {
+		args = append(args, RunFlag{Name: "--network", Value: n})
+	}
+
+	// The daemon adds the container name and short ID as aliases by itself
+	var aliases []string
+	for _, n := range slices.Sorted(maps.Keys(eps)) {
+		for _, a := range eps[n].Aliases {
+			if a != name && a != shortID && !slices.Contains(aliases, a) {
+				aliases = append(aliases, a)
+			}
+		}
+	}
+	for _, a := range aliases {
+		args = append(args, RunFlag{Name: "--network-alias", Value: a})
+	}
+
+	if ep, ok := eps[primary]; ok && ep.IPAMConfig != nil {
+		if ep.IPAMConfig.IPv4Address != "" {
+			args = append(args, RunFlag{Name: "--ip", Value: ep.IPAMConfig.IPv4Address})
+		}
+		if ep.IPAMConfig.IPv6Address != "" {
+			args = append(args, RunFlag{Name: "--ip6", Value: ep.IPAMConfig.IPv6Address})
+		}
+	}
+	if mac := ci.Config.MacAddress; mac != "" {
+		if _, err := net.ParseMAC(mac); err == nil {
+			args = append(args, RunFlag{Name: "--mac-address", Value: mac})
+		}
+	}
+	return args
+}
+
+func runLikeResources(hc HostConfig) []RunFlag {
+	var args []RunFlag
+	if hc.Memory > 0 {
+		args = append(args, RunFlag{Name: "--memory", Value: FormatBytes(hc.Memory)})
+	}
+	if hc.MemorySwap == -1 {
+		args = append(args, RunFlag{Name: "--memory-swap", Value: "-1"})
+	} else if hc.MemorySwap > 0 {
+		args = append(args, RunFlag{Name: "--memory-swap", Value: FormatBytes(hc.MemorySwap)})
+	}
+	if hc.MemoryReservation > 0 {
+		args = append(args, RunFlag{Name: "--memory-reservation", Value: FormatBytes(hc.MemoryReservation)})
+	}
+	if hc.NanoCpus > 0 {
+		args = append(args, RunFlag{Name: "--cpus", Value: strconv.FormatFloat(float64(hc.NanoCpus)/1e9, 'f', -1, 64)})
+	}
+	if hc.CpuShares > 0 {
+		args = append(args, RunFlag{Name: "--cpu-shares", Value: strconv.FormatInt(hc.CpuShares, 10)})
+	}
+	if hc.CpusetCpus != "" {
+		args = append(args, RunFlag{Name: "--cpuset-cpus", Value: hc.CpusetCpus})
+	}
+	if hc.PidsLimit != nil && *hc.PidsLimit != 0 {
+		args = append(args, RunFlag{Name: "--pids-limit", Value: strconv.FormatInt(*hc.PidsLimit, 10)})
+	}
+	if hc.BlkioWeight > 0 {
+		args = append(args, RunFlag{Name: "--blkio-weight", Value: strconv.Itoa(int(hc.BlkioWeight))})
+	}
+	for _, u := range hc.Ulimits {
+		args = append(args, RunFlag{Name: "--ulimit", Value: u.Name + "=" + strconv.FormatInt(u.Soft, 10) + ":" + strconv.FormatInt(u.Hard, 10)})
+	}
+	return args
+}
+
+func runLikeSecurity(hc HostConfig, securityOpt []string) []RunFlag {
+	var args []RunFlag
+	for _, c := range hc.CapAdd {
+		args = append(args, RunFlag{Name: "--cap-add", Value: c})
+	}
+	for _, c := range hc.CapDrop {
+		args = append(args, RunFlag{Name: "--cap-drop", Value: c})
+	}
+	if hc.Privileged {
+		args = append(args, RunFlag{Name: "--privileged"})
+	}
+	if hc.ReadonlyRootfs {
+		args = append(args, RunFlag{Name: "--read-only"})
+	}
+	for _, o := range securityOpt {
+		args = append(args, RunFlag{Name: "--security-opt", Value: o})
+	}
+	for _, d := range hc.Devices {
+		args = append(args, RunFlag{Name: "--device", Value: d.PathOnHost + ":" + d.PathInContainer + ":" + d.CgroupPermissions})
+	}
+	for _, g := range hc.GroupAdd {
+		args = append(args, RunFlag{Name: "--group-add", Value: g})
+	}
+	if hc.UsernsMode != "" {
+		args = append(args, RunFlag{Name: "--userns", Value: hc.UsernsMode})
+	}
+	return args
+}
+
+// FormatBytes writes a size with the largest unit that divides it exactly (1g, 512m...), as --memory reads it
+func FormatBytes(n int64) string {
+	for _, u := range []struct {
+		suffix string
+		size   int64
+	}{{"g", 1 << 30}, {"m", 1 << 20}, {"k", 1 << 10}} {
+		if n%u.size == 0 {
+			return strconv.FormatInt(n/u.size, 10) + u.suffix
+		}
+	}
+	return strconv.FormatInt(n, 10)
+}
@@ -0,0 +1,233 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 22:40
+// Original filename: src/run/security.go
+
+package run
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+)
+
+// applySecurity maps the hardening flags (capabilities, privileged, read-only, security options, devices,
+// groups, user namespace, tmpfs) onto HostConfig, validating them before anything reaches the daemon.
+func applySecurity(req *ContainerCreateRequest, opts Options) *ce.CustomError {
+	if req.HostConfig == nil {
+		req.HostConfig = &HostConfig{}
+	}
+	hc := req.HostConfig
+
+	hc.Privileged = opts.Privileged
+	hc.ReadonlyRootfs = opts.ReadOnly
+
+	for _, c := range opts.CapAdd {
+		capName, cerr := normalizeCapability(c)
+		if cerr != nil {
+			return cerr
+		}
+		hc.CapAdd = append(hc.CapAdd, capName)
+	}
+	for _, c := range opts.CapDrop {
+		capName, cerr := normalizeCapability(c)
+		if cerr != nil {
+			return cerr
+		}
+		hc.CapDrop = append(hc.CapDrop, capName)
+	}
+
+	for _, so := range opts.SecurityOpt {
+		opt, cerr := parseSecurityOpt(so)
+		if cerr != nil {
+			return cerr
+		}
+		hc.SecurityOpt = append(hc.SecurityOpt, opt)
+	}
+
+	for _, d := range opts.Device {
+		dm, cerr := parseDeviceSpec(d)
+		if cerr != nil {
+			return cerr
+		}
+		hc.Devices = append(hc.Devices, dm)
+	}
+
+	for _, g := range opts.GroupAdd {
+		g = strings.TrimSpace(g)
+		if g == "" {
+			return &ce.CustomError{Title: "Invalid group", Message: "empty --group-add value"}
+		}
+		hc.GroupAdd = append(hc.GroupAdd, g)
+	}
+
+	if opts.Userns != "" {
+		if cerr := validateUserns(opts.Userns); cerr != nil {
+			return cerr
+		}
+		hc.UsernsMode = opts.Userns
+	}
+
+	for _, t := range opts.Tmpfs {
+		target, options, cerr := parseTmpfsSpec(t)
+		if cerr != nil {
+			return cerr
+		}
+		if hc.Tmpfs == nil {
+			hc.Tmpfs = map[string]string{}
+		}
+		hc.Tmpfs[target] = options
+	}
+	return nil
+}
+
+// normalizeCapability accepts NET_ADMIN, net_admin or CAP_NET_ADMIN (and ALL), and returns the CAP_ form
+func normalizeCapability(name string) (string, *ce.CustomError) {
+	c := strings.ToUpper(strings.TrimSpace(name))
+	if c == "ALL" {
+		return c, nil
+	}
+	c = strings.TrimPrefix(c, "CAP_")
+	if c == "" {
+		return "", &ce.CustomError{Title: "Invalid capability", Message: fmt.Sprintf("invalid capability %q", name)}
+	}
+	for _, r := range c {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '_' {
+			return "", &ce.CustomError{Title: "Invalid capability", Message: fmt.Sprintf("invalid capability %q", name)}
+		}
+	}
+	return "CAP_" + c, nil
+}
+
+// parseSecurityOpt supports the --security-opt forms of the docker CLI:
+//
+//	--security-opt no-new-privileges[=true|false]
+//	--security-opt seccomp=unconfined|/path/to/profile.json
+//	--security-opt apparmor=unconfined|PROFILE
+//	--security-opt label=user:USER|role:ROLE|type:TYPE|level:LEVEL|disable|nested
+//
+// As with docker, a seccomp profile file is read here and its content sent to the daemon.
+func parseSecurityOpt(spec string) (string, *ce.CustomError) {
+	spec = strings.TrimSpace(spec)
+	k, v, hasEq := strings.Cut(spec, "=")
+	if !hasEq {
+		// Legacy key:value form
+		k, v, hasEq = strings.Cut(spec, ":")
+	}
+
+	switch k {
+	case "no-new-privileges":
+		if !hasEq {
+			return "no-new-privileges=true", nil
+		}
+		b, err := parseBoolish(v)
+		if err != nil {
+			return "", &ce.CustomError{Title: "Invalid security option", Message: fmt.Sprintf("invalid no-new-privileges value in --security-opt %q", spec)}
+		}
+		return fmt.Sprintf("no-new-privileges=%t", b), nil
+
+	case "seccomp":
+		if v == "" {
+			return "", &ce.CustomError{Title: "Invalid security option", Message: fmt.Sprintf("missing seccomp profile in --security-opt %q", spec)}
+		}
+		if v == "unconfined" {
+			return "seccomp=unconfined", nil
+		}
+		data, err := os.ReadFile(v)
+		if err != nil {
+			return "", &ce.CustomError{Title: "Invalid security option", Message: fmt.Sprintf("unable to read seccomp profile %s: %s", v, err)}
+		}
+		var profile bytes.Buffer
+		if err := json.Compact(&profile, data); err != nil {
+			return "", &ce.CustomError{Title: "Invalid security option", Message: fmt.Sprintf("seccomp profile %s is not valid JSON: %s", v, err)}
+		}
+		return "seccomp=" + profile.String(), nil
+
+	case "apparmor":
+		if v == "" {
+			return "", &ce.CustomError{Title: "Invalid security option", Message: fmt.Sprintf("missing apparmor profile in --security-opt %q", spec)}
+		}
+		return "apparmor=" + v, nil
+
+	case "label":
+		lk, lv, _ := strings.Cut(v, ":")
+		switch lk {
+		case "disable", "nested":
+			if lv != "" {
+				return "", &ce.CustomError{Title: "Invalid security option", Message: fmt.Sprintf("label=%s takes no value in --security-opt %q", lk, spec)}
+			}
+		case "user", "role", "type", "level", "filetype":
+			if lv == "" {
+				return "", &ce.CustomError{Title: "Invalid security option", Message: fmt.Sprintf("missing label %s in --security-opt %q", lk, spec)}
+			}
+		default:
+			return "", &ce.CustomError{Title: "Invalid security option", Message: fmt.Sprintf("unsupported label option %q in --security-opt %q", lk, spec)}
+		}
+		return "label=" + v, nil
+	}
+	return "", &ce.CustomError{Title: "Invalid security option", Message: fmt.Sprintf("unsupported --security-opt %q", spec)}
+}
+
+// parseDeviceSpec parses --device /dev/host[:/dev/container][:permissions], permissions being a mix of r, w and m
+func parseDeviceSpec(spec string) (DeviceMapping, *ce.CustomError) {
+	parts := strings.Split(strings.TrimSpace(spec), ":")
+	dm := DeviceMapping{PathOnHost: parts[0], CgroupPermissions: "rwm"}
+
+	switch len(parts) {
+	case 1:
+		dm.PathInContainer = parts[0]
+	case 2:
+		if isDevicePermissions(parts[1]) {
+			dm.PathInContainer, dm.CgroupPermissions = parts[0], parts[1]
+		} else {
+			dm.PathInContainer = parts[1]
+		}
+	case 3:
+		if !isDevicePermissions(parts[2]) {
+			return DeviceMapping{}, &ce.CustomError{Title: "Invalid device", Message: fmt.Sprintf("invalid permissions %q in --device %q (use r, w and m)", parts[2], spec)}
+		}
+		dm.PathInContainer, dm.CgroupPermissions = parts[1], parts[2]
+	default:
+		return DeviceMapping{}, &ce.CustomError{Title: "Invalid device", Message: fmt.Sprintf("too many fields in --device %q", spec)}
+	}
+
+	if !strings.HasPrefix(dm.PathOnHost, "/") || !strings.HasPrefix(dm.PathInContainer, "/") {
+		return DeviceMapping{}, &ce.CustomError{Title: "Invalid device", Message: fmt.Sprintf("device paths must be absolute in --device %q", spec)}
+	}
+	return dm, nil
+}
+
+func isDevicePermissions(s string) bool {
+	if s == "" || len(s) > 3 {
+		return false
+	}
+	for _, r := range s {
+		if !strings.ContainsRune("rwm", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// validateUserns accepts host (docker), and the podman modes: auto, keep-id, nomap, private, ns:PATH
+func validateUserns(mode string) *ce.CustomError {
+	name, _, _ := strings.Cut(mode, ":")
+	switch name {
+	case "host", "auto", "keep-id", "nomap", "private", "ns":
+		return nil
+	}
+	return &ce.CustomError{Title: "Invalid user namespace", Message: fmt.Sprintf("unsupported --userns %q (host, or with podman: auto, keep-id, nomap, private, ns:PATH)", mode)}
+}
+
+// parseTmpfsSpec parses --tmpfs /path[:options]; the options (e.g. rw,noexec,size=64m) are checked by the daemon
+func parseTmpfsSpec(spec string) (target string, options string, errCode *ce.CustomError) {
+	target, options, _ = strings.Cut(strings.TrimSpace(spec), ":")
+	if !strings.HasPrefix(target, "/") {
+		return "", "", &ce.CustomError{Title: "Invalid tmpfs", Message: fmt.Sprintf("tmpfs target must be absolute in --tmpfs %q", spec)}
+	}
+	return target, options, nil
+}
@@ -5,20 +5,87 @@
 
 package run
 
-var RunDetach bool       // -d
-var RunInteractive bool  // -i
-var RunTTY bool          // -t
-var RunRemove bool       // --rm
-var RunName string       // --name
-var RunUser string       // -u
-var RunWorkdir string    // -w
-var RunEnv []string      // -e
-var RunPublish []string  // -p
-var RunVolume []string   // -v
-var RunMount []string    // --mount
-var RunNetwork string    // --network
-var RunEntrypoint string // --entrypoint
-var RunHostname string   // --hostname
+import (
+	"dtools2/extras"
+	"dtools2/networks"
+	"io"
+	"time"
+)
+
+// Options controls RunContainer(), our `docker run`.
+type Options struct {
+	Detach      bool                   // -d
+	Interactive bool                   // -i
+	TTY         bool                   // -t
+	Remove      bool                   // --rm
+	Name        string                 // --name
+	User        string                 // -u
+	Workdir     string                 // -w
+	Env         []string               // -e
+	EnvFile     []string               // --env-file
+	Labels      []string               // -l, --label
+	LabelFile   []string               // --label-file
+	Pull        string                 // --pull: one of the Pull* constants; PullMissing when empty
+	Publish     []string               // -p
+	PublishAll  bool                   // -P
+	Volume      []string               // -v
+	Mount       []string               // --mount
+	Network     []string               // --network: the first one at creation, the others connected right after
+	Entrypoint  string                 // --entrypoint
+	Hostname    string                 // --hostname
+	Resources   extras.ResourceOptions // --memory, --cpus, --pids-limit, --ulimit...
+	Restart     string                 // --restart
+
+	// Healthcheck; the image one is kept when none of these is set
+	HealthCmd         string        // --health-cmd, run with /bin/sh -c; a JSON array (["pg_isready", "-U", "app"]) sets HealthTest instead
+	HealthTest        []string      // the healthcheck command in exec form, run without a shell
+	HealthInterval    time.Duration // --health-interval
+	HealthTimeout     time.Duration // --health-timeout
+	HealthRetries     int           // --health-retries
+	HealthStartPeriod time.Duration // --health-start-period
+	NoHealthcheck     bool          // --no-healthcheck: disable the image healthcheck
+
+	// Hardening
+	CapAdd      []string // --cap-add
+	CapDrop     []string // --cap-drop
+	Privileged  bool     // --privileged
+	ReadOnly    bool     // --read-only
+	SecurityOpt []string // --security-opt
+	Device      []string // --device
+	GroupAdd    []string // --group-add
+	Userns      string   // --userns
+	Tmpfs       []string // --tmpfs
+
+	// Networking
+	NetworkAlias []string // --network-alias
+	IP           string   // --ip, on the first network
+	IP6          string   // --ip6, on the first network
+	MacAddress   string   // --mac-address, on the first network
+	DNS          []string // --dns
+	DNSSearch    []string // --dns-search
+	DNSOption    []string // --dns-option
+	AddHost      []string // --add-host host:ip
+
+	// Where the attached container output goes; nil means our own stdout/stderr.
+	Stdout io.Writer
+	Stderr io.Writer
+	// Progress of the auto-pull, when the image is missing; nil discards it.
+	PullOutput io.Writer
+}
+
+// Pull policies of CreateContainer()
+const (
+	PullAlways  = "always"  // pull before creating the container
+	PullMissing = "missing" // pull only if the image is not present
+	PullNever   = "never"   // fail if the image is not present
+)
+
+// Conditions accepted by WaitContainer()
+const (
+	WaitNotRunning = "not-running" // returns at once if the container is not running
+	WaitNextExit   = "next-exit"   // waits for the next exit, even if the container is not running yet
+	WaitRemoved    = "removed"     // waits until the container is removed
+)
 
 // Minimal structures for the Docker/Podman "docker run" flow.
 //
@@ -52,13 +119,33 @@ type ContainerCreateRequest struct {
 	WorkingDir string   `json:"WorkingDir,omitempty"`
 	Hostname   string   `json:"Hostname,omitempty"`
 
+	Labels map[string]string `json:"Labels,omitempty"`
+
 	// Networking/ports
 	ExposedPorts map[string]struct{} `json:"ExposedPorts,omitempty"`
+	MacAddress   string              `json:"MacAddress,omitempty"` // default network only; see NetworkingConfig
 
 	// Anonymous volumes ("-v /path") use this older field.
 	Volumes map[string]struct{} `json:"Volumes,omitempty"`
 
-	HostConfig *HostConfig `json:"HostConfig,omitempty"`
+	Healthcheck *Healthcheck `json:"Healthcheck,omitempty"`
+
+	HostConfig       *HostConfig       `json:"HostConfig,omitempty"`
+	NetworkingConfig *NetworkingConfig `json:"NetworkingConfig,omitempty"`
+}
+
+// NetworkingConfig holds the endpoint settings of the network the container is created on.
+type NetworkingConfig struct {
+	EndpointsConfig map[string]*networks.EndpointSettings `json:"EndpointsConfig,omitempty"`
+}
+
+// Healthcheck overrides the image HEALTHCHECK; zero durations and retries mean "inherit".
+type Healthcheck struct {
+	Test        []string      `json:"Test,omitempty"` // ["CMD-SHELL", cmd], or ["NONE"] to disable
+	Interval    time.Duration `json:"Interval,omitempty"`
+	Timeout     time.Duration `json:"Timeout,omitempty"`
+	StartPeriod time.Duration `json:"StartPeriod,omitempty"`
+	Retries     int           `json:"Retries,omitempty"`
 }
 
 // HostConfig is a subset used by dtools2 for run.
@@ -68,7 +155,28 @@ type HostConfig struct {
 	Mounts      []Mount `json:"Mounts,omitempty"`
 	NetworkMode string  `json:"NetworkMode,omitempty"`
 
-	PortBindings map[string][]PortBinding `json:"PortBindings,omitempty"`
+	PortBindings    map[string][]PortBinding `json:"PortBindings,omitempty"`
+	PublishAllPorts bool                     `json:"PublishAllPorts,omitempty"`
+	DNS             []string                 `json:"Dns,omitempty"`
+	DNSSearch       []string                 `json:"DnsSearch,omitempty"`
+	DNSOptions      []string                 `json:"DnsOptions,omitempty"`
+	ExtraHosts      []string                 `json:"ExtraHosts,omitempty"`
+
+	RestartPolicy *extras.RestartPolicy `json:"RestartPolicy,omitempty"`
+
+	// Security
+	CapAdd         []string          `json:"CapAdd,omitempty"`
+	CapDrop        []string          `json:"CapDrop,omitempty"`
+	Privileged     bool              `json:"Privileged,omitempty"`
+	ReadonlyRootfs bool              `json:"ReadonlyRootfs,omitempty"`
+	SecurityOpt    []string          `json:"SecurityOpt,omitempty"`
+	Devices        []DeviceMapping   `json:"Devices,omitempty"`
+	GroupAdd       []string          `json:"GroupAdd,omitempty"`
+	UsernsMode     string            `json:"UsernsMode,omitempty"`
+	Tmpfs          map[string]string `json:"Tmpfs,omitempty"`
+
+	// Resource limits are inline in HostConfig
+	extras.Resources
 }
 
 // Mount is a minimal subset of the Docker API mount schema.
@@ -79,6 +187,13 @@ type Mount struct {
 	ReadOnly bool   `json:"ReadOnly,omitempty"`
 }
 
+// DeviceMapping is a --device, as HostConfig.Devices wants it
+type DeviceMapping struct {
+	PathOnHost        string `json:"PathOnHost"`
+	PathInContainer   string `json:"PathInContainer"`
+	CgroupPermissions string `json:"CgroupPermissions"`
+}
+
 // PortBinding is the Docker API schema for published ports.
 type PortBinding struct {
 	HostIP   string `json:"HostIp,omitempty"`
@@ -98,3 +213,79 @@ type ContainerWaitResponse struct {
 		Message string `json:"Message"`
 	} `json:"Error,omitempty"`
 }
+
+// ContainerInspect is the subset of GET /containers/{id}/json read by RunLikeSpec(); HostConfig has the same
+// shape as the one we create containers with.
+type ContainerInspect struct {
+	ID     string `json:"Id"`
+	Name   string `json:"Name"`
+	Config struct {
+		Hostname    string              `json:"Hostname"`
+		User        string              `json:"User"`
+		WorkingDir  string              `json:"WorkingDir"`
+		Image       string              `json:"Image"`
+		MacAddress  string              `json:"MacAddress"`
+		Tty         bool                `json:"Tty"`
+		OpenStdin   bool                `json:"OpenStdin"`
+		Env         []string            `json:"Env"`
+		Cmd         []string            `json:"Cmd"`
+		Entrypoint  []string            `json:"Entrypoint"`
+		Labels      map[string]string   `json:"Labels"`
+		Volumes     map[string]struct{} `json:"Volumes"`
+		Healthcheck *Healthcheck        `json:"Healthcheck"`
+		StopTimeout *int                `json:"StopTimeout"` // seconds; the daemon default (10) when not set
+	} `json:"Config"`
+	HostConfig      HostConfig `json:"HostConfig"`
+	NetworkSettings struct {
+		Networks map[string]networks.EndpointSettings `json:"Networks"`
+	} `json:"NetworkSettings"`
+	Mounts []struct {
+		Type        string `json:"Type"`
+		Name        string `json:"Name"`
+		Source      string `json:"Source"`
+		Destination string `json:"Destination"`
+		RW          bool   `json:"RW"`
+	} `json:"Mounts"`
+}
+
+// RunFlag is one flag of a `dtools run` command line; Value is empty for a boolean flag
+type RunFlag struct {
+	Name  string `json:"name"`
+	Value string `json:"value,omitempty"`
+}
+
+// RunSpec is a container as a `dtools run` command line: flags, image and command
+type RunSpec struct {
+	Flags   []RunFlag `json:"flags"`
+	Image   string    `json:"image"`
+	Command []string  `json:"command,omitempty"`
+}
+
+// ImageConfig is the part of GET /images/{id}/json that containers inherit
+type ImageConfig struct {
+	User        string              `json:"User"`
+	WorkingDir  string              `json:"WorkingDir"`
+	Env         []string            `json:"Env"`
+	Cmd         []string            `json:"Cmd"`
+	Entrypoint  []string            `json:"Entrypoint"`
+	Labels      map[string]string   `json:"Labels"`
+	Volumes     map[string]struct{} `json:"Volumes"`
+	Healthcheck *Healthcheck        `json:"Healthcheck"`
+}
+
+// Overrides is what a container sets on top of its image, as both runlike and compose export write it back;
+// the fields are empty when the container keeps the value of its image
+type Overrides struct {
+	Hostname    string // unless it is the short ID given by the daemon
+	User        string
+	WorkingDir  string
+	Entrypoint  []string // the whole entrypoint; Command then holds the full command too
+	Command     []string
+	Env         []string     // KEY=VALUE
+	Labels      []string     // KEY=VALUE, sorted by key
+	Ports       []string     // in the -p syntax, see PublishSpec()
+	Restart     string       // no, always, unless-stopped, on-failure[:N]; empty for no
+	SecurityOpt []string     // without the inline seccomp profile, which cannot be written back
+	Seccomp     bool         // an inline seccomp profile was left out of SecurityOpt
+	Healthcheck *Healthcheck // nil when it is the one of the image
+}
@@ -6,8 +6,8 @@
 package system
 
 import (
+	"context"
 	"dtools2/rest"
-	"fmt"
 	"net/http"
 	"net/url"
 	"os"
@@ -16,18 +16,18 @@ import (
 	"strings"
 
 	ce "github.com/jeanfrancoisgratton/customError/v3"
-	hftx "github.com/jeanfrancoisgratton/helperFunctions/v4/terminalfx"
 )
 
-func CopyFile(client *rest.Client, source, destination string) *ce.CustomError {
+// CopyFile copies a file or directory to or from a container; one (and only one) side is container:path
+func CopyFile(ctx context.Context, client *rest.Client, source, destination string) *ce.CustomError {
 	if strings.Contains(source, ":") {
-		return copyFrom(client, source, destination)
+		return copyFrom(ctx, client, source, destination)
 	}
-	return copyTo(client, source, destination)
+	return copyTo(ctx, client, source, destination)
 }
 
 // copyFrom implements: dtools cp <container>:<path> <host-dest>
-func copyFrom(client *rest.Client, source string, destination string) *ce.CustomError {
+func copyFrom(ctx context.Context, client *rest.Client, source string, destination string) *ce.CustomError {
 	containerRef, containerPath, ok := splitContainerPath(source)
 	if !ok {
 		return &ce.CustomError{Title: "Invalid source", Message: "expected container:path (e.g. mycontainer:/file)"}
@@ -37,7 +37,7 @@ func copyFrom(client *rest.Client, source string, destination string) *ce.Custom
 	}
 
 	// Stat the source inside the container.
-	st, exists, err := statContainerPath(client, containerRef, containerPath)
+	st, exists, err := statContainerPath(ctx, client, containerRef, containerPath)
 	if err != nil {
 		return err
 	}
@@ -73,14 +73,14 @@ func copyFrom(client *rest.Client, source string, destination string) *ce.Custom
 	q.Set("path", containerPathForAPI)
 	endpoint := "/containers/" + url.PathEscape(containerRef) + "/archive"
 
-	resp, rerr := client.Do(rest.Context, http.MethodGet, endpoint, q, nil, nil)
+	resp, rerr := client.Do(rest.StreamingContext(ctx), http.MethodGet, endpoint, q, nil, nil)
 	if rerr != nil {
 		return &ce.CustomError{Title: "Unable to fetch archive from daemon", Message: rerr.Error()}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return &ce.CustomError{Title: "HTTP request returned an error", Message: endpoint + " returned " + resp.Status + dockerMsg(resp.Body)}
+	if aerr := rest.CheckResponse(resp); aerr != nil {
+		return &ce.CustomError{Title: "Unable to fetch archive from daemon", Message: aerr.Error()}
 	}
 
 	// Destination write:
@@ -95,9 +95,6 @@ func copyFrom(client *rest.Client, source string, destination string) *ce.Custom
 		if xerr := extractTarDir(resp.Body, destination); xerr != nil {
 			return &ce.CustomError{Title: "Unable to write destination", Message: xerr.Error()}
 		}
-		if !rest.QuietOutput {
-			fmt.Println(hftx.EnabledSign("Copied : " + source + " ==> " + destination))
-		}
 		return nil
 	}
 
@@ -111,9 +108,6 @@ func copyFrom(client *rest.Client, source string, destination string) *ce.Custom
 		if xerr := extractTarDir(resp.Body, destination); xerr != nil {
 			return &ce.CustomError{Title: "Unable to write destination", Message: xerr.Error()}
 		}
-		if !rest.QuietOutput {
-			fmt.Println(hftx.EnabledSign("Copied : " + source + " ==> " + destination))
-		}
 		return nil
 	}
 
@@ -121,14 +115,11 @@ func copyFrom(client *rest.Client, source string, destination string) *ce.Custom
 	if xerr := extractSingleFile(resp.Body, destination); xerr != nil {
 		return &ce.CustomError{Title: "Unable to write destination", Message: xerr.Error()}
 	}
-	if !rest.QuietOutput {
-		fmt.Println(hftx.EnabledSign("Copied : " + source + " ==> " + destination))
-	}
 	return nil
 }
 
 // copyTo implements: dtools cp <host-src> <container>:<path>
-func copyTo(client *rest.Client, source string, destination string) *ce.CustomError {
+func copyTo(ctx context.Context, client *rest.Client, source string, destination string) *ce.CustomError {
 	containerRef, containerPath, ok := splitContainerPath(destination)
 	if !ok {
 		return &ce.CustomError{Title: "Invalid destination", Message: "expected container:path (e.g. mycontainer:/file)"}
@@ -160,7 +151,7 @@ func copyTo(client *rest.Client, source string, destination string) *ce.CustomEr
 	}
 
 	// Best-effort stat of destination path in container.
-	dstStat, dstExists, derr := statContainerPath(client, containerRef, containerPathNoDot)
+	dstStat, dstExists, derr := statContainerPath(ctx, client, containerRef, containerPathNoDot)
 	if derr != nil {
 		return derr
 	}
@@ -230,7 +221,7 @@ func copyTo(client *rest.Client, source string, destination string) *ce.CustomEr
 		}
 	}
 
-	stream, terr := makeTarStream(rest.Context, srcPath, tarRootName, srcIsDir, srcContentsOnly, tarNoRootDir)
+	stream, terr := makeTarStream(ctx, srcPath, tarRootName, srcIsDir, srcContentsOnly, tarNoRootDir)
 	if terr != nil {
 		return &ce.CustomError{Title: "Unable to create tar stream", Message: terr.Error()}
 	}
@@ -243,17 +234,14 @@ func copyTo(client *rest.Client, source string, destination string) *ce.CustomEr
 	headers := http.Header{}
 	headers.Set("Content-Type", "application/x-tar")
 
-	resp, rerr := client.Do(rest.Context, http.MethodPut, endpoint, q, stream, headers)
+	resp, rerr := client.Do(rest.StreamingContext(ctx), http.MethodPut, endpoint, q, stream, headers)
 	if rerr != nil {
 		return &ce.CustomError{Title: "Unable to upload archive to daemon", Message: rerr.Error()}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return &ce.CustomError{Title: "HTTP request returned an error", Message: endpoint + " returned " + resp.Status + dockerMsg(resp.Body)}
-	}
-	if !rest.QuietOutput {
-		fmt.Println(hftx.EnabledSign("Copied : " + source + " ==> " + destination))
+	if aerr := rest.CheckResponse(resp); aerr != nil {
+		return &ce.CustomError{Title: "Unable to upload archive to daemon", Message: aerr.Error()}
 	}
 	return nil
 }
@@ -7,6 +7,7 @@ package system
 
 import (
 	"archive/tar"
+	"context"
 	"dtools2/rest"
 	"encoding/base64"
 	"encoding/json"
@@ -57,19 +58,6 @@ func addDotIfDir(p string) string {
 	return trim + "/."
 }
 
-func dockerMsg(r io.Reader) string {
-	b, _ := io.ReadAll(io.LimitReader(r, 64*1024))
-	s := strings.TrimSpace(string(b))
-	if s == "" {
-		return ""
-	}
-	var der dockerErrorResponse
-	if json.Unmarshal(b, &der) == nil && strings.TrimSpace(der.Message) != "" {
-		return ": " + strings.TrimSpace(der.Message)
-	}
-	return ": " + s
-}
-
 func hostExistsIsDir(p string) (bool, bool, error) {
 	statPath := strings.TrimRight(p, string(os.PathSeparator))
 	if statPath == "" {
@@ -85,22 +73,22 @@ func hostExistsIsDir(p string) (bool, bool, error) {
 	return false, false, err
 }
 
-func statContainerPath(client *rest.Client, containerRef, containerPath string) (*containerPathStat, bool, *ce.CustomError) {
+func statContainerPath(ctx context.Context, client *rest.Client, containerRef, containerPath string) (*containerPathStat, bool, *ce.CustomError) {
 	q := url.Values{}
 	q.Set("path", containerPath)
 	endpoint := "/containers/" + url.PathEscape(containerRef) + "/archive"
 
-	resp, err := client.Do(rest.Context, http.MethodHead, endpoint, q, nil, nil)
+	resp, err := client.Do(ctx, http.MethodHead, endpoint, q, nil, nil)
 	if err != nil {
 		return nil, false, &ce.CustomError{Title: "Unable to stat container path", Message: err.Error()}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, false, nil
-	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, false, &ce.CustomError{Title: "HTTP request returned an error", Message: endpoint + " returned " + resp.Status}
+	if aerr := rest.CheckResponse(resp); aerr != nil {
+		if rest.IsNotFound(aerr) {
+			return nil, false, nil
+		}
+		return nil, false, &ce.CustomError{Title: "Unable to stat container path", Message: aerr.Error()}
 	}
 
 	h := resp.Header.Get("X-Docker-Container-Path-Stat")
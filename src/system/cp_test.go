@@ -0,0 +1,70 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 08:00
+// Original filename: src/system/cp_test.go
+
+package system
+
+import (
+	"context"
+	"dtools2/rest/fakedaemon"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopyFileRoundTrip(t *testing.T) {
+	d, client := fakedaemon.StartT(t)
+	ctx := context.Background()
+	d.AddContainer(fakedaemon.Container{Name: "web", Image: "nginx", State: fakedaemon.StateRunning,
+		Files: map[string]fakedaemon.File{
+			"/etc/motd": {Data: []byte("hello\n"), Mode: 0644},
+			"/tmp":      {Mode: os.ModeDir | 01777},
+		}})
+	dir := t.TempDir()
+
+	// Out of the container...
+	local := filepath.Join(dir, "motd")
+	if cerr := CopyFile(ctx, client, "web:/etc/motd", local); cerr != nil {
+		t.Fatal(cerr)
+	}
+	if b, err := os.ReadFile(local); err != nil || string(b) != "hello\n" {
+		t.Fatalf("copied out %q (%v), want %q", b, err, "hello\n")
+	}
+	if fi, err := os.Stat(local); err != nil {
+		t.Fatal(err)
+	} else if fi.Mode().Perm() != 0644 {
+		t.Errorf("copied out with mode %v, want 0644", fi.Mode().Perm())
+	}
+
+	// ...and back in, under another directory
+	if err := os.WriteFile(local, []byte("bye\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if cerr := CopyFile(ctx, client, local, "web:/tmp/"); cerr != nil {
+		t.Fatal(cerr)
+	}
+	c, _ := d.Container("web")
+	f, ok := c.Files["/tmp/motd"]
+	if !ok || string(f.Data) != "bye\n" {
+		t.Fatalf("/tmp/motd in the container: %q (present: %v), want %q", f.Data, ok, "bye\n")
+	}
+	if f.Mode.Perm() != 0644 {
+		t.Errorf("/tmp/motd has mode %v, want 0644", f.Mode.Perm())
+	}
+
+	// The seeded file is untouched
+	if string(c.Files["/etc/motd"].Data) != "hello\n" {
+		t.Errorf("/etc/motd changed to %q", c.Files["/etc/motd"].Data)
+	}
+}
+
+func TestCopyFileMissing(t *testing.T) {
+	d, client := fakedaemon.StartT(t)
+	ctx := context.Background()
+	d.AddContainer(fakedaemon.Container{Name: "web", Image: "nginx"})
+
+	if cerr := CopyFile(ctx, client, "web:/nope", filepath.Join(t.TempDir(), "x")); cerr == nil {
+		t.Error("copying a missing file succeeded")
+	}
+}
@@ -6,56 +6,53 @@
 package system
 
 import (
+	"context"
 	"dtools2/images"
 	"dtools2/networks"
 	"dtools2/rest"
 	"dtools2/volumes"
-	"fmt"
 
 	ce "github.com/jeanfrancoisgratton/customError/v3"
-	hftx "github.com/jeanfrancoisgratton/helperFunctions/v4/terminalfx"
 )
 
 // clean : removes all unused images
 // this will purge all unused images, volumes and networks
+// The blacklist and force policies of opts are enforced across resources.
 
-func Clean(client *rest.Client) *ce.CustomError {
-	// Ensure that the Blacklisted policy is enforced across resources
-	images.RemoveBlacklisted = RemoveBlacklisted
-	volumes.RemoveBlackListed = RemoveBlacklisted
-	networks.RemoveBlacklisted = RemoveBlacklisted
-	images.ForceRemove = ForceRemove
-	volumes.ForceRemove = ForceRemove
+func Clean(ctx context.Context, client *rest.Client, opts CleanOptions) (CleanReport, *ce.CustomError) {
+	var report CleanReport
+	var err *ce.CustomError
 
 	imgCandidates := []string{}
 	netCandidates := []string{}
 
 	// Remove images
-	if is, err := images.ImagesList(client, false); err != nil {
-		return err
+	if is, err := images.ImagesList(ctx, client, images.ListOptions{}); err != nil {
+		return report, err
 	} else {
+		// ImagesList returns one entry per tag: an image carrying several tags must only be removed once.
+		seen := make(map[string]bool)
 		for _, i := range is {
-			if i.Containers == 0 {
+			if i.Containers == 0 && !seen[i.ID] {
+				seen[i.ID] = true
 				imgCandidates = append(imgCandidates, i.ID)
 			}
 		}
-		q := rest.QuietOutput
-		rest.QuietOutput = true
-		if err := images.RemoveImage(client, imgCandidates); err != nil {
-			return err
-		}
-		fmt.Println(hftx.EnabledSign(fmt.Sprintf("Removed %d image(s)", len(imgCandidates))))
-		rest.QuietOutput = q
+	}
+	report.Images, err = images.RemoveImage(ctx, client, imgCandidates, images.RemoveOptions{Force: opts.Force, IgnoreBlacklist: opts.IgnoreBlacklist, OnEvent: opts.OnEvent})
+	if err != nil {
+		return report, err
 	}
 
 	// Remove volumes
-	if err := volumes.PruneVolumes(client); err != nil {
-		return err
+	report.Volumes, err = volumes.PruneVolumes(ctx, client, volumes.PruneOptions{All: true, Force: opts.Force, IgnoreBlacklist: opts.IgnoreBlacklist, OnEvent: opts.OnEvent})
+	if err != nil {
+		return report, err
 	}
 
 	// Remove networks
-	if ns, err := networks.NetworkList(client, false); err != nil {
-		return err
+	if ns, err := networks.NetworkList(ctx, client, networks.ListOptions{}); err != nil {
+		return report, err
 	} else {
 		for _, n := range ns {
 			if n.Name != "host" && n.Name != "none" && n.Name != "bridge" && !n.InUse {
@@ -63,13 +60,7 @@ func Clean(client *rest.Client) *ce.CustomError {
 				netCandidates = append(netCandidates, n.Name)
 			}
 		}
-		q := rest.QuietOutput
-		rest.QuietOutput = true
-		if err := networks.RemoveNetwork(client, netCandidates); err != nil {
-			return err
-		}
-		fmt.Println(hftx.EnabledSign(fmt.Sprintf("Removed %d network(s)", len(netCandidates))))
-		rest.QuietOutput = q
 	}
-	return nil
+	report.Networks, err = networks.RemoveNetwork(ctx, client, netCandidates, networks.RemoveOptions{IgnoreBlacklist: opts.IgnoreBlacklist, OnEvent: opts.OnEvent})
+	return report, err
 }
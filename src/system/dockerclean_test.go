@@ -0,0 +1,81 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 07:50
+// Original filename: src/system/dockerclean_test.go
+
+package system
+
+import (
+	"context"
+	"dtools2/blacklist"
+	"dtools2/rest/fakedaemon"
+	"slices"
+	"testing"
+)
+
+func TestClean(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // the blacklist file lives there
+	d, client := fakedaemon.StartT(t)
+	ctx := context.Background()
+	d.AddImage(fakedaemon.Image{RepoTags: []string{"nginx:1.27"}})
+	d.AddImage(fakedaemon.Image{RepoTags: []string{"old:1.0", "old:latest"}})
+	d.AddNetwork(fakedaemon.Network{Name: "front"})
+	d.AddNetwork(fakedaemon.Network{Name: "unused"})
+	d.AddVolume(fakedaemon.Volume{Name: "keep"})
+	d.AddVolume(fakedaemon.Volume{Name: "free"})
+	d.AddContainer(fakedaemon.Container{Name: "web", Image: "nginx:1.27", State: fakedaemon.StateRunning,
+		Networks: []string{"front"}, Mounts: []fakedaemon.Mount{{Type: "volume", Name: "data", Destination: "/data", RW: true}}})
+	if cerr := blacklist.AddToFile("volumes", "keep"); cerr != nil {
+		t.Fatal(cerr)
+	}
+
+	report, cerr := Clean(ctx, client, CleanOptions{})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+
+	if len(report.Images) != 1 {
+		t.Errorf("removed images %v, want the old one only", report.Images)
+	}
+	var tags []string
+	for _, img := range d.Images() {
+		tags = append(tags, img.RepoTags...)
+	}
+	if !slices.Equal(tags, []string{"nginx:1.27"}) {
+		t.Errorf("images left: %v", tags)
+	}
+
+	if !slices.Equal(report.Volumes, []string{"free"}) {
+		t.Errorf("removed volumes %v, want [free]", report.Volumes)
+	}
+	var vols []string
+	for _, v := range d.Volumes() {
+		vols = append(vols, v.Name)
+	}
+	slices.Sort(vols)
+	if !slices.Equal(vols, []string{"data", "keep"}) {
+		t.Errorf("volumes left: %v, want the one in use and the blacklisted one", vols)
+	}
+
+	if !slices.Equal(report.Networks, []string{"unused"}) {
+		t.Errorf("removed networks %v, want [unused]", report.Networks)
+	}
+}
+
+func TestCleanIgnoreBlacklist(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // the blacklist file lives there
+	d, client := fakedaemon.StartT(t)
+	ctx := context.Background()
+	d.AddVolume(fakedaemon.Volume{Name: "keep"})
+	if cerr := blacklist.AddToFile("volumes", "keep"); cerr != nil {
+		t.Fatal(cerr)
+	}
+
+	report, cerr := Clean(ctx, client, CleanOptions{IgnoreBlacklist: true})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	if !slices.Equal(report.Volumes, []string{"keep"}) || len(d.Volumes()) != 0 {
+		t.Errorf("removed volumes %v, left %v", report.Volumes, d.Volumes())
+	}
+}
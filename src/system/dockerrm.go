@@ -6,6 +6,7 @@
 package system
 
 import (
+	"context"
 	"dtools2/containers"
 	"dtools2/rest"
 	"strings"
@@ -13,18 +14,13 @@ import (
 	ce "github.com/jeanfrancoisgratton/customError/v3"
 )
 
-// RmContainers : removes all created or exited containers (not paused or running ones)
-
-func RmContainers(client *rest.Client) *ce.CustomError {
-	containers.OnlyRunningContainers = false
-	containers.ForceRemoveContainer = ForceRemove
-	containers.RemoveUnamedVolumes = RemoveUnamedVolumes
-	containers.RemoveBlacklisted = RemoveBlacklisted
+// RmContainers : removes all created or exited containers (not paused or running ones), and returns those removed
 
+func RmContainers(ctx context.Context, client *rest.Client, opts containers.RemoveOptions) ([]string, *ce.CustomError) {
 	candidates := []string{}
 
-	if cs, err := containers.ListContainers(client, false); err != nil {
-		return err
+	if cs, err := containers.ListContainers(ctx, client, containers.ListOptions{}); err != nil {
+		return nil, err
 	} else {
 		for _, c := range cs {
 			a := strings.ToLower(c.State)
@@ -33,5 +29,5 @@ func RmContainers(client *rest.Client) *ce.CustomError {
 			}
 		}
 	}
-	return containers.RemoveContainer(client, candidates)
+	return containers.RemoveContainer(ctx, client, candidates, opts)
 }
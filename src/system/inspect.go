@@ -0,0 +1,82 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/17 22:10
+// Original filename: src/system/inspect.go
+
+package system
+
+import (
+	"context"
+	"dtools2/rest"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+)
+
+// The kinds of objects Inspect() knows about; InspectAny tries them in that order, as docker inspect does.
+const (
+	InspectAny       = ""
+	InspectContainer = "container"
+	InspectImage     = "image"
+	InspectVolume    = "volume"
+	InspectNetwork   = "network"
+)
+
+var inspectKinds = []string{InspectContainer, InspectImage, InspectVolume, InspectNetwork}
+
+// Inspect returns the full inspect document of a container, image, volume or network, as sent by the daemon.
+// The document is decoded with json.Number, so that IDs, sizes and limits keep their exact value.
+func Inspect(ctx context.Context, client *rest.Client, kind, ref string) (map[string]any, *ce.CustomError) {
+	kinds := []string{kind}
+	if kind == InspectAny {
+		kinds = inspectKinds
+	} else if !slices.Contains(inspectKinds, kind) {
+		return nil, &ce.CustomError{Title: "Invalid object type", Message: "'" + kind + "' is not one of " + strings.Join(inspectKinds, ", ")}
+	}
+
+	for _, k := range kinds {
+		doc, err := inspect(ctx, client, k, ref)
+		if err == nil {
+			return doc, nil
+		}
+		if kind != InspectAny || !rest.IsNotFound(err) {
+			return nil, &ce.CustomError{Title: "Unable to inspect " + ref, Message: err.Error()}
+		}
+	}
+	return nil, &ce.CustomError{Title: "Unable to inspect " + ref, Message: "No such object: " + ref}
+}
+
+func inspect(ctx context.Context, client *rest.Client, kind, ref string) (map[string]any, error) {
+	var path string
+	switch kind {
+	case InspectContainer:
+		path = "/containers/" + ref + "/json"
+	case InspectImage:
+		path = "/images/" + ref + "/json"
+	case InspectVolume:
+		path = "/volumes/" + ref
+	default: // InspectNetwork
+		path = "/networks/" + ref
+	}
+
+	resp, err := client.Do(ctx, http.MethodGet, path, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if aerr := rest.CheckResponse(resp); aerr != nil {
+		return nil, aerr
+	}
+
+	var doc map[string]any
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
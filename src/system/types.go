@@ -5,10 +5,21 @@
 
 package system
 
-var JSONoutputfile = ""
-var ForceRemove = false
-var RemoveUnamedVolumes = true
-var RemoveBlacklisted = false
+import "dtools2/extras"
+
+// CleanOptions controls Clean().
+type CleanOptions struct {
+	Force           bool // force-remove the images and volumes
+	IgnoreBlacklist bool // remove blacklisted resources anyway
+	OnEvent         extras.EventFunc
+}
+
+// CleanReport lists what Clean() removed.
+type CleanReport struct {
+	Images   []string
+	Volumes  []string
+	Networks []string
+}
 
 type CatalogResponse struct {
 	Repositories []string `json:"repositories"`
@@ -82,10 +93,6 @@ type RegistryConfig struct {
 }
 
 // CP STRUCTS
-type dockerErrorResponse struct {
-	Message string `json:"message"`
-}
-
 // containerPathStat matches the decoded JSON inside X-Docker-Container-Path-Stat.
 // Mode uses Go's FileMode bit layout (e.g. os.ModeDir == 1<<31).
 type containerPathStat struct {
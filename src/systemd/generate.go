@@ -0,0 +1,125 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 04:15
+// Original filename: src/systemd/generate.go
+
+package systemd
+
+import (
+	"context"
+	"dtools2/rest"
+	"dtools2/run"
+	"strconv"
+	"strings"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+)
+
+// Generate inspects a container and returns the systemd files that run it: a service unit in the style of
+// `podman generate systemd --new`, or the Quadlet files with opts.Quadlet. Both are built from the same
+// arguments as `dtools runlike`, so what the container inherits from its image is left out.
+// The warnings tell what the Quadlet files need beyond Podman 4.4.
+func Generate(ctx context.Context, client *rest.Client, container string, opts GenerateOptions) ([]UnitFile, []string, *ce.CustomError) {
+	spec, ci, cerr := run.RunLikeSpec(ctx, client, container)
+	if cerr != nil {
+		return nil, nil, cerr
+	}
+	if opts.Quadlet {
+		return quadletFiles(ctx, client, spec, ci)
+	}
+	return []UnitFile{serviceUnit(spec, ci)}, nil, nil
+}
+
+// serviceUnit writes container-NAME.service; the container is created at start and removed at stop, so the
+// unit does not depend on a container that may be gone
+func serviceUnit(spec run.RunSpec, ci run.ContainerInspect) UnitFile {
+	name := strings.TrimPrefix(ci.Name, "/")
+	unitName := "container-" + name + ".service"
+	restart, burst := restartPolicy(ci)
+	timeout := stopTimeout(ci)
+
+	var b strings.Builder
+	b.WriteString("# " + unitName + "\n# Generated by dtools from the container " + name + "\n\n")
+	b.WriteString("[Unit]\nDescription=Container " + name + " (" + spec.Image + ")\n")
+	b.WriteString("Wants=network-online.target\nAfter=network-online.target\nRequiresMountsFor=%t/containers\n")
+	// A container sharing the network of another one needs it running
+	for _, f := range spec.Flags {
+		if other, ok := strings.CutPrefix(f.Value, "container:"); ok && f.Name == "--network" {
+			b.WriteString("Requires=container-" + other + ".service\nAfter=container-" + other + ".service\n")
+		}
+	}
+	if burst > 0 {
+		b.WriteString("StartLimitBurst=" + strconv.Itoa(burst) + "\n")
+	}
+
+	// One flag per line, the image and command on the last one
+	lines := []string{PodmanPath + " run", "--cidfile=%t/%n.ctr-id", "--cgroups=no-conmon", "--rm", "--sdnotify=conmon", "--replace", "-d"}
+	for _, f := range runFlags(spec) {
+		if f.Bool() {
+			lines = append(lines, f.Name)
+		} else {
+			lines = append(lines, f.Name+" "+execQuote(f.Value))
+		}
+	}
+	last := []string{execQuote(spec.Image)}
+	for _, c := range spec.Command {
+		last = append(last, execQuote(c))
+	}
+	lines = append(lines, strings.Join(last, " "))
+
+	t := strconv.Itoa(timeout)
+	b.WriteString("\n[Service]\nEnvironment=PODMAN_SYSTEMD_UNIT=%n\n")
+	b.WriteString("Restart=" + restart + "\nTimeoutStopSec=" + strconv.Itoa(timeout+60) + "\n")
+	b.WriteString("ExecStart=" + strings.Join(lines, " \\\n\t") + "\n")
+	b.WriteString("ExecStop=" + PodmanPath + " stop --ignore -t " + t + " --cidfile=%t/%n.ctr-id\n")
+	b.WriteString("ExecStopPost=" + PodmanPath + " rm -f --ignore -t " + t + " --cidfile=%t/%n.ctr-id\n")
+	b.WriteString("Type=notify\nNotifyAccess=all\n")
+	b.WriteString("\n[Install]\nWantedBy=default.target\n")
+	return UnitFile{Name: unitName, Content: b.String()}
+}
+
+// runFlags drops what systemd takes over: detaching, removal and the restart policy
+func runFlags(spec run.RunSpec) []run.RunFlag {
+	var flags []run.RunFlag
+	for _, f := range spec.Flags {
+		switch f.Name {
+		case "-d", "--rm", "--restart":
+			continue
+		}
+		flags = append(flags, f)
+	}
+	return flags
+}
+
+// restartPolicy maps the restart policy of the container on Restart=, with the StartLimitBurst= of on-failure:N
+func restartPolicy(ci run.ContainerInspect) (string, int) {
+	p := ci.HostConfig.RestartPolicy
+	if p == nil {
+		return "no", 0
+	}
+	switch p.Name {
+	case "always", "unless-stopped":
+		return "always", 0
+	case "on-failure":
+		return "on-failure", p.MaximumRetryCount
+	}
+	return "no", 0
+}
+
+// stopTimeout is the time given to the container to stop, in seconds
+func stopTimeout(ci run.ContainerInspect) int {
+	if t := ci.Config.StopTimeout; t != nil && *t >= 0 {
+		return *t
+	}
+	return 10
+}
+
+// execQuote writes a word of an Exec line: the specifiers and variables are escaped, and the word is
+// double-quoted when systemd would otherwise split it
+func execQuote(s string) string {
+	s = strings.NewReplacer("%", "%%", "$", "$$").Replace(s)
+	if s != "" && s != ";" && !strings.ContainsAny(s, " \t\n\"'\\") {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`).Replace(s) + `"`
+}
@@ -0,0 +1,65 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 04:45
+// Original filename: src/systemd/install.go
+
+package systemd
+
+import (
+	"dtools2/extras"
+	"os"
+	"path/filepath"
+	"strings"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+)
+
+// UserUnitDir is where systemd reads the units of the user: ~/.config/systemd/user
+func UserUnitDir() (string, *ce.CustomError) {
+	return configDir("systemd", "user")
+}
+
+// QuadletDir is where Quadlet reads the files of the user: ~/.config/containers/systemd
+func QuadletDir() (string, *ce.CustomError) {
+	return configDir("containers", "systemd")
+}
+
+func configDir(elem ...string) (string, *ce.CustomError) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", &ce.CustomError{Title: "Unable to find the configuration directory", Message: err.Error()}
+	}
+	return filepath.Join(append([]string{base}, elem...)...), nil
+}
+
+// Install writes the files in opts.Dir, created if needed, and returns their paths. Without a directory,
+// service units go to UserUnitDir() and Quadlet files to QuadletDir(), as systemd would not read them there.
+// Existing files are replaced. systemd still has to be told with `systemctl --user daemon-reload`.
+func Install(files []UnitFile, opts InstallOptions) ([]string, *ce.CustomError) {
+	var paths []string
+	for _, f := range files {
+		dir := opts.Dir
+		if dir == "" {
+			var cerr *ce.CustomError
+			if strings.HasSuffix(f.Name, ".service") {
+				dir, cerr = UserUnitDir()
+			} else {
+				dir, cerr = QuadletDir()
+			}
+			if cerr != nil {
+				return paths, cerr
+			}
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return paths, &ce.CustomError{Title: "Unable to create " + dir, Message: err.Error()}
+		}
+
+		path := filepath.Join(dir, f.Name)
+		if err := os.WriteFile(path, []byte(f.Content), 0644); err != nil {
+			return paths, &ce.CustomError{Title: "Unable to write " + path, Message: err.Error()}
+		}
+		paths = append(paths, path)
+		opts.OnEvent.Emit(extras.Event{Resource: "file", Name: path, Action: extras.EventCreated})
+	}
+	return paths, nil
+}
@@ -0,0 +1,224 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 04:30
+// Original filename: src/systemd/quadlet.go
+
+package systemd
+
+import (
+	"context"
+	"dtools2/extras"
+	"dtools2/networks"
+	"dtools2/rest"
+	"dtools2/run"
+	"dtools2/volumes"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	ce "github.com/jeanfrancoisgratton/customError/v3"
+)
+
+// quadletKeys are the run flags with a key of their own in the [Container] section of Podman 4.4
+var quadletKeys = map[string]string{
+	"--name":                "ContainerName",
+	"--env":                 "Environment",
+	"--label":               "Label",
+	"--publish":             "PublishPort",
+	"--ip":                  "IP",
+	"--ip6":                 "IP6",
+	"--cap-add":             "AddCapability",
+	"--cap-drop":            "DropCapability",
+	"--device":              "AddDevice",
+	"--tmpfs":               "Tmpfs",
+	"--health-interval":     "HealthInterval",
+	"--health-timeout":      "HealthTimeout",
+	"--health-start-period": "HealthStartPeriod",
+	"--health-retries":      "HealthRetries",
+}
+
+// quadletFiles writes NAME.container, plus a .network file per network; Quadlet prefixes the names of the
+// networks it creates with "systemd-". The named volumes are used as they are, so that the data stays.
+// What has no key in Podman 4.4 goes to PodmanArgs=, which needs Podman 4.5: it comes back in the warnings.
+func quadletFiles(ctx context.Context, client *rest.Client, spec run.RunSpec, ci run.ContainerInspect) ([]UnitFile, []string, *ce.CustomError) {
+	name := strings.TrimPrefix(ci.Name, "/")
+	restart, burst := restartPolicy(ci)
+
+	var keys []string // key=value lines of the [Container] section
+	var podmanArgs, warnings []string
+	var extra []UnitFile
+	key := func(k, v string) { keys = append(keys, k+"="+execQuote(v)) }
+	// The value of HealthCmd= is taken whole, quotes included
+	raw := func(k, v string) { keys = append(keys, k+"="+strings.ReplaceAll(v, "%", "%%")) }
+	podmanArg := func(f run.RunFlag) {
+		podmanArgs = append(podmanArgs, f.Name)
+		if !f.Bool() {
+			podmanArgs = append(podmanArgs, execQuote(f.Value))
+		}
+	}
+
+	key("Image", spec.Image)
+	for _, f := range runFlags(spec) {
+		if k, ok := quadletKeys[f.Name]; ok {
+			key(k, f.Value)
+			continue
+		}
+		switch {
+		case f.Name == "--read-only":
+			key("ReadOnly", "true")
+		case f.Name == "--user":
+			user, group, _ := strings.Cut(f.Value, ":")
+			key("User", user)
+			if group != "" {
+				key("Group", group)
+			}
+		case f.Name == "--health-cmd":
+			raw("HealthCmd", f.Value)
+		case f.Name == "--no-healthcheck":
+			key("HealthCmd", "none")
+		case f.Name == "--mount" && strings.HasPrefix(f.Value, "type=tmpfs,dst="):
+			key("Tmpfs", strings.TrimPrefix(f.Value, "type=tmpfs,dst="))
+		case f.Name == "--security-opt" && slices.Contains([]string{"no-new-privileges", "no-new-privileges=true", "no-new-privileges:true"}, f.Value):
+			key("NoNewPrivileges", "true")
+		case f.Name == "--security-opt" && f.Value == "label=disable":
+			key("SecurityLabelDisable", "true")
+		case f.Name == "--security-opt" && strings.HasPrefix(f.Value, "seccomp="):
+			key("SeccompProfile", strings.TrimPrefix(f.Value, "seccomp="))
+		case f.Name == "--volume":
+			// A NAME.volume file would have Quadlet create an empty systemd-NAME volume: the volume is
+			// referenced by its name instead, and podman creates it if it is not there yet
+			src, _, found := strings.Cut(f.Value, ":")
+			if found && !strings.HasPrefix(src, "/") {
+				w, cerr := volumeWarning(ctx, client, src)
+				if cerr != nil {
+					return nil, nil, cerr
+				}
+				if w != "" {
+					warnings = append(warnings, w)
+				}
+			}
+			key("Volume", f.Value)
+		case f.Name == "--network" && f.Value == "bridge":
+			// The default network of docker; podman has its own
+		case f.Name == "--network" && !isNetworkMode(f.Value):
+			nf, cerr := networkFile(ctx, client, f.Value)
+			if cerr != nil {
+				return nil, nil, cerr
+			}
+			extra = appendOnce(extra, nf)
+			key("Network", nf.Name)
+		case f.Name == "--network":
+			key("Network", f.Value)
+		default:
+			podmanArg(f)
+		}
+	}
+	if t := ci.Config.StopTimeout; t != nil && *t != 10 {
+		podmanArg(run.RunFlag{Name: "--stop-timeout", Value: strconv.Itoa(*t)})
+	}
+	if len(podmanArgs) > 0 {
+		keys = append(keys, "PodmanArgs="+strings.Join(podmanArgs, " "))
+		warnings = append(warnings, "PodmanArgs="+strings.Join(podmanArgs, " ")+" needs Podman 4.5; the Quadlet of Podman 4.4 rejects the key")
+	}
+	if len(spec.Command) > 0 {
+		words := make([]string, 0, len(spec.Command))
+		for _, c := range spec.Command {
+			words = append(words, execQuote(c))
+		}
+		keys = append(keys, "Exec="+strings.Join(words, " "))
+	}
+
+	var b strings.Builder
+	b.WriteString("# " + name + ".container\n# Generated by dtools from the container " + name + "\n\n")
+	b.WriteString("[Unit]\nDescription=Container " + name + " (" + spec.Image + ")\n")
+	b.WriteString("Wants=network-online.target\nAfter=network-online.target\n")
+	if burst > 0 {
+		b.WriteString("StartLimitBurst=" + strconv.Itoa(burst) + "\n")
+	}
+	b.WriteString("\n[Container]\n" + strings.Join(keys, "\n") + "\n")
+	b.WriteString("\n[Service]\nRestart=" + restart + "\nTimeoutStopSec=" + strconv.Itoa(stopTimeout(ci)+60) + "\n")
+	b.WriteString("\n[Install]\nWantedBy=default.target\n")
+
+	return append([]UnitFile{{Name: name + ".container", Content: b.String()}}, extra...), warnings, nil
+}
+
+// isNetworkMode tells the --network values that are not networks: host, none, container:, ns:...
+func isNetworkMode(v string) bool {
+	return v == "host" || v == "none" || v == "private" || strings.Contains(v, ":")
+}
+
+func appendOnce(files []UnitFile, f UnitFile) []UnitFile {
+	if slices.ContainsFunc(files, func(u UnitFile) bool { return u.Name == f.Name }) {
+		return files
+	}
+	return append(files, f)
+}
+
+// volumeWarning tells what podman would not get right when it creates the volume itself: the driver and
+// its options, and the labels. Nothing is said when the volume needs none of them.
+func volumeWarning(ctx context.Context, client *rest.Client, name string) (string, *ce.CustomError) {
+	vols, cerr := volumes.ListVolumes(ctx, client, volumes.ListOptions{Filters: extras.Filters{"name": {name}}})
+	if cerr != nil {
+		return "", cerr
+	}
+	i := slices.IndexFunc(vols, func(v volumes.Volume) bool { return v.Name == name })
+	if i < 0 {
+		return "", &ce.CustomError{Title: "Unable to inspect volume", Message: "volume " + name + " not found"}
+	}
+	v := vols[i]
+
+	args := []string{PodmanPath, "volume", "create"}
+	if v.Driver != "" && v.Driver != "local" {
+		args = append(args, "--driver", v.Driver)
+	}
+	for _, k := range slices.Sorted(maps.Keys(v.Options)) {
+		args = append(args, "--opt", execQuote(k+"="+v.Options[k]))
+	}
+	for _, k := range slices.Sorted(maps.Keys(v.Labels)) {
+		args = append(args, "--label", execQuote(k+"="+v.Labels[k]))
+	}
+	if len(args) == 3 {
+		return "", nil
+	}
+	return "volume " + name + " must exist before the container starts: " + strings.Join(append(args, name), " "), nil
+}
+
+// networkFile writes NAME.network from the network on the daemon
+func networkFile(ctx context.Context, client *rest.Client, name string) (UnitFile, *ce.CustomError) {
+	nets, cerr := networks.NetworkList(ctx, client, networks.ListOptions{Filters: extras.Filters{"name": {name}}})
+	if cerr != nil {
+		return UnitFile{}, cerr
+	}
+	i := slices.IndexFunc(nets, func(n networks.NetworkSummary) bool { return n.Name == name })
+	if i < 0 {
+		return UnitFile{}, &ce.CustomError{Title: "Unable to inspect network", Message: "network " + name + " not found"}
+	}
+	n := nets[i]
+
+	var b strings.Builder
+	b.WriteString("# " + name + ".network\n# Generated by dtools from the network " + name + "\n\n[Network]\n")
+	if n.Driver != "" && n.Driver != "bridge" {
+		b.WriteString("Driver=" + n.Driver + "\n")
+	}
+	if n.Internal {
+		b.WriteString("Internal=true\n")
+	}
+	if n.EnableIPv6 {
+		b.WriteString("IPv6=true\n")
+	}
+	for _, c := range n.IPAM.Config {
+		for _, kv := range [][2]string{{"Subnet", c.Subnet}, {"Gateway", c.Gateway}, {"IPRange", c.IPRange}} {
+			if kv[1] != "" {
+				b.WriteString(kv[0] + "=" + kv[1] + "\n")
+			}
+		}
+	}
+	for _, k := range slices.Sorted(maps.Keys(n.Options)) {
+		b.WriteString("Options=" + execQuote(k+"="+n.Options[k]) + "\n")
+	}
+	for _, k := range slices.Sorted(maps.Keys(n.Labels)) {
+		b.WriteString("Label=" + execQuote(k+"="+n.Labels[k]) + "\n")
+	}
+	return UnitFile{Name: name + ".network", Content: b.String()}, nil
+}
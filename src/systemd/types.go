@@ -0,0 +1,28 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 04:10
+// Original filename: src/systemd/types.go
+
+package systemd
+
+import "dtools2/extras"
+
+// PodmanPath is the podman binary the generated units run
+const PodmanPath = "/usr/bin/podman"
+
+// GenerateOptions controls Generate()
+type GenerateOptions struct {
+	Quadlet bool // --quadlet: .container/.network files for Podman 4.4+, instead of a service unit
+}
+
+// InstallOptions controls Install()
+type InstallOptions struct {
+	Dir     string // where to write; UserUnitDir() or QuadletDir() when empty, depending on the files
+	OnEvent extras.EventFunc
+}
+
+// UnitFile is a generated file: Name is its file name (container-web.service, web.container...)
+type UnitFile struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
@@ -7,18 +7,20 @@ package volumes
 
 import (
 	"bytes"
+	"context"
 	"dtools2/rest"
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"net/url"
 
 	ce "github.com/jeanfrancoisgratton/customError/v3"
-	hftx "github.com/jeanfrancoisgratton/helperFunctions/v4/terminalfx"
 )
 
-func CreateVolume(client *rest.Client, volumeName string) *ce.CustomError {
-	vco := &VolumeCreateOptions{Name: volumeName, Driver: CreateVolDriver}
+// CreateVolume creates a volume; an empty Driver means "local"
+func CreateVolume(ctx context.Context, client *rest.Client, vco VolumeCreateOptions) *ce.CustomError {
+	if vco.Driver == "" {
+		vco.Driver = "local"
+	}
 
 	payload, jerr := json.MarshalIndent(vco, "", "  ")
 	if jerr != nil {
@@ -28,19 +30,15 @@ func CreateVolume(client *rest.Client, volumeName string) *ce.CustomError {
 	headers := http.Header{}
 	headers.Set("Content-Type", "application/json")
 
-	resp, err := client.Do(rest.Context, http.MethodPost, "/volumes/create",
+	resp, err := client.Do(ctx, http.MethodPost, "/volumes/create",
 		url.Values{}, bytes.NewReader(payload), headers)
 	if err != nil {
 		return &ce.CustomError{Title: "Unable to create the volume", Message: err.Error()}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusCreated {
-		return &ce.CustomError{Title: "HTTP request returned an error", Message: resp.Status}
-	}
-
-	if !rest.QuietOutput {
-		fmt.Println(hftx.GreenGoSign("Volume " + hftx.Green(volumeName) + " created"))
+	if aerr := rest.CheckResponse(resp); aerr != nil {
+		return &ce.CustomError{Title: "Unable to create the volume", Message: aerr.Error()}
 	}
 	return nil
 }
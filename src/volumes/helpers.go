@@ -10,28 +10,8 @@ import (
 	"fmt"
 	"sort"
 	"strings"
-	"time"
 )
 
-func formatCreated(created string) string {
-	created = strings.TrimSpace(created)
-	if created == "" {
-		return ""
-	}
-
-	// Docker/Podman commonly return RFC3339 timestamps; sometimes with fractional seconds.
-	tm, err := time.Parse(time.RFC3339Nano, created)
-	if err != nil {
-		tm, err = time.Parse(time.RFC3339, created)
-		if err != nil {
-			// If parsing fails, keep original to avoid losing information.
-			return created
-		}
-	}
-
-	return tm.Format("2006.01.02 15:04:05")
-}
-
 func containerDisplayName(c containers.ContainerSummary) string {
 	name := ""
 	if len(c.Names) > 0 {
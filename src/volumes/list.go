@@ -8,35 +8,29 @@ package volumes
 
 import (
 	"bytes"
+	"context"
 	"dtools2/containers"
 	"dtools2/extras"
 	"dtools2/rest"
 	"encoding/json"
-	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"os"
 	"strings"
 
 	ce "github.com/jeanfrancoisgratton/customError/v3"
-	hfjson "github.com/jeanfrancoisgratton/helperFunctions/v4/prettyjson"
-	"github.com/jedib0t/go-pretty/v6/table"
-	"github.com/jedib0t/go-pretty/v6/text"
 )
 
-func ListVolumes(client *rest.Client, displayOutput bool) ([]Volume, *ce.CustomError) {
+// ListVolumes returns the volumes, along with the containers using them (see cmd/volumesOutput.go for the rendering)
+func ListVolumes(ctx context.Context, client *rest.Client, opts ListOptions) ([]Volume, *ce.CustomError) {
 	// 1) Fetch volumes
-	vols, verr := fetchVolumeList(client)
+	vols, verr := fetchVolumeList(ctx, client, opts.Filters)
 	if verr != nil {
 		return nil, verr
 	}
 
 	// 2) Fetch containers (include stopped; they still reference volumes)
-	oldOnlyRunning := containers.OnlyRunningContainers
-	containers.OnlyRunningContainers = false
-	cs, cerr := containers.ListContainers(client, false)
-	containers.OnlyRunningContainers = oldOnlyRunning
+	cs, cerr := containers.ListContainers(ctx, client, containers.ListOptions{})
 	if cerr != nil {
 		return nil, &ce.CustomError{Title: cerr.Title, Message: cerr.Message}
 	}
@@ -52,79 +46,6 @@ func ListVolumes(client *rest.Client, displayOutput bool) ([]Volume, *ce.CustomE
 		vols[i].RefCount = len(users)
 	}
 
-	// 5) Render output if displayOutput is set
-	if !displayOutput {
-		return vols, nil
-	}
-
-	// Optional: write JSON payload to a file and/or render JSON to stdout.
-	// This is only done when displayOutput is true (i.e., list commands).
-	var payloadBytes []byte
-	if extras.OutputFile != "" {
-		b, cerr := extras.Send2File(vols, extras.OutputFile)
-		if cerr != nil {
-			return nil, cerr
-		}
-		payloadBytes = b
-	}
-
-	// --format takes precedence over --json, tables and ignores --quiet.
-	if extras.OutputFormat != "" {
-		rows, cerr := extras.ExtractFormatRows(vols, extras.OutputFormat)
-		if cerr != nil {
-			return nil, cerr
-		}
-		if cerr := extras.PrintFormatRows(rows); cerr != nil {
-			return nil, cerr
-		}
-		return vols, nil
-	}
-
-	if extras.OutputJSON {
-		// Marshal once if we didn't already (for --file).
-		if payloadBytes == nil {
-			b, cerr := extras.MarshalJSON(vols)
-			if cerr != nil {
-				return nil, cerr
-			}
-			payloadBytes = b
-		}
-
-		hfjson.Print(payloadBytes)
-		return vols, nil
-	}
-
-	// JSON output not requested; if quiet, return data only.
-	if rest.QuietOutput {
-		return vols, nil
-	}
-	t := table.NewWriter()
-	t.SetOutputMirror(os.Stdout)
-	t.AppendHeader(table.Row{"Name", "Driver", "Scope", "Created", "RefCount", "Used by"})
-
-	if len(vols) == 0 {
-		t.AppendRow(table.Row{"", "", "", "", "", ""})
-	} else {
-		for _, v := range vols {
-			t.AppendRow(table.Row{v.Name, v.Driver, v.Scope, formatCreated(v.CreatedAt), v.RefCount, v.UsedByStr})
-		}
-	}
-
-	t.SortBy([]table.SortBy{{Name: "Name", Mode: table.Asc}})
-	t.SetStyle(table.StyleBold)
-	t.Style().Format.Header = text.FormatDefault
-
-	// Paint rows where the volume is used by at least one container.
-	t.SetRowPainter(func(row table.Row) text.Colors {
-		// Used by column is now index 5
-		if s, ok := row[5].(string); ok && s != "" {
-			return text.Colors{text.FgHiGreen}
-		}
-		return nil
-	})
-
-	t.Render()
-	fmt.Println()
 	return vols, nil
 }
 
@@ -135,15 +56,17 @@ func ListVolumes(client *rest.Client, displayOutput bool) ([]Volume, *ce.CustomE
 //	{"Volumes":[...],"Warnings":[...]}
 //
 // Some implementations (or older endpoints) may return a JSON array.
-func fetchVolumeList(client *rest.Client) ([]Volume, *ce.CustomError) {
-	resp, err := client.Do(rest.Context, http.MethodGet, "/volumes", url.Values{}, nil, nil)
+func fetchVolumeList(ctx context.Context, client *rest.Client, filters extras.Filters) ([]Volume, *ce.CustomError) {
+	q := url.Values{}
+	filters.AddTo(q)
+	resp, err := client.Do(ctx, http.MethodGet, "/volumes", q, nil, nil)
 	if err != nil {
 		return nil, &ce.CustomError{Title: "Unable to list volumes", Message: err.Error()}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		return nil, &ce.CustomError{Title: "http request returned an error", Message: "GET /volumes returned " + resp.Status}
+	if aerr := rest.CheckResponse(resp); aerr != nil {
+		return nil, &ce.CustomError{Title: "Unable to list volumes", Message: aerr.Error()}
 	}
 	if resp.StatusCode == http.StatusNoContent {
 		return []Volume{}, nil
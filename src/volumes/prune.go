@@ -6,14 +6,13 @@
 package volumes
 
 import (
+	"context"
 	"dtools2/rest"
-	"fmt"
 
 	ce "github.com/jeanfrancoisgratton/customError/v3"
-	hftx "github.com/jeanfrancoisgratton/helperFunctions/v4/terminalfx"
 )
 
-// PruneVolumes removes all unused volumes
+// PruneVolumes removes all unused volumes, and returns those actually removed
 // IMPORTANT NOTE:
 //
 // There is a REST API endppoint, POST /volumes/prune, but I AM NOT USING IT.
@@ -21,36 +20,22 @@ import (
 // to implement my own prune function
 // This is one of the few places where I somehow bypass the REST API.
 // I still use the volume removal endpoint here, though : DELETE /volumes/{name}, tru RemoveVolumes()
-func PruneVolumes(client *rest.Client) *ce.CustomError {
-	var volumes []Volume
-	var err *ce.CustomError
+func PruneVolumes(ctx context.Context, client *rest.Client, opts PruneOptions) ([]string, *ce.CustomError) {
 	var candidates []string
 
-	if volumes, err = ListVolumes(client, false); err != nil {
-		return err
+	volumes, err := ListVolumes(ctx, client, ListOptions{})
+	if err != nil {
+		return nil, err
 	}
 
 	for _, v := range volumes {
 		if v.Scope == "local" && v.Driver == "local" && v.RefCount == 0 {
 			_, isAnon := v.Labels["com.docker.volume.anonymous"]
-			if !isAnon {
-				if RemoveNamedVolumes {
-					candidates = append(candidates, v.Name)
-				}
-			} else {
+			if isAnon || opts.All {
 				candidates = append(candidates, v.Name)
 			}
 		}
 	}
-	rq := rest.QuietOutput
-	rest.QuietOutput = true
-	if err = RemoveVolumes(client, candidates); err != nil {
-		return err
-	}
-	rest.QuietOutput = rq
 
-	if !rest.QuietOutput {
-		fmt.Println(hftx.EnabledSign("Volumes pruned"))
-	}
-	return nil
+	return RemoveVolumes(ctx, client, candidates, RemoveOptions{Force: opts.Force, IgnoreBlacklist: opts.IgnoreBlacklist, OnEvent: opts.OnEvent})
 }
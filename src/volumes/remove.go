@@ -6,64 +6,62 @@
 package volumes
 
 import (
+	"context"
 	"dtools2/blacklist"
+	"dtools2/extras"
 	"dtools2/rest"
-	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
 
 	ce "github.com/jeanfrancoisgratton/customError/v3"
-	hftx "github.com/jeanfrancoisgratton/helperFunctions/v4/terminalfx"
 )
 
-func RemoveVolumes(client *rest.Client, volList []string) *ce.CustomError {
+// RemoveVolumes removes the volumes, and returns those actually removed.
+// Blacklisted volumes are skipped, unless opts.IgnoreBlacklist is set.
+func RemoveVolumes(ctx context.Context, client *rest.Client, volList []string, opts RemoveOptions) ([]string, *ce.CustomError) {
+	var removed []string
+
 	for _, vol := range volList {
 		isBL, err := blacklist.IsResourceBlackListed("volumes", vol)
 		if err != nil {
-			return err
+			return removed, err
 		}
 		if isBL {
-			if !rest.QuietOutput {
-				fmt.Println(hftx.WarningSign(" Volume " + vol + " is blacklisted"))
-			}
-			if RemoveBlackListed {
-				if !rest.QuietOutput {
-					fmt.Println(hftx.InfoSign("Force removal flag is present, continuing"))
-					if err := removeVol(client, vol); err != nil {
-						return err
-					}
-				}
-			} else {
-				if !rest.QuietOutput {
-					fmt.Println(hftx.InfoSign("Removal flag is absent, skipping volume"))
-				}
-			}
-		} else {
-			if err := removeVol(client, vol); err != nil {
-				return err
+			if !opts.IgnoreBlacklist {
+				opts.OnEvent.Emit(extras.Event{Resource: "volume", Name: vol, Action: extras.EventSkipped, Message: "blacklisted"})
+				continue
 			}
+			opts.OnEvent.Emit(extras.Event{Resource: "volume", Name: vol, Action: extras.EventBlacklisted})
+		}
+		if err := removeVol(ctx, client, vol, opts); err != nil {
+			return removed, err
 		}
+		removed = append(removed, vol)
 	}
-	return nil
+	return removed, nil
 }
 
-func removeVol(client *rest.Client, volumeName string) *ce.CustomError {
+func removeVol(ctx context.Context, client *rest.Client, volumeName string, opts RemoveOptions) *ce.CustomError {
 	q := url.Values{}
-	q.Set("force", strconv.FormatBool(ForceRemove))
+	q.Set("force", strconv.FormatBool(opts.Force))
 
 	path := "/volumes/" + volumeName
-	resp, derr := client.Do(rest.Context, http.MethodDelete, path, q, nil, nil)
+	resp, derr := client.Do(ctx, http.MethodDelete, path, q, nil, nil)
 	if derr != nil {
 		return &ce.CustomError{Title: "Unable to post DELETE", Message: derr.Error()}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusNoContent {
-		return &ce.CustomError{Title: "Error removing the volume", Message: "http request returned " + resp.Status}
-	}
-	if !rest.QuietOutput {
-		fmt.Println(hftx.InProgressSign("Volume " + volumeName + hftx.Red(" REMOVED")))
+	if aerr := rest.CheckResponse(resp); aerr != nil {
+		switch {
+		case rest.IsNotFound(aerr):
+			return &ce.CustomError{Title: "Error removing the volume", Message: "The volume " + volumeName + " is not found"}
+		case rest.IsInUse(aerr):
+			return &ce.CustomError{Title: "Error removing the volume", Message: "The volume " + volumeName + " is in use (" + aerr.Error() + ")"}
+		}
+		return &ce.CustomError{Title: "Error removing the volume", Message: aerr.Error()}
 	}
+	opts.OnEvent.Emit(extras.Event{Resource: "volume", Name: volumeName, Action: extras.EventRemoved})
 	return nil
 }
@@ -0,0 +1,88 @@
+// dtools2
+// Written by J.F. Gratton <jean-francois@famillegratton.net>
+// Original timestamp: 2026/10/18 07:40
+// Original filename: src/volumes/remove_test.go
+
+package volumes
+
+import (
+	"context"
+	"dtools2/blacklist"
+	"dtools2/rest/fakedaemon"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func volumeNames(d *fakedaemon.Daemon) []string {
+	var names []string
+	for _, v := range d.Volumes() {
+		names = append(names, v.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestRemoveVolumes(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // the blacklist file lives there
+	d, client := fakedaemon.StartT(t)
+	ctx := context.Background()
+	d.AddVolume(fakedaemon.Volume{Name: "cache"})
+	d.AddVolume(fakedaemon.Volume{Name: "data"})
+
+	removed, cerr := RemoveVolumes(ctx, client, []string{"cache"}, RemoveOptions{})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	if !slices.Equal(removed, []string{"cache"}) || !slices.Equal(volumeNames(d), []string{"data"}) {
+		t.Errorf("removed %v, left %v", removed, volumeNames(d))
+	}
+}
+
+func TestRemoveVolumesMissing(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // the blacklist file lives there
+	d, client := fakedaemon.StartT(t)
+	ctx := context.Background()
+
+	_, cerr := RemoveVolumes(ctx, client, []string{"nope"}, RemoveOptions{})
+	if cerr == nil || !strings.Contains(cerr.Message, "not found") {
+		t.Fatalf("got %v, want a not found error", cerr)
+	}
+	if !slices.Contains(d.Requests(), "DELETE /volumes/nope") {
+		t.Errorf("the daemon was not asked: %v", d.Requests())
+	}
+
+	// Forced, the daemon ignores the missing volume
+	if _, cerr := RemoveVolumes(ctx, client, []string{"nope"}, RemoveOptions{Force: true}); cerr != nil {
+		t.Errorf("forced removal: %v", cerr)
+	}
+}
+
+func TestRemoveVolumesBlacklist(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // the blacklist file lives there
+	d, client := fakedaemon.StartT(t)
+	ctx := context.Background()
+	d.AddVolume(fakedaemon.Volume{Name: "keep"})
+	d.AddVolume(fakedaemon.Volume{Name: "free"})
+	if cerr := blacklist.AddToFile("volumes", "keep"); cerr != nil {
+		t.Fatal(cerr)
+	}
+
+	removed, cerr := RemoveVolumes(ctx, client, []string{"keep", "free"}, RemoveOptions{})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	if !slices.Equal(removed, []string{"free"}) || !slices.Equal(volumeNames(d), []string{"keep"}) {
+		t.Errorf("removed %v, left %v", removed, volumeNames(d))
+	}
+	if slices.Contains(d.Requests(), "DELETE /volumes/keep") {
+		t.Error("the blacklisted volume reached the daemon")
+	}
+
+	if removed, cerr = RemoveVolumes(ctx, client, []string{"keep"}, RemoveOptions{IgnoreBlacklist: true}); cerr != nil {
+		t.Fatal(cerr)
+	}
+	if !slices.Equal(removed, []string{"keep"}) || len(volumeNames(d)) != 0 {
+		t.Errorf("with IgnoreBlacklist: removed %v, left %v", removed, volumeNames(d))
+	}
+}
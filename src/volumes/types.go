@@ -5,10 +5,27 @@
 
 package volumes
 
-var RemoveBlackListed = false
-var ForceRemove = false
-var RemoveNamedVolumes = false
-var CreateVolDriver = "local"
+import "dtools2/extras"
+
+// ListOptions controls ListVolumes().
+type ListOptions struct {
+	Filters extras.Filters // applied to the volumes, not to the containers looked up for the usage
+}
+
+// RemoveOptions controls RemoveVolumes().
+type RemoveOptions struct {
+	Force           bool // force-remove the volume
+	IgnoreBlacklist bool // remove blacklisted volumes anyway
+	OnEvent         extras.EventFunc
+}
+
+// PruneOptions controls PruneVolumes().
+type PruneOptions struct {
+	All             bool // named volumes too, not only the anonymous ones
+	Force           bool // force-remove the volumes
+	IgnoreBlacklist bool // prune blacklisted volumes anyway
+	OnEvent         extras.EventFunc
+}
 
 type VolumeListResponse struct {
 	Volumes  []Volume `json:"Volumes"`